@@ -0,0 +1,57 @@
+package backup
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/cmd"
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+var backupKeep int
+
+// backupCmd 在线备份数据库
+// 嘿嘿~ 使用 VACUUM INTO 生成一致快照，MCP 服务运行中也能安全备份！💾
+var backupCmd = &cobra.Command{
+	Use:   "backup [dest]",
+	Short: "在线备份数据库",
+	Long: `使用 SQLite 的 VACUUM INTO 生成一致的数据库快照，无需停止 MCP 服务~ ✨
+
+省略 dest 时备份到 ~/.llm-memory/backups（可在配置 backup.dir 中修改），
+并按配置 backup.keep 轮转；dest 为目录时在其中按时间命名
+
+示例：
+  llm-memory backup
+  llm-memory backup /mnt/nas/llm-memory/ --keep 7
+  llm-memory backup ./snapshot.db`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		dest := ""
+		if len(args) > 0 {
+			dest = args[0]
+		}
+		keep := backupKeep
+		if dest == "" && !c.Flags().Changed("keep") {
+			keep = bs.Config().Backup.Keep
+		}
+
+		handler := handlers.NewBackupHandler(bs)
+		if err := handler.Backup(bs.Context(), dest, keep); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	backupCmd.Flags().IntVar(&backupKeep, "keep", 0, "只保留最新的 N 份自动命名的备份（0 表示不轮转）")
+
+	cmd.RootCmd.AddCommand(backupCmd)
+}
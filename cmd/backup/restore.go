@@ -0,0 +1,39 @@
+package backup
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/cmd"
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+// restoreCmd 从备份恢复数据库
+var restoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "从备份恢复数据库",
+	Long: `用备份文件替换当前数据库~ ⚠️
+
+恢复前会检查备份的完整性（PRAGMA integrity_check）和结构版本，
+并把当前数据库备份为 pre-restore-*.db；MCP 服务、TUI 等其他进程仍在使用数据库时拒绝恢复`,
+	Args: cobra.ExactArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+			startup.WithAutoMigrate(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewBackupHandler(bs)
+		if err := handler.Restore(bs.Context(), args[0]); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(restoreCmd)
+}
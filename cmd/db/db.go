@@ -0,0 +1,34 @@
+package db
+
+import (
+	"github.com/XiaoLFeng/llm-memory/cmd"
+	"github.com/spf13/cobra"
+)
+
+// dbCmd 是 db 父命令
+// 嘿嘿~ 数据库维护命令组！🗄️
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "数据库维护命令",
+	Long: `维护 LLM-Memory 的数据库结构，包括迁移、查看状态、回滚和一致性检查~ ✨
+
+示例：
+  # 执行所有未执行的迁移
+  llm-memory db migrate
+
+  # 查看迁移状态
+  llm-memory db status
+
+  # 回滚最近一次迁移
+  llm-memory db rollback
+
+  # 检查并修复数据一致性
+  llm-memory db check --fix`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(dbCmd)
+}
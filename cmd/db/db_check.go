@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+var checkFix bool
+
+// dbCheckCmd 检查数据库完整性和一致性
+var dbCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "检查数据库完整性和一致性",
+	Long: `检查数据库完整性（SQLite integrity_check / foreign_key_check）和数据一致性~ 🩺
+
+检查项包括：孤儿标签、语义向量和修订历史、计划不存在的待办、
+路径与计划不一致的待办、指向不存在的组或路径的组映射、全文索引是否同步等。
+发现问题时以非零状态退出。
+
+使用 --fix 在一个事务中修复可自动修复的问题（建议先执行 backup）。
+
+示例：
+  llm-memory db check
+  llm-memory db check --fix`,
+	Run: func(cmd *cobra.Command, args []string) {
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+			startup.WithAutoMigrate(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewDBHandler(bs)
+		if err := handler.Check(bs.Context(), checkFix); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	dbCheckCmd.Flags().BoolVar(&checkFix, "fix", false, "修复可自动修复的问题")
+
+	dbCmd.AddCommand(dbCheckCmd)
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+// dbMigrateCmd 执行数据库迁移
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "执行数据库迁移",
+	Long:  `按版本顺序执行所有未执行的数据库迁移~ 🚀`,
+	Run: func(cmd *cobra.Command, args []string) {
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+			startup.WithAutoMigrate(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewDBHandler(bs)
+		if err := handler.Migrate(bs.Context()); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbMigrateCmd)
+}
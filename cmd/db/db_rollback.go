@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"os"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+var rollbackSteps int
+
+// dbRollbackCmd 回滚数据库迁移
+var dbRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "回滚数据库迁移",
+	Long: `回滚最近执行的数据库迁移（默认 1 个）~ ⏪
+
+注意：回滚可能删除表或列中的数据，请先备份！
+
+示例：
+  llm-memory db rollback
+  llm-memory db rollback --steps 2`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if rollbackSteps < 1 {
+			cli.PrintError("回滚步数必须大于 0")
+			os.Exit(1)
+		}
+
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+			startup.WithAutoMigrate(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewDBHandler(bs)
+		if err := handler.Rollback(bs.Context(), rollbackSteps); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	dbRollbackCmd.Flags().IntVarP(&rollbackSteps, "steps", "n", 1, "回滚的迁移数量")
+
+	dbCmd.AddCommand(dbRollbackCmd)
+}
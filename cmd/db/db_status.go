@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+// dbStatusCmd 查看迁移状态
+var dbStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "查看数据库迁移状态",
+	Long:  `列出所有迁移及其执行状态，并显示数据库版本~ 📊`,
+	Run: func(cmd *cobra.Command, args []string) {
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+			startup.WithAutoMigrate(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewDBHandler(bs)
+		if err := handler.Status(bs.Context()); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbStatusCmd)
+}
@@ -0,0 +1,34 @@
+package encrypt
+
+import (
+	"github.com/XiaoLFeng/llm-memory/cmd"
+	"github.com/spf13/cobra"
+)
+
+// encryptCmd 是 encrypt 父命令
+// 嘿嘿~ 记忆里的敏感内容加密落盘，data.db 被拷走也不怕！🔐
+var encryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "静态数据加密管理命令",
+	Long: `启用或轮换静态数据加密~ ✨
+
+启用后记忆内容、修订历史内容、计划内容和待办描述使用 AES-256-GCM 加密存储，
+密钥来自密钥文件或环境变量 LLM_MEMORY_PASSPHRASE 中的口令（PBKDF2 派生）
+
+示例：
+  # 使用口令启用
+  LLM_MEMORY_PASSPHRASE=... llm-memory encrypt enable
+
+  # 使用密钥文件启用（文件不存在时自动生成）
+  llm-memory encrypt enable --key-file ~/.llm-memory/key
+
+  # 轮换为新口令
+  LLM_MEMORY_PASSPHRASE=旧口令 LLM_MEMORY_NEW_PASSPHRASE=新口令 llm-memory encrypt rotate-key`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(encryptCmd)
+}
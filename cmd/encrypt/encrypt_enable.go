@@ -0,0 +1,42 @@
+package encrypt
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+var enableKeyFile string
+
+// encryptEnableCmd 启用静态加密
+var encryptEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "启用静态加密并加密已有数据",
+	Long: `启用静态加密，并在一个事务中加密已有的记忆、计划和待办内容~ 🔐
+
+不指定 --key-file 时使用环境变量 LLM_MEMORY_PASSPHRASE 中的口令，
+配置文件只保存盐和校验值，口令和密钥不会写入配置
+
+标题、分类、标签、计划描述及其全文索引，以及语义向量（memory_embeddings）不加密`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewEncryptHandler(bs)
+		if err := handler.Enable(bs.Context(), enableKeyFile); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	encryptEnableCmd.Flags().StringVar(&enableKeyFile, "key-file", "", "密钥文件路径（不存在时自动生成）")
+	encryptCmd.AddCommand(encryptEnableCmd)
+}
@@ -0,0 +1,40 @@
+package encrypt
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+var rotateKeyFile string
+
+// encryptRotateKeyCmd 轮换加密密钥
+var encryptRotateKeyCmd = &cobra.Command{
+	Use:   "rotate-key",
+	Short: "轮换加密密钥",
+	Long: `用新密钥重新加密全部内容（包括回收站中的数据），在一个事务中完成~ 🔑
+
+当前密钥按配置读取（密钥文件或 LLM_MEMORY_PASSPHRASE），
+新密钥来自 --key-file（不存在时自动生成）或环境变量 LLM_MEMORY_NEW_PASSPHRASE`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewEncryptHandler(bs)
+		if err := handler.RotateKey(bs.Context(), rotateKeyFile); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	encryptRotateKeyCmd.Flags().StringVar(&rotateKeyFile, "key-file", "", "新的密钥文件路径（不存在时自动生成）")
+	encryptCmd.AddCommand(encryptRotateKeyCmd)
+}
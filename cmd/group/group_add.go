@@ -3,7 +3,6 @@ package group
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/XiaoLFeng/llm-memory/startup"
 	"github.com/spf13/cobra"
@@ -16,7 +15,7 @@ var groupAddPathCmd = &cobra.Command{
 	Short: "将路径添加到组",
 	Long: `将当前目录或指定路径添加到组中~ ✨
 
-如果不指定路径，则默认添加当前工作目录。
+如果不指定路径，则默认添加当前工作目录（配置了 scope.resolution 时按解析模式映射，如 git 仓库根目录）。
 
 示例：
   llm-memory group add-path my-project           # 添加当前目录
@@ -45,13 +44,12 @@ var groupAddPathCmd = &cobra.Command{
 		if len(args) > 1 {
 			pathToAdd = args[1]
 		} else {
-			// 使用当前目录
-			pwd, err := os.Getwd()
-			if err != nil {
-				fmt.Printf("无法获取当前目录: %v\n", err)
+			// 使用当前目录（按作用域解析模式映射，如 git-root 模式下为仓库根目录）
+			pathToAdd = boot.CurrentScope.ScopePath
+			if pathToAdd == "" {
+				fmt.Println("无法获取当前目录")
 				return
 			}
-			pathToAdd = pwd
 		}
 
 		// 添加路径到组
@@ -17,7 +17,8 @@ var groupCurrentCmd = &cobra.Command{
 	Short: "显示当前作用域",
 	Long: `显示当前工作目录所属的作用域信息~ ✨
 
-包括：当前路径 (Personal)、所属组 (Group)、全局 (Global)
+包括：当前路径 (Personal)、所属组 (Group)、全局 (Global)，
+以及按作用域解析模式（exact / ancestor / git-root）命中的路径和规则
 
 示例：
   llm-memory group current`,
@@ -46,10 +47,15 @@ var groupCurrentCmd = &cobra.Command{
 		fmt.Println(iconSearch + " 当前作用域信息:")
 		fmt.Println("─────────────────────────────────────")
 		fmt.Printf(iconPin+" 当前路径: %s\n", pwd)
+		fmt.Printf(iconFolder+" 作用域路径: %s\n", scope.ScopePath)
+		fmt.Printf(iconTag+" 命中规则: %s\n", describeScopeRule(scope.MatchedRule, boot.GroupService.Resolution()))
+		if scope.ProjectID != "" {
+			fmt.Printf(iconPackage+" 项目标识: %s\n", scope.ProjectID)
+		}
 
 		// Personal 作用域
 		if scope.IncludePersonal {
-			fmt.Printf(iconUser + " Personal: " + iconCheck + " 启用 (精确匹配当前路径)\n")
+			fmt.Printf(iconUser + " Personal: " + iconCheck + " 启用 (匹配作用域路径)\n")
 		} else {
 			fmt.Println(iconUser + " Personal: " + iconTimes + " 未启用")
 		}
@@ -77,6 +83,24 @@ var groupCurrentCmd = &cobra.Command{
 	},
 }
 
+// describeScopeRule 描述命中的作用域解析规则
+// 配置的模式没有找到祖先目录或 git 根目录时，会退回精确匹配
+func describeScopeRule(matched, configured types.ScopeResolution) string {
+	var desc string
+	switch matched {
+	case types.ScopeResolutionAncestor:
+		desc = "ancestor (当前目录或最近的已登记祖先目录)"
+	case types.ScopeResolutionGitRoot:
+		desc = "git-root (git 工作区根目录)"
+	default:
+		desc = "exact (精确匹配当前路径)"
+	}
+	if matched != configured {
+		desc += fmt.Sprintf("，配置为 %s 但未找到匹配目录", configured)
+	}
+	return desc
+}
+
 func init() {
 	groupCmd.AddCommand(groupCurrentCmd)
 }
@@ -3,9 +3,9 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/XiaoLFeng/llm-memory/internal/mcp"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
 	"github.com/XiaoLFeng/llm-memory/startup"
 	"github.com/spf13/cobra"
 )
@@ -22,13 +22,28 @@ MCP 服务支持以下功能：
   - 计划管理：创建、更新、查询计划
   - TODO 管理：管理待办事项
 
+默认通过 stdio 与客户端通信，每个客户端启动各自的进程。
+使用 --http 以 HTTP 方式运行，一个进程同时服务多个客户端：
+  llm-memory mcp --http 127.0.0.1:7777
+  - streamable HTTP 端点: http://127.0.0.1:7777/mcp
+  - 旧版 SSE 端点:        http://127.0.0.1:7777/sse
+配置 mcp.auth_token（或环境变量 LLM_MEMORY_MCP_TOKEN）后，
+客户端需要携带 Authorization: Bearer <token> 请求头。
+
+使用 --profile 为不同的配置档分别启动服务，例如：
+  llm-memory mcp --profile work
+
 嘿嘿~ AI 模型可以通过 MCP 协议与此服务通信！✨`,
 	Run: func(cmd *cobra.Command, args []string) {
 		runMCP()
 	},
 }
 
+// mcpHTTPAddr HTTP 监听地址（为空时使用 stdio）
+var mcpHTTPAddr string
+
 func init() {
+	mcpCmd.Flags().StringVar(&mcpHTTPAddr, "http", "", "以 HTTP 方式运行并监听指定地址（如 127.0.0.1:7777）")
 	RootCmd.AddCommand(mcpCmd)
 }
 
@@ -38,13 +53,18 @@ func runMCP() {
 	// 使用 startup 包统一初始化
 	bs := startup.New(
 		startup.WithSignalHandler(true),
+		startup.WithChangeSource(types.ChangeSourceMCP),
 	).MustInitialize(context.Background())
 	defer bs.Shutdown()
 
 	// 启动 MCP 服务
 	server := mcp.NewServer(bs)
-	if err := server.Run(); err != nil {
+	run := server.Run
+	if mcpHTTPAddr != "" {
+		run = func() error { return server.RunHTTP(mcpHTTPAddr) }
+	}
+	if err := run(); err != nil {
 		fmt.Printf("MCP 服务运行出错: %v\n", err)
-		os.Exit(1)
+		bs.Exit(1)
 	}
 }
@@ -57,7 +57,7 @@ var memoryCreateCmd = &cobra.Command{
 		handler := handlers.NewMemoryHandler(bs)
 		if err := handler.Create(bs.Context(), memoryCode, memoryTitle, memoryContent, memoryCategory, tags, memoryGlobal); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
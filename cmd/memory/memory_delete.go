@@ -31,7 +31,7 @@ var memoryDeleteCmd = &cobra.Command{
 		handler := handlers.NewMemoryHandler(bs)
 		if err := handler.Delete(bs.Context(), memoryDeleteCode); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"os"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/internal/service"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+// memoryDiffCmd 对比记忆的两个修订
+// 呀~ 看看到底改了什么！🔍
+var memoryDiffCmd = &cobra.Command{
+	Use:   "diff <code> <rev1> <rev2>",
+	Short: "对比记忆的两个修订",
+	Long: `逐字段对比记忆两个修订点的差异~ 🔍
+
+修订号可以是数字，也可以是 current（当前版本），例如：
+  llm-memory memory diff my-note 1 current`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		fromRev, err := service.ParseRevision(args[1])
+		if err != nil {
+			cli.PrintError(err.Error())
+			os.Exit(1)
+		}
+		toRev, err := service.ParseRevision(args[2])
+		if err != nil {
+			cli.PrintError(err.Error())
+			os.Exit(1)
+		}
+
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewMemoryHandler(bs)
+		if err := handler.Diff(bs.Context(), args[0], fromRev, toRev); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	memoryCmd.AddCommand(memoryDiffCmd)
+}
@@ -31,7 +31,7 @@ var memoryGetCmd = &cobra.Command{
 		handler := handlers.NewMemoryHandler(bs)
 		if err := handler.Get(bs.Context(), memoryGetCode); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
@@ -0,0 +1,35 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+// memoryHistoryCmd 查看记忆修订历史
+// 嘿嘿~ 每次修改前的旧内容都在这里！🕘
+var memoryHistoryCmd = &cobra.Command{
+	Use:   "history <code>",
+	Short: "查看记忆修订历史",
+	Long:  `列出记忆的所有修订，包括修改时间和修改来源（cli/mcp/tui）~ 🕘`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewMemoryHandler(bs)
+		if err := handler.History(bs.Context(), args[0]); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	memoryCmd.AddCommand(memoryHistoryCmd)
+}
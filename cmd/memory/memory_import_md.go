@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+// memoryImportMdCmd 从 Markdown 目录导入记忆
+// 呀~ 仓库里的 ADR 和笔记直接变成记忆！📥
+var memoryImportMdCmd = &cobra.Command{
+	Use:   "import-md <dir>",
+	Short: "从 Markdown 目录导入记忆",
+	Long: `递归读取目录中的 .md 文件，每个文件导入为当前路径作用域的一条记忆~ ✨
+
+  标题   front matter 的 title，其次正文第一个标题，最后是文件名
+  标识码 front matter 的 code，否则为文件名的 slug（如 0001-Use-Go.md -> doc-0001-use-go）
+  分类   front matter 的 category（默认"默认"）
+  标签   front matter 的 tags（列表或逗号分隔）
+
+标识码已存在时更新对应记忆（内容有变化才会记录修订），隐藏目录（如 .git、.obsidian）会被跳过
+
+示例：
+  llm-memory memory import-md docs/adr`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewMemoryHandler(bs)
+		if err := handler.ImportMarkdown(bs.Context(), args[0]); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	memoryCmd.AddCommand(memoryImportMdCmd)
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"os"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/internal/service"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+// memoryRevertCmd 回滚记忆到指定修订
+// 嘿嘿~ 改坏了也不怕，一键恢复！💖
+var memoryRevertCmd = &cobra.Command{
+	Use:   "revert <code> <rev>",
+	Short: "回滚记忆到指定修订",
+	Long:  `将记忆恢复为指定修订保存的内容，回滚前的内容同样会记录为新的修订~ ⏪`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		revision, err := service.ParseRevision(args[1])
+		if err != nil {
+			cli.PrintError(err.Error())
+			os.Exit(1)
+		}
+
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewMemoryHandler(bs)
+		if err := handler.Revert(bs.Context(), args[0], revision); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	memoryCmd.AddCommand(memoryRevertCmd)
+}
@@ -10,14 +10,20 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var memorySearchKeyword string
+var (
+	memorySearchKeyword  string
+	memorySearchSemantic bool
+	memorySearchTopK     int
+)
 
 // memorySearchCmd 搜索记忆
 // 呀~ 根据关键词搜索记忆！🔍
 var memorySearchCmd = &cobra.Command{
 	Use:   "search",
 	Short: "搜索记忆",
-	Long:  `根据关键词搜索记忆条目~ 🔍`,
+	Long: `根据关键词搜索记忆条目~ 🔍
+
+默认使用全文检索；加上 --semantic 后按语义相似度返回最相关的记忆~`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if memorySearchKeyword == "" {
 			cli.PrintError("请使用 --keyword 参数指定搜索关键词")
@@ -30,15 +36,23 @@ var memorySearchCmd = &cobra.Command{
 		defer bs.Shutdown()
 
 		handler := handlers.NewMemoryHandler(bs)
-		if err := handler.Search(bs.Context(), memorySearchKeyword); err != nil {
+		var err error
+		if memorySearchSemantic {
+			err = handler.SemanticSearch(bs.Context(), memorySearchKeyword, memorySearchTopK)
+		} else {
+			err = handler.Search(bs.Context(), memorySearchKeyword)
+		}
+		if err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
 
 func init() {
 	memorySearchCmd.Flags().StringVarP(&memorySearchKeyword, "keyword", "k", "", "搜索关键词（必填）")
+	memorySearchCmd.Flags().BoolVar(&memorySearchSemantic, "semantic", false, "使用语义（向量）搜索")
+	memorySearchCmd.Flags().IntVarP(&memorySearchTopK, "top", "n", 5, "语义搜索返回的最大数量")
 	_ = memorySearchCmd.MarkFlagRequired("keyword")
 
 	memoryCmd.AddCommand(memorySearchCmd)
@@ -18,6 +18,7 @@ var (
 	updateCategory string
 	updateTags     string
 	updatePriority int
+	updateVersion  int64
 )
 
 // memoryUpdateCmd 更新记忆
@@ -72,15 +73,21 @@ var memoryUpdateCmd = &cobra.Command{
 		if hasPriority {
 			if updatePriority < 1 || updatePriority > 4 {
 				cli.PrintError("优先级必须在 1-4 之间")
-				os.Exit(1)
+				bs.Exit(1)
 			}
 			priority = &updatePriority
 		}
 
+		// 指定版本时，记忆在此之后被修改过则拒绝更新
+		var version *int64
+		if cmd.Flags().Changed("if-version") {
+			version = &updateVersion
+		}
+
 		handler := handlers.NewMemoryHandler(bs)
-		if err := handler.Update(bs.Context(), updateCode, title, content, category, tags, priority); err != nil {
+		if err := handler.Update(bs.Context(), updateCode, title, content, category, tags, priority, version); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
@@ -92,6 +99,7 @@ func init() {
 	memoryUpdateCmd.Flags().StringVarP(&updateCategory, "category", "C", "", "新分类")
 	memoryUpdateCmd.Flags().StringVar(&updateTags, "tags", "", "新标签（逗号分隔）")
 	memoryUpdateCmd.Flags().IntVarP(&updatePriority, "priority", "p", 0, "新优先级 1-4")
+	memoryUpdateCmd.Flags().Int64Var(&updateVersion, "if-version", 0, "仅当记忆仍是该版本时更新（版本见 memory get）")
 
 	_ = memoryUpdateCmd.MarkFlagRequired("code")
 
@@ -0,0 +1,31 @@
+package path
+
+import (
+	"github.com/XiaoLFeng/llm-memory/cmd"
+	"github.com/spf13/cobra"
+)
+
+// pathCmd 是 path 父命令
+// 嘿嘿~ 项目目录搬家、改名后的善后工具！📦
+var pathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "路径维护命令",
+	Long: `维护已登记的项目路径~ ✨
+
+项目目录移动或改名后，在新目录运行命令会登记一个全新的路径，
+原来的记忆、计划、待办和组成员关系仍然留在旧路径上，可以用这里的命令带过去。
+
+示例：
+  # 目录改名后，把旧路径改写为新路径（保留所有数据和组）
+  llm-memory path move ~/code/old-name ~/code/new-name
+
+  # 新路径已经有数据时，把旧路径的数据合并过去
+  llm-memory path merge ~/code/old-name ~/code/new-name`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(pathCmd)
+}
@@ -0,0 +1,40 @@
+package path
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+// pathMergeCmd 合并两个路径的数据
+var pathMergeCmd = &cobra.Command{
+	Use:   "merge <from> <to>",
+	Short: "把一个路径的数据合并到另一个路径",
+	Long: `把 <from> 下的所有记忆、计划、待办（包括回收站）改为属于 <to>，然后删除 <from> 的登记~ 🔀
+
+两边有相同 code 的进行中计划或待办时，<from> 一侧的条目会追加 -2、-3… 后缀。
+<to> 未加入组时会继承 <from> 的组；两者属于不同的组时保留 <to> 的组。
+
+示例：
+  llm-memory path merge ~/code/old-name ~/code/new-name`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewPathHandler(bs)
+		if err := handler.Merge(bs.Context(), args[0], args[1]); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	pathCmd.AddCommand(pathMergeCmd)
+}
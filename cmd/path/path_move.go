@@ -0,0 +1,41 @@
+package path
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+// pathMoveCmd 把路径搬迁到新位置
+var pathMoveCmd = &cobra.Command{
+	Use:   "move <old> <new>",
+	Short: "把已登记的路径改写为新位置",
+	Long: `把已登记的路径改写为新位置，保留路径 ID~ 📦
+
+记忆、计划、待办（包括回收站）和组成员关系都会跟着走，旧路径下登记的子目录一并改写。
+新位置必须是已存在的目录；如果新位置已经登记过但没有任何数据（例如在新目录运行过命令），
+会直接替换它；已有数据时请使用 path merge。
+
+示例：
+  llm-memory path move ~/code/old-name ~/code/new-name`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewPathHandler(bs)
+		if err := handler.Move(bs.Context(), args[0], args[1]); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	pathCmd.AddCommand(pathMoveCmd)
+}
@@ -31,7 +31,7 @@ var planCompleteCmd = &cobra.Command{
 		handler := handlers.NewPlanHandler(bs)
 		if err := handler.Complete(bs.Context(), planCompleteCode); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
@@ -42,7 +42,7 @@ var planCreateCmd = &cobra.Command{
 		handler := handlers.NewPlanHandler(bs)
 		if err := handler.Create(bs.Context(), planCode, planTitle, planDescription, planContent, planGlobal); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
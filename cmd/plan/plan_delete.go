@@ -31,7 +31,7 @@ var planDeleteCmd = &cobra.Command{
 		handler := handlers.NewPlanHandler(bs)
 		if err := handler.Delete(bs.Context(), planDeleteCode); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
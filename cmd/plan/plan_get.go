@@ -31,7 +31,7 @@ var planGetCmd = &cobra.Command{
 		handler := handlers.NewPlanHandler(bs)
 		if err := handler.Get(bs.Context(), planGetCode); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
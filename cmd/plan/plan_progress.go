@@ -38,7 +38,7 @@ var planProgressCmd = &cobra.Command{
 		handler := handlers.NewPlanHandler(bs)
 		if err := handler.UpdateProgress(bs.Context(), planProgressCode, planProgressValue); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
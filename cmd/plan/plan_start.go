@@ -31,7 +31,7 @@ var planStartCmd = &cobra.Command{
 		handler := handlers.NewPlanHandler(bs)
 		if err := handler.Start(bs.Context(), planStartCode); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
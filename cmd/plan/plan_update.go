@@ -16,6 +16,7 @@ var (
 	planUpdateDescription string
 	planUpdateContent     string
 	planUpdateProgress    int
+	planUpdateVersion     int64
 )
 
 // planUpdateCmd 更新计划
@@ -61,15 +62,21 @@ var planUpdateCmd = &cobra.Command{
 		if hasProgress {
 			if planUpdateProgress < 0 || planUpdateProgress > 100 {
 				cli.PrintError("进度必须在 0-100 之间")
-				os.Exit(1)
+				bs.Exit(1)
 			}
 			progress = &planUpdateProgress
 		}
 
+		// 指定版本时，计划在此之后被修改过则拒绝更新
+		var version *int64
+		if cmd.Flags().Changed("if-version") {
+			version = &planUpdateVersion
+		}
+
 		handler := handlers.NewPlanHandler(bs)
-		if err := handler.Update(bs.Context(), planUpdateCode, title, description, content, progress); err != nil {
+		if err := handler.Update(bs.Context(), planUpdateCode, title, description, content, progress, version); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
@@ -80,6 +87,7 @@ func init() {
 	planUpdateCmd.Flags().StringVarP(&planUpdateDescription, "description", "d", "", "新描述")
 	planUpdateCmd.Flags().StringVar(&planUpdateContent, "content", "", "新内容")
 	planUpdateCmd.Flags().IntVarP(&planUpdateProgress, "progress", "p", -1, "新进度 0-100")
+	planUpdateCmd.Flags().Int64Var(&planUpdateVersion, "if-version", 0, "仅当计划仍是该版本时更新（版本见 plan get）")
 
 	_ = planUpdateCmd.MarkFlagRequired("code")
 
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/XiaoLFeng/llm-memory/internal/app"
+	"github.com/XiaoLFeng/llm-memory/startup"
 	"github.com/spf13/cobra"
 )
 
@@ -18,12 +20,39 @@ var RootCmd = &cobra.Command{
 
 嘿嘿~ 支持记忆管理、计划管理和 TODO 管理功能！
 可以通过 GUI 界面操作，也可以作为 MCP 服务运行~ ✨`,
+	// 所有子命令执行前应用全局选项
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		applyGlobalFlags()
+	},
 	// 如果直接运行 llm-memory 不带子命令，显示帮助
 	Run: func(cmd *cobra.Command, args []string) {
 		_ = cmd.Help()
 	},
 }
 
+// 全局参数（优先级高于对应的环境变量）
+var (
+	globalConfigPath string
+	globalDBPath     string
+	globalProfile    string
+)
+
+// applyGlobalFlags 把全局参数传给之后创建的所有 Bootstrap
+// 只传递显式指定的参数，未指定时仍使用环境变量
+func applyGlobalFlags() {
+	var opts []startup.Option
+	if globalConfigPath != "" {
+		opts = append(opts, startup.WithConfigPath(globalConfigPath))
+	}
+	if globalDBPath != "" {
+		opts = append(opts, startup.WithDBPath(globalDBPath))
+	}
+	if globalProfile != "" {
+		opts = append(opts, startup.WithProfile(globalProfile))
+	}
+	startup.SetGlobalOptions(opts...)
+}
+
 // Execute 执行根命令
 // 这是程序的入口点~ 🚀
 func Execute() {
@@ -37,4 +66,9 @@ func init() {
 	// 添加版本标志
 	RootCmd.Version = Version
 	RootCmd.SetVersionTemplate("LLM-Memory 版本: {{.Version}}\n")
+
+	// 全局参数：选择配置文件、数据库和 profile
+	RootCmd.PersistentFlags().StringVar(&globalConfigPath, "config", "", "配置文件路径（默认 ~/.llm-memory/config.json，环境变量 "+app.ConfigPathEnv+"）")
+	RootCmd.PersistentFlags().StringVar(&globalDBPath, "db", "", "SQLite 数据库文件路径，覆盖配置（环境变量 "+app.DBPathEnv+"）")
+	RootCmd.PersistentFlags().StringVar(&globalProfile, "profile", "", "使用配置文件中的 profile（环境变量 "+app.ProfileEnv+"）")
 }
@@ -44,7 +44,7 @@ var todoBatchCancelCmd = &cobra.Command{
 		handler := handlers.NewTodoHandler(bs)
 		if err := handler.BatchCancel(bs.Context(), codes); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
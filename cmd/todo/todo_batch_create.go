@@ -67,7 +67,7 @@ var todoBatchCreateCmd = &cobra.Command{
 		handler := handlers.NewTodoHandler(bs)
 		if err := handler.BatchCreate(bs.Context(), items); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
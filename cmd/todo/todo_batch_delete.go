@@ -44,7 +44,7 @@ var todoBatchDeleteCmd = &cobra.Command{
 		handler := handlers.NewTodoHandler(bs)
 		if err := handler.BatchDelete(bs.Context(), codes); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
@@ -31,7 +31,7 @@ var todoCancelCmd = &cobra.Command{
 		handler := handlers.NewTodoHandler(bs)
 		if err := handler.Cancel(bs.Context(), todoCancelCode); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
@@ -31,7 +31,7 @@ var todoCompleteCmd = &cobra.Command{
 		handler := handlers.NewTodoHandler(bs)
 		if err := handler.Complete(bs.Context(), todoCompleteCode); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
@@ -46,7 +46,7 @@ var todoCreateCmd = &cobra.Command{
 		handler := handlers.NewTodoHandler(bs)
 		if err := handler.Create(bs.Context(), todoCode, todoPlanCode, todoTitle, todoDescription, todoPriority); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
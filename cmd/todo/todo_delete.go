@@ -31,7 +31,7 @@ var todoDeleteCmd = &cobra.Command{
 		handler := handlers.NewTodoHandler(bs)
 		if err := handler.Delete(bs.Context(), todoDeleteCode); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
@@ -2,7 +2,6 @@ package todo
 
 import (
 	"context"
-	"os"
 
 	"github.com/XiaoLFeng/llm-memory/internal/cli"
 	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
@@ -14,7 +13,7 @@ import (
 var todoFinalCmd = &cobra.Command{
 	Use:   "final",
 	Short: "删除当前作用域的所有待办",
-	Long:  `删除当前作用域内的所有待办事项（不可恢复）~ 🗑️`,
+	Long:  `删除当前作用域内的所有待办事项（移入回收站）~ 🗑️`,
 	Run: func(cmd *cobra.Command, args []string) {
 		bs := startup.New(
 			startup.WithSignalHandler(false),
@@ -24,7 +23,7 @@ var todoFinalCmd = &cobra.Command{
 		handler := handlers.NewTodoHandler(bs)
 		if err := handler.Final(bs.Context()); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
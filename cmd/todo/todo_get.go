@@ -31,7 +31,7 @@ var todoGetCmd = &cobra.Command{
 		handler := handlers.NewTodoHandler(bs)
 		if err := handler.Get(bs.Context(), todoGetCode); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
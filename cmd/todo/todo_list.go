@@ -2,7 +2,6 @@ package todo
 
 import (
 	"context"
-	"os"
 
 	"github.com/XiaoLFeng/llm-memory/internal/cli"
 	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
@@ -25,7 +24,7 @@ var todoListCmd = &cobra.Command{
 		handler := handlers.NewTodoHandler(bs)
 		if err := handler.List(bs.Context()); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
@@ -31,7 +31,7 @@ var todoStartCmd = &cobra.Command{
 		handler := handlers.NewTodoHandler(bs)
 		if err := handler.Start(bs.Context(), todoStartCode); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
@@ -16,6 +16,7 @@ var (
 	todoUpdateDescription string
 	todoUpdatePriority    int
 	todoUpdateStatus      int
+	todoUpdateVersion     int64
 )
 
 // todoUpdateCmd 更新待办
@@ -58,22 +59,28 @@ var todoUpdateCmd = &cobra.Command{
 		if hasPriority {
 			if todoUpdatePriority < 1 || todoUpdatePriority > 4 {
 				cli.PrintError("优先级必须在 1-4 之间")
-				os.Exit(1)
+				bs.Exit(1)
 			}
 			priority = &todoUpdatePriority
 		}
 		if hasStatus {
 			if todoUpdateStatus < 0 || todoUpdateStatus > 3 {
 				cli.PrintError("状态必须在 0-3 之间（0待处理/1进行中/2已完成/3已取消）")
-				os.Exit(1)
+				bs.Exit(1)
 			}
 			status = &todoUpdateStatus
 		}
 
+		// 指定版本时，待办在此之后被修改过则拒绝更新
+		var version *int64
+		if cmd.Flags().Changed("if-version") {
+			version = &todoUpdateVersion
+		}
+
 		handler := handlers.NewTodoHandler(bs)
-		if err := handler.Update(bs.Context(), todoUpdateCode, title, description, priority, status); err != nil {
+		if err := handler.Update(bs.Context(), todoUpdateCode, title, description, priority, status, version); err != nil {
 			cli.PrintError(err.Error())
-			os.Exit(1)
+			bs.Exit(1)
 		}
 	},
 }
@@ -84,6 +91,7 @@ func init() {
 	todoUpdateCmd.Flags().StringVarP(&todoUpdateDescription, "description", "d", "", "新描述")
 	todoUpdateCmd.Flags().IntVarP(&todoUpdatePriority, "priority", "p", 0, "新优先级 1-4")
 	todoUpdateCmd.Flags().IntVarP(&todoUpdateStatus, "status", "s", -1, "新状态 0-3（0待处理/1进行中/2已完成/3已取消）")
+	todoUpdateCmd.Flags().Int64Var(&todoUpdateVersion, "if-version", 0, "仅当待办仍是该版本时更新（版本见 todo get）")
 
 	_ = todoUpdateCmd.MarkFlagRequired("code")
 
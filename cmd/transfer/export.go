@@ -0,0 +1,47 @@
+package transfer
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/cmd"
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd 导出数据
+// 嘿嘿~ 把记忆、计划、待办和路径都打包成 JSONL！📦
+var exportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "导出全部数据为 JSONL",
+	Long: `将路径、组、记忆（含标签）、计划、待办导出为带版本号的 JSONL 文件~ ✨
+
+省略 file 或使用 "-" 时输出到标准输出；回收站中的条目不会导出
+
+示例：
+  llm-memory export backup.jsonl
+  llm-memory export > backup.jsonl`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		file := ""
+		if len(args) > 0 {
+			file = args[0]
+		}
+
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewTransferHandler(bs)
+		if err := handler.Export(bs.Context(), file); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,48 @@
+package transfer
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/cmd"
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+// exportMdCmd 导出 Markdown 仓库
+// 呀~ 每条记忆、每个计划都是一个 Markdown 文件，可以直接用 Obsidian 打开！📝
+var exportMdCmd = &cobra.Command{
+	Use:   "export-md <dir>",
+	Short: "导出为 Obsidian 兼容的 Markdown 仓库",
+	Long: `将记忆和计划导出为带 YAML front matter 的 Markdown 文件~ ✨
+
+目录结构：
+  global/memories/<code>.md              全局记忆
+  groups/<组名>/memories|plans/<code>.md  组内路径的记忆和计划
+  personal/<目录名-哈希>/memories|plans/  未加入组的路径
+
+计划中的待办渲染为 - [ ] / - [x] 任务清单
+重复导出到同一目录时只重写有变化的文件，已删除的记录对应的文件会被移除
+（只处理本工具写出的文件，目录中的其他笔记不受影响）
+
+示例：
+  llm-memory export-md ~/Obsidian/llm-memory`,
+	Args: cobra.ExactArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewTransferHandler(bs)
+		if err := handler.ExportVault(bs.Context(), args[0]); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(exportMdCmd)
+}
@@ -0,0 +1,55 @@
+package transfer
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/cmd"
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importStrategy string
+	importMapPaths []string
+)
+
+// importCmd 导入数据
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "从 JSONL 导入数据",
+	Long: `从 llm-memory export 生成的 JSONL 文件导入数据，所有写入在同一事务中完成~ 💖
+
+code 冲突策略（--strategy）：
+  skip       跳过冲突条目，保留本地数据（默认）
+  overwrite  用导入数据替换本地冲突条目
+  rename     为导入条目生成新的 code（如 my-note-2）
+
+--map-path 可把导出方的路径映射到本机路径（按最长前缀匹配，可重复指定）
+
+示例：
+  llm-memory import backup.jsonl
+  llm-memory import backup.jsonl --strategy rename \
+    --map-path /home/alice/work=/Users/bob/code`,
+	Args: cobra.ExactArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewTransferHandler(bs)
+		if err := handler.Import(bs.Context(), args[0], importStrategy, importMapPaths); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importStrategy, "strategy", "skip", "code 冲突策略：skip/overwrite/rename")
+	importCmd.Flags().StringArrayVar(&importMapPaths, "map-path", nil, "路径映射 旧路径=新路径（可重复）")
+
+	cmd.RootCmd.AddCommand(importCmd)
+}
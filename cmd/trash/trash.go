@@ -0,0 +1,31 @@
+package trash
+
+import (
+	"github.com/XiaoLFeng/llm-memory/cmd"
+	"github.com/spf13/cobra"
+)
+
+// trashCmd 是 trash 父命令
+// 嘿嘿~ 删除的东西都先进回收站，后悔了还能捞回来！🗑️
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "回收站管理命令",
+	Long: `查看、恢复或彻底清理已删除的记忆、计划和待办~ ✨
+
+示例：
+  # 查看回收站
+  llm-memory trash list
+
+  # 恢复记忆
+  llm-memory trash restore memory my-note
+
+  # 彻底删除 30 天前移入回收站的条目
+  llm-memory trash purge --older-than 30d`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(trashCmd)
+}
@@ -0,0 +1,33 @@
+package trash
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+// trashListCmd 列出回收站条目
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出回收站条目",
+	Long:  `列出当前作用域中已删除的记忆、计划和待办~ 📋`,
+	Run: func(cmd *cobra.Command, args []string) {
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewTrashHandler(bs)
+		if err := handler.List(bs.Context()); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	trashCmd.AddCommand(trashListCmd)
+}
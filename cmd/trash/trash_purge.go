@@ -0,0 +1,39 @@
+package trash
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+var trashPurgeOlderThan string
+
+// trashPurgeCmd 彻底清理回收站
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "彻底删除回收站中的旧条目",
+	Long: `彻底删除在指定时长之前移入回收站的条目，删除后无法恢复~ ⚠️
+
+时长支持 d/h/m/s 单位，例如 30d、12h、1d12h；使用 0 清空整个回收站`,
+	Run: func(cmd *cobra.Command, args []string) {
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewTrashHandler(bs)
+		if err := handler.Purge(bs.Context(), trashPurgeOlderThan); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	trashPurgeCmd.Flags().StringVar(&trashPurgeOlderThan, "older-than", "30d", "只清理删除时间早于该时长的条目")
+
+	trashCmd.AddCommand(trashPurgeCmd)
+}
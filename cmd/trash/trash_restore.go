@@ -0,0 +1,37 @@
+package trash
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/handlers"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"github.com/spf13/cobra"
+)
+
+// trashRestoreCmd 从回收站恢复
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <type> <code>",
+	Short: "从回收站恢复条目",
+	Long: `从回收站恢复指定的记忆、计划或待办~ 💖
+
+type 可选值: memory / plan / todo
+恢复计划时，随计划一起删除的待办也会一并恢复`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		bs := startup.New(
+			startup.WithSignalHandler(false),
+		).MustInitialize(context.Background())
+		defer bs.Shutdown()
+
+		handler := handlers.NewTrashHandler(bs)
+		if err := handler.Restore(bs.Context(), args[0], args[1]); err != nil {
+			cli.PrintError(err.Error())
+			bs.Exit(1)
+		}
+	},
+}
+
+func init() {
+	trashCmd.AddCommand(trashRestoreCmd)
+}
@@ -3,9 +3,9 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/XiaoLFeng/llm-memory/internal/tui"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
 	"github.com/XiaoLFeng/llm-memory/startup"
 	"github.com/spf13/cobra"
 )
@@ -30,12 +30,13 @@ func runTUI() {
 	// 使用 startup 包统一初始化
 	bs := startup.New(
 		startup.WithSignalHandler(true),
+		startup.WithChangeSource(types.ChangeSourceTUI),
 	).MustInitialize(context.Background())
 	defer bs.Shutdown()
 
 	// 启动 TUI
 	if err := tui.Run(bs); err != nil {
 		fmt.Printf("TUI 运行出错: %v\n", err)
-		os.Exit(1)
+		bs.Exit(1)
 	}
 }
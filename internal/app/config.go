@@ -3,29 +3,287 @@ package app
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/embedding"
+	"github.com/XiaoLFeng/llm-memory/internal/encryption"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
 )
 
 // Config 应用配置结构体 ✨
-// 存储应用的各项配置信息，包括数据库路径、主题和调试模式
+// 存储应用的各项配置信息，包括数据库路径、主题、调试模式和向量嵌入
 type Config struct {
-	DBPath string `json:"db_path"` // 数据库文件路径
-	Theme  string `json:"theme"`   // 主题名称
-	Debug  bool   `json:"debug"`   // 调试模式开关
+	Driver     string            `json:"driver"`     // 数据库驱动：sqlite（默认）| postgres
+	DBPath     string            `json:"db_path"`    // 数据库文件路径（sqlite）
+	Postgres   PostgresConfig    `json:"postgres"`   // PostgreSQL 连接配置（postgres）
+	Theme      string            `json:"theme"`      // 主题名称
+	Debug      bool              `json:"debug"`      // 调试模式开关
+	Embedding  embedding.Config  `json:"embedding"`  // 语义搜索向量嵌入配置
+	Backup     BackupConfig      `json:"backup"`     // 自动备份配置
+	Encryption encryption.Config `json:"encryption"` // 静态数据加密配置
+	Scope      ScopeConfig       `json:"scope"`      // 作用域解析配置
+	MCP        MCPConfig         `json:"mcp"`        // MCP 服务配置
+
+	Profiles map[string]Profile `json:"profiles,omitempty"` // 命名配置档，通过 --profile 选择
+
+	path    string  // 配置文件路径
+	profile string  // 当前使用的 profile（为空表示顶层配置）
+	file    *Config // 配置文件原始内容（应用 profile 和覆盖前），保存时写回它
+}
+
+// 选择配置文件、数据库和 profile 的环境变量（命令行参数优先）
+const (
+	ConfigPathEnv = "LLM_MEMORY_CONFIG"
+	DBPathEnv     = "LLM_MEMORY_DB"
+	ProfileEnv    = "LLM_MEMORY_PROFILE"
+	MCPTokenEnv   = "LLM_MEMORY_MCP_TOKEN"
+)
+
+// LoadOptions 加载配置时的选择，字段为空时使用默认值
+type LoadOptions struct {
+	ConfigPath string // 配置文件路径，默认 ~/.llm-memory/config.json
+	Profile    string // 使用的 profile 名称
+	DBPath     string // 覆盖数据库文件路径（同时切换为 sqlite 驱动）
+}
+
+// Profile 命名配置档 🗂️
+// 未设置的字段沿用顶层配置；设置了独立存储（driver / db_path / postgres）的 profile
+// 使用自己的加密配置，备份默认写到 <备份目录>/<profile 名称>，与其他数据库互不影响
+type Profile struct {
+	Driver     string             `json:"driver,omitempty"`     // 数据库驱动
+	DBPath     string             `json:"db_path,omitempty"`    // 数据库文件路径（相对路径基于配置文件所在目录）
+	Postgres   *PostgresConfig    `json:"postgres,omitempty"`   // PostgreSQL 连接配置
+	Theme      string             `json:"theme,omitempty"`      // 主题名称
+	Debug      *bool              `json:"debug,omitempty"`      // 调试模式开关
+	Embedding  *embedding.Config  `json:"embedding,omitempty"`  // 语义搜索向量嵌入配置
+	Backup     *BackupConfig      `json:"backup,omitempty"`     // 自动备份配置
+	Encryption *encryption.Config `json:"encryption,omitempty"` // 静态数据加密配置（仅独立存储时生效）
+	Scope      *ScopeConfig       `json:"scope,omitempty"`      // 作用域解析配置
+	MCP        *MCPConfig         `json:"mcp,omitempty"`        // MCP 服务配置
+}
+
+// ownsStorage 是否使用独立的数据库
+func (p Profile) ownsStorage() bool {
+	return p.Driver != "" || p.DBPath != "" || p.Postgres != nil
+}
+
+// PostgresConfig PostgreSQL 连接配置 🐘
+// 连接串优先取环境变量 LLM_MEMORY_POSTGRES_DSN，其次取配置文件；
+// 都为空时使用 PGHOST/PGPORT/PGUSER/PGPASSWORD/PGDATABASE 等标准环境变量
+type PostgresConfig struct {
+	DSN string `json:"dsn,omitempty"` // 连接串，如 postgres://user@host:5432/llm_memory?sslmode=disable
+}
+
+// DatabaseDriver 获取数据库驱动（未配置时为 sqlite）
+func (c *Config) DatabaseDriver() (string, error) {
+	switch driver := strings.ToLower(strings.TrimSpace(c.Driver)); driver {
+	case "", database.DriverSQLite:
+		return database.DriverSQLite, nil
+	case database.DriverPostgres, "postgresql":
+		return database.DriverPostgres, nil
+	default:
+		return "", fmt.Errorf("不支持的数据库驱动: %s（可选 sqlite / postgres）", c.Driver)
+	}
+}
+
+// PostgresDSN 获取 PostgreSQL 连接串（环境变量优先）
+func (c *Config) PostgresDSN() string {
+	if dsn := os.Getenv(database.PostgresDSNEnv); dsn != "" {
+		return dsn
+	}
+	return c.Postgres.DSN
+}
+
+// ConfigPath 获取配置文件路径
+func (c *Config) ConfigPath() string {
+	if c.path != "" {
+		return c.path
+	}
+	return DefaultConfigPath()
+}
+
+// Profile 获取当前使用的 profile 名称（未使用时为空）
+func (c *Config) Profile() string {
+	return c.profile
+}
+
+// ProfileNames 获取配置中定义的所有 profile 名称（按字母排序）
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetEncryption 设置加密配置
+// 当前 profile 使用独立存储时写入该 profile，否则写入顶层配置；之后调用 SaveConfig 持久化
+func (c *Config) SetEncryption(enc encryption.Config) {
+	c.Encryption = enc
+	doc := c.document()
+	if p, ok := doc.Profiles[c.profile]; ok && c.profile != "" && p.ownsStorage() {
+		p.Encryption = &enc
+		doc.Profiles[c.profile] = p
+		return
+	}
+	doc.Encryption = enc
+}
+
+// document 获取写回配置文件的内容
+func (c *Config) document() *Config {
+	if c.file != nil {
+		return c.file
+	}
+	return c
+}
+
+// withProfile 应用 profile，返回生效的配置（不修改原配置）
+func (c *Config) withProfile(name string) (*Config, error) {
+	cfg := *c
+	cfg.file = c
+	if name == "" {
+		return &cfg, nil
+	}
+
+	p, ok := c.Profiles[name]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return nil, fmt.Errorf("配置文件中没有定义 profile: %s", name)
+		}
+		return nil, fmt.Errorf("配置中不存在 profile: %s（可用: %s）", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	cfg.profile = name
+
+	if p.ownsStorage() {
+		cfg.Driver = p.Driver
+		cfg.DBPath = ""
+		cfg.Postgres = PostgresConfig{}
+		cfg.Encryption = encryption.Config{}
+		if p.DBPath != "" {
+			cfg.DBPath = resolvePath(filepath.Dir(c.ConfigPath()), p.DBPath)
+		} else {
+			cfg.DBPath = filepath.Join(filepath.Dir(c.ConfigPath()), name+".db")
+		}
+		if p.Postgres != nil {
+			cfg.Postgres = *p.Postgres
+		}
+		if p.Encryption != nil {
+			cfg.Encryption = *p.Encryption
+		}
+	}
+	if p.Theme != "" {
+		cfg.Theme = p.Theme
+	}
+	if p.Debug != nil {
+		cfg.Debug = *p.Debug
+	}
+	if p.Embedding != nil {
+		cfg.Embedding = *p.Embedding
+	}
+	if p.Backup != nil {
+		cfg.Backup = *p.Backup
+	}
+	if p.Scope != nil {
+		cfg.Scope = *p.Scope
+	}
+	if p.MCP != nil {
+		cfg.MCP = *p.MCP
+	}
+	if p.ownsStorage() && (p.Backup == nil || p.Backup.Dir == "") {
+		cfg.Backup.Dir = filepath.Join(c.BackupDir(), name)
+	}
+	return &cfg, nil
+}
+
+// resolvePath 把相对路径解析为基于 base 的绝对路径
+func resolvePath(base, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
+
+// BackupConfig 备份配置 💾
+type BackupConfig struct {
+	AutoDaily bool   `json:"auto_daily"`    // 启动时每天自动备份一次
+	Dir       string `json:"dir,omitempty"` // 备份目录，默认 ~/.llm-memory/backups
+	Keep      int    `json:"keep"`          // 自动备份保留份数（0 表示不轮转）
+}
+
+// ScopeConfig 作用域解析配置 🧭
+// Resolution 决定当前目录如何映射到已登记的路径：
+//   - exact（默认）: 精确匹配当前目录
+//   - ancestor: 当前目录或最近的已登记祖先目录
+//   - git-root: 所在 git 工作区的根目录（找不到时退回 exact）
+//
+// ClaimMoved 开启后，启动时登记新路径会把同一项目在本机已不存在的旧路径改写为当前路径；
+// 默认关闭，只按项目标识匹配旧路径的数据，改写需要执行 path move
+type ScopeConfig struct {
+	Resolution string `json:"resolution,omitempty"`  // 解析模式：exact | ancestor | git-root
+	ClaimMoved bool   `json:"claim_moved,omitempty"` // 自动认领同一项目已不存在的旧路径
+}
+
+// ScopeResolution 获取作用域解析模式（未配置时为 exact）
+func (c *Config) ScopeResolution() (types.ScopeResolution, error) {
+	value := strings.ToLower(strings.TrimSpace(c.Scope.Resolution))
+	if value == "" {
+		return types.ScopeResolutionExact, nil
+	}
+	resolution := types.ScopeResolution(value)
+	if !resolution.IsValid() {
+		return "", fmt.Errorf("不支持的作用域解析模式: %s（可选 exact / ancestor / git-root）", c.Scope.Resolution)
+	}
+	return resolution, nil
+}
+
+// MCPConfig MCP 服务配置 🔌
+type MCPConfig struct {
+	AuthToken string `json:"auth_token,omitempty"` // HTTP 传输的 Bearer Token（为空时不校验，stdio 传输不使用）
+}
+
+// MCPAuthToken 获取 HTTP 传输的 Bearer Token（环境变量 LLM_MEMORY_MCP_TOKEN 优先）
+func (c *Config) MCPAuthToken() string {
+	if token := os.Getenv(MCPTokenEnv); token != "" {
+		return token
+	}
+	return c.MCP.AuthToken
+}
+
+// DefaultBackupKeep 默认保留的备份份数
+const DefaultBackupKeep = 7
+
+// BackupDir 获取备份目录
+func (c *Config) BackupDir() string {
+	if c.Backup.Dir != "" {
+		return c.Backup.Dir
+	}
+	return filepath.Join(GetConfigDir(), "backups")
 }
 
 // DefaultConfig 返回默认配置 🎮
 // 默认配置包括：
+// - Driver: sqlite
 // - DBPath: ~/.llm-memory/data.db
 // - Theme: default
 // - Debug: false
+// - Embedding: 内置离线哈希嵌入
+// - Backup: 不自动备份，保留 7 份
+// - Encryption: 不加密
 func DefaultConfig() *Config {
 	configDir := GetConfigDir()
 	return &Config{
-		DBPath: filepath.Join(configDir, "data.db"),
-		Theme:  "default",
-		Debug:  false,
+		Driver:    database.DriverSQLite,
+		DBPath:    filepath.Join(configDir, "data.db"),
+		Theme:     "default",
+		Debug:     false,
+		Embedding: embedding.Config{Provider: embedding.ProviderHash},
+		Backup:    BackupConfig{Keep: DefaultBackupKeep},
 	}
 }
 
@@ -40,17 +298,58 @@ func GetConfigDir() string {
 	return filepath.Join(homeDir, ".llm-memory")
 }
 
-// LoadConfig 从配置文件加载配置 💾
-// 如果配置文件不存在，则创建默认配置文件并返回默认配置
-// 如果配置文件存在但读取失败，返回错误
+// DefaultConfigPath 获取默认配置文件路径
+// 返回 ~/.llm-memory/config.json
+func DefaultConfigPath() string {
+	return filepath.Join(GetConfigDir(), "config.json")
+}
+
+// LoadConfig 从默认配置文件加载配置 💾
 func LoadConfig() (*Config, error) {
-	configDir := GetConfigDir()
-	configPath := filepath.Join(configDir, "config.json")
+	return LoadConfigWith(LoadOptions{})
+}
+
+// LoadConfigWith 按选择加载配置 💾
+// 如果配置文件不存在，则创建默认配置文件并使用默认配置
+// 如果配置文件存在但读取失败，返回错误
+// 之后依次应用 profile 和数据库路径覆盖
+func LoadConfigWith(opts LoadOptions) (*Config, error) {
+	configPath := DefaultConfigPath()
+	if opts.ConfigPath != "" {
+		absPath, err := filepath.Abs(opts.ConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		configPath = absPath
+	}
+
+	file, err := readConfigFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := file.withProfile(opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DBPath != "" {
+		absPath, err := filepath.Abs(opts.DBPath)
+		if err != nil {
+			return nil, err
+		}
+		config.Driver = database.DriverSQLite
+		config.DBPath = absPath
+	}
+	return config, nil
+}
 
+// readConfigFile 读取配置文件，不存在时创建默认配置文件
+func readConfigFile(configPath string) (*Config, error) {
 	// 检查配置文件是否存在
 	if _, err := os.Stat(configPath); errors.Is(err, os.ErrNotExist) {
 		// 配置文件不存在，创建默认配置
 		defaultCfg := DefaultConfig()
+		defaultCfg.path = configPath
 		if err := SaveConfig(defaultCfg); err != nil {
 			return nil, err
 		}
@@ -66,27 +365,26 @@ func LoadConfig() (*Config, error) {
 	// 解析 JSON 配置
 	var config Config
 	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %w", configPath, err)
 	}
+	config.path = configPath
 
 	return &config, nil
 }
 
 // SaveConfig 保存配置到文件 💖
-// 将配置序列化为 JSON 并写入 ~/.llm-memory/config.json
-// 如果配置目录不存在，会自动创建
+// 将配置文件内容（不含 profile 和命令行覆盖）序列化为 JSON，写回加载时的配置文件
+// （默认 ~/.llm-memory/config.json）；如果配置目录不存在，会自动创建
 func SaveConfig(config *Config) error {
-	configDir := GetConfigDir()
+	configPath := config.ConfigPath()
 
 	// 确保配置目录存在
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
 		return err
 	}
 
-	configPath := filepath.Join(configDir, "config.json")
-
 	// 序列化配置为 JSON（格式化输出，方便人类阅读）
-	data, err := json.MarshalIndent(config, "", "  ")
+	data, err := json.MarshalIndent(config.document(), "", "  ")
 	if err != nil {
 		return err
 	}
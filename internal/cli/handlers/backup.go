@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/startup"
+)
+
+// BackupHandler 备份恢复命令处理器
+type BackupHandler struct {
+	bs *startup.Bootstrap
+}
+
+// NewBackupHandler 创建备份恢复处理器
+func NewBackupHandler(bs *startup.Bootstrap) *BackupHandler {
+	return &BackupHandler{bs: bs}
+}
+
+// Backup 生成在线备份
+// dest 为空时写入配置的备份目录；dest 为目录时在其中按时间命名
+// keep > 0 时轮转备份所在目录中自动命名的备份
+func (h *BackupHandler) Backup(ctx context.Context, dest string, keep int) error {
+	if err := h.requireSQLite(); err != nil {
+		return err
+	}
+	now := time.Now()
+	switch {
+	case dest == "":
+		dest = filepath.Join(h.bs.Config().BackupDir(), database.BackupFileName(now))
+	case strings.HasSuffix(dest, string(filepath.Separator)) || isDir(dest):
+		dest = filepath.Join(dest, database.BackupFileName(now))
+	}
+
+	if err := database.BackupSQLite(ctx, h.bs.DB(), dest); err != nil {
+		return err
+	}
+	size := int64(0)
+	if stat, err := os.Stat(dest); err == nil {
+		size = stat.Size()
+	}
+	cli.PrintSuccess(fmt.Sprintf("备份完成！%s (%s)", dest, formatSize(size)))
+
+	removed, err := database.RotateBackups(filepath.Dir(dest), keep)
+	for _, path := range removed {
+		cli.PrintInfo("已删除旧备份: " + filepath.Base(path))
+	}
+	return err
+}
+
+// Restore 用备份文件替换当前数据库
+// 先检查完整性和结构版本，再为当前数据库生成一份 pre-restore 备份
+func (h *BackupHandler) Restore(ctx context.Context, file string) error {
+	if err := h.requireSQLite(); err != nil {
+		return err
+	}
+	cfg := h.bs.Config()
+	src, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	dbPath, _ := filepath.Abs(cfg.DBPath)
+	if src == dbPath {
+		return errors.New("不能用当前数据库文件恢复自身")
+	}
+
+	// 1. 检查备份文件
+	info, err := database.InspectSQLiteFile(ctx, src)
+	if err != nil {
+		return fmt.Errorf("备份文件检查失败: %w", err)
+	}
+	latest := database.NewMigrator(h.bs.DB()).LatestVersion()
+	if info.SchemaVersion > latest {
+		return fmt.Errorf("%w（备份 v%d，程序支持 v%d）", database.ErrSchemaTooNew, info.SchemaVersion, latest)
+	}
+	cli.PrintInfo(fmt.Sprintf("备份检查通过：完整性 ok，结构版本 v%d", info.SchemaVersion))
+
+	// 2. 其他进程（MCP 服务、TUI 等）仍在使用数据库时拒绝恢复
+	if err := h.checkOtherProcesses(ctx); err != nil {
+		return err
+	}
+
+	// 3. 为当前数据库生成安全备份，防止误操作
+	safety := filepath.Join(cfg.BackupDir(), "pre-restore-"+database.BackupFileName(time.Now()))
+	if err := database.BackupSQLite(ctx, h.bs.DB(), safety); err != nil {
+		return fmt.Errorf("备份当前数据库失败: %w", err)
+	}
+	cli.PrintInfo("当前数据库已备份到: " + safety)
+
+	// 4. 释放雪花节点租约、关闭连接后替换文件
+	if err := h.bs.CloseDatabase(); err != nil {
+		return err
+	}
+	if err := database.RestoreSQLite(src, cfg.DBPath); err != nil {
+		return err
+	}
+
+	cli.PrintSuccess(fmt.Sprintf("已从 %s 恢复数据库", src))
+	if info.SchemaVersion < latest {
+		cli.PrintInfo(fmt.Sprintf("备份结构版本较旧，下次启动时会自动迁移到 v%d", latest))
+	}
+	return nil
+}
+
+// checkOtherProcesses 检查是否还有其他进程持有雪花节点租约
+// 异常退出的进程留下的租约会在有效期后过期，届时即可恢复
+func (h *BackupHandler) checkOtherProcesses(ctx context.Context) error {
+	owner := ""
+	if lease := h.bs.NodeLease(); lease != nil {
+		owner = lease.Owner()
+	}
+	leases, err := database.LiveNodeLeases(ctx, h.bs.DB(), owner)
+	if err != nil {
+		return fmt.Errorf("读取雪花节点租约失败: %w", err)
+	}
+	if len(leases) == 0 {
+		return nil
+	}
+	owners := make([]string, len(leases))
+	for i, lease := range leases {
+		owners[i] = lease.Owner
+	}
+	return fmt.Errorf("还有 %d 个进程正在使用数据库（%s），请先停止 MCP 服务、TUI 等再恢复；异常退出的进程 %s 后自动释放",
+		len(leases), strings.Join(owners, ", "), database.NodeLeaseTTL)
+}
+
+// requireSQLite 备份恢复基于 SQLite 数据库文件，PostgreSQL 请使用自身的备份工具
+func (h *BackupHandler) requireSQLite() error {
+	if database.IsPostgres(h.bs.DB()) {
+		return errors.New("当前使用 PostgreSQL 存储，请使用 pg_dump / pg_restore 备份和恢复")
+	}
+	return nil
+}
+
+// isDir 判断路径是否为已存在的目录
+func isDir(path string) bool {
+	stat, err := os.Stat(path)
+	return err == nil && stat.IsDir()
+}
+
+// formatSize 格式化文件大小
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/XiaoLFeng/llm-memory/internal/app"
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/output"
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/startup"
+)
+
+// DBHandler 数据库维护命令处理器
+type DBHandler struct {
+	bs *startup.Bootstrap
+}
+
+// NewDBHandler 创建数据库维护处理器
+func NewDBHandler(bs *startup.Bootstrap) *DBHandler {
+	return &DBHandler{bs: bs}
+}
+
+// Migrate 执行所有未执行的迁移
+func (h *DBHandler) Migrate(ctx context.Context) error {
+	migrator := database.NewMigrator(h.bs.DB())
+	executed, err := migrator.Migrate(ctx)
+	for _, mig := range executed {
+		cli.PrintSuccess(fmt.Sprintf("已执行迁移 %04d_%s", mig.Version, mig.Name))
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(executed) == 0 {
+		cli.PrintInfo(fmt.Sprintf("数据库已是最新版本 (v%d)", migrator.LatestVersion()))
+		return nil
+	}
+	cli.PrintSuccess(fmt.Sprintf("迁移完成！当前版本: v%d", migrator.LatestVersion()))
+	return nil
+}
+
+// Status 显示迁移状态
+func (h *DBHandler) Status(ctx context.Context) error {
+	migrator := database.NewMigrator(h.bs.DB())
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	current, err := migrator.CurrentVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	cli.PrintTitle(cli.IconChart + " 数据库迁移状态")
+	printStorageInfo(h.bs.Config())
+	table := output.NewTable("版本", "名称", "状态", "执行时间")
+	pending := 0
+	for _, s := range statuses {
+		state := "已执行"
+		appliedAt := "-"
+		if s.Applied {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		} else {
+			state = "待执行"
+			pending++
+		}
+		if s.Version > migrator.LatestVersion() {
+			state = "未知（程序过旧）"
+		}
+		table.AddRow(fmt.Sprintf("%04d", s.Version), s.Name, state, appliedAt)
+	}
+	table.Print()
+
+	fmt.Printf("\n数据库版本: v%d | 程序支持: v%d | 待执行: %d\n", current, migrator.LatestVersion(), pending)
+	if err := migrator.CheckCompatibility(ctx); err != nil {
+		cli.PrintWarning(err.Error())
+	}
+	return nil
+}
+
+// Rollback 回滚最近的迁移
+func (h *DBHandler) Rollback(ctx context.Context, steps int) error {
+	migrator := database.NewMigrator(h.bs.DB())
+	rolledBack, err := migrator.Rollback(ctx, steps)
+	for _, mig := range rolledBack {
+		cli.PrintSuccess(fmt.Sprintf("已回滚迁移 %04d_%s", mig.Version, mig.Name))
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(rolledBack) == 0 {
+		cli.PrintInfo("没有可回滚的迁移")
+		return nil
+	}
+	current, err := migrator.CurrentVersion(ctx)
+	if err != nil {
+		return err
+	}
+	cli.PrintSuccess(fmt.Sprintf("回滚完成！当前版本: v%d", current))
+	// 当前程序只能使用最新结构，其他命令启动时会自动迁移回去
+	if current < migrator.LatestVersion() {
+		cli.PrintInfo(fmt.Sprintf("当前程序需要 v%d，请换用对应版本的程序访问此数据库；再次运行本程序的普通命令会自动迁移回 v%d", migrator.LatestVersion(), migrator.LatestVersion()))
+	}
+	return nil
+}
+
+// Check 检查数据库完整性和数据一致性
+// fix 为 true 时在一个事务中修复可自动修复的问题，然后重新检查
+func (h *DBHandler) Check(ctx context.Context, fix bool) error {
+	migrator := database.NewMigrator(h.bs.DB())
+	current, err := migrator.CurrentVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if current != migrator.LatestVersion() {
+		return fmt.Errorf("数据库版本 v%d 与程序支持的 v%d 不一致，请先执行 db migrate", current, migrator.LatestVersion())
+	}
+
+	report, err := database.CheckDatabase(ctx, h.bs.DB())
+	if err != nil {
+		return err
+	}
+	cli.PrintTitle(cli.IconSearch + " 数据库检查")
+	printStorageInfo(h.bs.Config())
+	printCheckReport(report)
+
+	if fix && report.ProblemCount() > 0 {
+		fixes, err := database.FixDatabase(ctx, h.bs.DB())
+		if err != nil {
+			return fmt.Errorf("修复失败，数据库未改动: %w", err)
+		}
+		fmt.Println()
+		for _, f := range fixes {
+			cli.PrintSuccess(fmt.Sprintf("已修复「%s」%d 行", f.Name, f.Fixed))
+		}
+		if report, err = database.CheckDatabase(ctx, h.bs.DB()); err != nil {
+			return err
+		}
+	}
+
+	problems := report.ProblemCount()
+	if problems == 0 {
+		fmt.Println()
+		cli.PrintSuccess("数据库检查通过，没有发现问题")
+		return nil
+	}
+	if fix {
+		return fmt.Errorf("仍有 %d 项问题需要手动处理", problems)
+	}
+	return fmt.Errorf("发现 %d 项问题，可执行 db check --fix 修复（修复前建议先 backup）", problems)
+}
+
+// printCheckReport 输出检查报告
+func printCheckReport(report *database.CheckReport) {
+	if len(report.Integrity) > 0 {
+		if report.IntegrityOK() {
+			cli.PrintSuccess("完整性检查（integrity_check）: ok")
+		} else {
+			cli.PrintError("完整性检查（integrity_check）未通过，请从备份恢复:")
+			for _, line := range report.Integrity {
+				fmt.Println("  " + line)
+			}
+		}
+		if len(report.ForeignKeys) == 0 {
+			cli.PrintSuccess("外键检查（foreign_key_check）: ok")
+		} else {
+			cli.PrintWarning(fmt.Sprintf("外键检查（foreign_key_check）发现 %d 处问题:", len(report.ForeignKeys)))
+			for i, line := range report.ForeignKeys {
+				if i == 5 {
+					fmt.Printf("  ...（共 %d 处）\n", len(report.ForeignKeys))
+					break
+				}
+				fmt.Println("  " + line)
+			}
+		}
+		fmt.Println()
+	}
+
+	table := output.NewTable("检查项", "问题数", "示例", "自动修复")
+	for _, issue := range report.Issues {
+		fixable := "-"
+		if issue.Count > 0 {
+			fixable = "否"
+			if issue.Fixable {
+				fixable = "是"
+			}
+		}
+		table.AddRow(issue.Name, fmt.Sprintf("%d", issue.Count), strings.Join(issue.Samples, ", "), fixable)
+	}
+	table.Print()
+}
+
+// printStorageInfo 输出当前使用的配置文件、profile 和数据库
+func printStorageInfo(cfg *app.Config) {
+	profile := cfg.Profile()
+	if profile == "" {
+		profile = "-"
+	}
+	storage := cfg.DBPath
+	if driver, _ := cfg.DatabaseDriver(); driver == database.DriverPostgres {
+		storage = "PostgreSQL"
+	}
+	fmt.Printf("配置: %s | Profile: %s | 数据库: %s\n\n", cfg.ConfigPath(), profile, storage)
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/XiaoLFeng/llm-memory/internal/app"
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/encryption"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	"gorm.io/gorm"
+)
+
+// EncryptHandler 静态加密命令处理器
+type EncryptHandler struct {
+	bs *startup.Bootstrap
+}
+
+// NewEncryptHandler 创建静态加密处理器
+func NewEncryptHandler(bs *startup.Bootstrap) *EncryptHandler {
+	return &EncryptHandler{bs: bs}
+}
+
+// Enable 启用静态加密并加密已有数据
+// keyFile 为空时使用 LLM_MEMORY_PASSPHRASE 环境变量中的口令
+func (h *EncryptHandler) Enable(ctx context.Context, keyFile string) error {
+	cfg := h.bs.Config()
+	if cfg.Encryption.Enabled {
+		return errors.New("已启用加密，如需更换密钥请使用 encrypt rotate-key")
+	}
+
+	passphrase := ""
+	if keyFile == "" {
+		if passphrase = os.Getenv(encryption.PassphraseEnv); passphrase == "" {
+			return fmt.Errorf("请使用 --key-file 指定密钥文件，或设置环境变量 %s", encryption.PassphraseEnv)
+		}
+	}
+	newCfg, c, err := encryption.NewConfig(keyFile, passphrase)
+	if err != nil {
+		return err
+	}
+
+	// 先保存配置：即使后续加密中断，未加密的数据仍可按明文读取
+	cfg.SetEncryption(newCfg)
+	if err := app.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("保存配置失败: %w", err)
+	}
+	database.SetContentCipher(c)
+
+	var count int
+	err = h.bs.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if count, err = database.ReencryptContent(tx, nil, c); err != nil {
+			return err
+		}
+		// 重建索引，把明文内容从全文索引中移除
+		return database.RebuildMemoryFTS(tx)
+	})
+	if err != nil {
+		return fmt.Errorf("加密已有数据失败，已有数据仍为明文（新写入的内容会加密，可执行 encrypt rotate-key 补全加密）: %w", err)
+	}
+	if err := compactDatabase(ctx, h.bs.DB()); err != nil {
+		cli.PrintWarning("清理数据库空闲页失败，旧的明文可能仍残留在文件中: " + err.Error())
+	}
+
+	cli.PrintSuccess(fmt.Sprintf("已启用静态加密！加密 %d 条内容", count))
+	if newCfg.KeyFile != "" {
+		cli.PrintInfo("密钥文件: " + newCfg.KeyFile + "（请妥善备份，丢失后数据无法恢复）")
+	} else {
+		cli.PrintInfo(fmt.Sprintf("之后运行 llm-memory（包括 MCP 服务）都需要设置环境变量 %s", encryption.PassphraseEnv))
+	}
+	cli.PrintWarning("启用前生成的备份仍为明文，请自行确认是否删除: " + cfg.BackupDir())
+	cli.PrintWarning("以下数据不加密：标题、分类、标签、计划描述（全文索引中的标题、分类和标签同样为明文），" +
+		"以及语义向量表 memory_embeddings（由明文计算，可能被还原出部分词语），请不要在其中写敏感信息")
+	return nil
+}
+
+// RotateKey 轮换加密密钥
+// keyFile 为空时使用 LLM_MEMORY_NEW_PASSPHRASE 环境变量中的新口令
+func (h *EncryptHandler) RotateKey(ctx context.Context, keyFile string) error {
+	cfg := h.bs.Config()
+	if !cfg.Encryption.Enabled {
+		return errors.New("尚未启用加密，请先执行 encrypt enable")
+	}
+	oldCfg := cfg.Encryption
+	oldCipher, err := oldCfg.LoadCipher()
+	if err != nil {
+		return err
+	}
+
+	passphrase := ""
+	if keyFile == "" {
+		if passphrase = os.Getenv(encryption.NewPassphraseEnv); passphrase == "" {
+			return fmt.Errorf("请使用 --key-file 指定新的密钥文件，或设置环境变量 %s", encryption.NewPassphraseEnv)
+		}
+	} else if absPath, _ := filepath.Abs(keyFile); absPath == oldCfg.KeyFile {
+		return errors.New("新密钥文件与当前密钥文件相同")
+	}
+	newCfg, newCipher, err := encryption.NewConfig(keyFile, passphrase)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	err = h.bs.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		count, err = database.ReencryptContent(tx, oldCipher, newCipher)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("重新加密失败，数据未改动: %w", err)
+	}
+	database.SetContentCipher(newCipher)
+
+	cfg.SetEncryption(newCfg)
+	if err := app.SaveConfig(cfg); err != nil {
+		// 配置写入失败时把数据换回旧密钥，保证配置与数据一致
+		rollbackErr := h.bs.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			_, err := database.ReencryptContent(tx, newCipher, oldCipher)
+			return err
+		})
+		cfg.SetEncryption(oldCfg)
+		database.SetContentCipher(oldCipher)
+		if rollbackErr != nil {
+			return fmt.Errorf("保存配置失败且无法恢复旧密钥（%v），请保留新旧密钥并联系维护者: %w", rollbackErr, err)
+		}
+		return fmt.Errorf("保存配置失败，已恢复为旧密钥: %w", err)
+	}
+	if err := compactDatabase(ctx, h.bs.DB()); err != nil {
+		cli.PrintWarning("清理数据库空闲页失败: " + err.Error())
+	}
+
+	cli.PrintSuccess(fmt.Sprintf("密钥轮换完成！重新加密 %d 条内容", count))
+	if newCfg.KeyFile != "" {
+		cli.PrintInfo("新密钥文件: " + newCfg.KeyFile)
+	} else {
+		cli.PrintInfo(fmt.Sprintf("请将环境变量 %s 更新为新口令（包括 MCP 客户端配置）", encryption.PassphraseEnv))
+	}
+	cli.PrintWarning("旧备份仍使用旧密钥加密，恢复后需要用旧密钥读取")
+	return nil
+}
+
+// compactDatabase 写回 WAL 并 VACUUM，清除空闲页中残留的旧内容
+// PostgreSQL 使用 VACUUM FULL 重写加密字段所在的表，清除旧版本行
+func compactDatabase(ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
+	if database.IsPostgres(db) {
+		tables := make([]string, 0, len(database.EncryptedColumns))
+		for _, col := range database.EncryptedColumns {
+			tables = append(tables, col.Table)
+		}
+		return db.Exec("VACUUM FULL " + strings.Join(tables, ", ")).Error
+	}
+	if err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)").Error; err != nil {
+		return err
+	}
+	if err := db.Exec("VACUUM").Error; err != nil {
+		return err
+	}
+	return db.Exec("PRAGMA wal_checkpoint(TRUNCATE)").Error
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/encryption"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/XiaoLFeng/llm-memory/startup"
+)
+
+// TestRotateKeyRollback 轮换密钥后保存配置失败时，数据换回旧密钥，配置和进程内的密钥保持一致
+func TestRotateKeyRollback(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	bs := startup.New(
+		startup.WithConfigPath(configPath),
+		startup.WithDBPath(filepath.Join(dir, "data.db")),
+		startup.WithSignalHandler(false),
+	)
+	if err := bs.Initialize(ctx); err != nil {
+		t.Fatalf("初始化应用失败: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = bs.Shutdown()
+		database.SetContentCipher(nil)
+	})
+
+	memory := &entity.Memory{ID: database.GenerateID(), Code: "mem", Title: "记忆", Content: "秘密内容", Version: 1}
+	if err := bs.DB().Create(memory).Error; err != nil {
+		t.Fatalf("创建记忆失败: %v", err)
+	}
+	handler := NewEncryptHandler(bs)
+	oldKeyFile := filepath.Join(dir, "old.key")
+	if err := handler.Enable(ctx, oldKeyFile); err != nil {
+		t.Fatalf("启用加密失败: %v", err)
+	}
+
+	// 把配置文件换成目录，让保存配置失败
+	if err := os.Remove(configPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(configPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	err := handler.RotateKey(ctx, filepath.Join(dir, "new.key"))
+	if err == nil || !strings.Contains(err.Error(), "已恢复为旧密钥") {
+		t.Fatalf("保存配置失败时应恢复旧密钥: %v", err)
+	}
+
+	if got := bs.Config().Encryption.KeyFile; got != oldKeyFile {
+		t.Fatalf("配置中的密钥文件 = %s，期望 %s", got, oldKeyFile)
+	}
+	oldCipher, err := bs.Config().Encryption.LoadCipher()
+	if err != nil {
+		t.Fatalf("加载旧密钥失败: %v", err)
+	}
+	var raw string
+	if err := bs.DB().Raw("SELECT content FROM memories WHERE id = ?", memory.ID).Scan(&raw).Error; err != nil {
+		t.Fatalf("读取原始内容失败: %v", err)
+	}
+	if plaintext, err := oldCipher.Decrypt(raw); !encryption.IsEncrypted(raw) || err != nil || plaintext != "秘密内容" {
+		t.Fatalf("数据应使用旧密钥加密: %q（%v）", raw, err)
+	}
+	var stored entity.Memory
+	if err := bs.DB().First(&stored, memory.ID).Error; err != nil || stored.Content != "秘密内容" {
+		t.Fatalf("进程内应恢复为旧密钥: %q（%v）", stored.Content, err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/XiaoLFeng/llm-memory/internal/cli/output"
 	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
 	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/XiaoLFeng/llm-memory/pkg/utils"
 	"github.com/XiaoLFeng/llm-memory/startup"
 )
 
@@ -76,24 +77,52 @@ func (h *MemoryHandler) Create(ctx context.Context, code, title, content, catego
 
 // Search 搜索记忆
 func (h *MemoryHandler) Search(ctx context.Context, keyword string) error {
-	// 使用 SearchMemoriesByScope 确保权限隔离：全局 + 当前路径相关
-	memories, err := h.bs.MemoryService.SearchMemoriesByScope(ctx, keyword, "all", h.bs.CurrentScope)
+	// 使用 SearchMemoriesWithSnippets 确保权限隔离：全局 + 当前路径相关
+	hits, err := h.bs.MemoryService.SearchMemoriesWithSnippets(ctx, keyword, "all", h.bs.CurrentScope)
 	if err != nil {
 		return err
 	}
 
-	if len(memories) == 0 {
+	if len(hits) == 0 {
 		cli.PrintInfo(fmt.Sprintf("未找到包含 \"%s\" 的记忆~", keyword))
 		return nil
 	}
 
-	cli.PrintTitle(fmt.Sprintf("%s 搜索结果 (%d 条)", cli.IconSearch, len(memories)))
-	table := output.NewTable("标识码", "标题", "分类")
-	for _, m := range memories {
+	cli.PrintTitle(fmt.Sprintf("%s 搜索结果 (%d 条)", cli.IconSearch, len(hits)))
+	table := output.NewTable("标识码", "标题", "分类", "匹配片段")
+	for _, hit := range hits {
 		table.AddRow(
-			m.Code,
-			m.Title,
-			m.Category,
+			hit.Memory.Code,
+			hit.Memory.Title,
+			hit.Memory.Category,
+			hit.Snippet,
+		)
+	}
+	table.Print()
+
+	return nil
+}
+
+// SemanticSearch 语义搜索记忆
+func (h *MemoryHandler) SemanticSearch(ctx context.Context, query string, topK int) error {
+	hits, err := h.bs.MemoryService.SemanticSearchMemories(ctx, query, "all", h.bs.CurrentScope, topK)
+	if err != nil {
+		return err
+	}
+
+	if len(hits) == 0 {
+		cli.PrintInfo("暂无可搜索的记忆~")
+		return nil
+	}
+
+	cli.PrintTitle(fmt.Sprintf("%s 语义搜索结果 (%d 条)", cli.IconSearch, len(hits)))
+	table := output.NewTable("标识码", "标题", "分类", "相似度")
+	for _, hit := range hits {
+		table.AddRow(
+			hit.Memory.Code,
+			hit.Memory.Title,
+			hit.Memory.Category,
+			fmt.Sprintf("%.3f", hit.Similarity),
 		)
 	}
 	table.Print()
@@ -131,6 +160,7 @@ func (h *MemoryHandler) Get(ctx context.Context, code string) error {
 		fmt.Printf("标签:     %s\n", strings.Join(tags, ", "))
 	}
 	fmt.Printf("优先级:   %d\n", memory.Priority)
+	fmt.Printf("版本:     %d\n", memory.Version)
 	fmt.Printf("创建时间: %s\n", memory.CreatedAt.Format("2006-01-02 15:04:05"))
 	fmt.Printf("更新时间: %s\n", memory.UpdatedAt.Format("2006-01-02 15:04:05"))
 	fmt.Println("\n内容:")
@@ -140,7 +170,8 @@ func (h *MemoryHandler) Get(ctx context.Context, code string) error {
 }
 
 // Update 更新记忆
-func (h *MemoryHandler) Update(ctx context.Context, code string, title, content, category *string, tags *[]string, priority *int) error {
+// version 不为 nil 时要求记忆仍是该版本，否则返回版本冲突
+func (h *MemoryHandler) Update(ctx context.Context, code string, title, content, category *string, tags *[]string, priority *int, version *int64) error {
 	updateDTO := &dto.MemoryUpdateDTO{
 		Code:     code,
 		Title:    title,
@@ -148,6 +179,7 @@ func (h *MemoryHandler) Update(ctx context.Context, code string, title, content,
 		Category: category,
 		Tags:     tags,
 		Priority: priority,
+		Version:  version,
 	}
 
 	if err := h.bs.MemoryService.UpdateMemory(ctx, updateDTO); err != nil {
@@ -175,3 +207,101 @@ func (h *MemoryHandler) Update(ctx context.Context, code string, title, content,
 	cli.PrintSuccess(fmt.Sprintf("记忆 %s 更新成功！更新字段: %s", code, strings.Join(updated, ", ")))
 	return nil
 }
+
+// History 显示记忆的修订历史
+func (h *MemoryHandler) History(ctx context.Context, code string) error {
+	memory, revisions, err := h.bs.MemoryService.ListMemoryRevisions(ctx, code)
+	if err != nil {
+		return err
+	}
+
+	cli.PrintTitle(fmt.Sprintf("%s 修订历史: %s (%s)", cli.IconMemory, memory.Title, memory.Code))
+	if len(revisions) == 0 {
+		cli.PrintInfo("这条记忆还没有被修改过~")
+		return nil
+	}
+
+	table := output.NewTable("修订", "操作", "来源", "修改前标题", "修改时间")
+	table.AddRow("current", "-", "-", memory.Title, memory.UpdatedAt.Format("2006-01-02 15:04:05"))
+	for _, rev := range revisions {
+		table.AddRow(
+			fmt.Sprintf("%d", rev.Revision),
+			rev.Action,
+			rev.Source,
+			rev.Title,
+			rev.CreatedAt.Format("2006-01-02 15:04:05"),
+		)
+	}
+	table.Print()
+
+	fmt.Println()
+	cli.PrintInfo("修订 N 保存的是第 N 次修改之前的内容，可使用 memory diff / memory revert 查看或恢复")
+	return nil
+}
+
+// Diff 对比记忆两个修订点的差异
+func (h *MemoryHandler) Diff(ctx context.Context, code string, fromRev, toRev int) error {
+	diffs, err := h.bs.MemoryService.DiffMemoryRevisions(ctx, code, fromRev, toRev)
+	if err != nil {
+		return err
+	}
+
+	cli.PrintTitle(fmt.Sprintf("%s %s: %s → %s", cli.IconEdit, code, revisionLabel(fromRev), revisionLabel(toRev)))
+	if len(diffs) == 0 {
+		cli.PrintInfo("两个版本内容完全一致~")
+		return nil
+	}
+
+	for _, d := range diffs {
+		fmt.Printf("\n%s\n", cli.TitleStyle.Render("["+d.Field+"]"))
+		for _, line := range d.Lines {
+			switch line.Op {
+			case utils.DiffInsert:
+				fmt.Println(cli.SuccessStyle.Render(line.String()))
+			case utils.DiffDelete:
+				fmt.Println(cli.ErrorStyle.Render(line.String()))
+			default:
+				fmt.Println(cli.MutedStyle.Render(line.String()))
+			}
+		}
+	}
+	return nil
+}
+
+// Revert 将记忆回滚到指定修订
+func (h *MemoryHandler) Revert(ctx context.Context, code string, revision int) error {
+	if err := h.bs.MemoryService.RevertMemory(ctx, code, revision); err != nil {
+		return err
+	}
+	cli.PrintSuccess(fmt.Sprintf("记忆 %s 已回滚到修订 %d 的内容", code, revision))
+	return nil
+}
+
+// revisionLabel 修订号的展示文本
+func revisionLabel(revision int) string {
+	if revision == 0 {
+		return "current"
+	}
+	return fmt.Sprintf("r%d", revision)
+}
+
+// ImportMarkdown 将目录中的 Markdown 文件导入为当前作用域的记忆
+func (h *MemoryHandler) ImportMarkdown(ctx context.Context, dir string) error {
+	result, err := h.bs.MemoryService.ImportMarkdownDir(ctx, dir, h.bs.CurrentScope)
+	if err != nil {
+		return err
+	}
+
+	cli.PrintSuccess(fmt.Sprintf("导入完成！新建 %d，更新 %d，未变化 %d",
+		len(result.Created), len(result.Updated), result.Unchanged))
+	for _, code := range result.Created {
+		cli.PrintInfo("新建: " + code)
+	}
+	for _, code := range result.Updated {
+		cli.PrintInfo("更新: " + code)
+	}
+	for _, w := range result.Warnings {
+		cli.PrintWarning(w)
+	}
+	return nil
+}
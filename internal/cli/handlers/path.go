@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/output"
+	"github.com/XiaoLFeng/llm-memory/startup"
+)
+
+// PathHandler 路径维护命令处理器
+type PathHandler struct {
+	bs *startup.Bootstrap
+}
+
+// NewPathHandler 创建路径维护处理器
+func NewPathHandler(bs *startup.Bootstrap) *PathHandler {
+	return &PathHandler{bs: bs}
+}
+
+// Move 把路径搬迁到新位置
+func (h *PathHandler) Move(ctx context.Context, oldPath, newPath string) error {
+	result, err := h.bs.PathService.Move(ctx, oldPath, newPath)
+	if err != nil {
+		return err
+	}
+
+	table := output.NewTable("原路径", "新路径")
+	for _, m := range result.Moved {
+		table.AddRow(m.From, m.To)
+	}
+	table.Print()
+
+	fmt.Println()
+	cli.PrintSuccess(fmt.Sprintf("已搬迁 %d 个路径，记忆、计划、待办和组成员关系保持不变", len(result.Moved)))
+	if result.Replaced > 0 {
+		cli.PrintInfo(fmt.Sprintf("替换了 %d 个没有数据的新路径记录", result.Replaced))
+	}
+	return nil
+}
+
+// Merge 把一个路径的数据合并到另一个路径
+func (h *PathHandler) Merge(ctx context.Context, fromPath, toPath string) error {
+	result, err := h.bs.PathService.Merge(ctx, fromPath, toPath)
+	if err != nil {
+		return err
+	}
+
+	cli.PrintSuccess(fmt.Sprintf("已将 %s 合并到 %s：%d 条记忆、%d 个计划、%d 个待办",
+		result.From, result.To, result.Memories, result.Plans, result.Todos))
+	if len(result.Renamed) > 0 {
+		cli.PrintWarning(fmt.Sprintf("%d 个条目的 code 与目标路径冲突，已改名:", len(result.Renamed)))
+		table := output.NewTable("类型", "原 code", "新 code")
+		for _, r := range result.Renamed {
+			table.AddRow(r.Type, r.From, r.To)
+		}
+		table.Print()
+	}
+	if result.JoinedGroup {
+		cli.PrintInfo("目标路径已加入原路径所在的组")
+	}
+	if result.LeftGroup {
+		cli.PrintWarning("两个路径属于不同的组，保留目标路径的组，原路径已从它的组中移除")
+	}
+	return nil
+}
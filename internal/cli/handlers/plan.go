@@ -118,6 +118,7 @@ func (h *PlanHandler) Get(ctx context.Context, code string) error {
 	fmt.Printf("标题:     %s\n", plan.Title)
 	fmt.Printf("状态:     %s\n", getPlanStatusText(plan.Status))
 	fmt.Printf("进度:     %d%%\n", plan.Progress)
+	fmt.Printf("版本:     %d\n", plan.Version)
 	fmt.Printf("创建时间: %s\n", plan.CreatedAt.Format("2006-01-02 15:04:05"))
 	fmt.Printf("更新时间: %s\n", plan.UpdatedAt.Format("2006-01-02 15:04:05"))
 	if plan.Description != "" {
@@ -133,13 +134,15 @@ func (h *PlanHandler) Get(ctx context.Context, code string) error {
 }
 
 // Update 更新计划
-func (h *PlanHandler) Update(ctx context.Context, code string, title, description, content *string, progress *int) error {
+// version 不为 nil 时要求计划仍是该版本，否则返回版本冲突
+func (h *PlanHandler) Update(ctx context.Context, code string, title, description, content *string, progress *int, version *int64) error {
 	updateDTO := &dto.PlanUpdateDTO{
 		Code:        code,
 		Title:       title,
 		Description: description,
 		Content:     content,
 		Progress:    progress,
+		Version:     version,
 	}
 
 	if err := h.bs.PlanService.UpdatePlan(ctx, updateDTO); err != nil {
@@ -139,6 +139,7 @@ func (h *TodoHandler) Get(ctx context.Context, code string) error {
 	if todo.CompletedAt != nil {
 		fmt.Printf("完成时间: %s\n", todo.CompletedAt.Format("2006-01-02 15:04:05"))
 	}
+	fmt.Printf("版本:     %d\n", todo.Version)
 	fmt.Printf("创建时间: %s\n", todo.CreatedAt.Format("2006-01-02 15:04:05"))
 	if todo.Description != "" {
 		fmt.Println("\n描述:")
@@ -149,13 +150,14 @@ func (h *TodoHandler) Get(ctx context.Context, code string) error {
 }
 
 // Update 更新待办
-func (h *TodoHandler) Update(ctx context.Context, code string, title, description *string, priority, status *int) error {
+func (h *TodoHandler) Update(ctx context.Context, code string, title, description *string, priority, status *int, version *int64) error {
 	updateDTO := &dto.ToDoUpdateDTO{
 		Code:        code,
 		Title:       title,
 		Description: description,
 		Priority:    priority,
 		Status:      status,
+		Version:     version,
 	}
 
 	if err := h.bs.ToDoService.UpdateToDo(ctx, updateDTO); err != nil {
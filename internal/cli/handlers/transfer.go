@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/startup"
+)
+
+// TransferHandler 导入导出命令处理器
+type TransferHandler struct {
+	bs *startup.Bootstrap
+}
+
+// NewTransferHandler 创建导入导出处理器
+func NewTransferHandler(bs *startup.Bootstrap) *TransferHandler {
+	return &TransferHandler{bs: bs}
+}
+
+// Export 导出数据到文件（file 为空或 "-" 时写到标准输出）
+func (h *TransferHandler) Export(ctx context.Context, file string) error {
+	toStdout := file == "" || file == "-"
+
+	var w io.Writer = os.Stdout
+	if !toStdout {
+		f, err := os.Create(file)
+		if err != nil {
+			return fmt.Errorf("创建导出文件失败: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	stats, err := h.bs.TransferService.Export(ctx, w)
+	if err != nil {
+		return err
+	}
+
+	summary := fmt.Sprintf("导出完成！路径 %d，组 %d，记忆 %d，计划 %d，待办 %d",
+		stats.Paths, stats.Groups, stats.Memories, stats.Plans, stats.Todos)
+	if toStdout {
+		// 标准输出已被数据占用，摘要写到标准错误
+		fmt.Fprintln(os.Stderr, summary)
+		return nil
+	}
+	cli.PrintSuccess(summary + " → " + file)
+	return nil
+}
+
+// Import 从文件导入数据（file 为 "-" 时从标准输入读取）
+// mappings 形如 "/old/path=/new/path"
+func (h *TransferHandler) Import(ctx context.Context, file, strategy string, mappings []string) error {
+	pathMap := make(map[string]string, len(mappings))
+	for _, m := range mappings {
+		from, to, ok := strings.Cut(m, "=")
+		if !ok || strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+			return errors.New("路径映射格式错误，应为 旧路径=新路径")
+		}
+		pathMap[strings.TrimSpace(from)] = strings.TrimSpace(to)
+	}
+
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return fmt.Errorf("打开导入文件失败: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	result, err := h.bs.TransferService.Import(ctx, r, &dto.ImportOptionsDTO{
+		Strategy: strategy,
+		PathMap:  pathMap,
+	})
+	if err != nil {
+		return err
+	}
+
+	imported := result.Imported
+	cli.PrintSuccess(fmt.Sprintf("导入完成！路径 %d，组 %d，记忆 %d，计划 %d，待办 %d",
+		imported.Paths, imported.Groups, imported.Memories, imported.Plans, imported.Todos))
+	if result.Skipped > 0 {
+		cli.PrintInfo(fmt.Sprintf("跳过 %d 条冲突条目", result.Skipped))
+	}
+	if result.Overwritten > 0 {
+		cli.PrintInfo(fmt.Sprintf("覆盖 %d 条本地条目", result.Overwritten))
+	}
+	for _, r := range result.Renamed {
+		cli.PrintInfo("重命名: " + r)
+	}
+	for _, m := range result.Matched {
+		cli.PrintInfo("按项目匹配路径: " + m)
+	}
+	for _, w := range result.Warnings {
+		cli.PrintWarning(w)
+	}
+	return nil
+}
+
+// ExportVault 导出为 Obsidian 兼容的 Markdown 仓库（增量更新）
+func (h *TransferHandler) ExportVault(ctx context.Context, dir string) error {
+	result, err := h.bs.TransferService.ExportVault(ctx, dir)
+	if err != nil {
+		return err
+	}
+
+	cli.PrintSuccess(fmt.Sprintf("Markdown 导出完成！写入 %d，未变化 %d，删除 %d → %s",
+		result.Written, result.Unchanged, result.Removed, dir))
+	return nil
+}
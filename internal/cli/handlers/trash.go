@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/XiaoLFeng/llm-memory/internal/cli"
+	"github.com/XiaoLFeng/llm-memory/internal/cli/output"
+	"github.com/XiaoLFeng/llm-memory/pkg/utils"
+	"github.com/XiaoLFeng/llm-memory/startup"
+)
+
+// TrashHandler 回收站命令处理器
+type TrashHandler struct {
+	bs *startup.Bootstrap
+}
+
+// NewTrashHandler 创建回收站处理器
+func NewTrashHandler(bs *startup.Bootstrap) *TrashHandler {
+	return &TrashHandler{bs: bs}
+}
+
+// List 列出回收站条目
+func (h *TrashHandler) List(ctx context.Context) error {
+	items, err := h.bs.TrashService.ListTrash(ctx, h.bs.CurrentScope)
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		cli.PrintInfo("回收站是空的~")
+		return nil
+	}
+
+	cli.PrintTitle(fmt.Sprintf("%s 回收站 (%d 条)", cli.IconTrash, len(items)))
+	table := output.NewTable("类型", "标识码", "标题", "删除时间")
+	for _, item := range items {
+		table.AddRow(
+			item.Type,
+			item.Code,
+			item.Title,
+			utils.FormatDateTime(item.DeletedAt),
+		)
+	}
+	table.Print()
+
+	fmt.Println()
+	cli.PrintInfo("使用 trash restore <type> <code> 恢复，随计划删除的待办会在恢复计划时一并恢复")
+	return nil
+}
+
+// Restore 从回收站恢复条目
+func (h *TrashHandler) Restore(ctx context.Context, itemType, code string) error {
+	if err := h.bs.TrashService.RestoreItem(ctx, itemType, code, h.bs.CurrentScope); err != nil {
+		return err
+	}
+	cli.PrintSuccess(fmt.Sprintf("%s %s 已从回收站恢复", itemType, code))
+	return nil
+}
+
+// Purge 彻底删除回收站中的旧条目
+func (h *TrashHandler) Purge(ctx context.Context, olderThan string) error {
+	d, err := utils.ParseDurationWithDays(olderThan)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.bs.TrashService.Purge(ctx, d)
+	if err != nil {
+		return err
+	}
+
+	if result.Total() == 0 {
+		cli.PrintInfo(fmt.Sprintf("没有删除超过 %s 的条目~", olderThan))
+		return nil
+	}
+	cli.PrintSuccess(fmt.Sprintf("已彻底删除 %d 条：记忆 %d，计划 %d，待办 %d",
+		result.Total(), result.Memories, result.Plans, result.Todos))
+	return nil
+}
@@ -23,4 +23,5 @@ const (
 	IconBulb      = "" // nf-fa-lightbulb_o - 提示
 	IconClipboard = "" // nf-fa-clipboard - 剪贴板
 	IconChart     = "" // nf-fa-bar_chart - 图表
+	IconTrash     = "" // nf-fa-trash - 回收站
 )
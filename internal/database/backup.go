@@ -0,0 +1,253 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 嘿嘿~ 这是在线备份与恢复模块！(´∀｀)💖
+// 使用 VACUUM INTO 在不停机的情况下生成一致的快照，WAL 中的数据也会包含在内~
+
+// 备份文件命名：llm-memory-20060102-150405.db
+const (
+	BackupFilePrefix = "llm-memory-"
+	BackupFileExt    = ".db"
+	backupTimeLayout = "20060102-150405"
+)
+
+// backupNameRegex 匹配自动命名的备份文件（轮转只处理这类文件）
+var backupNameRegex = regexp.MustCompile(`^llm-memory-\d{8}-\d{6}\.db$`)
+
+// 错误定义
+var (
+	// ErrBackupExists 备份目标文件已存在
+	ErrBackupExists = errors.New("备份目标文件已存在")
+	// ErrIntegrityCheck 数据库完整性检查未通过
+	ErrIntegrityCheck = errors.New("数据库完整性检查未通过")
+	// ErrNotLLMMemoryDB 文件不是 llm-memory 数据库
+	ErrNotLLMMemoryDB = errors.New("文件不是 llm-memory 数据库（缺少 schema_migrations 和 memories 表）")
+)
+
+// BackupInfo 备份文件检查结果
+type BackupInfo struct {
+	Path          string
+	Size          int64
+	SchemaVersion int // 备份中的数据库结构版本
+}
+
+// BackupFileName 生成指定时间的备份文件名
+func BackupFileName(t time.Time) string {
+	return BackupFilePrefix + t.Format(backupTimeLayout) + BackupFileExt
+}
+
+// BackupSQLite 通过 VACUUM INTO 生成一致的在线备份
+// 先写入临时文件，完成后再重命名，避免留下半截备份
+func BackupSQLite(ctx context.Context, db *gorm.DB, dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("%w: %s", ErrBackupExists, dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	tmp := dest + ".tmp"
+	_ = os.Remove(tmp)
+	if err := db.WithContext(ctx).Exec("VACUUM INTO ?", tmp).Error; err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("生成备份失败: %w", err)
+	}
+	return os.Rename(tmp, dest)
+}
+
+// ListBackups 列出目录中自动命名的备份文件（按时间从新到旧）
+func ListBackups(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && backupNameRegex.MatchString(e.Name()) {
+			names = append(names, e.Name())
+		}
+	}
+	// 文件名中的时间戳可以直接按字典序比较
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(dir, name)
+	}
+	return paths, nil
+}
+
+// RotateBackups 只保留最新的 keep 份自动命名的备份，返回被删除的文件
+// keep <= 0 时不做轮转
+func RotateBackups(dir string, keep int) ([]string, error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) <= keep {
+		return nil, nil
+	}
+
+	var removed []string
+	for _, path := range backups[keep:] {
+		if err := os.Remove(path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
+
+// HasBackupOnDay 目录中是否已有指定日期的自动备份
+func HasBackupOnDay(dir string, day time.Time) (bool, error) {
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return false, err
+	}
+	prefix := BackupFilePrefix + day.Format("20060102") + "-"
+	for _, path := range backups {
+		if strings.HasPrefix(filepath.Base(path), prefix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// InspectSQLiteFile 检查备份文件：完整性检查 + 读取结构版本
+// 使用独立的只读连接打开，不会修改备份文件，也不影响当前数据库
+func InspectSQLiteFile(ctx context.Context, path string) (*BackupInfo, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if stat.IsDir() {
+		return nil, fmt.Errorf("%s 是目录", path)
+	}
+
+	conn, err := gorm.Open(sqlite.Open("file:"+path+"?mode=ro"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return nil, err
+	}
+	defer sqlDB.Close()
+	conn = conn.WithContext(ctx)
+
+	var results []string
+	if err := conn.Raw("PRAGMA integrity_check").Scan(&results).Error; err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIntegrityCheck, err)
+	}
+	if len(results) != 1 || results[0] != "ok" {
+		return nil, fmt.Errorf("%w: %s", ErrIntegrityCheck, strings.Join(results, "; "))
+	}
+
+	// 直接查询迁移表（Migrator 会建表，不能用在只读的备份上）
+	// 没有迁移表的旧版本数据库视为 v0，恢复后启动时会自动迁移
+	version := 0
+	if conn.Migrator().HasTable(&SchemaMigration{}) {
+		if err := conn.Model(&SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error; err != nil {
+			return nil, err
+		}
+	} else if !conn.Migrator().HasTable("memories") {
+		return nil, ErrNotLLMMemoryDB
+	}
+
+	return &BackupInfo{
+		Path:          path,
+		Size:          stat.Size(),
+		SchemaVersion: version,
+	}, nil
+}
+
+// RestoreSQLite 用备份文件替换数据库文件
+// 调用前必须关闭数据库连接；先复制到临时文件再原子重命名，并清理旧的 WAL/SHM 文件
+// 备份中的雪花节点租约属于备份时运行的进程，恢复时一并清除
+func RestoreSQLite(src, dbPath string) error {
+	tmp := dbPath + ".restore.tmp"
+	if err := copyFile(src, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("复制备份文件失败: %w", err)
+	}
+	if err := clearNodeLeases(tmp); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("清除备份中的雪花节点租约失败: %w", err)
+	}
+
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			_ = os.Remove(tmp)
+			return err
+		}
+	}
+	return os.Rename(tmp, dbPath)
+}
+
+// clearNodeLeases 删除数据库文件中的所有雪花节点租约（旧版本没有租约表时跳过）
+func clearNodeLeases(path string) error {
+	conn, err := gorm.Open(sqlite.Open(path), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return err
+	}
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	if !conn.Migrator().HasTable(&SnowflakeNodeLease{}) {
+		return nil
+	}
+	return conn.Where("1 = 1").Delete(&SnowflakeNodeLease{}).Error
+}
+
+// copyFile 复制文件并刷盘
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
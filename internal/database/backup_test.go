@@ -0,0 +1,172 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// backupTestRow 备份测试数据
+type backupTestRow struct {
+	ID   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name string
+}
+
+// openBackupTestDB 以正式连接相同的 PRAGMA（WAL）打开数据库
+func openBackupTestDB(t *testing.T, path string) (*gorm.DB, func()) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(path+"?"+SQLitePragmas), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	closeDB := func() { _ = sqlDB.Close() }
+	t.Cleanup(closeDB)
+	return db, closeDB
+}
+
+// rowNames 读取测试数据的名称
+func rowNames(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	var names []string
+	if err := db.Model(&backupTestRow{}).Order("id ASC").Pluck("name", &names).Error; err != nil {
+		t.Fatalf("读取数据失败: %v", err)
+	}
+	return names
+}
+
+// TestBackupInspectRestore 备份得到一致快照，检查不修改备份文件，恢复后回到备份时的数据
+func TestBackupInspectRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "data.db")
+	db, closeDB := openBackupTestDB(t, dbPath)
+	if _, err := NewMigrator(db).Migrate(ctx); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	if err := db.AutoMigrate(&backupTestRow{}); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+	if err := db.Create(&backupTestRow{ID: 1, Name: "before"}).Error; err != nil {
+		t.Fatalf("写入数据失败: %v", err)
+	}
+
+	// 备份时本进程持有的租约，恢复后不应带回来
+	if err := db.Create(&SnowflakeNodeLease{NodeID: 1, Owner: "backup", ExpiresAt: time.Now().UTC().Add(NodeLeaseTTL)}).Error; err != nil {
+		t.Fatalf("写入租约失败: %v", err)
+	}
+
+	// 1. 备份（WAL 中未检查点的数据也要包含在内）
+	backup := filepath.Join(dir, "backups", BackupFileName(time.Now()))
+	if err := BackupSQLite(ctx, db, backup); err != nil {
+		t.Fatalf("备份失败: %v", err)
+	}
+	if err := BackupSQLite(ctx, db, backup); !errors.Is(err, ErrBackupExists) {
+		t.Fatalf("重复备份: %v，期望 ErrBackupExists", err)
+	}
+	if _, err := os.Stat(backup + ".tmp"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("不应留下临时文件")
+	}
+
+	// 2. 检查备份：只读，不修改文件
+	before, err := os.ReadFile(backup)
+	if err != nil {
+		t.Fatalf("读取备份失败: %v", err)
+	}
+	info, err := InspectSQLiteFile(ctx, backup)
+	if err != nil {
+		t.Fatalf("检查备份失败: %v", err)
+	}
+	if latest := NewMigrator(db).LatestVersion(); info.SchemaVersion != latest {
+		t.Fatalf("备份结构版本 = v%d，期望 v%d", info.SchemaVersion, latest)
+	}
+	after, err := os.ReadFile(backup)
+	if err != nil {
+		t.Fatalf("读取备份失败: %v", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Fatalf("检查不应修改备份文件")
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if _, err := os.Stat(backup + suffix); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("检查不应生成 %s 文件", suffix)
+		}
+	}
+
+	// 3. 备份后继续写入，然后恢复
+	if err := db.Create(&backupTestRow{ID: 2, Name: "after"}).Error; err != nil {
+		t.Fatalf("写入数据失败: %v", err)
+	}
+	closeDB()
+	if err := RestoreSQLite(backup, dbPath); err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if _, err := os.Stat(dbPath + suffix); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("恢复后应清理旧的 %s 文件", suffix)
+		}
+	}
+
+	restored, _ := openBackupTestDB(t, dbPath)
+	names := rowNames(t, restored)
+	if len(names) != 1 || names[0] != "before" {
+		t.Fatalf("恢复后的数据 = %v，期望 [before]", names)
+	}
+	leases, err := LiveNodeLeases(ctx, restored, "")
+	if err != nil || len(leases) != 0 {
+		t.Fatalf("恢复后的租约 = %v (%v)，期望清空", leases, err)
+	}
+}
+
+// TestInspectSQLiteFileRejects 检查拒绝不存在、损坏和不是 llm-memory 的文件
+func TestInspectSQLiteFileRejects(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	corrupt := filepath.Join(dir, "corrupt.db")
+	if err := os.WriteFile(corrupt, bytes.Repeat([]byte("not a database "), 512), 0644); err != nil {
+		t.Fatal(err)
+	}
+	foreign := filepath.Join(dir, "foreign.db")
+	db, closeDB := openBackupTestDB(t, foreign)
+	if err := db.AutoMigrate(&backupTestRow{}); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+	closeDB()
+
+	cases := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{name: "文件不存在", path: filepath.Join(dir, "missing.db"), wantErr: os.ErrNotExist},
+		{name: "目录", path: dir},
+		{name: "损坏的文件", path: corrupt},
+		{name: "其他程序的数据库", path: foreign, wantErr: ErrNotLLMMemoryDB},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := InspectSQLiteFile(ctx, tc.path)
+			if err == nil {
+				t.Fatalf("检查 %s 应失败", tc.path)
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Fatalf("错误 = %v，期望 %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// 嘿嘿~ 这是数据库一致性检查！🩺
+// 删除记忆、计划时由 Model 层手动清理关联数据，group_paths 对 paths 的引用也比较宽松，
+// 中途失败或旧版本留下的孤儿行会越积越多，这里逐项检查并在 --fix 时统一修复~
+
+// checkSampleLimit 每项检查最多列出的示例数
+const checkSampleLimit = 5
+
+// CheckIssue 一项一致性检查的结果
+type CheckIssue struct {
+	Name    string   // 检查项说明
+	Count   int64    // 有问题的行数
+	Samples []string // 示例（最多 checkSampleLimit 条）
+	Fixable bool     // 是否可以自动修复
+}
+
+// CheckReport 数据库检查报告
+type CheckReport struct {
+	Integrity   []string     // PRAGMA integrity_check 结果（通过时为 ok；PostgreSQL 为空）
+	ForeignKeys []string     // PRAGMA foreign_key_check 发现的问题（PostgreSQL 为空）
+	Issues      []CheckIssue // 各项一致性检查结果（包括没有问题的项）
+}
+
+// IntegrityOK 完整性检查是否通过
+func (r *CheckReport) IntegrityOK() bool {
+	return len(r.Integrity) == 0 || (len(r.Integrity) == 1 && r.Integrity[0] == "ok")
+}
+
+// ProblemCount 有问题的检查项数量
+func (r *CheckReport) ProblemCount() int {
+	count := len(r.ForeignKeys)
+	if !r.IntegrityOK() {
+		count++
+	}
+	for _, issue := range r.Issues {
+		if issue.Count > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// CheckFix 一项修复的结果
+type CheckFix struct {
+	Name  string // 检查项说明
+	Fixed int64  // 修复的行数
+}
+
+// consistencyCheck 一项一致性检查
+type consistencyCheck struct {
+	name   string                           // 检查项说明
+	table  string                           // 检查的表
+	where  string                           // 有问题的行的条件
+	sample string                           // 示例的列表达式
+	fix    func(tx *gorm.DB) (int64, error) // 修复方法，nil 表示需要手动处理
+	fts    bool                             // 是否依赖全文索引表（仅 SQLite）
+}
+
+// consistencyChecks 所有一致性检查（按修复顺序排列：先删除孤儿行，再清理由此产生的下游孤儿行）
+var consistencyChecks = []consistencyCheck{
+	{
+		name:   "组路径映射指向不存在的组",
+		table:  "group_paths",
+		where:  `group_id NOT IN (SELECT id FROM "groups")`,
+		sample: `'id=' || id || ' group_id=' || group_id`,
+		fix:    deleteRows("group_paths", `group_id NOT IN (SELECT id FROM "groups")`),
+	},
+	{
+		name:   "组路径映射指向不存在的路径",
+		table:  "group_paths",
+		where:  `personal_path_id NOT IN (SELECT id FROM paths)`,
+		sample: `'id=' || id || ' personal_path_id=' || personal_path_id`,
+		fix:    deleteRows("group_paths", `personal_path_id NOT IN (SELECT id FROM paths)`),
+	},
+	{
+		name:   "待办所属的计划不存在",
+		table:  "todos",
+		where:  `plan_id NOT IN (SELECT id FROM plans)`,
+		sample: `code || ' plan_id=' || plan_id`,
+		fix:    deleteRows("todos", `plan_id NOT IN (SELECT id FROM plans)`),
+	},
+	{
+		name:   "计划已在回收站但待办未移入",
+		table:  "todos",
+		where:  `deleted_at IS NULL AND plan_id IN (SELECT id FROM plans WHERE deleted_at IS NOT NULL)`,
+		sample: `code || ' plan_id=' || plan_id`,
+		fix: execFix(`UPDATE todos SET deleted_at = (SELECT p.deleted_at FROM plans p WHERE p.id = todos.plan_id)
+			WHERE deleted_at IS NULL AND plan_id IN (SELECT id FROM plans WHERE deleted_at IS NOT NULL)`),
+	},
+	{
+		name:   "待办的路径与所属计划不一致",
+		table:  "todos",
+		where:  `path_id <> (SELECT p.path_id FROM plans p WHERE p.id = todos.plan_id)`,
+		sample: `code || ' path_id=' || path_id`,
+		fix: execFix(`UPDATE todos SET path_id = (SELECT p.path_id FROM plans p WHERE p.id = todos.plan_id)
+			WHERE path_id <> (SELECT p.path_id FROM plans p WHERE p.id = todos.plan_id)`),
+	},
+	{
+		name:   "待办标签所属的待办不存在",
+		table:  "todo_tags",
+		where:  `to_do_id NOT IN (SELECT id FROM todos)`,
+		sample: `tag || ' to_do_id=' || to_do_id`,
+		fix:    deleteRows("todo_tags", `to_do_id NOT IN (SELECT id FROM todos)`),
+	},
+	{
+		name:   "记忆标签所属的记忆不存在",
+		table:  "memory_tags",
+		where:  `memory_id NOT IN (SELECT id FROM memories)`,
+		sample: `tag || ' memory_id=' || memory_id`,
+		fix:    deleteRows("memory_tags", `memory_id NOT IN (SELECT id FROM memories)`),
+	},
+	{
+		name:   "语义向量所属的记忆不存在",
+		table:  "memory_embeddings",
+		where:  `memory_id NOT IN (SELECT id FROM memories)`,
+		sample: `'memory_id=' || memory_id`,
+		fix:    deleteRows("memory_embeddings", `memory_id NOT IN (SELECT id FROM memories)`),
+	},
+	{
+		name:   "修订历史所属的记忆不存在",
+		table:  "memory_revisions",
+		where:  `memory_id NOT IN (SELECT id FROM memories)`,
+		sample: `'memory_id=' || memory_id || ' revision=' || revision`,
+		fix:    deleteRows("memory_revisions", `memory_id NOT IN (SELECT id FROM memories)`),
+	},
+	{
+		name:   "记忆既不是全局也没有绑定路径（任何作用域都不可见）",
+		table:  "memories",
+		where:  `path_id = 0 AND NOT global`,
+		sample: `code`,
+		fix:    execFix(`UPDATE memories SET global = TRUE WHERE path_id = 0 AND NOT global`),
+	},
+	{
+		name:   "记忆绑定的路径不存在（需手动处理）",
+		table:  "memories",
+		where:  `path_id <> 0 AND path_id NOT IN (SELECT id FROM paths)`,
+		sample: `code || ' path_id=' || path_id`,
+	},
+	{
+		name:   "计划绑定的路径不存在（需手动处理）",
+		table:  "plans",
+		where:  `path_id <> 0 AND path_id NOT IN (SELECT id FROM paths)`,
+		sample: `code || ' path_id=' || path_id`,
+	},
+	{
+		name:   "全文索引缺少记忆",
+		table:  "memories",
+		where:  `id NOT IN (SELECT rowid FROM ` + MemoryFTSTable + `)`,
+		sample: `code`,
+		fix:    rebuildFTSFix,
+		fts:    true,
+	},
+	{
+		// 回收站中的记忆保留索引行（恢复后直接可搜），只有彻底删除或不存在的记忆才算孤儿
+		name:   "全文索引包含已删除的记忆",
+		table:  MemoryFTSTable,
+		where:  `rowid NOT IN (SELECT id FROM memories)`,
+		sample: `'rowid=' || rowid`,
+		fix:    rebuildFTSFix,
+		fts:    true,
+	},
+}
+
+// deleteRows 删除满足条件的行
+func deleteRows(table, where string) func(tx *gorm.DB) (int64, error) {
+	return execFix(fmt.Sprintf(`DELETE FROM %s WHERE %s`, table, where))
+}
+
+// execFix 执行一条修复语句，返回影响的行数
+func execFix(sql string) func(tx *gorm.DB) (int64, error) {
+	return func(tx *gorm.DB) (int64, error) {
+		result := tx.Exec(sql)
+		return result.RowsAffected, result.Error
+	}
+}
+
+// rebuildFTSFix 重建全文索引（修复行数以重建前的问题数计）
+func rebuildFTSFix(tx *gorm.DB) (int64, error) {
+	return 0, RebuildMemoryFTS(tx)
+}
+
+// CheckDatabase 检查数据库完整性和数据一致性（只读）
+func CheckDatabase(ctx context.Context, db *gorm.DB) (*CheckReport, error) {
+	db = db.WithContext(ctx)
+	report := &CheckReport{}
+
+	if !IsPostgres(db) {
+		if err := db.Raw("PRAGMA integrity_check").Scan(&report.Integrity).Error; err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrIntegrityCheck, err)
+		}
+		foreignKeys, err := checkForeignKeys(db)
+		if err != nil {
+			return nil, err
+		}
+		report.ForeignKeys = foreignKeys
+	}
+
+	for _, check := range activeChecks(db) {
+		count, err := check.count(db)
+		if err != nil {
+			return nil, fmt.Errorf("检查「%s」失败: %w", check.name, err)
+		}
+		issue := CheckIssue{Name: check.name, Count: count, Fixable: check.fix != nil}
+		if count > 0 {
+			query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s LIMIT %d`, check.sample, quoteTable(check.table), check.where, checkSampleLimit)
+			if err := db.Raw(query).Scan(&issue.Samples).Error; err != nil {
+				return nil, fmt.Errorf("检查「%s」失败: %w", check.name, err)
+			}
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+	return report, nil
+}
+
+// FixDatabase 在一个事务中修复所有可自动修复的不一致
+// 每项修复前重新计数，前面的修复产生的新问题（如删除待办后留下的标签）也会被处理
+func FixDatabase(ctx context.Context, db *gorm.DB) ([]CheckFix, error) {
+	fixes := make([]CheckFix, 0)
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, check := range activeChecks(tx) {
+			if check.fix == nil {
+				continue
+			}
+			count, err := check.count(tx)
+			if err != nil {
+				return fmt.Errorf("检查「%s」失败: %w", check.name, err)
+			}
+			if count == 0 {
+				continue
+			}
+			fixed, err := check.fix(tx)
+			if err != nil {
+				return fmt.Errorf("修复「%s」失败: %w", check.name, err)
+			}
+			// 重建索引等无法统计影响行数的修复，以修复前的问题数计
+			if fixed == 0 {
+				fixed = count
+			}
+			fixes = append(fixes, CheckFix{Name: check.name, Fixed: fixed})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fixes, nil
+}
+
+// count 统计有问题的行数
+func (c consistencyCheck) count(db *gorm.DB) (int64, error) {
+	var count int64
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s`, quoteTable(c.table), c.where)
+	err := db.Raw(query).Scan(&count).Error
+	return count, err
+}
+
+// activeChecks 当前数据库适用的检查项（全文索引检查仅适用于 SQLite 且索引表存在时）
+func activeChecks(db *gorm.DB) []consistencyCheck {
+	hasFTS := SupportsFTS(db) && db.Migrator().HasTable(MemoryFTSTable)
+	checks := make([]consistencyCheck, 0, len(consistencyChecks))
+	for _, check := range consistencyChecks {
+		if check.fts && !hasFTS {
+			continue
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkForeignKeys 执行 SQLite 外键检查
+func checkForeignKeys(db *gorm.DB) ([]string, error) {
+	var rows []struct {
+		Table  string
+		RowID  *int64 `gorm:"column:rowid"`
+		Parent string
+	}
+	if err := db.Raw("PRAGMA foreign_key_check").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("外键检查失败: %w", err)
+	}
+	problems := make([]string, 0, len(rows))
+	for _, r := range rows {
+		rowID := "-"
+		if r.RowID != nil {
+			rowID = fmt.Sprintf("%d", *r.RowID)
+		}
+		problems = append(problems, fmt.Sprintf("%s rowid=%s -> %s", r.Table, rowID, r.Parent))
+	}
+	return problems, nil
+}
+
+// quoteTable 给表名加引号（groups 在部分数据库中是关键字）
+func quoteTable(table string) string {
+	return `"` + table + `"`
+}
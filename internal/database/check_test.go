@@ -0,0 +1,249 @@
+package database
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"gorm.io/gorm"
+)
+
+// checkTestFixture 一致性检查测试的基础数据：一个路径、一个组、一个计划和一条已建索引的记忆
+type checkTestFixture struct {
+	path   *entity.PersonalPath
+	group  *entity.Group
+	plan   *entity.Plan
+	memory *entity.Memory
+}
+
+// seedCheckFixture 写入没有任何问题的基础数据
+func seedCheckFixture(t *testing.T, db *gorm.DB) *checkTestFixture {
+	t.Helper()
+	f := &checkTestFixture{
+		path:  &entity.PersonalPath{ID: GenerateID(), Path: "/project"},
+		group: &entity.Group{ID: GenerateID(), Name: "team"},
+	}
+	f.plan = &entity.Plan{ID: GenerateID(), Code: "plan", PathID: f.path.ID, Title: "计划", Version: 1}
+	f.memory = &entity.Memory{ID: GenerateID(), Code: "mem", PathID: f.path.ID, Title: "记忆", Content: "内容", Version: 1}
+	for _, record := range []interface{}{
+		f.path, f.group, f.plan, f.memory,
+		&entity.GroupPath{ID: GenerateID(), GroupID: f.group.ID, PersonalPathID: f.path.ID},
+		&entity.ToDo{ID: GenerateID(), Code: "todo", PlanID: f.plan.ID, PathID: f.path.ID, Title: "待办", Version: 1},
+		&entity.MemoryTag{ID: GenerateID(), MemoryID: f.memory.ID, Tag: "tag"},
+	} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("写入基础数据失败: %v", err)
+		}
+	}
+	if err := SyncMemoryFTS(db, f.memory.ID); err != nil {
+		t.Fatalf("写入索引失败: %v", err)
+	}
+	return f
+}
+
+// TestCheckDatabase 每项检查都能发现对应的问题，可修复的问题修复后不再出现
+func TestCheckDatabase(t *testing.T) {
+	const missing = int64(42)
+	cases := []struct {
+		name      string
+		seed      func(db *gorm.DB, f *checkTestFixture) error
+		wantIssue string // 应报告问题的检查项
+		wantFixes []CheckFix
+	}{
+		{
+			name: "组路径映射指向不存在的组",
+			seed: func(db *gorm.DB, f *checkTestFixture) error {
+				return db.Create(&entity.GroupPath{ID: GenerateID(), GroupID: missing, PersonalPathID: missing}).Error
+			},
+			wantIssue: "组路径映射指向不存在的组",
+			wantFixes: []CheckFix{{Name: "组路径映射指向不存在的组", Fixed: 1}},
+		},
+		{
+			name: "删除路径后留下组路径映射",
+			seed: func(db *gorm.DB, f *checkTestFixture) error {
+				return db.Exec("DELETE FROM paths WHERE id = ?", f.path.ID).Error
+			},
+			wantIssue: "组路径映射指向不存在的路径",
+			// 记忆和计划绑定的路径也不存在了，但它们需要手动处理
+			wantFixes: []CheckFix{{Name: "组路径映射指向不存在的路径", Fixed: 1}},
+		},
+		{
+			name: "删除计划后清理待办及其标签",
+			seed: func(db *gorm.DB, f *checkTestFixture) error {
+				var todo entity.ToDo
+				if err := db.First(&todo, "code = ?", "todo").Error; err != nil {
+					return err
+				}
+				if err := db.Create(&entity.ToDoTag{ID: GenerateID(), ToDoID: todo.ID, Tag: "tag"}).Error; err != nil {
+					return err
+				}
+				return db.Exec("DELETE FROM plans WHERE id = ?", f.plan.ID).Error
+			},
+			wantIssue: "待办所属的计划不存在",
+			wantFixes: []CheckFix{
+				{Name: "待办所属的计划不存在", Fixed: 1},
+				{Name: "待办标签所属的待办不存在", Fixed: 1},
+			},
+		},
+		{
+			name: "计划已在回收站但待办未移入",
+			seed: func(db *gorm.DB, f *checkTestFixture) error {
+				return db.Delete(f.plan).Error
+			},
+			wantIssue: "计划已在回收站但待办未移入",
+			wantFixes: []CheckFix{{Name: "计划已在回收站但待办未移入", Fixed: 1}},
+		},
+		{
+			name: "待办的路径与所属计划不一致",
+			seed: func(db *gorm.DB, f *checkTestFixture) error {
+				return db.Exec("UPDATE todos SET path_id = ?", missing).Error
+			},
+			wantIssue: "待办的路径与所属计划不一致",
+			wantFixes: []CheckFix{{Name: "待办的路径与所属计划不一致", Fixed: 1}},
+		},
+		{
+			name: "记忆标签所属的记忆不存在",
+			seed: func(db *gorm.DB, f *checkTestFixture) error {
+				return db.Create(&entity.MemoryTag{ID: GenerateID(), MemoryID: missing, Tag: "tag"}).Error
+			},
+			wantIssue: "记忆标签所属的记忆不存在",
+			wantFixes: []CheckFix{{Name: "记忆标签所属的记忆不存在", Fixed: 1}},
+		},
+		{
+			name: "语义向量所属的记忆不存在",
+			seed: func(db *gorm.DB, f *checkTestFixture) error {
+				return db.Create(&entity.MemoryEmbedding{MemoryID: missing, Model: "hash", Dimensions: 1, Vector: []byte{0, 0, 0, 0}, ContentHash: "x"}).Error
+			},
+			wantIssue: "语义向量所属的记忆不存在",
+			wantFixes: []CheckFix{{Name: "语义向量所属的记忆不存在", Fixed: 1}},
+		},
+		{
+			name: "修订历史所属的记忆不存在",
+			seed: func(db *gorm.DB, f *checkTestFixture) error {
+				return db.Create(&entity.MemoryRevision{ID: GenerateID(), MemoryID: missing, Revision: 1, Title: "旧", Action: "update", Source: "cli"}).Error
+			},
+			wantIssue: "修订历史所属的记忆不存在",
+			wantFixes: []CheckFix{{Name: "修订历史所属的记忆不存在", Fixed: 1}},
+		},
+		{
+			name: "记忆既不是全局也没有绑定路径",
+			seed: func(db *gorm.DB, f *checkTestFixture) error {
+				return db.Model(f.memory).Update("path_id", 0).Error
+			},
+			wantIssue: "记忆既不是全局也没有绑定路径（任何作用域都不可见）",
+			wantFixes: []CheckFix{{Name: "记忆既不是全局也没有绑定路径（任何作用域都不可见）", Fixed: 1}},
+		},
+		{
+			name: "记忆绑定的路径不存在",
+			seed: func(db *gorm.DB, f *checkTestFixture) error {
+				return db.Model(f.memory).Update("path_id", missing).Error
+			},
+			wantIssue: "记忆绑定的路径不存在（需手动处理）",
+		},
+		{
+			name: "计划绑定的路径不存在",
+			seed: func(db *gorm.DB, f *checkTestFixture) error {
+				// 待办跟随计划，避免触发路径不一致的检查
+				if err := db.Exec("UPDATE todos SET path_id = ?", missing).Error; err != nil {
+					return err
+				}
+				return db.Model(f.plan).Update("path_id", missing).Error
+			},
+			wantIssue: "计划绑定的路径不存在（需手动处理）",
+		},
+		{
+			name: "全文索引缺少记忆",
+			seed: func(db *gorm.DB, f *checkTestFixture) error {
+				return DeleteMemoryFTS(db, f.memory.ID)
+			},
+			wantIssue: "全文索引缺少记忆",
+			wantFixes: []CheckFix{{Name: "全文索引缺少记忆", Fixed: 1}},
+		},
+		{
+			name: "全文索引包含已删除的记忆",
+			seed: func(db *gorm.DB, f *checkTestFixture) error {
+				memory := &entity.Memory{ID: GenerateID(), Code: "mem-gone", Global: true, Title: "已删除", Content: "内容", Version: 1}
+				if err := db.Create(memory).Error; err != nil {
+					return err
+				}
+				if err := SyncMemoryFTS(db, memory.ID); err != nil {
+					return err
+				}
+				return db.Exec("DELETE FROM memories WHERE id = ?", memory.ID).Error
+			},
+			wantIssue: "全文索引包含已删除的记忆",
+			wantFixes: []CheckFix{{Name: "全文索引包含已删除的记忆", Fixed: 1}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := openMigrateTestDB(t)
+			if _, err := NewMigrator(db).Migrate(ctx); err != nil {
+				t.Fatalf("迁移失败: %v", err)
+			}
+			f := seedCheckFixture(t, db)
+
+			report, err := CheckDatabase(ctx, db)
+			if err != nil {
+				t.Fatalf("检查失败: %v", err)
+			}
+			if !report.IntegrityOK() || report.ProblemCount() != 0 {
+				t.Fatalf("基础数据不应有问题: %+v", report)
+			}
+			if len(report.Issues) != len(consistencyChecks) {
+				t.Fatalf("SQLite 应执行全部 %d 项检查，实际 %d 项", len(consistencyChecks), len(report.Issues))
+			}
+
+			if err := tc.seed(db, f); err != nil {
+				t.Fatalf("制造问题失败: %v", err)
+			}
+			report, err = CheckDatabase(ctx, db)
+			if err != nil {
+				t.Fatalf("检查失败: %v", err)
+			}
+			issue := findCheckIssue(report, tc.wantIssue)
+			if issue == nil || issue.Count == 0 || len(issue.Samples) == 0 {
+				t.Fatalf("应报告「%s」: %+v", tc.wantIssue, report.Issues)
+			}
+			if issue.Fixable != (tc.wantFixes != nil) {
+				t.Fatalf("「%s」可修复 = %v，期望 %v", tc.wantIssue, issue.Fixable, tc.wantFixes != nil)
+			}
+
+			fixes, err := FixDatabase(ctx, db)
+			if err != nil {
+				t.Fatalf("修复失败: %v", err)
+			}
+			if tc.wantFixes == nil {
+				tc.wantFixes = []CheckFix{}
+			}
+			if !reflect.DeepEqual(fixes, tc.wantFixes) {
+				t.Fatalf("修复结果 = %+v，期望 %+v", fixes, tc.wantFixes)
+			}
+
+			report, err = CheckDatabase(ctx, db)
+			if err != nil {
+				t.Fatalf("检查失败: %v", err)
+			}
+			if remaining := findCheckIssue(report, tc.wantIssue); (remaining.Count == 0) != issue.Fixable {
+				t.Fatalf("修复后「%s」剩余 %d 行", tc.wantIssue, remaining.Count)
+			}
+			for _, other := range report.Issues {
+				if other.Count > 0 && other.Fixable {
+					t.Fatalf("修复后仍有可修复的问题「%s」", other.Name)
+				}
+			}
+		})
+	}
+}
+
+// findCheckIssue 按名称查找检查项
+func findCheckIssue(report *CheckReport, name string) *CheckIssue {
+	for i := range report.Issues {
+		if report.Issues[i].Name == name {
+			return &report.Issues[i]
+		}
+	}
+	return nil
+}
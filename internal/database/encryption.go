@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/XiaoLFeng/llm-memory/internal/encryption"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// 嘿嘿~ 这是字段级加密的 GORM 序列化器！(´∀｀)💖
+// 实体字段标记 serializer:encrypted 后，写入时自动加密、读取时自动解密；
+// 没有配置密钥时按明文读写，因此未启用加密的用户完全无感知~
+
+// EncryptedSerializerName 加密字段序列化器名称
+const EncryptedSerializerName = "encrypted"
+
+// EncryptedColumn 被加密的表字段
+type EncryptedColumn struct {
+	Table  string
+	Column string
+}
+
+// EncryptedColumns 所有使用 serializer:encrypted 的字段（启用加密/轮换密钥时整表重写）
+var EncryptedColumns = []EncryptedColumn{
+	{Table: "memories", Column: "content"},
+	{Table: "memory_revisions", Column: "content"},
+	{Table: "plans", Column: "content"},
+	{Table: "todos", Column: "description"},
+}
+
+var (
+	contentCipher   *encryption.Cipher
+	contentCipherMu sync.RWMutex
+)
+
+func init() {
+	schema.RegisterSerializer(EncryptedSerializerName, encryptedSerializer{})
+}
+
+// SetContentCipher 设置字段加解密器（nil 表示不加密）
+func SetContentCipher(c *encryption.Cipher) {
+	contentCipherMu.Lock()
+	defer contentCipherMu.Unlock()
+	contentCipher = c
+}
+
+// ContentEncrypted 是否已启用字段加密
+func ContentEncrypted() bool {
+	return getContentCipher() != nil
+}
+
+// getContentCipher 获取当前加解密器
+func getContentCipher() *encryption.Cipher {
+	contentCipherMu.RLock()
+	defer contentCipherMu.RUnlock()
+	return contentCipher
+}
+
+// encryptedSerializer 加密字段序列化器
+type encryptedSerializer struct{}
+
+// Scan 读取时解密
+func (encryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("加密字段 %s 类型不支持: %T", field.Name, dbValue)
+	}
+
+	if encryption.IsEncrypted(value) {
+		c := getContentCipher()
+		if c == nil {
+			return encryption.ErrKeyRequired
+		}
+		plaintext, err := c.Decrypt(value)
+		if err != nil {
+			return err
+		}
+		value = plaintext
+	}
+	field.ReflectValueOf(ctx, dst).SetString(value)
+	return nil
+}
+
+// Value 写入时加密
+func (encryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value, _ := fieldValue.(string)
+	c := getContentCipher()
+	if c == nil || value == "" {
+		return value, nil
+	}
+	return c.Encrypt(value)
+}
+
+// ReencryptContent 用新的加解密器重写所有加密字段（包括回收站中的数据）
+// from 为 nil 表示当前数据为明文，to 为 nil 表示解密为明文；返回重写的行数
+// 直接读写原始列值，不经过序列化器，需在事务中调用
+func ReencryptContent(tx *gorm.DB, from, to *encryption.Cipher) (int, error) {
+	total := 0
+	for _, col := range EncryptedColumns {
+		type row struct {
+			ID    int64
+			Value string
+		}
+		var rows []row
+		if err := tx.Raw(fmt.Sprintf("SELECT id, %s AS value FROM %s", col.Column, col.Table)).Scan(&rows).Error; err != nil {
+			return total, err
+		}
+
+		for _, r := range rows {
+			plaintext := r.Value
+			if encryption.IsEncrypted(r.Value) {
+				if from == nil {
+					return total, encryption.ErrKeyRequired
+				}
+				var err error
+				if plaintext, err = from.Decrypt(r.Value); err != nil {
+					return total, fmt.Errorf("%s.%s (id=%d): %w", col.Table, col.Column, r.ID, err)
+				}
+			}
+
+			value := plaintext
+			if to != nil && plaintext != "" {
+				var err error
+				if value, err = to.Encrypt(plaintext); err != nil {
+					return total, err
+				}
+			}
+			if value == r.Value {
+				continue
+			}
+			if err := tx.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ?", col.Table, col.Column), value, r.ID).Error; err != nil {
+				return total, err
+			}
+			total++
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/XiaoLFeng/llm-memory/internal/encryption"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"gorm.io/gorm"
+)
+
+// openEncryptionTestDB 打开迁移好的临时数据库，测试结束后清除全局加解密器
+func openEncryptionTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := openMigrateTestDB(t)
+	if _, err := NewMigrator(db).Migrate(context.Background()); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	t.Cleanup(func() { SetContentCipher(nil) })
+	return db
+}
+
+// newEncryptionTestCipher 用临时密钥文件创建加解密器
+func newEncryptionTestCipher(t *testing.T) *encryption.Cipher {
+	t.Helper()
+	_, c, err := encryption.NewConfig(filepath.Join(t.TempDir(), "key"), "")
+	if err != nil {
+		t.Fatalf("创建加解密器失败: %v", err)
+	}
+	return c
+}
+
+// rawColumn 绕过序列化器读取原始列值
+func rawColumn(t *testing.T, db *gorm.DB, table, column string, id int64) string {
+	t.Helper()
+	var value string
+	if err := db.Raw("SELECT "+column+" FROM "+table+" WHERE id = ?", id).Scan(&value).Error; err != nil {
+		t.Fatalf("读取 %s.%s 失败: %v", table, column, err)
+	}
+	return value
+}
+
+// TestEncryptedSerializer 加密字段写入时加密、读取时解密，未配置密钥时按明文读写
+func TestEncryptedSerializer(t *testing.T) {
+	key := newEncryptionTestCipher(t)
+	other := newEncryptionTestCipher(t)
+
+	cases := []struct {
+		name        string
+		content     string
+		write       *encryption.Cipher
+		read        *encryption.Cipher
+		wantRawEnc  bool
+		wantErr     error
+		wantContent string
+	}{
+		{name: "未启用加密", content: "明文内容", wantContent: "明文内容"},
+		{name: "加密往返", content: "秘密内容 ✨", write: key, read: key, wantRawEnc: true, wantContent: "秘密内容 ✨"},
+		{name: "启用前的明文照常读取", content: "旧数据", read: key, wantContent: "旧数据"},
+		{name: "空内容不加密", content: "", write: key, read: key},
+		{name: "缺少密钥", content: "秘密", write: key, wantRawEnc: true, wantErr: encryption.ErrKeyRequired},
+		{name: "密钥不匹配", content: "秘密", write: key, read: other, wantRawEnc: true, wantErr: encryption.ErrDecryptFailed},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := openEncryptionTestDB(t)
+			memory := &entity.Memory{ID: GenerateID(), Code: "mem", Title: "标题", Content: tc.content, Version: 1}
+
+			SetContentCipher(tc.write)
+			if err := db.Create(memory).Error; err != nil {
+				t.Fatalf("写入失败: %v", err)
+			}
+			raw := rawColumn(t, db, "memories", "content", memory.ID)
+			if encryption.IsEncrypted(raw) != tc.wantRawEnc {
+				t.Fatalf("原始列值 = %q，期望加密 %v", raw, tc.wantRawEnc)
+			}
+			if title := rawColumn(t, db, "memories", "title", memory.ID); title != "标题" {
+				t.Fatalf("标题不应加密: %q", title)
+			}
+
+			SetContentCipher(tc.read)
+			var stored entity.Memory
+			err := db.First(&stored, memory.ID).Error
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("读取错误 = %v，期望 %v", err, tc.wantErr)
+			}
+			if tc.wantErr == nil && stored.Content != tc.wantContent {
+				t.Fatalf("读取内容 = %q，期望 %q", stored.Content, tc.wantContent)
+			}
+		})
+	}
+}
+
+// TestReencryptContent 依次执行启用加密、轮换密钥、解密，每一步都覆盖所有加密字段（包括回收站）
+func TestReencryptContent(t *testing.T) {
+	db := openEncryptionTestDB(t)
+	oldKey := newEncryptionTestCipher(t)
+	newKey := newEncryptionTestCipher(t)
+
+	memory := &entity.Memory{ID: GenerateID(), Code: "mem", Title: "记忆", Content: "记忆内容", Version: 1}
+	trashed := &entity.Memory{ID: GenerateID(), Code: "mem-trashed", Title: "回收站", Content: "回收站内容", Version: 1}
+	plan := &entity.Plan{ID: GenerateID(), Code: "plan", PathID: 1, Title: "计划", Content: "计划内容", Version: 1}
+	todo := &entity.ToDo{ID: GenerateID(), Code: "todo", PlanID: plan.ID, PathID: 1, Title: "待办", Description: "待办描述", Version: 1}
+	empty := &entity.ToDo{ID: GenerateID(), Code: "todo-empty", PlanID: plan.ID, PathID: 1, Title: "空描述", Version: 1}
+	for _, record := range []interface{}{memory, trashed, plan, todo, empty} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("写入失败: %v", err)
+		}
+	}
+	if err := db.Delete(trashed).Error; err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	fields := []struct {
+		table, column string
+		id            int64
+		plaintext     string
+	}{
+		{"memories", "content", memory.ID, "记忆内容"},
+		{"memories", "content", trashed.ID, "回收站内容"},
+		{"plans", "content", plan.ID, "计划内容"},
+		{"todos", "description", todo.ID, "待办描述"},
+	}
+
+	steps := []struct {
+		name      string
+		from, to  *encryption.Cipher
+		wantCount int
+		wantErr   error
+		wantKey   *encryption.Cipher // 成功后各字段应能用它解密，nil 表示明文
+	}{
+		{name: "启用加密", to: oldKey, wantCount: 4, wantKey: oldKey},
+		{name: "重复启用缺少旧密钥", to: oldKey, wantErr: encryption.ErrKeyRequired, wantKey: oldKey},
+		{name: "旧密钥不正确", from: newKey, to: newKey, wantErr: encryption.ErrDecryptFailed, wantKey: oldKey},
+		{name: "轮换密钥", from: oldKey, to: newKey, wantCount: 4, wantKey: newKey},
+		{name: "用同一密钥重新加密", from: newKey, to: newKey, wantCount: 4, wantKey: newKey},
+		{name: "解密为明文", from: newKey, wantCount: 4},
+		{name: "明文无需重写", wantCount: 0},
+	}
+	for _, step := range steps {
+		var count int
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			count, err = ReencryptContent(tx, step.from, step.to)
+			return err
+		})
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: 错误 = %v，期望 %v", step.name, err, step.wantErr)
+		}
+		if err == nil && count != step.wantCount {
+			t.Fatalf("%s: 重写 %d 行，期望 %d", step.name, count, step.wantCount)
+		}
+
+		for _, f := range fields {
+			raw := rawColumn(t, db, f.table, f.column, f.id)
+			if encryption.IsEncrypted(raw) != (step.wantKey != nil) {
+				t.Fatalf("%s: %s.%s = %q，期望加密 %v", step.name, f.table, f.column, raw, step.wantKey != nil)
+			}
+			plaintext := raw
+			if step.wantKey != nil {
+				if plaintext, err = step.wantKey.Decrypt(raw); err != nil {
+					t.Fatalf("%s: %s.%s 无法用期望的密钥解密: %v", step.name, f.table, f.column, err)
+				}
+			}
+			if plaintext != f.plaintext {
+				t.Fatalf("%s: %s.%s = %q，期望 %q", step.name, f.table, f.column, plaintext, f.plaintext)
+			}
+		}
+		if raw := rawColumn(t, db, "todos", "description", empty.ID); raw != "" {
+			t.Fatalf("%s: 空描述不应加密: %q", step.name, raw)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"gorm.io/gorm"
+)
+
+// 嘿嘿~ 这是记忆的 FTS5 全文索引！(´∀｀)💖
+// 索引表 rowid 与 memories.id 一一对应，由 Model 层在写入时同步维护~
+// 写入的是 TokenizeForIndex 分词后的文本，因此摘要需要用 BuildSearchSnippet 在原文上生成~
+// 启用静态加密后内容列不写入索引（否则明文会留在索引表中），内容检索改为在进程内对解密后的文本进行~
+// FTS5 是 SQLite 独有的，PostgreSQL 下以下函数均为空操作~
+
+// MemoryFTSTable 记忆全文索引表名
+const MemoryFTSTable = "memories_fts"
+
+// 高亮标记（用于摘要输出）
+const (
+	FTSHighlightOpen  = "【"
+	FTSHighlightClose = "】"
+)
+
+// CreateMemoryFTS 创建记忆全文索引表并回填已有数据
+func CreateMemoryFTS(tx *gorm.DB) error {
+	if !SupportsFTS(tx) {
+		return nil
+	}
+	if err := tx.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS ` + MemoryFTSTable +
+		` USING fts5(title, content, category, tags, tokenize = 'unicode61')`).Error; err != nil {
+		return err
+	}
+	return RebuildMemoryFTS(tx)
+}
+
+// DropMemoryFTS 删除记忆全文索引表
+func DropMemoryFTS(tx *gorm.DB) error {
+	if !SupportsFTS(tx) {
+		return nil
+	}
+	return tx.Exec(`DROP TABLE IF EXISTS ` + MemoryFTSTable).Error
+}
+
+// RebuildMemoryFTS 清空并重建整个全文索引
+// 回收站中的记忆同样保留索引行（检索时按 deleted_at 过滤），恢复后无需重建即可搜索~
+func RebuildMemoryFTS(tx *gorm.DB) error {
+	if !SupportsFTS(tx) {
+		return nil
+	}
+	if err := tx.Exec(`DELETE FROM ` + MemoryFTSTable).Error; err != nil {
+		return err
+	}
+
+	var ids []int64
+	if err := tx.Unscoped().Model(&entity.Memory{}).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := SyncMemoryFTS(tx, id); err != nil {
+			return err
+		}
+	}
+	// 合并索引段，真正清除已删除行的旧词条
+	return tx.Exec(`INSERT INTO ` + MemoryFTSTable + `(` + MemoryFTSTable + `) VALUES ('optimize')`).Error
+}
+
+// SyncMemoryFTS 同步单条记忆的索引
+// 记忆被彻底删除（不存在）时会删除对应的索引行；回收站中的记忆照常写入索引
+func SyncMemoryFTS(tx *gorm.DB, memoryID int64) error {
+	if !SupportsFTS(tx) {
+		return nil
+	}
+	if err := DeleteMemoryFTS(tx, memoryID); err != nil {
+		return err
+	}
+
+	var memory entity.Memory
+	err := tx.Unscoped().Preload("Tags").First(&memory, memoryID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	content := memory.Content
+	if ContentEncrypted() {
+		content = ""
+	}
+
+	return tx.Exec(`INSERT INTO `+MemoryFTSTable+`(rowid, title, content, category, tags) VALUES (?, ?, ?, ?, ?)`,
+		memory.ID,
+		TokenizeForIndex(memory.Title),
+		TokenizeForIndex(content),
+		TokenizeForIndex(memory.Category),
+		TokenizeForIndex(strings.Join(memory.GetTagStrings(), " ")),
+	).Error
+}
+
+// DeleteMemoryFTS 删除单条记忆的索引
+func DeleteMemoryFTS(tx *gorm.DB, memoryID int64) error {
+	if !SupportsFTS(tx) {
+		return nil
+	}
+	return tx.Exec(`DELETE FROM `+MemoryFTSTable+` WHERE rowid = ?`, memoryID).Error
+}
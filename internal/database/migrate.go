@@ -0,0 +1,247 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 嘿嘿~ 这是版本化的数据库迁移子系统！(´∀｀)💖
+// 每个迁移都有递增的版本号，在事务中执行，并记录到 schema_migrations 表~
+
+// 错误定义
+var (
+	// ErrSchemaTooNew 数据库版本比当前程序支持的版本更新
+	ErrSchemaTooNew = errors.New("数据库结构版本高于当前程序支持的版本，请升级 llm-memory")
+	// ErrIrreversibleMigration 迁移不支持回滚
+	ErrIrreversibleMigration = errors.New("迁移不支持回滚")
+)
+
+// Migration 单个迁移定义
+// Up 和 Down 都在独立事务中执行，Down 为 nil 表示不可回滚
+type Migration struct {
+	Version int                     // 版本号（递增且唯一）
+	Name    string                  // 迁移名称（便于识别）
+	Up      func(tx *gorm.DB) error // 升级操作
+	Down    func(tx *gorm.DB) error // 回滚操作（可选）
+}
+
+// SchemaMigration 迁移记录表
+// 记录已执行的迁移版本
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName 指定表名
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus 迁移状态（供 db status 展示）
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator 使用已注册的迁移列表创建迁移执行器
+func NewMigrator(db *gorm.DB) *Migrator {
+	return NewMigratorWith(db, schemaMigrations)
+}
+
+// NewMigratorWith 使用指定的迁移列表创建迁移执行器
+// 迁移会按版本号排序
+func NewMigratorWith(db *gorm.DB, migrations []Migration) *Migrator {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+	return &Migrator{db: db, migrations: sorted}
+}
+
+// LatestVersion 获取当前程序支持的最新版本号
+func (m *Migrator) LatestVersion() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// ensureTable 确保迁移记录表存在
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	return m.db.WithContext(ctx).AutoMigrate(&SchemaMigration{})
+}
+
+// appliedRecords 获取已执行的迁移记录（按版本升序）
+func (m *Migrator) appliedRecords(ctx context.Context) ([]SchemaMigration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	var records []SchemaMigration
+	err := m.db.WithContext(ctx).Order("version ASC").Find(&records).Error
+	return records, err
+}
+
+// CurrentVersion 获取数据库当前版本号（0 表示未执行任何迁移）
+func (m *Migrator) CurrentVersion(ctx context.Context) (int, error) {
+	records, err := m.appliedRecords(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	return records[len(records)-1].Version, nil
+}
+
+// CheckCompatibility 检查数据库版本是否兼容当前程序
+// 数据库版本高于程序支持的最新版本时返回 ErrSchemaTooNew
+func (m *Migrator) CheckCompatibility(ctx context.Context) error {
+	current, err := m.CurrentVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if current > m.LatestVersion() {
+		return fmt.Errorf("%w (数据库: %d, 程序: %d)", ErrSchemaTooNew, current, m.LatestVersion())
+	}
+	return nil
+}
+
+// Status 获取所有迁移的执行状态
+// 数据库中存在但程序未知的迁移也会列出
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	records, err := m.appliedRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]SchemaMigration, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	known := make(map[int]struct{}, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = struct{}{}
+		status := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if r, ok := applied[mig.Version]; ok {
+			appliedAt := r.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	for _, r := range records {
+		if _, ok := known[r.Version]; ok {
+			continue
+		}
+		appliedAt := r.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   r.Version,
+			Name:      r.Name,
+			Applied:   true,
+			AppliedAt: &appliedAt,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// Migrate 执行所有未执行的迁移
+// 返回本次执行的迁移列表
+func (m *Migrator) Migrate(ctx context.Context) ([]Migration, error) {
+	if err := m.CheckCompatibility(ctx); err != nil {
+		return nil, err
+	}
+
+	records, err := m.appliedRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[int]struct{}, len(records))
+	for _, r := range records {
+		applied[r.Version] = struct{}{}
+	}
+
+	executed := make([]Migration, 0)
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if mig.Up != nil {
+				if err := mig.Up(tx); err != nil {
+					return err
+				}
+			}
+			return tx.Create(&SchemaMigration{
+				Version:   mig.Version,
+				Name:      mig.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return executed, fmt.Errorf("执行迁移 %04d_%s 失败: %w", mig.Version, mig.Name, err)
+		}
+		executed = append(executed, mig)
+	}
+	return executed, nil
+}
+
+// Rollback 回滚最近执行的 steps 个迁移
+// 返回本次回滚的迁移列表
+func (m *Migrator) Rollback(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+
+	records, err := m.appliedRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[int]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+	}
+
+	rolledBack := make([]Migration, 0, steps)
+	for i := len(records) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+		record := records[i]
+		mig, ok := known[record.Version]
+		if !ok {
+			return rolledBack, fmt.Errorf("%w (版本 %d 未知)", ErrSchemaTooNew, record.Version)
+		}
+		if mig.Down == nil {
+			return rolledBack, fmt.Errorf("%w: %04d_%s", ErrIrreversibleMigration, mig.Version, mig.Name)
+		}
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := mig.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, "version = ?", mig.Version).Error
+		})
+		if err != nil {
+			return rolledBack, fmt.Errorf("回滚迁移 %04d_%s 失败: %w", mig.Version, mig.Name, err)
+		}
+		rolledBack = append(rolledBack, mig)
+	}
+	return rolledBack, nil
+}
@@ -0,0 +1,344 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// openMigrateTestDB 打开一个空的临时 SQLite 数据库
+func openMigrateTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "migrate.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return db
+}
+
+// assertVersion 断言当前数据库版本
+func assertVersion(t *testing.T, m *Migrator, want int) {
+	t.Helper()
+	got, err := m.CurrentVersion(context.Background())
+	if err != nil {
+		t.Fatalf("读取版本失败: %v", err)
+	}
+	if got != want {
+		t.Fatalf("版本 = %d, want %d", got, want)
+	}
+}
+
+// entityColumns 实体对应的表名和列名
+func entityColumns(t *testing.T, model interface{}) (string, []string) {
+	t.Helper()
+	s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("解析实体失败: %v", err)
+	}
+	return s.Table, s.DBNames
+}
+
+// TestMigrateMatchesEntities 全新数据库迁移到最新版本后，表结构与当前实体一致
+// 冻结的迁移结构漏掉列时，这里会失败
+func TestMigrateMatchesEntities(t *testing.T) {
+	db := openMigrateTestDB(t)
+	m := NewMigrator(db)
+	if _, err := m.Migrate(context.Background()); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	assertVersion(t, m, m.LatestVersion())
+
+	models := []interface{}{
+		&entity.Memory{}, &entity.MemoryTag{}, &entity.MemoryEmbedding{}, &entity.MemoryRevision{},
+		&entity.Plan{}, &entity.ToDo{}, &entity.ToDoTag{},
+		&entity.Group{}, &entity.GroupPath{}, &entity.PersonalPath{},
+		&SnowflakeNodeLease{},
+	}
+	for _, model := range models {
+		table, columns := entityColumns(t, model)
+		for _, column := range columns {
+			if !db.Migrator().HasColumn(table, column) {
+				t.Errorf("表 %s 缺少列 %s", table, column)
+			}
+		}
+	}
+	if !db.Migrator().HasTable(MemoryFTSTable) {
+		t.Errorf("缺少全文索引表 %s", MemoryFTSTable)
+	}
+}
+
+// TestMigrationRoundTrip 逐个版本升级到最新、逐个回滚到 v0 再迁移回来，每一步的表结构都与版本号一致
+func TestMigrationRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	db := openMigrateTestDB(t)
+	m := NewMigrator(db)
+
+	// 版本 v 引入的列/表（v 之前不存在，v 之后一直存在）
+	introducedIn := map[int][][2]string{
+		10: {{"memories", "version"}, {"plans", "version"}, {"todos", "version"}},
+		9:  {{"snowflake_nodes", ""}},
+		8:  {{"paths", "project_id"}},
+		7:  {{"memories", "deleted_at"}, {"plans", "deleted_at"}, {"todos", "deleted_at"}},
+		6:  {{"memory_revisions", ""}},
+		5:  {{"memory_embeddings", ""}},
+		3:  {{MemoryFTSTable, ""}},
+		2:  {{"memories", "global"}},
+		1:  {{"memories", ""}, {"plans", ""}, {"todos", ""}, {"paths", ""}, {"groups", ""}},
+	}
+	exists := func(table, column string) bool {
+		if column == "" {
+			return db.Migrator().HasTable(table)
+		}
+		return db.Migrator().HasColumn(table, column)
+	}
+	assertSchema := func(stage string, version int) {
+		t.Helper()
+		for introduced, items := range introducedIn {
+			for _, tc := range items {
+				if got, want := exists(tc[0], tc[1]), introduced <= version; got != want {
+					t.Errorf("%s v%d: %s.%s 存在 = %v, want %v", stage, version, tc[0], tc[1], got, want)
+				}
+			}
+		}
+	}
+
+	// 逐个版本升级
+	for v := 1; v <= m.LatestVersion(); v++ {
+		if _, err := NewMigratorWith(db, schemaMigrations[:v]).Migrate(ctx); err != nil {
+			t.Fatalf("迁移到 v%d 失败: %v", v, err)
+		}
+		assertVersion(t, m, v)
+		assertSchema("迁移到", v)
+	}
+
+	// 逐个版本回滚
+	for v := m.LatestVersion(); v >= 1; v-- {
+		rolledBack, err := m.Rollback(ctx, 1)
+		if err != nil {
+			t.Fatalf("回滚 v%d 失败: %v", v, err)
+		}
+		if len(rolledBack) != 1 || rolledBack[0].Version != v {
+			t.Fatalf("回滚 v%d 结果不符: %+v", v, rolledBack)
+		}
+		assertVersion(t, m, v-1)
+		assertSchema("回滚到", v-1)
+	}
+
+	if rolledBack, err := m.Rollback(ctx, 1); err != nil || len(rolledBack) != 0 {
+		t.Fatalf("v0 时回滚应为空操作: %+v %v", rolledBack, err)
+	}
+
+	executed, err := m.Migrate(ctx)
+	if err != nil {
+		t.Fatalf("重新迁移失败: %v", err)
+	}
+	if len(executed) != m.LatestVersion() {
+		t.Fatalf("重新迁移执行了 %d 个迁移, want %d", len(executed), m.LatestVersion())
+	}
+	assertVersion(t, m, m.LatestVersion())
+}
+
+// TestRollbackKeepsData 回滚到较早版本再迁移回来，已有数据不丢失（回收站中的数据重新出现）
+func TestRollbackKeepsData(t *testing.T) {
+	ctx := context.Background()
+	db := openMigrateTestDB(t)
+	m := NewMigrator(db)
+	if _, err := m.Migrate(ctx); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+
+	memories := []entity.Memory{
+		{ID: 1, Code: "mem-kept", Global: true, Title: "保留", Content: "内容", Category: "默认", Priority: 1},
+		{ID: 2, Code: "mem-trashed", Global: true, Title: "回收站", Content: "内容", Category: "默认", Priority: 1},
+	}
+	if err := db.Create(&memories).Error; err != nil {
+		t.Fatalf("写入记忆失败: %v", err)
+	}
+	if err := db.Delete(&entity.Memory{}, 2).Error; err != nil {
+		t.Fatalf("删除记忆失败: %v", err)
+	}
+
+	// 回滚到 v6（去掉版本号、节点租约、项目标识和回收站）
+	if _, err := m.Rollback(ctx, m.LatestVersion()-6); err != nil {
+		t.Fatalf("回滚失败: %v", err)
+	}
+	assertVersion(t, m, 6)
+	var count int64
+	if err := db.Table("memories").Count(&count).Error; err != nil || count != 2 {
+		t.Fatalf("回滚后记忆数 = %d (%v), want 2", count, err)
+	}
+
+	if _, err := m.Migrate(ctx); err != nil {
+		t.Fatalf("重新迁移失败: %v", err)
+	}
+	var restored []entity.Memory
+	if err := db.Order("id").Find(&restored).Error; err != nil {
+		t.Fatalf("读取记忆失败: %v", err)
+	}
+	if len(restored) != 2 {
+		t.Fatalf("重新迁移后可见记忆数 = %d, want 2", len(restored))
+	}
+	for _, memory := range restored {
+		if memory.Version != 1 {
+			t.Errorf("记忆 %s 的版本号 = %d, want 1", memory.Code, memory.Version)
+		}
+	}
+}
+
+// TestBackfillMemoryGlobal 只有新加 global 列时才按 path_id=0 回填，回滚后列被删除
+func TestBackfillMemoryGlobal(t *testing.T) {
+	ctx := context.Background()
+	globals := func(db *gorm.DB) map[string]bool {
+		t.Helper()
+		var rows []struct {
+			Code   string
+			Global bool
+		}
+		if err := db.Table("memories").Select("code, global").Scan(&rows).Error; err != nil {
+			t.Fatalf("读取记忆失败: %v", err)
+		}
+		result := make(map[string]bool, len(rows))
+		for _, r := range rows {
+			result[r.Code] = r.Global
+		}
+		return result
+	}
+
+	t.Run("旧版数据库", func(t *testing.T) {
+		db := openMigrateTestDB(t)
+		v1 := NewMigratorWith(db, schemaMigrations[:1])
+		if _, err := v1.Migrate(ctx); err != nil {
+			t.Fatalf("迁移到 v1 失败: %v", err)
+		}
+		if err := db.Exec(`INSERT INTO memories (id, code, path_id, title, content) VALUES
+			(1, 'legacy-global', 0, 't', 'c'), (2, 'legacy-personal', 5, 't', 'c')`).Error; err != nil {
+			t.Fatalf("写入记忆失败: %v", err)
+		}
+
+		v2 := NewMigratorWith(db, schemaMigrations[:2])
+		if _, err := v2.Migrate(ctx); err != nil {
+			t.Fatalf("迁移到 v2 失败: %v", err)
+		}
+		got := globals(db)
+		if !got["legacy-global"] || got["legacy-personal"] {
+			t.Fatalf("回填结果不符: %v", got)
+		}
+
+		if _, err := v2.Rollback(ctx, 1); err != nil {
+			t.Fatalf("回滚 v2 失败: %v", err)
+		}
+		if db.Migrator().HasColumn("memories", "global") {
+			t.Fatal("回滚 v2 后 global 列应被删除")
+		}
+	})
+
+	t.Run("已有 global 列", func(t *testing.T) {
+		db := openMigrateTestDB(t)
+		// 旧版 AutoMigrate 创建的数据库：表和 global 列已存在，但还没有迁移记录
+		if err := db.AutoMigrate(initialSchemaModels()...); err != nil {
+			t.Fatalf("建表失败: %v", err)
+		}
+		if err := db.AutoMigrate(&memoryGlobalV2{}); err != nil {
+			t.Fatalf("建表失败: %v", err)
+		}
+		if err := db.Exec(`INSERT INTO memories (id, code, global, path_id, title, content) VALUES
+			(1, 'real-global', true, 0, 't', 'c'), (2, 'orphan', false, 0, 't', 'c')`).Error; err != nil {
+			t.Fatalf("写入记忆失败: %v", err)
+		}
+
+		if _, err := NewMigrator(db).Migrate(ctx); err != nil {
+			t.Fatalf("迁移失败: %v", err)
+		}
+		got := globals(db)
+		if !got["real-global"] || got["orphan"] {
+			t.Fatalf("已有 global 列时不应回填: %v", got)
+		}
+	})
+}
+
+// TestSchemaTooNew 数据库版本高于程序时拒绝迁移和回滚
+func TestSchemaTooNew(t *testing.T) {
+	ctx := context.Background()
+	db := openMigrateTestDB(t)
+	m := NewMigrator(db)
+	if _, err := m.Migrate(ctx); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	if err := m.CheckCompatibility(ctx); err != nil {
+		t.Fatalf("最新版本应兼容: %v", err)
+	}
+
+	future := m.LatestVersion() + 1
+	if err := db.Create(&SchemaMigration{Version: future, Name: "future", AppliedAt: time.Now()}).Error; err != nil {
+		t.Fatalf("写入迁移记录失败: %v", err)
+	}
+
+	if err := m.CheckCompatibility(ctx); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("CheckCompatibility() = %v, want ErrSchemaTooNew", err)
+	}
+	if _, err := m.Migrate(ctx); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("Migrate() = %v, want ErrSchemaTooNew", err)
+	}
+	if _, err := m.Rollback(ctx, 1); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("Rollback() = %v, want ErrSchemaTooNew", err)
+	}
+	assertVersion(t, m, future)
+}
+
+// TestMigrateStopsOnError 迁移失败时回滚该迁移的事务并停止，之后的迁移不执行；不可回滚的迁移拒绝回滚
+func TestMigrateStopsOnError(t *testing.T) {
+	ctx := context.Background()
+	db := openMigrateTestDB(t)
+	boom := errors.New("boom")
+	ran := map[int]bool{}
+	m := NewMigratorWith(db, []Migration{
+		{Version: 2, Name: "fail", Up: func(tx *gorm.DB) error {
+			if err := tx.Exec("CREATE TABLE half_done (id INTEGER)").Error; err != nil {
+				return err
+			}
+			return boom
+		}},
+		{Version: 1, Name: "create", Up: func(tx *gorm.DB) error {
+			ran[1] = true
+			return tx.Exec("CREATE TABLE created (id INTEGER)").Error
+		}},
+		{Version: 3, Name: "after", Up: func(tx *gorm.DB) error {
+			ran[3] = true
+			return nil
+		}},
+	})
+
+	executed, err := m.Migrate(ctx)
+	if !errors.Is(err, boom) {
+		t.Fatalf("Migrate() = %v, want boom", err)
+	}
+	if len(executed) != 1 || executed[0].Version != 1 || !ran[1] || ran[3] {
+		t.Fatalf("执行结果不符: executed=%+v ran=%v", executed, ran)
+	}
+	if db.Migrator().HasTable("half_done") {
+		t.Error("失败迁移的改动应被回滚")
+	}
+	assertVersion(t, m, 1)
+
+	if _, err := m.Rollback(ctx, 1); !errors.Is(err, ErrIrreversibleMigration) {
+		t.Errorf("Rollback() = %v, want ErrIrreversibleMigration", err)
+	}
+	assertVersion(t, m, 1)
+}
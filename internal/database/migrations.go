@@ -0,0 +1,412 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// schemaMigrations 已注册的迁移列表
+// 呀~ 新增迁移时只能追加到末尾，版本号不可复用！✨
+// 每个迁移只使用本文件中冻结的表结构（而不是 entity 中的实体），
+// 实体以后再加字段也不会改变旧迁移的行为，版本号始终准确描述表结构~
+var schemaMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "initial_schema",
+		Up:      migrateInitialSchema,
+		Down:    dropInitialSchema,
+	},
+	{
+		Version: 2,
+		Name:    "backfill_memory_global",
+		Up:      addMemoryGlobal,
+		Down:    dropMemoryGlobal,
+	},
+	{
+		Version: 3,
+		Name:    "memory_fts",
+		Up:      CreateMemoryFTS,
+		Down:    DropMemoryFTS,
+	},
+	{
+		Version: 4,
+		Name:    "memory_fts_cjk_tokenize",
+		Up:      RebuildMemoryFTS,
+		Down:    func(tx *gorm.DB) error { return nil }, // 仅重建索引，无需回滚
+	},
+	{
+		Version: 5,
+		Name:    "memory_embeddings",
+		Up: func(tx *gorm.DB) error {
+			return AutoMigrateSQLite(tx, &memoryEmbeddingV5{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&memoryEmbeddingV5{})
+		},
+	},
+	{
+		Version: 6,
+		Name:    "memory_revisions",
+		Up: func(tx *gorm.DB) error {
+			return AutoMigrateSQLite(tx, &memoryRevisionV6{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&memoryRevisionV6{})
+		},
+	},
+	{
+		Version: 7,
+		Name:    "soft_delete",
+		Up:      addSoftDelete,
+		Down:    dropSoftDelete,
+	},
+	{
+		Version: 8,
+		Name:    "path_project_id",
+		Up: func(tx *gorm.DB) error {
+			return AutoMigrateSQLite(tx, &pathProjectIDV8{})
+		},
+		Down: dropPathProjectID,
+	},
+	{
+		Version: 9,
+		Name:    "snowflake_nodes",
+		Up: func(tx *gorm.DB) error {
+			return AutoMigrateSQLite(tx, &snowflakeNodeV9{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&snowflakeNodeV9{})
+		},
+	},
+	{
+		Version: 10,
+		Name:    "record_version",
+		Up:      addRecordVersion,
+		Down:    dropRecordVersion,
+	},
+}
+
+// ==================== 0001 initial_schema ====================
+
+// memoryV1 初始的记忆表
+type memoryV1 struct {
+	ID         int64     `gorm:"primaryKey"`
+	Code       string    `gorm:"uniqueIndex;size:100;not null;comment:人类可读的唯一标识码"`
+	PathID     int64     `gorm:"index;default:0;comment:关联路径ID(0=无绑定/全局)"`
+	Title      string    `gorm:"index;size:255;not null;comment:标题"`
+	Content    string    `gorm:"type:text;not null;comment:内容"`
+	Category   string    `gorm:"index;size:100;default:'默认';comment:分类"`
+	Priority   int       `gorm:"default:1;comment:优先级 1-4"`
+	IsArchived bool      `gorm:"index;default:false;comment:是否归档"`
+	CreatedAt  time.Time `gorm:"index;autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+func (memoryV1) TableName() string { return "memories" }
+
+// memoryTagV1 初始的记忆标签表
+type memoryTagV1 struct {
+	ID       int64  `gorm:"primaryKey"`
+	MemoryID int64  `gorm:"index;not null"`
+	Tag      string `gorm:"index;size:100;not null"`
+}
+
+func (memoryTagV1) TableName() string { return "memory_tags" }
+
+// planV1 初始的计划表
+type planV1 struct {
+	ID          int64     `gorm:"primaryKey"`
+	Code        string    `gorm:"index;size:100;not null;comment:人类可读的唯一标识码"`
+	PathID      int64     `gorm:"index;not null;comment:路径ID（关联个人或小组路径）"`
+	Title       string    `gorm:"index;size:255;not null;comment:标题"`
+	Description string    `gorm:"type:text;not null;comment:简要描述（摘要）"`
+	Content     string    `gorm:"type:text;not null;comment:详细内容"`
+	Status      string    `gorm:"index;size:20;default:'pending'"`
+	Progress    int       `gorm:"default:0;comment:进度 0-100"`
+	CreatedAt   time.Time `gorm:"index;autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (planV1) TableName() string { return "plans" }
+
+// toDoV1 初始的待办表
+type toDoV1 struct {
+	ID          int64      `gorm:"primaryKey"`
+	Code        string     `gorm:"index;size:100;not null;comment:人类可读的唯一标识码"`
+	PlanID      int64      `gorm:"index;not null;comment:所属计划ID"`
+	PathID      int64      `gorm:"index;not null;comment:路径ID（关联个人或小组路径）"`
+	Title       string     `gorm:"index;size:255;not null;comment:标题"`
+	Description string     `gorm:"type:text;comment:描述"`
+	Priority    int        `gorm:"index;default:2;comment:优先级 1-4"`
+	Status      int        `gorm:"index;default:0;comment:状态"`
+	SortOrder   int        `gorm:"default:0;comment:排序顺序"`
+	DueDate     *time.Time `gorm:"index;comment:截止日期"`
+	CompletedAt *time.Time `gorm:"comment:完成时间"`
+	CreatedAt   time.Time  `gorm:"index;autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime"`
+}
+
+func (toDoV1) TableName() string { return "todos" }
+
+// toDoTagV1 初始的待办标签表
+type toDoTagV1 struct {
+	ID     int64  `gorm:"primaryKey"`
+	ToDoID int64  `gorm:"index;not null"`
+	Tag    string `gorm:"index;size:100;not null"`
+}
+
+func (toDoTagV1) TableName() string { return "todo_tags" }
+
+// groupV1 初始的组表
+type groupV1 struct {
+	ID          int64     `gorm:"primaryKey"`
+	Name        string    `gorm:"uniqueIndex;size:100;not null;comment:组名称"`
+	Description string    `gorm:"type:text;comment:组描述"`
+	CreatedAt   time.Time `gorm:"index;autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (groupV1) TableName() string { return "groups" }
+
+// groupPathV1 初始的组路径映射表
+type groupPathV1 struct {
+	ID             int64 `gorm:"primaryKey"`
+	GroupID        int64 `gorm:"index;not null"`
+	PersonalPathID int64 `gorm:"uniqueIndex;not null;comment:关联的路径ID（全局唯一）"`
+}
+
+func (groupPathV1) TableName() string { return "group_paths" }
+
+// personalPathV1 初始的路径表
+type personalPathV1 struct {
+	ID        int64     `gorm:"primaryKey"`
+	Path      string    `gorm:"uniqueIndex;size:1024;not null"`
+	LastVisit time.Time `gorm:"index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (personalPathV1) TableName() string { return "paths" }
+
+// initialSchemaModels 初始表结构
+func initialSchemaModels() []interface{} {
+	return []interface{}{
+		&memoryV1{},
+		&memoryTagV1{},
+		&planV1{},
+		&toDoV1{},
+		&toDoTagV1{},
+		&groupV1{},
+		&groupPathV1{},
+		&personalPathV1{},
+	}
+}
+
+// migrateInitialSchema 创建初始表结构
+// 对已有数据库（旧版 AutoMigrate 创建）同样安全，只会补齐缺失的列和索引
+func migrateInitialSchema(tx *gorm.DB) error {
+	return AutoMigrateSQLite(tx, initialSchemaModels()...)
+}
+
+// dropInitialSchema 删除初始表结构
+func dropInitialSchema(tx *gorm.DB) error {
+	models := initialSchemaModels()
+	// 逆序删除，先删关联表
+	for i := len(models) - 1; i >= 0; i-- {
+		if err := tx.Migrator().DropTable(models[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ==================== 0002 backfill_memory_global ====================
+
+// memoryGlobalV2 记忆的 global 列
+type memoryGlobalV2 struct {
+	Global bool `gorm:"index;default:false;comment:是否全局可见"`
+}
+
+func (memoryGlobalV2) TableName() string { return "memories" }
+
+// addMemoryGlobal 添加 global 列并回填旧数据
+// 旧版本以 PathID=0 表示全局记忆，没有 global 列，迁移后这些记忆会被可见性过滤掉；
+// 只在本迁移新加列时回填，已有 global 列的数据库（包括路径删除后留下的 path_id=0 记录）不受影响
+func addMemoryGlobal(tx *gorm.DB) error {
+	if tx.Migrator().HasColumn(&memoryGlobalV2{}, "Global") {
+		return nil
+	}
+	if err := AutoMigrateSQLite(tx, &memoryGlobalV2{}); err != nil {
+		return err
+	}
+	return tx.Model(&memoryGlobalV2{}).
+		Where("path_id = ?", 0).
+		UpdateColumn("global", true).Error
+}
+
+// dropMemoryGlobal 删除 global 列，回到以 PathID=0 表示全局记忆的结构
+// 再次升级时按 PathID=0 重新回填
+func dropMemoryGlobal(tx *gorm.DB) error {
+	return dropColumnWithIndex(tx, &memoryGlobalV2{}, "Global")
+}
+
+// ==================== 0005 memory_embeddings ====================
+
+// memoryEmbeddingV5 记忆向量表
+type memoryEmbeddingV5 struct {
+	MemoryID    int64     `gorm:"primaryKey;autoIncrement:false"`
+	Model       string    `gorm:"size:255;not null;comment:嵌入模型标识"`
+	Dimensions  int       `gorm:"not null;comment:向量维度"`
+	Vector      []byte    `gorm:"not null;comment:小端序float32向量"`
+	ContentHash string    `gorm:"size:64;not null;comment:嵌入文本哈希"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (memoryEmbeddingV5) TableName() string { return "memory_embeddings" }
+
+// ==================== 0006 memory_revisions ====================
+
+// memoryRevisionV6 记忆修订历史表
+type memoryRevisionV6 struct {
+	ID        int64     `gorm:"primaryKey"`
+	MemoryID  int64     `gorm:"uniqueIndex:idx_memory_revision;not null"`
+	Revision  int       `gorm:"uniqueIndex:idx_memory_revision;not null"`
+	Title     string    `gorm:"size:255;not null;comment:修改前的标题"`
+	Content   string    `gorm:"type:text;not null;comment:修改前的内容"`
+	Category  string    `gorm:"size:100;comment:修改前的分类"`
+	Priority  int       `gorm:"default:1;comment:修改前的优先级"`
+	Tags      string    `gorm:"type:text;comment:修改前的标签(逗号分隔)"`
+	Action    string    `gorm:"size:20;not null;comment:变更类型(update/revert)"`
+	Source    string    `gorm:"size:20;not null;comment:变更来源(cli/mcp/tui)"`
+	CreatedAt time.Time `gorm:"index;autoCreateTime"`
+}
+
+func (memoryRevisionV6) TableName() string { return "memory_revisions" }
+
+// ==================== 0007 soft_delete ====================
+
+// 回收站需要的 deleted_at 列（只声明新增的列，AutoMigrate 不会改动其他列）
+// gorm 按类型缓存表名，所以每张表各用一个类型
+type (
+	memorySoftDeleteV7 struct {
+		DeletedAt gorm.DeletedAt `gorm:"index;comment:删除时间(回收站)"`
+	}
+	planSoftDeleteV7 struct {
+		DeletedAt gorm.DeletedAt `gorm:"index;comment:删除时间(回收站)"`
+	}
+	toDoSoftDeleteV7 struct {
+		DeletedAt gorm.DeletedAt `gorm:"index;comment:删除时间(回收站)"`
+	}
+)
+
+func (memorySoftDeleteV7) TableName() string { return "memories" }
+func (planSoftDeleteV7) TableName() string   { return "plans" }
+func (toDoSoftDeleteV7) TableName() string   { return "todos" }
+
+// softDeleteModels 支持软删除（回收站）的表
+func softDeleteModels() []interface{} {
+	return []interface{}{
+		&memorySoftDeleteV7{},
+		&planSoftDeleteV7{},
+		&toDoSoftDeleteV7{},
+	}
+}
+
+// addSoftDelete 为记忆、计划、待办添加 deleted_at 列
+func addSoftDelete(tx *gorm.DB) error {
+	return AutoMigrateSQLite(tx, softDeleteModels()...)
+}
+
+// dropSoftDelete 删除 deleted_at 列
+// 呀~ 回滚后回收站中的数据会重新出现，而不是被彻底删除！
+func dropSoftDelete(tx *gorm.DB) error {
+	for _, model := range softDeleteModels() {
+		if err := dropColumnWithIndex(tx, model, "DeletedAt"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ==================== 0008 path_project_id ====================
+
+// pathProjectIDV8 路径的项目标识列
+type pathProjectIDV8 struct {
+	ProjectID string `gorm:"index;size:1024"`
+}
+
+func (pathProjectIDV8) TableName() string { return "paths" }
+
+// dropPathProjectID 删除路径的项目标识列
+func dropPathProjectID(tx *gorm.DB) error {
+	return dropColumnWithIndex(tx, &pathProjectIDV8{}, "ProjectID")
+}
+
+// ==================== 0009 snowflake_nodes ====================
+
+// snowflakeNodeV9 雪花节点租约表
+type snowflakeNodeV9 struct {
+	NodeID    int64     `gorm:"primaryKey;autoIncrement:false"`
+	Owner     string    `gorm:"size:255;not null"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+}
+
+func (snowflakeNodeV9) TableName() string { return "snowflake_nodes" }
+
+// ==================== 0010 record_version ====================
+
+// 乐观锁版本号列（已有数据从版本 1 开始）
+type (
+	memoryVersionV10 struct {
+		Version int64 `gorm:"not null;default:1;comment:乐观锁版本号"`
+	}
+	planVersionV10 struct {
+		Version int64 `gorm:"not null;default:1;comment:乐观锁版本号"`
+	}
+	toDoVersionV10 struct {
+		Version int64 `gorm:"not null;default:1;comment:乐观锁版本号"`
+	}
+)
+
+func (memoryVersionV10) TableName() string { return "memories" }
+func (planVersionV10) TableName() string   { return "plans" }
+func (toDoVersionV10) TableName() string   { return "todos" }
+
+// versionedModels 支持乐观锁（版本号）的表
+func versionedModels() []interface{} {
+	return []interface{}{
+		&memoryVersionV10{},
+		&planVersionV10{},
+		&toDoVersionV10{},
+	}
+}
+
+// addRecordVersion 为记忆、计划、待办添加乐观锁版本号列
+func addRecordVersion(tx *gorm.DB) error {
+	return AutoMigrateSQLite(tx, versionedModels()...)
+}
+
+// dropRecordVersion 删除乐观锁版本号列
+func dropRecordVersion(tx *gorm.DB) error {
+	for _, model := range versionedModels() {
+		if err := dropColumnWithIndex(tx, model, "Version"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropColumnWithIndex 删除列（列上有索引时先删除索引），列不存在时跳过
+func dropColumnWithIndex(tx *gorm.DB, model interface{}, field string) error {
+	migrator := tx.Migrator()
+	if !migrator.HasColumn(model, field) {
+		return nil
+	}
+	if migrator.HasIndex(model, field) {
+		if err := migrator.DropIndex(model, field); err != nil {
+			return err
+		}
+	}
+	return migrator.DropColumn(model, field)
+}
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 嘿嘿~ 这是雪花节点租约！🪪
+// MCP 服务、TUI 和每次 CLI 调用都是独立的进程，按 MAC 地址推导出的节点 ID 彼此相同，
+// 同一毫秒内并发写入就会生成重复的 ID。每个进程启动时在数据库中租用一个独立的节点，
+// 后台定期续约，退出时释放；进程崩溃留下的租约过期后可被其他进程接管~
+// 共享同一个 PostgreSQL 的多台机器也因此不会撞上同一个节点！
+
+const (
+	// NodeLeaseTTL 租约有效期，超过有效期未续约的节点可被其他进程接管
+	NodeLeaseTTL = 2 * time.Minute
+	// nodeLeaseRenewInterval 续约间隔（有效期内有多次重试机会）
+	nodeLeaseRenewInterval = NodeLeaseTTL / 4
+)
+
+// 错误定义
+var (
+	// ErrNoFreeNode 所有节点都被占用
+	ErrNoFreeNode = errors.New("没有可用的雪花节点，所有节点都被其他进程占用")
+	// ErrNodeLeaseLost 租约已过期并被其他进程接管，或已被删除
+	ErrNodeLeaseLost = errors.New("雪花节点租约已丢失")
+)
+
+// SnowflakeNodeLease 雪花节点租约表
+// 每行表示一个被进程占用的节点，时间统一使用 UTC 保存
+type SnowflakeNodeLease struct {
+	NodeID    int64     `gorm:"primaryKey;autoIncrement:false"` // 节点 ID（0-1023）
+	Owner     string    `gorm:"size:255;not null"`              // 持有者（主机名:进程号:随机串）
+	ExpiresAt time.Time `gorm:"index;not null"`                 // 过期时间
+}
+
+// TableName 指定表名
+func (SnowflakeNodeLease) TableName() string {
+	return "snowflake_nodes"
+}
+
+// NodeLease 当前进程持有的节点租约
+type NodeLease struct {
+	db    *gorm.DB
+	owner string
+
+	mu     sync.Mutex
+	nodeID int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// maxNodeID 最大节点 ID
+func maxNodeID() int64 {
+	return -1 ^ (-1 << snowflake.NodeBits)
+}
+
+// AcquireNodeLease 在数据库中租用一个空闲节点
+// 从随机位置开始查找，避免同时启动的进程争抢同一个节点
+func AcquireNodeLease(ctx context.Context, db *gorm.DB) (*NodeLease, error) {
+	hostname, _ := os.Hostname()
+	l := &NodeLease{
+		db:    db,
+		owner: fmt.Sprintf("%s:%d:%08x", hostname, os.Getpid(), rand.Uint32()),
+	}
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// NodeID 获取租用的节点 ID
+func (l *NodeLease) NodeID() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.nodeID
+}
+
+// Owner 获取租约持有者标识
+func (l *NodeLease) Owner() string {
+	return l.owner
+}
+
+// NewNode 使用租用的节点创建雪花 ID 生成器
+func (l *NodeLease) NewNode() (*snowflake.Node, error) {
+	return snowflake.NewNode(l.NodeID())
+}
+
+// Renew 续约，租约已被接管或删除时返回 ErrNodeLeaseLost
+func (l *NodeLease) Renew(ctx context.Context) error {
+	nodeID := l.NodeID()
+	result := l.db.WithContext(ctx).Model(&SnowflakeNodeLease{}).
+		Where("node_id = ? AND owner = ?", nodeID, l.owner).
+		Update("expires_at", time.Now().UTC().Add(NodeLeaseTTL))
+	if result.Error != nil {
+		return fmt.Errorf("续约雪花节点 %d 失败: %w", nodeID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNodeLeaseLost
+	}
+	return nil
+}
+
+// Release 释放租约（只删除自己持有的租约）
+// 租约表已被回滚删除时无需释放
+func (l *NodeLease) Release(ctx context.Context) error {
+	db := l.db.WithContext(ctx)
+	if !db.Migrator().HasTable(&SnowflakeNodeLease{}) {
+		return nil
+	}
+	return db.Where("node_id = ? AND owner = ?", l.NodeID(), l.owner).
+		Delete(&SnowflakeNodeLease{}).Error
+}
+
+// LiveNodeLeases 列出除 exceptOwner 之外仍在有效期内的租约（即其他正在运行的进程）
+// 租约表不存在（旧版本数据库）时返回空列表
+func LiveNodeLeases(ctx context.Context, db *gorm.DB, exceptOwner string) ([]SnowflakeNodeLease, error) {
+	db = db.WithContext(ctx)
+	if !db.Migrator().HasTable(&SnowflakeNodeLease{}) {
+		return nil, nil
+	}
+	var leases []SnowflakeNodeLease
+	err := db.Where("owner <> ? AND expires_at >= ?", exceptOwner, time.Now().UTC()).
+		Order("node_id ASC").
+		Find(&leases).Error
+	return leases, err
+}
+
+// Close 停止后台续约并释放租约
+func (l *NodeLease) Close() error {
+	if l.stop != nil {
+		close(l.stop)
+		<-l.done
+		l.stop = nil
+	}
+	return l.Release(context.Background())
+}
+
+// keepAlive 在后台定期续约
+// 租约丢失（如进程长时间挂起后被接管）时重新租用新节点，并通过 onChange 通知调用方切换节点
+func (l *NodeLease) keepAlive(onChange func(nodeID int64)) {
+	l.stop = make(chan struct{})
+	l.done = make(chan struct{})
+	go func() {
+		defer close(l.done)
+		ticker := time.NewTicker(nodeLeaseRenewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-l.stop:
+				return
+			case <-ticker.C:
+				// 数据库暂时不可用时等下一次重试，有效期内有多次机会
+				if err := l.Renew(context.Background()); !errors.Is(err, ErrNodeLeaseLost) {
+					continue
+				}
+				if err := l.acquire(context.Background()); err != nil {
+					fmt.Fprintf(os.Stderr, "重新租用雪花节点失败: %v\n", err)
+					continue
+				}
+				onChange(l.NodeID())
+			}
+		}
+	}()
+}
+
+// acquire 查找并占用一个空闲节点
+func (l *NodeLease) acquire(ctx context.Context) error {
+	db := l.db.WithContext(ctx)
+	now := time.Now().UTC()
+
+	var held []int64
+	if err := db.Model(&SnowflakeNodeLease{}).Where("expires_at >= ?", now).Pluck("node_id", &held).Error; err != nil {
+		return fmt.Errorf("读取雪花节点租约失败: %w", err)
+	}
+	busy := make(map[int64]bool, len(held))
+	for _, nodeID := range held {
+		busy[nodeID] = true
+	}
+
+	count := maxNodeID() + 1
+	start := rand.Int64N(count)
+	for i := int64(0); i < count; i++ {
+		nodeID := (start + i) % count
+		if busy[nodeID] {
+			continue
+		}
+		ok, err := l.claim(db, nodeID, now)
+		if err != nil {
+			return fmt.Errorf("租用雪花节点 %d 失败: %w", nodeID, err)
+		}
+		if ok {
+			l.mu.Lock()
+			l.nodeID = nodeID
+			l.mu.Unlock()
+			return nil
+		}
+	}
+	return ErrNoFreeNode
+}
+
+// claim 尝试占用节点：接管已过期的租约，或新建租约
+// 两步都是条件写入，多个进程同时争抢同一个节点时只有一个能成功
+func (l *NodeLease) claim(db *gorm.DB, nodeID int64, now time.Time) (bool, error) {
+	expiresAt := now.Add(NodeLeaseTTL)
+	result := db.Model(&SnowflakeNodeLease{}).
+		Where("node_id = ? AND expires_at < ?", nodeID, now).
+		Updates(map[string]interface{}{"owner": l.owner, "expires_at": expiresAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return true, nil
+	}
+
+	result = db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&SnowflakeNodeLease{NodeID: nodeID, Owner: l.owner, ExpiresAt: expiresAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
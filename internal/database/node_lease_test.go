@@ -0,0 +1,359 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 子进程写入测试使用的环境变量
+const (
+	testLeaseDBEnv   = "LLM_MEMORY_TEST_LEASE_DB"
+	testLeaseRowsEnv = "LLM_MEMORY_TEST_LEASE_ROWS"
+)
+
+// leaseTestRow 并发写入测试表，ID 由 GenerateID 生成，重复时主键冲突
+type leaseTestRow struct {
+	ID     int64 `gorm:"primaryKey;autoIncrement:false"`
+	Writer int
+}
+
+// testLeasePragmas 测试连接的 PRAGMA
+// -race 模式下写入很慢，多个进程持续写入时等锁可能超过默认的 5 秒，这里放宽忙等待超时
+const testLeasePragmas = SQLitePragmas + "&_pragma=busy_timeout(60000)"
+
+// openLeaseTestDB 打开 SQLite 数据库（每次调用都是独立连接，模拟独立进程）
+func openLeaseTestDB(t *testing.T, path string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(path+"?"+testLeasePragmas), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if err := db.AutoMigrate(&SnowflakeNodeLease{}, &leaseTestRow{}); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+	return db
+}
+
+// TestNodeLeaseConcurrentAcquire 并发租用的节点互不相同，各自生成的 ID 不重复
+func TestNodeLeaseConcurrentAcquire(t *testing.T) {
+	const workers = 16
+	const idsPerWorker = 20000
+
+	path := filepath.Join(t.TempDir(), "lease.db")
+	openLeaseTestDB(t, path)
+
+	leases := make([]*NodeLease, workers)
+	dbs := make([]*gorm.DB, workers)
+	for i := range dbs {
+		dbs[i] = openLeaseTestDB(t, path)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			lease, err := AcquireNodeLease(context.Background(), dbs[i])
+			if err != nil {
+				errs <- err
+				return
+			}
+			leases[i] = lease
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("租用节点失败: %v", err)
+	}
+
+	nodes := map[int64]bool{}
+	for _, lease := range leases {
+		if nodes[lease.NodeID()] {
+			t.Fatalf("节点 %d 被重复租用", lease.NodeID())
+		}
+		nodes[lease.NodeID()] = true
+	}
+
+	ids := make([][]int64, workers)
+	for i, lease := range leases {
+		wg.Add(1)
+		go func(i int, lease *NodeLease) {
+			defer wg.Done()
+			node, err := lease.NewNode()
+			if err != nil {
+				t.Errorf("创建节点失败: %v", err)
+				return
+			}
+			for j := 0; j < idsPerWorker; j++ {
+				ids[i] = append(ids[i], node.Generate().Int64())
+			}
+		}(i, lease)
+	}
+	wg.Wait()
+
+	seen := make(map[int64]bool, workers*idsPerWorker)
+	for _, list := range ids {
+		for _, id := range list {
+			if seen[id] {
+				t.Fatalf("生成了重复的 ID: %d", id)
+			}
+			seen[id] = true
+		}
+	}
+}
+
+// TestNodeLeaseParallelProcesses 多个进程同时写入同一个数据库，ID 不冲突
+func TestNodeLeaseParallelProcesses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("short 模式跳过多进程测试")
+	}
+	const processes = 6
+	const rowsPerProcess = 3000
+
+	path := filepath.Join(t.TempDir(), "lease.db")
+	db := openLeaseTestDB(t, path)
+
+	cmds := make([]*exec.Cmd, processes)
+	outputs := make([]bytes.Buffer, processes)
+	for i := range cmds {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestNodeLeaseHelperProcess$")
+		cmd.Env = append(os.Environ(),
+			testLeaseDBEnv+"="+path,
+			testLeaseRowsEnv+"="+strconv.Itoa(rowsPerProcess),
+		)
+		cmd.Stdout = &outputs[i]
+		cmd.Stderr = &outputs[i]
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("启动子进程失败: %v", err)
+		}
+		cmds[i] = cmd
+	}
+	for i, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("子进程 %d 写入失败: %v\n%s", i, err, outputs[i].String())
+		}
+	}
+
+	var count int64
+	if err := db.Model(&leaseTestRow{}).Count(&count).Error; err != nil {
+		t.Fatalf("统计行数失败: %v", err)
+	}
+	if count != processes*rowsPerProcess {
+		t.Fatalf("写入行数 = %d，期望 %d", count, processes*rowsPerProcess)
+	}
+
+	// 每个进程使用不同的节点
+	var rows []leaseTestRow
+	if err := db.Find(&rows).Error; err != nil {
+		t.Fatalf("读取数据失败: %v", err)
+	}
+	writerNodes := map[int]int64{}
+	nodeWriters := map[int64]int{}
+	for _, row := range rows {
+		node := snowflake.ParseInt64(row.ID).Node()
+		if prev, ok := writerNodes[row.Writer]; ok && prev != node {
+			t.Fatalf("进程 %d 使用了多个节点: %d, %d", row.Writer, prev, node)
+		}
+		if prev, ok := nodeWriters[node]; ok && prev != row.Writer {
+			t.Fatalf("节点 %d 被进程 %d 和 %d 同时使用", node, prev, row.Writer)
+		}
+		writerNodes[row.Writer] = node
+		nodeWriters[node] = row.Writer
+	}
+	if len(nodeWriters) != processes {
+		t.Fatalf("使用的节点数 = %d，期望 %d", len(nodeWriters), processes)
+	}
+
+	// 进程退出前释放了租约
+	var leases int64
+	if err := db.Model(&SnowflakeNodeLease{}).Count(&leases).Error; err != nil {
+		t.Fatalf("统计租约失败: %v", err)
+	}
+	if leases != 0 {
+		t.Fatalf("仍有 %d 个租约未释放", leases)
+	}
+}
+
+// TestNodeLeaseHelperProcess 子进程入口：租用节点后写入数据
+func TestNodeLeaseHelperProcess(t *testing.T) {
+	path := os.Getenv(testLeaseDBEnv)
+	if path == "" {
+		t.Skip("仅作为 TestNodeLeaseParallelProcesses 的子进程运行")
+	}
+	rows, err := strconv.Atoi(os.Getenv(testLeaseRowsEnv))
+	if err != nil {
+		t.Fatalf("解析行数失败: %v", err)
+	}
+
+	db := openLeaseTestDB(t, path)
+	lease, err := UseNodeLease(context.Background(), db)
+	if err != nil {
+		t.Fatalf("租用节点失败: %v", err)
+	}
+	defer func() {
+		if err := lease.Close(); err != nil {
+			t.Errorf("释放租约失败: %v", err)
+		}
+	}()
+
+	const batch = 100
+	writer := os.Getpid()
+	for written := 0; written < rows; written += batch {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for i := 0; i < batch && written+i < rows; i++ {
+				if err := tx.Create(&leaseTestRow{ID: GenerateID(), Writer: writer}).Error; err != nil {
+					return fmt.Errorf("写入第 %d 行失败: %w", written+i, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestNodeLeaseTakeover 过期的租约可被接管，原持有者续约时发现租约丢失
+func TestNodeLeaseTakeover(t *testing.T) {
+	db := openLeaseTestDB(t, filepath.Join(t.TempDir(), "lease.db"))
+	ctx := context.Background()
+
+	a, err := AcquireNodeLease(ctx, db)
+	if err != nil {
+		t.Fatalf("租用节点失败: %v", err)
+	}
+	b := &NodeLease{db: db, owner: "other"}
+
+	// 租约有效时无法接管
+	ok, err := b.claim(db, a.NodeID(), time.Now().UTC())
+	if err != nil || ok {
+		t.Fatalf("接管有效租约: ok=%v err=%v，期望失败", ok, err)
+	}
+	if err := a.Renew(ctx); err != nil {
+		t.Fatalf("续约失败: %v", err)
+	}
+
+	// 租约过期后可以接管
+	past := time.Now().UTC().Add(-time.Minute)
+	if err := db.Model(&SnowflakeNodeLease{}).Where("node_id = ?", a.NodeID()).Update("expires_at", past).Error; err != nil {
+		t.Fatalf("修改过期时间失败: %v", err)
+	}
+	ok, err = b.claim(db, a.NodeID(), time.Now().UTC())
+	if err != nil || !ok {
+		t.Fatalf("接管过期租约: ok=%v err=%v，期望成功", ok, err)
+	}
+	if err := a.Renew(ctx); !errors.Is(err, ErrNodeLeaseLost) {
+		t.Fatalf("原持有者续约: %v，期望 ErrNodeLeaseLost", err)
+	}
+
+	// 原持有者释放时不会删除别人的租约
+	if err := a.Release(ctx); err != nil {
+		t.Fatalf("释放租约失败: %v", err)
+	}
+	var count int64
+	db.Model(&SnowflakeNodeLease{}).Where("owner = ?", "other").Count(&count)
+	if count != 1 {
+		t.Fatalf("接管后的租约被误删")
+	}
+}
+
+// TestNodeLeaseRelease 释放后节点可以被重新租用
+func TestNodeLeaseRelease(t *testing.T) {
+	db := openLeaseTestDB(t, filepath.Join(t.TempDir(), "lease.db"))
+	ctx := context.Background()
+
+	lease, err := AcquireNodeLease(ctx, db)
+	if err != nil {
+		t.Fatalf("租用节点失败: %v", err)
+	}
+	if err := lease.Release(ctx); err != nil {
+		t.Fatalf("释放租约失败: %v", err)
+	}
+	other := &NodeLease{db: db, owner: "other"}
+	ok, err := other.claim(db, lease.NodeID(), time.Now().UTC())
+	if err != nil || !ok {
+		t.Fatalf("租用已释放的节点: ok=%v err=%v，期望成功", ok, err)
+	}
+}
+
+// TestLiveNodeLeases 只列出其他进程仍在有效期内的租约
+func TestLiveNodeLeases(t *testing.T) {
+	db := openLeaseTestDB(t, filepath.Join(t.TempDir(), "lease.db"))
+	ctx := context.Background()
+
+	self, err := AcquireNodeLease(ctx, db)
+	if err != nil {
+		t.Fatalf("租用节点失败: %v", err)
+	}
+	now := time.Now().UTC()
+	for _, lease := range []SnowflakeNodeLease{
+		{NodeID: (self.NodeID() + 1) % (maxNodeID() + 1), Owner: "live", ExpiresAt: now.Add(NodeLeaseTTL)},
+		{NodeID: (self.NodeID() + 2) % (maxNodeID() + 1), Owner: "expired", ExpiresAt: now.Add(-time.Minute)},
+	} {
+		if err := db.Create(&lease).Error; err != nil {
+			t.Fatalf("创建租约失败: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name   string
+		except string
+		want   []string
+	}{
+		{name: "排除自己", except: self.Owner(), want: []string{"live"}},
+		{name: "不排除", except: "", want: []string{self.Owner(), "live"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			leases, err := LiveNodeLeases(ctx, db, tc.except)
+			if err != nil {
+				t.Fatalf("查询租约失败: %v", err)
+			}
+			got := make(map[string]bool, len(leases))
+			for _, lease := range leases {
+				got[lease.Owner] = true
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("租约 = %v，期望 %v", got, tc.want)
+			}
+			for _, owner := range tc.want {
+				if !got[owner] {
+					t.Fatalf("租约 = %v，缺少 %s", got, owner)
+				}
+			}
+		})
+	}
+
+	// 没有租约表的旧版本数据库
+	if err := db.Migrator().DropTable(&SnowflakeNodeLease{}); err != nil {
+		t.Fatalf("删除租约表失败: %v", err)
+	}
+	leases, err := LiveNodeLeases(ctx, db, "")
+	if err != nil || len(leases) != 0 {
+		t.Fatalf("没有租约表时: %v %v，期望空列表", leases, err)
+	}
+}
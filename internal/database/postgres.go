@@ -0,0 +1,59 @@
+package database
+
+import (
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 嘿嘿~ 这是 PostgreSQL 存储后端！(´∀｀)💖
+// 多台机器连接同一个 Postgres，小组记忆就能跨机器共享啦~
+// 表结构与 SQLite 完全一致，由同一套版本化迁移创建；全文索引（FTS5）为 SQLite 独有，
+// Postgres 下检索改为在进程内使用同一套分词和字段权重~
+
+// 数据库驱动
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// PostgresDSNEnv 提供 Postgres 连接串的环境变量（优先于配置文件）
+const PostgresDSNEnv = "LLM_MEMORY_POSTGRES_DSN"
+
+// PostgresConfig PostgreSQL 数据库配置
+type PostgresConfig struct {
+	DSN   string // 连接串（URL 或 key=value 形式），为空时使用 PGHOST/PGUSER 等标准环境变量
+	Debug bool   // 是否开启调试模式
+}
+
+// postgresConn PostgreSQL 的进程内单例连接
+var postgresConn = &connection{}
+
+// OpenPostgres 打开 PostgreSQL 数据库连接（单例模式）
+func OpenPostgres(cfg *PostgresConfig) (*gorm.DB, error) {
+	return postgresConn.open(func() (*gorm.DB, error) {
+		logLevel := logger.Silent
+		if cfg.Debug {
+			logLevel = logger.Info
+		}
+
+		return gorm.Open(postgres.Open(cfg.DSN), &gorm.Config{
+			Logger: logger.Default.LogMode(logLevel),
+		})
+	})
+}
+
+// DriverName 获取连接使用的驱动名称（sqlite / postgres）
+func DriverName(db *gorm.DB) string {
+	return db.Dialector.Name()
+}
+
+// IsPostgres 判断连接是否为 PostgreSQL
+func IsPostgres(db *gorm.DB) bool {
+	return DriverName(db) == DriverPostgres
+}
+
+// SupportsFTS 判断连接是否支持 FTS5 全文索引（仅 SQLite）
+func SupportsFTS(db *gorm.DB) bool {
+	return DriverName(db) == DriverSQLite
+}
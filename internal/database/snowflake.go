@@ -1,22 +1,27 @@
 package database
 
 import (
+	"context"
 	"hash/fnv"
 	"net"
 	"os"
 	"sync"
+	"sync/atomic"
 
 	"github.com/bwmarrin/snowflake"
+	"gorm.io/gorm"
 )
 
 var (
-	snowflakeNode *snowflake.Node
+	snowflakeNode atomic.Pointer[snowflake.Node]
+	snowflakeID   atomic.Int64
 	snowflakeOnce sync.Once
 	snowflakeErr  error
 )
 
 // getNodeID 自动生成节点 ID (基于 MAC 或 hostname)
 // 返回值范围: 0-1023
+// 注意：同一台机器上的所有进程得到的节点相同，连接数据库后应改用 UseNodeLease 租用独立节点
 func getNodeID() int64 {
 	// 尝试从 MAC 地址生成
 	interfaces, err := net.Interfaces()
@@ -37,25 +42,59 @@ func getNodeID() int64 {
 }
 
 // InitSnowflake 初始化雪花算法节点
-// 节点 ID 基于机器 MAC 地址或 hostname 自动生成
+// 节点 ID 基于机器 MAC 地址或 hostname 自动生成，仅在租用到独立节点前使用
 func InitSnowflake() error {
 	snowflakeOnce.Do(func() {
-		nodeID := getNodeID()
-		snowflakeNode, snowflakeErr = snowflake.NewNode(nodeID)
+		if snowflakeNode.Load() == nil {
+			snowflakeErr = setSnowflakeNode(getNodeID())
+		}
 	})
 	return snowflakeErr
 }
 
+// UseNodeLease 在数据库中租用独立节点，之后 GenerateID 使用该节点
+// 后台自动续约，租约丢失时重新租用并切换节点；进程退出前调用返回租约的 Close 释放节点
+func UseNodeLease(ctx context.Context, db *gorm.DB) (*NodeLease, error) {
+	lease, err := AcquireNodeLease(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if err := setSnowflakeNode(lease.NodeID()); err != nil {
+		_ = lease.Release(ctx)
+		return nil, err
+	}
+	lease.keepAlive(func(nodeID int64) {
+		_ = setSnowflakeNode(nodeID)
+	})
+	return lease, nil
+}
+
+// setSnowflakeNode 切换 GenerateID 使用的节点
+func setSnowflakeNode(nodeID int64) error {
+	node, err := snowflake.NewNode(nodeID)
+	if err != nil {
+		return err
+	}
+	snowflakeNode.Store(node)
+	snowflakeID.Store(nodeID)
+	return nil
+}
+
 // GenerateID 生成雪花 ID
 // 如果未初始化，会自动初始化
 func GenerateID() int64 {
-	if snowflakeNode == nil {
+	node := snowflakeNode.Load()
+	if node == nil {
 		_ = InitSnowflake()
+		node = snowflakeNode.Load()
 	}
-	return snowflakeNode.Generate().Int64()
+	return node.Generate().Int64()
 }
 
-// GetNodeID 获取当前节点 ID (用于调试)
+// GetNodeID 获取当前使用的节点 ID (用于调试)
 func GetNodeID() int64 {
-	return getNodeID()
+	if snowflakeNode.Load() == nil {
+		return getNodeID()
+	}
+	return snowflakeID.Load()
 }
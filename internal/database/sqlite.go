@@ -1,6 +1,7 @@
 package database
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"sync"
@@ -13,11 +14,11 @@ import (
 // 嘿嘿~ 这是全新的 SQLite + GORM 数据库模块！(/)
 // 用来替代旧的 Storm 数据库，支持并发访问哦~ 💖
 
-var (
-	gormDB   *gorm.DB
-	gormOnce sync.Once
-	gormErr  error
-)
+// sqliteConn SQLite 的进程内单例连接
+var sqliteConn = &connection{}
+
+// SQLitePragmas 打开 SQLite 连接时执行的 PRAGMA（DSN 查询参数）
+const SQLitePragmas = "_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
 
 // SQLiteConfig SQLite 数据库配置
 type SQLiteConfig struct {
@@ -27,12 +28,11 @@ type SQLiteConfig struct {
 
 // OpenSQLite 打开 SQLite 数据库连接（单例模式）
 func OpenSQLite(cfg *SQLiteConfig) (*gorm.DB, error) {
-	gormOnce.Do(func() {
+	return sqliteConn.open(func() (*gorm.DB, error) {
 		// 确保目录存在
 		dir := filepath.Dir(cfg.DBPath)
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			gormErr = err
-			return
+			return nil, err
 		}
 
 		// 配置 GORM Logger
@@ -42,61 +42,90 @@ func OpenSQLite(cfg *SQLiteConfig) (*gorm.DB, error) {
 		}
 
 		// 打开 SQLite 连接
-		// 启用 WAL 模式支持并发读写，设置忙等待超时
-		dsn := cfg.DBPath + "?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=ON"
+		// 启用 WAL 模式支持并发读写，设置忙等待超时（多个进程同时写入时排队而不是直接报错）
+		// 注意：驱动只识别 _pragma 参数；外键约束保持关闭，关联数据由 Model 层清理
+		dsn := cfg.DBPath + "?" + SQLitePragmas
 		conn, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
 			Logger: logger.Default.LogMode(logLevel),
 		})
 		if err != nil {
-			gormErr = err
-			return
+			return nil, err
 		}
 
 		// 获取底层 sql.DB 设置连接池
 		// SQLite 推荐单连接，避免锁争用
 		sqlDB, err := conn.DB()
 		if err != nil {
-			gormErr = err
-			return
+			return nil, err
 		}
 		sqlDB.SetMaxOpenConns(1)
 		sqlDB.SetMaxIdleConns(1)
 
-		gormDB = conn
+		return conn, nil
 	})
-
-	if gormErr != nil {
-		return nil, gormErr
-	}
-
-	return gormDB, nil
 }
 
 // GetGormDB 获取 GORM 数据库实例
 // 嘿嘿~ 方便在其他地方获取数据库连接！💖
 func GetGormDB() *gorm.DB {
-	return gormDB
+	if sqliteConn.db != nil {
+		return sqliteConn.db
+	}
+	return postgresConn.db
 }
 
 // CloseSQLite 关闭 SQLite 数据库连接
 // 优雅地关闭数据库连接~ (^o^)/
 func CloseSQLite() error {
-	if gormDB != nil {
-		sqlDB, err := gormDB.DB()
+	return sqliteConn.close()
+}
+
+// Close 关闭当前数据库连接（SQLite / PostgreSQL 通用）
+func Close() error {
+	return errors.Join(sqliteConn.close(), postgresConn.close())
+}
+
+// ResetSQLiteConnection 重置数据库连接（仅用于测试）
+// 呀~ 这个方法只在测试时使用哦！两种驱动的单例都会重置
+func ResetSQLiteConnection() {
+	sqliteConn = &connection{}
+	postgresConn = &connection{}
+}
+
+// connection 进程内单例连接
+// 每种驱动各自维护一份，先打开失败的驱动不会影响另一种驱动
+type connection struct {
+	once sync.Once
+	db   *gorm.DB
+	err  error
+}
+
+// open 首次调用时用 dial 建立连接，之后返回同一个连接（或同一个错误）
+func (c *connection) open(dial func() (*gorm.DB, error)) (*gorm.DB, error) {
+	c.once.Do(func() {
+		db, err := dial()
 		if err != nil {
-			return err
+			c.err = err
+			return
 		}
-		return sqlDB.Close()
+		c.db = db
+	})
+	if c.err != nil {
+		return nil, c.err
 	}
-	return nil
+	return c.db, nil
 }
 
-// ResetSQLiteConnection 重置数据库连接（仅用于测试）
-// 呀~ 这个方法只在测试时使用哦！
-func ResetSQLiteConnection() {
-	gormDB = nil
-	gormOnce = sync.Once{}
-	gormErr = nil
+// close 关闭已打开的连接，未打开时什么都不做
+func (c *connection) close() error {
+	if c.db == nil {
+		return nil
+	}
+	sqlDB, err := c.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
 }
 
 // AutoMigrateSQLite 自动迁移数据库表结构
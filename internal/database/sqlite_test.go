@@ -0,0 +1,38 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestOpenPerDriver 两种驱动各自维护单例连接，PostgreSQL 打开失败不影响随后打开 SQLite
+func TestOpenPerDriver(t *testing.T) {
+	ResetSQLiteConnection()
+	t.Cleanup(func() {
+		_ = Close()
+		ResetSQLiteConnection()
+	})
+
+	unreachable := &PostgresConfig{DSN: "host=127.0.0.1 port=1 user=test dbname=test sslmode=disable connect_timeout=1"}
+	if _, err := OpenPostgres(unreachable); err == nil {
+		t.Fatal("连接不存在的 PostgreSQL 应失败")
+	}
+	if GetGormDB() != nil {
+		t.Fatal("打开失败时不应留下连接")
+	}
+
+	db, err := OpenSQLite(&SQLiteConfig{DBPath: filepath.Join(t.TempDir(), "data.db")})
+	if err != nil {
+		t.Fatalf("PostgreSQL 打开失败后应仍能打开 SQLite: %v", err)
+	}
+	if DriverName(db) != DriverSQLite || GetGormDB() != db {
+		t.Fatalf("应返回 SQLite 连接: %s", DriverName(db))
+	}
+	again, err := OpenSQLite(&SQLiteConfig{DBPath: filepath.Join(t.TempDir(), "other.db")})
+	if err != nil || again != db {
+		t.Fatalf("再次打开应返回同一个连接: %v", err)
+	}
+	if _, err := OpenPostgres(unreachable); err == nil {
+		t.Fatal("PostgreSQL 的打开失败应被保留")
+	}
+}
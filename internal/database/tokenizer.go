@@ -0,0 +1,235 @@
+package database
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 嘿嘿~ 这是全文索引用的分词器！(´∀｀)💖
+// SQLite 自带的 unicode61 分词器会把一整段中文当成一个词，根本搜不到~
+// 所以写入索引前先在 Go 侧分词：拉丁文按单词切分，中日韩文本切成二元组(bigram)，
+// 再用空格拼接交给 unicode61，它只需要按空格切开即可。
+
+// segmentKind 文本片段类型
+type segmentKind int
+
+const (
+	segmentWord segmentKind = iota // 拉丁文/数字单词
+	segmentCJK                     // 中日韩连续文本
+)
+
+// textSegment 按字符类别切分出的连续片段
+type textSegment struct {
+	kind  segmentKind
+	runes []rune
+}
+
+// isCJK 判断字符是否属于中日韩文字
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		r == 'ー' // 日文长音符
+}
+
+// isWordRune 判断字符是否属于普通单词字符
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_'
+}
+
+// splitSegments 将文本切分为单词片段和中日韩片段（统一转小写，标点和空白作为分隔符）
+func splitSegments(text string) []textSegment {
+	var segments []textSegment
+	var current *textSegment
+
+	for _, r := range text {
+		var kind segmentKind
+		switch {
+		case isCJK(r):
+			kind = segmentCJK
+		case isWordRune(r):
+			kind = segmentWord
+		default:
+			current = nil
+			continue
+		}
+		if current == nil || current.kind != kind {
+			segments = append(segments, textSegment{kind: kind})
+			current = &segments[len(segments)-1]
+		}
+		current.runes = append(current.runes, unicode.ToLower(r))
+	}
+	return segments
+}
+
+// cjkBigrams 将中日韩片段切分为相邻二元组
+func cjkBigrams(runes []rune) []string {
+	if len(runes) < 2 {
+		return []string{string(runes)}
+	}
+	grams := make([]string, 0, len(runes)-1)
+	for i := 0; i+1 < len(runes); i++ {
+		grams = append(grams, string(runes[i:i+2]))
+	}
+	return grams
+}
+
+// TokenizeForIndex 将文本转换为写入全文索引的词序列（空格分隔）
+// 中日韩片段额外追加末尾单字，保证任意单字都能以前缀方式命中
+func TokenizeForIndex(text string) string {
+	var tokens []string
+	for _, seg := range splitSegments(text) {
+		if seg.kind == segmentWord {
+			tokens = append(tokens, string(seg.runes))
+			continue
+		}
+		tokens = append(tokens, cjkBigrams(seg.runes)...)
+		if len(seg.runes) > 1 {
+			tokens = append(tokens, string(seg.runes[len(seg.runes)-1]))
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+// BuildFTSMatchQuery 将用户输入的关键词转换为 FTS5 MATCH 表达式
+// 每个片段生成一个短语，片段之间为 AND 关系：
+//   - 拉丁文单词：前缀匹配，如 "wal"*
+//   - 中日韩多字：相邻二元组组成的短语，如 "数据 据库"
+//   - 中日韩单字：前缀匹配，如 "库"*
+//
+// 没有有效关键词时返回空字符串
+func BuildFTSMatchQuery(keyword string) string {
+	segments := splitSegments(keyword)
+	parts := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		if seg.kind == segmentCJK && len(seg.runes) > 1 {
+			parts = append(parts, `"`+strings.Join(cjkBigrams(seg.runes), " ")+`"`)
+			continue
+		}
+		// 分词结果只包含字母数字，无需转义引号
+		parts = append(parts, `"`+string(seg.runes)+`"*`)
+	}
+	return strings.Join(parts, " ")
+}
+
+// BuildSearchSnippet 生成搜索结果的高亮摘要
+// 依次在各字段中查找关键词，取第一个命中的字段截取 width 个字符左右的片段，
+// 命中部分用【】包裹；都未命中时返回第一个非空字段的开头
+func BuildSearchSnippet(keyword string, width int, fields ...string) string {
+	var needles [][]rune
+	for _, seg := range splitSegments(keyword) {
+		needles = append(needles, seg.runes)
+	}
+
+	fallback := ""
+	for _, field := range fields {
+		text := []rune(strings.Join(strings.Fields(field), " "))
+		if len(text) == 0 {
+			continue
+		}
+		if fallback == "" {
+			fallback = string(truncateRunes(text, width))
+		}
+
+		marks := matchMarks(text, needles)
+		first := -1
+		for i, m := range marks {
+			if m {
+				first = i
+				break
+			}
+		}
+		if first < 0 {
+			continue
+		}
+
+		// 命中位置前保留约四分之一窗口作为上下文
+		start := first - width/4
+		if start < 0 {
+			start = 0
+		}
+		end := start + width
+		if end > len(text) {
+			end = len(text)
+		}
+
+		var b strings.Builder
+		if start > 0 {
+			b.WriteString("…")
+		}
+		for i := start; i < end; i++ {
+			if marks[i] && (i == start || !marks[i-1]) {
+				b.WriteString(FTSHighlightOpen)
+			}
+			b.WriteRune(text[i])
+			if marks[i] && (i == end-1 || !marks[i+1]) {
+				b.WriteString(FTSHighlightClose)
+			}
+		}
+		if end < len(text) {
+			b.WriteString("…")
+		}
+		return b.String()
+	}
+	return fallback
+}
+
+// matchMarks 标记文本中被任一关键词覆盖的字符（忽略大小写）
+func matchMarks(text []rune, needles [][]rune) []bool {
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	marks := make([]bool, len(text))
+	for _, needle := range needles {
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) == string(needle) {
+				for j := i; j < i+len(needle); j++ {
+					marks[j] = true
+				}
+			}
+		}
+	}
+	return marks
+}
+
+// truncateRunes 截取前 width 个字符，超出部分以省略号表示
+func truncateRunes(text []rune, width int) []rune {
+	if width >= len(text) {
+		return text
+	}
+	return append(append([]rune{}, text[:width]...), []rune("…")...)
+}
+
+// ScoreKeyword 在进程内对原文计算关键词相关度（用于内容加密后无法走全文索引的场景）
+// 关键词的每个片段都必须命中某个字段（与 BuildFTSMatchQuery 的 AND 语义一致），
+// 每个片段取命中字段中的最大权重累加；返回值取负数，与 bm25 一样越小越相关
+func ScoreKeyword(keyword string, weights []float64, fields ...string) (float64, bool) {
+	segments := splitSegments(keyword)
+	if len(segments) == 0 {
+		return 0, false
+	}
+
+	lowered := make([]string, len(fields))
+	for i, field := range fields {
+		lowered[i] = strings.ToLower(field)
+	}
+
+	score := 0.0
+	for _, seg := range segments {
+		needle := string(seg.runes)
+		best := 0.0
+		for i, field := range lowered {
+			if i < len(weights) && weights[i] > best && strings.Contains(field, needle) {
+				best = weights[i]
+			}
+		}
+		if best == 0 {
+			return 0, false
+		}
+		score += best
+	}
+	return -score, true
+}
@@ -0,0 +1,100 @@
+package database
+
+import "testing"
+
+func TestTokenizeForIndex(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "空文本", text: "", want: ""},
+		{name: "拉丁文小写", text: "Hello, WAL_mode 2024!", want: "hello wal_mode 2024"},
+		{name: "中文二元组加末尾单字", text: "数据库", want: "数据 据库 库"},
+		{name: "中文单字", text: "库", want: "库"},
+		{name: "中英混排", text: "启用WAL模式", want: "启用 用 wal 模式 式"},
+		{name: "标点分隔", text: "数据，备份。", want: "数据 据 备份 份"},
+		{name: "日文假名与长音", text: "データ", want: "デー ータ タ"},
+		{name: "韩文", text: "한국어", want: "한국 국어 어"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := TokenizeForIndex(tc.text); got != tc.want {
+				t.Fatalf("TokenizeForIndex(%q) = %q，期望 %q", tc.text, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildFTSMatchQuery(t *testing.T) {
+	cases := []struct {
+		name    string
+		keyword string
+		want    string
+	}{
+		{name: "空关键词", keyword: "", want: ""},
+		{name: "只有标点", keyword: `"*()`, want: ""},
+		{name: "拉丁文前缀", keyword: "WAL", want: `"wal"*`},
+		{name: "多个单词为 AND", keyword: "wal mode", want: `"wal"* "mode"*`},
+		{name: "中文多字为二元组短语", keyword: "数据库", want: `"数据 据库"`},
+		{name: "中文单字前缀", keyword: "库", want: `"库"*`},
+		{name: "中英混排", keyword: "SQLite数据库", want: `"sqlite"* "数据 据库"`},
+		{name: "引号被丢弃", keyword: `wal" OR "x`, want: `"wal"* "or"* "x"*`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := BuildFTSMatchQuery(tc.keyword); got != tc.want {
+				t.Fatalf("BuildFTSMatchQuery(%q) = %q，期望 %q", tc.keyword, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildSearchSnippet(t *testing.T) {
+	cases := []struct {
+		name    string
+		keyword string
+		width   int
+		fields  []string
+		want    string
+	}{
+		{name: "高亮命中", keyword: "wal", width: 48, fields: []string{"启用 WAL 模式"}, want: "启用 【WAL】 模式"},
+		{name: "合并相邻命中", keyword: "数据 据库", width: 48, fields: []string{"数据库备份"}, want: "【数据库】备份"},
+		{name: "命中靠后时截断前文", keyword: "目标", width: 8, fields: []string{"一二三四五六七八九十目标在这里结尾"}, want: "…九十【目标】在这里结…"},
+		{name: "第一个字段未命中时查找后续字段", keyword: "标题", width: 48, fields: []string{"内容", "这是标题"}, want: "这是【标题】"},
+		{name: "都未命中时返回第一个非空字段", keyword: "无", width: 4, fields: []string{"", "一二三四五六"}, want: "一二三四…"},
+		{name: "折叠空白", keyword: "b", width: 48, fields: []string{"a\n\n  b"}, want: "a 【b】"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := BuildSearchSnippet(tc.keyword, tc.width, tc.fields...); got != tc.want {
+				t.Fatalf("BuildSearchSnippet(%q) = %q，期望 %q", tc.keyword, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScoreKeyword(t *testing.T) {
+	weights := []float64{10, 5, 2, 1}
+	cases := []struct {
+		name    string
+		keyword string
+		fields  []string
+		want    float64
+		wantOK  bool
+	}{
+		{name: "标题命中", keyword: "wal", fields: []string{"WAL 模式", "", "", ""}, want: -10, wantOK: true},
+		{name: "取最大权重", keyword: "wal", fields: []string{"", "wal", "", "wal"}, want: -5, wantOK: true},
+		{name: "多个片段累加", keyword: "wal 数据", fields: []string{"wal", "", "", "数据"}, want: -11, wantOK: true},
+		{name: "任一片段未命中", keyword: "wal 数据", fields: []string{"wal", "", "", ""}},
+		{name: "空关键词", keyword: "  ", fields: []string{"wal"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := ScoreKeyword(tc.keyword, weights, tc.fields...)
+			if ok != tc.wantOK || got != tc.want {
+				t.Fatalf("ScoreKeyword(%q) = %v, %v，期望 %v, %v", tc.keyword, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
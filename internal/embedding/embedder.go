@@ -0,0 +1,111 @@
+package embedding
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// 嘿嘿~ 这是语义搜索用的向量嵌入层！(´∀｀)💖
+// 默认使用离线的哈希 n-gram 嵌入器，无需任何外部服务；
+// 也可以配置 Ollama / OpenAI 兼容的 HTTP 嵌入服务获得更好的语义效果~
+
+// 嵌入提供方
+const (
+	ProviderHash   = "hash"   // 内置离线哈希 n-gram 嵌入
+	ProviderOllama = "ollama" // Ollama /api/embed
+	ProviderOpenAI = "openai" // OpenAI 兼容 /embeddings
+)
+
+// 错误定义
+var (
+	ErrUnknownProvider   = errors.New("未知的向量嵌入提供方")
+	ErrDimensionMismatch = errors.New("向量维度不一致")
+)
+
+// Embedder 向量嵌入器接口
+type Embedder interface {
+	// Name 嵌入模型标识（与向量一起保存，变化时会触发重新嵌入）
+	Name() string
+	// Embed 批量计算文本向量，返回顺序与输入一致
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Config 向量嵌入配置
+type Config struct {
+	Provider   string `json:"provider"`             // hash(默认)/ollama/openai
+	BaseURL    string `json:"base_url,omitempty"`   // HTTP 服务地址
+	Model      string `json:"model,omitempty"`      // 嵌入模型名称
+	APIKey     string `json:"api_key,omitempty"`    // HTTP 服务密钥（可选）
+	Dimensions int    `json:"dimensions,omitempty"` // 哈希嵌入维度（仅 hash 使用）
+}
+
+// New 根据配置创建嵌入器
+// Provider 为空时使用内置哈希嵌入器
+func New(cfg Config) (Embedder, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Provider)) {
+	case "", ProviderHash:
+		return NewHashEmbedder(cfg.Dimensions), nil
+	case ProviderOllama, ProviderOpenAI:
+		return NewHTTPEmbedder(cfg)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, cfg.Provider)
+	}
+}
+
+// CosineSimilarity 计算两个向量的余弦相似度
+// 维度不一致或存在零向量时返回 0
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// EncodeVector 将向量编码为小端序 float32 字节（用于数据库存储）
+func EncodeVector(vec []float32) []byte {
+	buf := make([]byte, 4*len(vec))
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	return buf
+}
+
+// DecodeVector 将数据库中的字节解码为向量
+func DecodeVector(buf []byte) ([]float32, error) {
+	if len(buf)%4 != 0 {
+		return nil, ErrDimensionMismatch
+	}
+	vec := make([]float32, len(buf)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	return vec, nil
+}
+
+// normalize 将向量归一化为单位长度（原地修改）
+func normalize(vec []float32) {
+	var sum float64
+	for _, v := range vec {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vec {
+		vec[i] /= norm
+	}
+}
@@ -0,0 +1,75 @@
+package embedding
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name     string
+		cfg      Config
+		wantName string
+		wantErr  error
+	}{
+		{name: "默认哈希嵌入", cfg: Config{}, wantName: "hash-ngram-512"},
+		{name: "哈希嵌入自定义维度", cfg: Config{Provider: "HASH", Dimensions: 64}, wantName: "hash-ngram-64"},
+		{name: "Ollama 默认模型", cfg: Config{Provider: "ollama"}, wantName: "ollama:" + DefaultOllamaModel},
+		{name: "OpenAI 指定模型", cfg: Config{Provider: " openai ", Model: "m"}, wantName: "openai:m"},
+		{name: "未知提供方", cfg: Config{Provider: "bert"}, wantErr: ErrUnknownProvider},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := New(tc.cfg)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("错误 = %v，期望 %v", err, tc.wantErr)
+			}
+			if err == nil && e.Name() != tc.wantName {
+				t.Fatalf("Name() = %s，期望 %s", e.Name(), tc.wantName)
+			}
+		})
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{name: "相同方向", a: []float32{1, 2}, b: []float32{2, 4}, want: 1},
+		{name: "正交", a: []float32{1, 0}, b: []float32{0, 1}, want: 0},
+		{name: "相反方向", a: []float32{1, 0}, b: []float32{-3, 0}, want: -1},
+		{name: "维度不一致", a: []float32{1}, b: []float32{1, 0}, want: 0},
+		{name: "零向量", a: []float32{0, 0}, b: []float32{1, 0}, want: 0},
+		{name: "空向量", want: 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := CosineSimilarity(tc.a, tc.b); math.Abs(got-tc.want) > 1e-6 {
+				t.Fatalf("CosineSimilarity = %v，期望 %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestVectorEncoding(t *testing.T) {
+	vec := []float32{0, 1.5, -2.25, float32(math.Inf(1)), math.SmallestNonzeroFloat32}
+	buf := EncodeVector(vec)
+	if len(buf) != 4*len(vec) {
+		t.Fatalf("编码长度 = %d，期望 %d", len(buf), 4*len(vec))
+	}
+	got, err := DecodeVector(buf)
+	if err != nil {
+		t.Fatalf("解码失败: %v", err)
+	}
+	for i := range vec {
+		if got[i] != vec[i] {
+			t.Fatalf("解码结果 = %v，期望 %v", got, vec)
+		}
+	}
+	if _, err := DecodeVector(buf[:5]); !errors.Is(err, ErrDimensionMismatch) {
+		t.Fatalf("长度不是 4 的倍数时应返回维度错误: %v", err)
+	}
+}
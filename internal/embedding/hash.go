@@ -0,0 +1,113 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// DefaultHashDimensions 哈希嵌入默认维度
+const DefaultHashDimensions = 512
+
+// HashEmbedder 离线哈希 n-gram 嵌入器
+// 呀~ 把单词、字符三元组和中日韩二元组哈希到固定维度，完全离线也能做近似语义匹配！✨
+type HashEmbedder struct {
+	dimensions int
+}
+
+// NewHashEmbedder 创建哈希嵌入器（dimensions <= 0 时使用默认维度）
+func NewHashEmbedder(dimensions int) *HashEmbedder {
+	if dimensions <= 0 {
+		dimensions = DefaultHashDimensions
+	}
+	return &HashEmbedder{dimensions: dimensions}
+}
+
+// Name 嵌入模型标识
+func (e *HashEmbedder) Name() string {
+	return fmt.Sprintf("hash-ngram-%d", e.dimensions)
+}
+
+// Embed 批量计算文本向量
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		vectors[i] = e.embedOne(text)
+	}
+	return vectors, nil
+}
+
+// embedOne 计算单条文本的向量
+func (e *HashEmbedder) embedOne(text string) []float32 {
+	vec := make([]float32, e.dimensions)
+	add := func(feature string, weight float32) {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(feature))
+		sum := h.Sum64()
+		idx := int(sum % uint64(e.dimensions))
+		// 用哈希的最高位决定符号，减少碰撞带来的偏差
+		if sum>>63 == 1 {
+			weight = -weight
+		}
+		vec[idx] += weight
+	}
+
+	for _, seg := range splitFeatureSegments(text) {
+		if seg.cjk {
+			// 中日韩：单字 + 相邻二元组
+			for i, r := range seg.runes {
+				add("u:"+string(r), 0.5)
+				if i+1 < len(seg.runes) {
+					add("b:"+string(seg.runes[i:i+2]), 1.0)
+				}
+			}
+			continue
+		}
+		// 拉丁文：整词 + 字符三元组（容忍词形变化）
+		word := string(seg.runes)
+		add("w:"+word, 1.0)
+		padded := []rune("#" + word + "#")
+		for i := 0; i+3 <= len(padded); i++ {
+			add("t:"+string(padded[i:i+3]), 0.5)
+		}
+	}
+
+	normalize(vec)
+	return vec
+}
+
+// featureSegment 按字符类别切分出的连续片段
+type featureSegment struct {
+	cjk   bool
+	runes []rune
+}
+
+// splitFeatureSegments 将文本切分为单词片段和中日韩片段（统一转小写）
+func splitFeatureSegments(text string) []featureSegment {
+	var segments []featureSegment
+	inSegment := false
+	for _, r := range strings.ToLower(text) {
+		var cjk bool
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			cjk = true
+		case unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_':
+			cjk = false
+		default:
+			inSegment = false
+			continue
+		}
+		if !inSegment || segments[len(segments)-1].cjk != cjk {
+			segments = append(segments, featureSegment{cjk: cjk})
+			inSegment = true
+		}
+		last := &segments[len(segments)-1]
+		last.runes = append(last.runes, r)
+	}
+	return segments
+}
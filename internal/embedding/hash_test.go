@@ -0,0 +1,74 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestHashEmbedder(t *testing.T) {
+	e := NewHashEmbedder(0)
+	texts := []string{
+		"数据库备份策略",
+		"定期备份数据库",
+		"今天天气很好",
+		"configure the database backup",
+		"Database backups configured",
+		"",
+	}
+	vectors, err := e.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("嵌入失败: %v", err)
+	}
+	if len(vectors) != len(texts) {
+		t.Fatalf("向量数量 = %d，期望 %d", len(vectors), len(texts))
+	}
+	for i, vec := range vectors {
+		if len(vec) != DefaultHashDimensions {
+			t.Fatalf("第 %d 条向量维度 = %d", i, len(vec))
+		}
+		var norm float64
+		for _, v := range vec {
+			norm += float64(v) * float64(v)
+		}
+		want := 1.0
+		if texts[i] == "" {
+			want = 0
+		}
+		if math.Abs(norm-want) > 1e-5 {
+			t.Fatalf("第 %d 条向量模长平方 = %v，期望 %v", i, norm, want)
+		}
+	}
+
+	// 共享词语的文本比无关文本更相似，且大小写、词形变化不影响匹配
+	cases := []struct {
+		name         string
+		a, near, far int
+	}{
+		{name: "中文", a: 0, near: 1, far: 2},
+		{name: "英文词形变化", a: 3, near: 4, far: 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			near := CosineSimilarity(vectors[tc.a], vectors[tc.near])
+			far := CosineSimilarity(vectors[tc.a], vectors[tc.far])
+			if near <= far || near <= 0.2 {
+				t.Fatalf("相近文本相似度 %.3f 应明显高于无关文本 %.3f", near, far)
+			}
+		})
+	}
+
+	again, _ := e.Embed(context.Background(), texts[:1])
+	if CosineSimilarity(again[0], vectors[0]) < 0.999999 {
+		t.Fatalf("同一文本的向量应保持确定")
+	}
+}
+
+func TestHashEmbedderCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewHashEmbedder(16).Embed(ctx, []string{"a"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("上下文取消后应返回错误: %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 默认 HTTP 服务配置
+const (
+	DefaultOllamaBaseURL = "http://localhost:11434"
+	DefaultOllamaModel   = "nomic-embed-text"
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+	DefaultOpenAIModel   = "text-embedding-3-small"
+)
+
+// httpTimeout HTTP 请求超时时间
+const httpTimeout = 30 * time.Second
+
+// HTTPEmbedder 基于 HTTP 的嵌入器
+// 嘿嘿~ 支持 Ollama (/api/embed) 和 OpenAI 兼容 (/embeddings) 两种接口！💖
+type HTTPEmbedder struct {
+	provider string
+	baseURL  string
+	model    string
+	apiKey   string
+	client   *http.Client
+}
+
+// NewHTTPEmbedder 创建 HTTP 嵌入器
+// 未配置 BaseURL / Model 时使用对应提供方的默认值
+func NewHTTPEmbedder(cfg Config) (*HTTPEmbedder, error) {
+	provider := strings.ToLower(strings.TrimSpace(cfg.Provider))
+	e := &HTTPEmbedder{
+		provider: provider,
+		baseURL:  strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/"),
+		model:    strings.TrimSpace(cfg.Model),
+		apiKey:   cfg.APIKey,
+		client:   &http.Client{Timeout: httpTimeout},
+	}
+
+	switch provider {
+	case ProviderOllama:
+		if e.baseURL == "" {
+			e.baseURL = DefaultOllamaBaseURL
+		}
+		if e.model == "" {
+			e.model = DefaultOllamaModel
+		}
+	case ProviderOpenAI:
+		if e.baseURL == "" {
+			e.baseURL = DefaultOpenAIBaseURL
+		}
+		if e.model == "" {
+			e.model = DefaultOpenAIModel
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, cfg.Provider)
+	}
+	return e, nil
+}
+
+// Name 嵌入模型标识
+func (e *HTTPEmbedder) Name() string {
+	return e.provider + ":" + e.model
+}
+
+// embedRequest 请求体（Ollama 与 OpenAI 兼容接口字段相同）
+type embedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// ollamaResponse Ollama /api/embed 响应
+type ollamaResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// openAIResponse OpenAI 兼容 /embeddings 响应
+type openAIResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed 批量计算文本向量
+func (e *HTTPEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+
+	endpoint := e.baseURL + "/embeddings"
+	if e.provider == ProviderOllama {
+		endpoint = e.baseURL + "/api/embed"
+	}
+
+	body, err := json.Marshal(embedRequest{Model: e.model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求嵌入服务失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("嵌入服务返回错误 (%d): %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var vectors [][]float32
+	if e.provider == ProviderOllama {
+		var parsed ollamaResponse
+		if err := json.Unmarshal(data, &parsed); err != nil {
+			return nil, fmt.Errorf("解析嵌入服务响应失败: %w", err)
+		}
+		vectors = parsed.Embeddings
+	} else {
+		var parsed openAIResponse
+		if err := json.Unmarshal(data, &parsed); err != nil {
+			return nil, fmt.Errorf("解析嵌入服务响应失败: %w", err)
+		}
+		vectors = make([][]float32, len(parsed.Data))
+		for i, item := range parsed.Data {
+			idx := item.Index
+			if idx < 0 || idx >= len(vectors) {
+				idx = i
+			}
+			vectors[idx] = item.Embedding
+		}
+	}
+
+	if len(vectors) != len(texts) {
+		return nil, errors.New("嵌入服务返回的向量数量与输入不一致")
+	}
+	for _, vec := range vectors {
+		normalize(vec)
+	}
+	return vectors, nil
+}
@@ -0,0 +1,121 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPEmbedder(t *testing.T) {
+	cases := []struct {
+		name      string
+		provider  string
+		apiKey    string
+		wantPath  string
+		response  string
+		status    int
+		wantErr   string
+		wantFirst []float32 // 归一化后的第一条向量
+	}{
+		{
+			name:      "Ollama",
+			provider:  ProviderOllama,
+			wantPath:  "/api/embed",
+			response:  `{"embeddings": [[3, 4], [0, 2]]}`,
+			wantFirst: []float32{0.6, 0.8},
+		},
+		{
+			name:      "OpenAI 按 index 排序",
+			provider:  ProviderOpenAI,
+			apiKey:    "sk-test",
+			wantPath:  "/embeddings",
+			response:  `{"data": [{"index": 1, "embedding": [0, 2]}, {"index": 0, "embedding": [3, 4]}]}`,
+			wantFirst: []float32{0.6, 0.8},
+		},
+		{
+			name:     "服务返回错误",
+			provider: ProviderOpenAI,
+			wantPath: "/embeddings",
+			response: "model not found\n",
+			status:   http.StatusNotFound,
+			wantErr:  "嵌入服务返回错误 (404): model not found",
+		},
+		{
+			name:     "向量数量不一致",
+			provider: ProviderOllama,
+			wantPath: "/api/embed",
+			response: `{"embeddings": [[1, 0]]}`,
+			wantErr:  "数量与输入不一致",
+		},
+		{
+			name:     "响应不是 JSON",
+			provider: ProviderOllama,
+			wantPath: "/api/embed",
+			response: "<html>",
+			wantErr:  "解析嵌入服务响应失败",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != tc.wantPath {
+					t.Errorf("请求 = %s %s，期望 POST %s", r.Method, r.URL.Path, tc.wantPath)
+				}
+				wantAuth := ""
+				if tc.apiKey != "" {
+					wantAuth = "Bearer " + tc.apiKey
+				}
+				if got := r.Header.Get("Authorization"); got != wantAuth {
+					t.Errorf("Authorization = %q，期望 %q", got, wantAuth)
+				}
+				var req embedRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model != "m" || len(req.Input) != 2 {
+					t.Errorf("请求体 = %+v（%v）", req, err)
+				}
+				if tc.status != 0 {
+					w.WriteHeader(tc.status)
+				}
+				_, _ = w.Write([]byte(tc.response))
+			}))
+			defer server.Close()
+
+			e, err := NewHTTPEmbedder(Config{Provider: tc.provider, BaseURL: server.URL + "/", Model: "m", APIKey: tc.apiKey})
+			if err != nil {
+				t.Fatalf("创建嵌入器失败: %v", err)
+			}
+			vectors, err := e.Embed(context.Background(), []string{"a", "b"})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("错误 = %v，期望包含 %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("嵌入失败: %v", err)
+			}
+			if len(vectors) != 2 {
+				t.Fatalf("向量数量 = %d", len(vectors))
+			}
+			for i, v := range tc.wantFirst {
+				if d := vectors[0][i] - v; d > 1e-6 || d < -1e-6 {
+					t.Fatalf("第一条向量 = %v，期望 %v", vectors[0], tc.wantFirst)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPEmbedderEmptyInput(t *testing.T) {
+	// 空输入不发请求
+	e, err := NewHTTPEmbedder(Config{Provider: ProviderOllama, BaseURL: "http://127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("创建嵌入器失败: %v", err)
+	}
+	vectors, err := e.Embed(context.Background(), nil)
+	if err != nil || len(vectors) != 0 {
+		t.Fatalf("空输入应直接返回: %v, %v", vectors, err)
+	}
+}
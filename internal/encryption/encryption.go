@@ -0,0 +1,239 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 嘿嘿~ 这是静态数据加密模块！(´∀｀)💖
+// 记忆内容、计划内容和待办描述在写入数据库前用 AES-256-GCM 加密，读取时自动解密~
+// 密钥来自口令（PBKDF2-SHA256 派生）或密钥文件，密钥本身永远不会写进配置文件！
+
+// PassphraseEnv 提供加密口令的环境变量
+const PassphraseEnv = "LLM_MEMORY_PASSPHRASE"
+
+// NewPassphraseEnv 轮换密钥时提供新口令的环境变量
+const NewPassphraseEnv = "LLM_MEMORY_NEW_PASSPHRASE"
+
+// 密文格式：enc:v1:<base64(nonce || ciphertext)>
+// 没有前缀的值视为明文，便于启用加密前的旧数据平滑过渡
+const ciphertextPrefix = "enc:v1:"
+
+// 密钥参数
+const (
+	KeySize          = 32     // AES-256
+	saltSize         = 16     // 口令派生盐长度
+	pbkdf2Iterations = 600000 // OWASP 推荐的 PBKDF2-SHA256 迭代次数
+	keyCheckText     = "llm-memory-key-check"
+)
+
+// 错误定义
+var (
+	ErrKeyRequired   = errors.New("数据已加密，但未提供密钥（请设置 " + PassphraseEnv + " 或在配置中指定 key_file）")
+	ErrWrongKey      = errors.New("加密密钥不正确")
+	ErrInvalidKey    = errors.New("密钥文件格式错误，需要 32 字节的原始、hex 或 base64 密钥")
+	ErrDecryptFailed = errors.New("解密失败，数据可能已损坏或密钥不匹配")
+)
+
+// Config 加密配置
+// 只保存盐和校验值，口令和密钥不会出现在配置文件中
+type Config struct {
+	Enabled  bool   `json:"enabled"`             // 是否启用静态加密
+	KeyFile  string `json:"key_file,omitempty"`  // 密钥文件路径（为空时使用口令）
+	Salt     string `json:"salt,omitempty"`      // 口令派生盐（base64）
+	KeyCheck string `json:"key_check,omitempty"` // 用密钥加密的固定文本，用于校验密钥是否正确
+}
+
+// Cipher AES-GCM 加解密器
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher 使用 32 字节密钥创建加解密器
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// IsEncrypted 判断值是否为密文
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, ciphertextPrefix)
+}
+
+// Encrypt 加密字符串（每次使用随机 nonce）
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return ciphertextPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密字符串
+// 没有密文前缀的值视为明文原样返回
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, ciphertextPrefix))
+	if err != nil {
+		return "", ErrDecryptFailed
+	}
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", ErrDecryptFailed
+	}
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", ErrDecryptFailed
+	}
+	return string(plaintext), nil
+}
+
+// DeriveKey 使用 PBKDF2-SHA256 从口令派生密钥
+func DeriveKey(passphrase string, salt []byte) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("加密口令不能为空")
+	}
+	return pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, KeySize)
+}
+
+// LoadKeyFile 读取密钥文件（支持 base64、hex 或 32 字节原始密钥）
+func LoadKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取密钥文件失败: %w", err)
+	}
+	text := strings.TrimSpace(string(data))
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	if key, err := hex.DecodeString(text); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	if len(data) == KeySize {
+		return data, nil
+	}
+	return nil, ErrInvalidKey
+}
+
+// GenerateKeyFile 生成随机密钥并写入文件（base64，权限 0600）
+// 文件已存在时返回错误，避免覆盖正在使用的密钥
+func GenerateKeyFile(path string) error {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(base64.StdEncoding.EncodeToString(key) + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// NewConfig 根据密钥来源生成新的加密配置和加解密器
+// keyFile 不为空时使用密钥文件（不存在则自动生成），否则使用口令并生成新的盐
+func NewConfig(keyFile, passphrase string) (Config, *Cipher, error) {
+	cfg := Config{Enabled: true}
+
+	var key []byte
+	if keyFile != "" {
+		absPath, err := filepath.Abs(keyFile)
+		if err != nil {
+			return cfg, nil, err
+		}
+		if _, err := os.Stat(absPath); errors.Is(err, os.ErrNotExist) {
+			if err := GenerateKeyFile(absPath); err != nil {
+				return cfg, nil, fmt.Errorf("生成密钥文件失败: %w", err)
+			}
+		}
+		if key, err = LoadKeyFile(absPath); err != nil {
+			return cfg, nil, err
+		}
+		cfg.KeyFile = absPath
+	} else {
+		salt := make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return cfg, nil, err
+		}
+		var err error
+		if key, err = DeriveKey(passphrase, salt); err != nil {
+			return cfg, nil, err
+		}
+		cfg.Salt = base64.StdEncoding.EncodeToString(salt)
+	}
+
+	c, err := NewCipher(key)
+	if err != nil {
+		return cfg, nil, err
+	}
+	if cfg.KeyCheck, err = c.Encrypt(keyCheckText); err != nil {
+		return cfg, nil, err
+	}
+	return cfg, c, nil
+}
+
+// LoadCipher 根据配置加载加解密器
+// 未启用加密时返回 nil；口令从 LLM_MEMORY_PASSPHRASE 环境变量读取
+func (cfg Config) LoadCipher() (*Cipher, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	var key []byte
+	var err error
+	if cfg.KeyFile != "" {
+		if key, err = LoadKeyFile(cfg.KeyFile); err != nil {
+			return nil, err
+		}
+	} else {
+		passphrase := os.Getenv(PassphraseEnv)
+		if passphrase == "" {
+			return nil, ErrKeyRequired
+		}
+		salt, err := base64.StdEncoding.DecodeString(cfg.Salt)
+		if err != nil || len(salt) == 0 {
+			return nil, errors.New("加密配置中的 salt 无效")
+		}
+		if key, err = DeriveKey(passphrase, salt); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if check, err := c.Decrypt(cfg.KeyCheck); err != nil || check != keyCheckText {
+		return nil, ErrWrongKey
+	}
+	return c, nil
+}
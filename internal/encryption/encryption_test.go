@@ -0,0 +1,150 @@
+package encryption
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestCipher 用密钥文件创建加密配置和加解密器
+func newTestCipher(t *testing.T, name string) (Config, *Cipher) {
+	t.Helper()
+	cfg, c, err := NewConfig(filepath.Join(t.TempDir(), name), "")
+	if err != nil {
+		t.Fatalf("创建加密配置失败: %v", err)
+	}
+	return cfg, c
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	_, c := newTestCipher(t, "key")
+	_, other := newTestCipher(t, "other")
+
+	cases := []struct {
+		name  string
+		value string
+	}{
+		{name: "空字符串", value: ""},
+		{name: "ASCII", value: "hello world"},
+		{name: "中文与表情", value: "记忆内容 嘿嘿~ ✨💖"},
+		{name: "像密文前缀的明文", value: "enc:v0:not-really"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ciphertext, err := c.Encrypt(tc.value)
+			if err != nil {
+				t.Fatalf("加密失败: %v", err)
+			}
+			if !IsEncrypted(ciphertext) || (tc.value != "" && strings.Contains(ciphertext, tc.value)) {
+				t.Fatalf("密文格式错误: %s", ciphertext)
+			}
+			again, _ := c.Encrypt(tc.value)
+			if again == ciphertext {
+				t.Fatalf("相同明文每次加密应使用不同的 nonce")
+			}
+			plaintext, err := c.Decrypt(ciphertext)
+			if err != nil || plaintext != tc.value {
+				t.Fatalf("解密 = %q（%v），期望 %q", plaintext, err, tc.value)
+			}
+			if _, err := other.Decrypt(ciphertext); !errors.Is(err, ErrDecryptFailed) {
+				t.Fatalf("错误的密钥应解密失败: %v", err)
+			}
+		})
+	}
+}
+
+func TestDecryptInvalid(t *testing.T) {
+	_, c := newTestCipher(t, "key")
+	valid, _ := c.Encrypt("payload")
+
+	cases := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr error
+	}{
+		{name: "明文原样返回", value: "plain text", want: "plain text"},
+		{name: "base64 损坏", value: ciphertextPrefix + "!!!", wantErr: ErrDecryptFailed},
+		{name: "长度不足 nonce", value: ciphertextPrefix + "AAAA", wantErr: ErrDecryptFailed},
+		{name: "密文被篡改", value: valid[:len(valid)-4] + "AAA=", wantErr: ErrDecryptFailed},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := c.Decrypt(tc.value)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("错误 = %v，期望 %v", err, tc.wantErr)
+			}
+			if tc.wantErr == nil && got != tc.want {
+				t.Fatalf("解密 = %q，期望 %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestLoadCipherKeyCheck 加载密钥时用 key_check 校验：密钥不匹配时拒绝，而不是读出乱码
+func TestLoadCipherKeyCheck(t *testing.T) {
+	fileCfg, fileCipher := newTestCipher(t, "key")
+	otherKeyFile := filepath.Join(t.TempDir(), "other-key")
+	if err := GenerateKeyFile(otherKeyFile); err != nil {
+		t.Fatalf("生成密钥文件失败: %v", err)
+	}
+	passCfg, _, err := NewConfig("", "correct horse")
+	if err != nil {
+		t.Fatalf("创建口令配置失败: %v", err)
+	}
+	// 同一口令、不同的盐派生出不同的密钥
+	otherPassCfg, _, err := NewConfig("", "correct horse")
+	if err != nil {
+		t.Fatalf("创建口令配置失败: %v", err)
+	}
+
+	withKeyFile := func(cfg Config, keyFile string) Config {
+		cfg.KeyFile = keyFile
+		return cfg
+	}
+	withSalt := func(cfg Config, salt string) Config {
+		cfg.Salt = salt
+		return cfg
+	}
+
+	cases := []struct {
+		name       string
+		cfg        Config
+		passphrase string
+		wantErr    error
+		wantCipher *Cipher // 非 nil 时校验能解开它加密的内容
+	}{
+		{name: "未启用", cfg: Config{}},
+		{name: "密钥文件正确", cfg: fileCfg, wantCipher: fileCipher},
+		{name: "密钥文件不匹配", cfg: withKeyFile(fileCfg, otherKeyFile), wantErr: ErrWrongKey},
+		{name: "口令正确", cfg: passCfg, passphrase: "correct horse"},
+		{name: "口令错误", cfg: passCfg, passphrase: "wrong horse", wantErr: ErrWrongKey},
+		{name: "未提供口令", cfg: passCfg, wantErr: ErrKeyRequired},
+		{name: "盐被改动", cfg: withSalt(passCfg, otherPassCfg.Salt), passphrase: "correct horse", wantErr: ErrWrongKey},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(PassphraseEnv, tc.passphrase)
+			c, err := tc.cfg.LoadCipher()
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("错误 = %v，期望 %v", err, tc.wantErr)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+			if !tc.cfg.Enabled {
+				if c != nil {
+					t.Fatalf("未启用加密时不应返回加解密器")
+				}
+				return
+			}
+			if tc.wantCipher != nil {
+				ciphertext, _ := tc.wantCipher.Encrypt("shared")
+				if plaintext, err := c.Decrypt(ciphertext); err != nil || plaintext != "shared" {
+					t.Fatalf("加载的密钥与创建时不一致: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// 嘿嘿~ 这是 HTTP 传输！🌐
+// stdio 传输下每个编辑器窗口都会启动自己的进程，各自争抢 SQLite 写锁。
+// HTTP 模式下一个进程同时服务多个客户端会话：/mcp 为 streamable HTTP，/sse 为旧版 SSE~
+
+// HTTP 端点路径
+const (
+	StreamablePath = "/mcp"
+	SSEPath        = "/sse"
+)
+
+// httpDrainTimeout 关闭时等待进行中的请求完成的最长时间
+const httpDrainTimeout = 10 * time.Second
+
+// errServerClosing 服务正在关闭，拒绝新的请求
+var errServerClosing = errors.New("MCP 服务正在关闭")
+
+// RunHTTP 通过 HTTP 运行 MCP 服务
+// 嘿嘿~ 应用 Context 关闭（收到 SIGINT/SIGTERM）时停止接收新连接，等待进行中的请求完成后关闭所有会话！🚀
+func (s *Server) RunHTTP(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("监听 %s 失败: %w", addr, err)
+	}
+
+	token := s.bs.Config().MCPAuthToken()
+	if token == "" && !isLoopback(listener.Addr()) {
+		fmt.Fprintf(os.Stderr, "警告: MCP 服务监听在非本机地址 %s 且未配置访问令牌（mcp.auth_token），任何能访问该地址的人都可以读写数据\n", listener.Addr())
+	}
+
+	httpServer := &http.Server{
+		Handler:           s.httpHandler(token),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// 应用 Context 关闭时优雅停止
+	// 通过 AppContext.Go 启动，Bootstrap.Shutdown 会等它结束后再关闭数据库
+	stopped := make(chan struct{})
+	s.bs.AppContext().Go(func(ctx context.Context) {
+		defer close(stopped)
+		<-ctx.Done()
+		s.shutdownHTTP(httpServer)
+	})
+
+	fmt.Fprintf(os.Stderr, "MCP HTTP 服务已启动: http://%s%s（SSE: http://%s%s）\n",
+		listener.Addr(), StreamablePath, listener.Addr(), SSEPath)
+
+	if err := httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	<-stopped
+	return nil
+}
+
+// httpHandler 创建 /mcp 和 /sse 两个端点，token 不为空时要求 Bearer 认证
+func (s *Server) httpHandler(token string) http.Handler {
+	getServer := func(*http.Request) *mcp.Server { return s.server }
+	mux := http.NewServeMux()
+	mux.Handle(StreamablePath, mcp.NewStreamableHTTPHandler(getServer, nil))
+	mux.Handle(SSEPath, mcp.NewSSEHandler(getServer, nil))
+	if token == "" {
+		return mux
+	}
+	return requireBearerToken(token, mux)
+}
+
+// shutdownHTTP 优雅关闭 HTTP 服务
+// 顺序：停止接收新连接 -> 等待进行中的请求完成 -> 关闭所有会话（结束挂起的 SSE 流）-> 等待连接关闭
+func (s *Server) shutdownHTTP(httpServer *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), httpDrainTimeout)
+	defer cancel()
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- httpServer.Shutdown(ctx)
+	}()
+
+	if err := s.inflight.drain(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "等待 MCP 请求完成超时: %v\n", err)
+	}
+	for session := range s.server.Sessions() {
+		_ = session.Close()
+	}
+
+	if err := <-shutdownErr; err != nil {
+		_ = httpServer.Close()
+	}
+}
+
+// requireBearerToken 校验请求头中的固定访问令牌，不匹配时返回 401
+// 固定令牌没有过期时间，SDK 的 auth.RequireBearerToken 要求令牌带过期时间，所以这里自己校验
+func requireBearerToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, got, _ := strings.Cut(strings.TrimSpace(r.Header.Get("Authorization")), " ")
+		if !strings.EqualFold(scheme, "Bearer") || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(got)), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "访问令牌无效或缺失", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopback 是否为本机回环地址
+func isLoopback(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	return ok && tcpAddr.IP.IsLoopback()
+}
+
+// requestTracker 统计进行中的 MCP 请求
+// 关闭时先拒绝新请求，再等待进行中的请求完成
+type requestTracker struct {
+	mu      sync.Mutex
+	count   int
+	closing bool
+	idle    chan struct{}
+}
+
+// begin 开始一个请求，服务正在关闭时返回 false
+func (t *requestTracker) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closing {
+		return false
+	}
+	t.count++
+	return true
+}
+
+// end 结束一个请求
+func (t *requestTracker) end() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.count--
+	if t.count == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+// drain 拒绝新请求，并等待进行中的请求完成
+func (t *requestTracker) drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.closing = true
+	if t.count == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	if t.idle == nil {
+		t.idle = make(chan struct{})
+	}
+	idle := t.idle
+	t.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// middleware 统计进行中的请求
+func (t *requestTracker) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if !t.begin() {
+			return nil, errServerClosing
+		}
+		defer t.end()
+		return next(ctx, method, req)
+	}
+}
@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// initializeBody MCP 初始化请求
+const initializeBody = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`
+
+// bearerTransport 给每个请求加上 Authorization 头
+type bearerTransport struct {
+	token string
+}
+
+func (t bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestHTTPBearerAuth(t *testing.T) {
+	cases := []struct {
+		name          string
+		token         string // 服务端配置的令牌
+		authorization string
+		wantStatus    int
+	}{
+		{name: "未配置令牌时不校验", wantStatus: http.StatusOK},
+		{name: "缺少令牌", token: "secret", wantStatus: http.StatusUnauthorized},
+		{name: "令牌错误", token: "secret", authorization: "Bearer wrong", wantStatus: http.StatusUnauthorized},
+		{name: "不是 Bearer", token: "secret", authorization: "Basic secret", wantStatus: http.StatusUnauthorized},
+		{name: "只有令牌", token: "secret", authorization: "secret", wantStatus: http.StatusUnauthorized},
+		{name: "令牌正确", token: "secret", authorization: "Bearer secret", wantStatus: http.StatusOK},
+		{name: "scheme 不区分大小写", token: "secret", authorization: "bearer secret", wantStatus: http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(NewServer(testBS).httpHandler(tc.token))
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodPost, server.URL+StreamablePath, strings.NewReader(initializeBody))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", "application/json, text/event-stream")
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("请求失败: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("状态码 = %d，期望 %d", resp.StatusCode, tc.wantStatus)
+			}
+			if tc.wantStatus == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") != "Bearer" {
+				t.Fatalf("401 响应应带 WWW-Authenticate: %q", resp.Header.Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+// TestHTTPShutdownDrainsRequests 关闭时等待进行中的工具调用完成，调用方拿到正常结果
+func TestHTTPShutdownDrainsRequests(t *testing.T) {
+	ctx := context.Background()
+	s := NewServer(testBS)
+	started, release := make(chan struct{}), make(chan struct{})
+	mcp.AddTool(s.server, &mcp.Tool{Name: "block", Description: "阻塞直到测试放行"},
+		func(ctx context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+			close(started)
+			<-release
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+		})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpServer := &http.Server{Handler: s.httpHandler("secret")}
+	go func() { _ = httpServer.Serve(listener) }()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "1"}, nil)
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:   "http://" + listener.Addr().String() + StreamablePath,
+		HTTPClient: &http.Client{Transport: bearerTransport{token: "secret"}},
+		MaxRetries: -1,
+	}, nil)
+	if err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	defer session.Close()
+
+	type callResult struct {
+		result *mcp.CallToolResult
+		err    error
+	}
+	called := make(chan callResult, 1)
+	go func() {
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "block"})
+		called <- callResult{result, err}
+	}()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("工具调用未开始")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.shutdownHTTP(httpServer)
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("进行中的请求完成前不应关闭")
+	case <-time.After(200 * time.Millisecond):
+	}
+	if s.inflight.begin() {
+		t.Fatal("关闭期间应拒绝新的请求")
+	}
+
+	close(release)
+	select {
+	case got := <-called:
+		if got.err != nil || got.result.IsError || got.result.Content[0].(*mcp.TextContent).Text != "done" {
+			t.Fatalf("进行中的请求应正常完成: %+v（%v）", got.result, got.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("工具调用未返回")
+	}
+	select {
+	case <-stopped:
+	case <-time.After(httpDrainTimeout):
+		t.Fatal("请求完成后应关闭服务")
+	}
+}
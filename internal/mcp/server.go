@@ -6,32 +6,53 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/XiaoLFeng/llm-memory/internal/mcp/tools"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
 	"github.com/XiaoLFeng/llm-memory/startup"
 )
 
 // Server MCP 服务器
 // 嘿嘿~ 使用官方 SDK 实现的 MCP 服务器！(´∀｀)💖
 type Server struct {
-	bs     *startup.Bootstrap
-	server *mcp.Server
+	bs       *startup.Bootstrap
+	server   *mcp.Server
+	inflight requestTracker // 进行中的请求（HTTP 模式关闭时等待它们完成）
 }
 
 // NewServer 创建新的 MCP 服务器
 // 呀~ 初始化服务器并注册所有工具！✨
 func NewServer(bs *startup.Bootstrap) *Server {
 	// 创建 MCP 服务器
+	// 资源订阅和参数补全需要在创建服务器时作为选项传入
+	watcher := tools.NewResourceWatcher(bs)
+
+	// 客户端 roots 变更时，让会话下次调用工具时重新解析作用域
 	mcpServer := mcp.NewServer(&mcp.Implementation{
 		Name:    "llm-memory",
 		Version: "0.0.1",
-	}, nil)
+	}, &mcp.ServerOptions{
+		RootsListChangedHandler: func(_ context.Context, req *mcp.RootsListChangedRequest) {
+			tools.InvalidateSessionRoots(req.Session)
+		},
+		SubscribeHandler:   watcher.Subscribe,
+		UnsubscribeHandler: watcher.Unsubscribe,
+		CompletionHandler: func(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+			return tools.Complete(ctx, bs, req)
+		},
+	})
 
 	s := &Server{
 		bs:     bs,
 		server: mcpServer,
 	}
 
-	// 注册所有工具
+	// 标记变更来源，修订历史据此记录 mcp；资源列表按会话作用域生成
+	mcpServer.AddReceivingMiddleware(changeSourceMiddleware, s.inflight.middleware, tools.ResourceListMiddleware(bs))
+
+	// 注册所有工具、资源和提示词
 	s.registerTools()
+	tools.RegisterResources(mcpServer, bs)
+	tools.RegisterPrompts(mcpServer, bs)
+	watcher.Start(mcpServer)
 
 	return s
 }
@@ -53,4 +74,13 @@ func (s *Server) registerTools() {
 	tools.RegisterTodoTools(s.server, s.bs)
 	// 组管理工具
 	tools.RegisterGroupTools(s.server, s.bs)
+	// 回收站工具
+	tools.RegisterTrashTools(s.server, s.bs)
+}
+
+// changeSourceMiddleware 为每个请求的 context 标记变更来源
+func changeSourceMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		return next(types.WithChangeSource(ctx, types.ChangeSourceMCP), method, req)
+	}
 }
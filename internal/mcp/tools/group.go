@@ -6,6 +6,7 @@ import (
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
 	"github.com/XiaoLFeng/llm-memory/startup"
 )
 
@@ -15,16 +16,23 @@ type GroupAddPathInput struct {
 	Path      string `json:"path,omitempty" jsonschema:"要添加的路径，留空则添加当前工作目录"`
 }
 
+// GroupAddPathOutput group_add_path 工具输出
+type GroupAddPathOutput struct {
+	GroupName string `json:"group_name"`
+	Path      string `json:"path" jsonschema:"加入组的路径"`
+	Added     bool   `json:"added" jsonschema:"本次是否新加入（false 表示已在该组中）"`
+}
+
 // validateGroupOperationPermission 验证组操作权限
-func validateGroupOperationPermission(ctx context.Context, bs *startup.Bootstrap, groupName string) error {
+func validateGroupOperationPermission(ctx context.Context, bs *startup.Bootstrap, scope *types.ScopeContext, groupName string) error {
 	// 1. 验证组是否存在
 	group, err := bs.GroupService.GetGroupByName(ctx, groupName)
 	if err != nil {
 		return fmt.Errorf("组不存在: %s", groupName)
 	}
 
-	// 2. 获取当前路径
-	currentPath := bs.CurrentScope.CurrentPath
+	// 2. 获取当前路径（按解析模式映射后的作用域路径）
+	currentPath := scope.ScopePath
 	if currentPath == "" {
 		return fmt.Errorf("当前不在任何项目路径中")
 	}
@@ -41,12 +49,13 @@ func validateGroupOperationPermission(ctx context.Context, bs *startup.Bootstrap
 // RegisterGroupTools 注册组管理工具
 func RegisterGroupTools(server *mcp.Server, bs *startup.Bootstrap) {
 	// group_add_path - 添加路径到组（增加权限验证）
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "group_add_path",
-		Description: `将当前路径添加到指定组。注意：只能操作当前路径，不能操作其他路径。如果当前路径已在其他组中，会先移除再加入新组。`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input GroupAddPathInput) (*mcp.CallToolResult, any, error) {
-		// 权限验证
-		if err := validateGroupOperationPermission(ctx, bs, input.GroupName); err != nil {
+		Description: `将当前路径添加到指定组（按作用域解析模式映射，如 git-root 模式下为仓库根目录）。注意：只能操作当前路径，不能操作其他路径。如果当前路径已在其他组中，会先移除再加入新组。`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input GroupAddPathInput) (*mcp.CallToolResult, *GroupAddPathOutput, error) {
+		// 权限验证（当前路径取自会话作用域）
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
+		if err := validateGroupOperationPermission(ctx, bs, scopeCtx, input.GroupName); err != nil {
 			return NewErrorResult(err.Error()), nil, nil
 		}
 
@@ -60,13 +69,13 @@ func RegisterGroupTools(server *mcp.Server, bs *startup.Bootstrap) {
 		pathToAdd := input.Path
 		if pathToAdd != "" {
 			// 如果用户指定了路径，验证是否与当前路径一致
-			currentPath := bs.CurrentScope.CurrentPath
+			currentPath := scopeCtx.ScopePath
 			if pathToAdd != currentPath {
 				return NewErrorResult(fmt.Sprintf("只能操作当前路径。当前路径: %s，指定路径: %s", currentPath, pathToAdd)), nil, nil
 			}
 		} else {
 			// 使用当前路径
-			pathToAdd = bs.CurrentScope.CurrentPath
+			pathToAdd = scopeCtx.ScopePath
 			if pathToAdd == "" {
 				return NewErrorResult("当前不在任何项目路径中"), nil, nil
 			}
@@ -75,7 +84,8 @@ func RegisterGroupTools(server *mcp.Server, bs *startup.Bootstrap) {
 		// 检查是否已经在该组中
 		for _, existingPath := range group.Paths {
 			if existingPath.GetPath() == pathToAdd {
-				return NewTextResult(fmt.Sprintf("当前路径已在组 '%s' 中", input.GroupName)), nil, nil
+				return NewTextResult(fmt.Sprintf("当前路径已在组 '%s' 中", input.GroupName)),
+					&GroupAddPathOutput{GroupName: input.GroupName, Path: pathToAdd}, nil
 			}
 		}
 
@@ -84,6 +94,7 @@ func RegisterGroupTools(server *mcp.Server, bs *startup.Bootstrap) {
 			return NewErrorResult(err.Error()), nil, nil
 		}
 
-		return NewTextResult(fmt.Sprintf("已将当前路径 '%s' 添加到组 '%s'", pathToAdd, input.GroupName)), nil, nil
+		return NewTextResult(fmt.Sprintf("已将当前路径 '%s' 添加到组 '%s'", pathToAdd, input.GroupName)),
+			&GroupAddPathOutput{GroupName: input.GroupName, Path: pathToAdd, Added: true}, nil
 	})
 }
@@ -2,13 +2,48 @@ package tools
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
 
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/XiaoLFeng/llm-memory/internal/models"
 	"github.com/XiaoLFeng/llm-memory/pkg/types"
 	"github.com/XiaoLFeng/llm-memory/startup"
 )
 
+// outputSchemaOptions 推导输出 schema 的选项
+// jsonschema 对内置类型（time.Time）直接返回预设 schema，不会为 *time.Time 加上 null，
+// 截止时间、完成时间等可空字段会因此校验失败，所以把时间统一声明为可为 null 的 date-time 字符串
+var outputSchemaOptions = &jsonschema.ForOptions{
+	TypeSchemas: map[reflect.Type]*jsonschema.Schema{
+		reflect.TypeFor[time.Time](): {Types: []string{"null", "string"}, Format: "date-time"},
+	},
+}
+
+// addTool 注册带结构化输出的工具
+// 嘿嘿~ 输出 schema 由 Out 推导，成功时同时返回文本和结构化内容！✨
+// 处理函数不提供文本结果（nil）时，SDK 把结构化内容序列化为 JSON 作为文本块（批量待办工具即如此）；
+// 处理函数返回 nil 输出（错误结果）时不附带结构化内容，
+// 否则 SDK 会用 Out 的零值填充，而零值中的 nil 切片无法通过 schema 校验
+func addTool[In, Out any](server *mcp.Server, tool *mcp.Tool, handler func(context.Context, *mcp.CallToolRequest, In) (*mcp.CallToolResult, *Out, error)) {
+	schema, err := jsonschema.For[Out](outputSchemaOptions)
+	if err != nil {
+		panic(fmt.Sprintf("工具 %s 的输出 schema 推导失败: %v", tool.Name, err))
+	}
+	tool.OutputSchema = schema
+	mcp.AddTool(server, tool, func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, any, error) {
+		result, output, err := handler(ctx, req, input)
+		if output == nil {
+			return result, nil, err
+		}
+		return result, output, err
+	})
+}
+
 // NewTextResult 创建文本结果
 func NewTextResult(text string) *mcp.CallToolResult {
 	return &mcp.CallToolResult{
@@ -28,20 +63,48 @@ func NewErrorResult(errMsg string) *mcp.CallToolResult {
 	}
 }
 
-// getScopeContext 动态获取当前作用域上下文
+// NewUpdateErrorResult 创建更新失败结果
+func NewUpdateErrorResult(err error, getTool string) *mcp.CallToolResult {
+	return NewErrorResult(updateErrorMessage(err, getTool))
+}
+
+// updateErrorMessage 更新失败的提示信息
+// 版本冲突时说明当前版本，并提示先用 getTool 重新读取、合并修改后再携带新版本号更新
+func updateErrorMessage(err error, getTool string) string {
+	var conflict *models.VersionConflictError
+	if errors.As(err, &conflict) {
+		return fmt.Sprintf("版本冲突: %s\n请先调用 %s 读取最新内容（当前 version=%d），在最新内容上合并你的修改后，携带 version=%d 重新更新。",
+			err.Error(), getTool, conflict.Actual, conflict.Actual)
+	}
+	return err.Error()
+}
+
+// expectedVersion 将工具输入的版本号转换为更新 DTO 中的期望版本（0 表示不校验）
+func expectedVersion(version int64) *int64 {
+	if version <= 0 {
+		return nil
+	}
+	return &version
+}
+
+// getScopeContext 动态获取会话的作用域上下文
 // 每次调用时实时解析，确保 GroupPathIDs 是最新的
-func getScopeContext(bs *startup.Bootstrap) *types.ScopeContext {
-	ctx := context.Background()
-	scope, err := bs.GroupService.GetCurrentScope(ctx)
+// 客户端提供了 roots 时按第一个文件根目录解析，否则按服务进程的工作目录解析
+func getScopeContext(ctx context.Context, bs *startup.Bootstrap, session *mcp.ServerSession) *types.ScopeContext {
+	var scope *types.ScopeContext
+	var err error
+	if root := sessionRootPath(ctx, bs, session); root != "" {
+		scope, err = bs.GroupService.ResolveScope(ctx, root)
+	} else {
+		scope, err = bs.GroupService.GetCurrentScope(ctx)
+	}
 	if err != nil {
-		// 降级到静态 CurrentScope
+		// 降级到启动时解析的 CurrentScope
 		if bs.CurrentScope != nil {
 			return bs.CurrentScope
 		}
 		return types.NewScopeContext("")
 	}
-	// 更新缓存
-	bs.CurrentScope = scope
 	return scope
 }
 
@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// connectToolSession 建立注册了所有工具的内存会话，客户端以 root 作为项目目录
+func connectToolSession(t *testing.T, root string) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0"}, nil)
+	RegisterMemoryTools(server, testBS)
+	RegisterPlanTools(server, testBS)
+	RegisterTodoTools(server, testBS)
+	RegisterGroupTools(server, testBS)
+	RegisterTrashTools(server, testBS)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0"}, nil)
+	client.AddRoots(fileRoot(root))
+	st, ct := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, st, nil); err != nil {
+		t.Fatalf("服务端连接失败: %v", err)
+	}
+	cs, err := client.Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatalf("客户端连接失败: %v", err)
+	}
+	t.Cleanup(func() { _ = cs.Close() })
+	return cs
+}
+
+// TestToolOutputSchemas 每个工具都声明输出 schema，成功时返回文本和符合 schema 的结构化内容，
+// 失败时只返回错误文本
+func TestToolOutputSchemas(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	cs := connectToolSession(t, root)
+	if _, err := testBS.GroupService.CreateGroup(ctx, "schema-team", ""); err != nil {
+		t.Fatalf("创建组失败: %v", err)
+	}
+
+	listed, err := cs.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("列出工具失败: %v", err)
+	}
+	schemas := make(map[string]*jsonschema.Resolved, len(listed.Tools))
+	for _, tool := range listed.Tools {
+		raw, err := json.Marshal(tool.OutputSchema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var schema jsonschema.Schema
+		if err := json.Unmarshal(raw, &schema); err != nil || schema.Type != "object" {
+			t.Fatalf("工具 %s 应声明 object 类型的输出 schema: %s（%v）", tool.Name, raw, err)
+		}
+		resolved, err := schema.Resolve(nil)
+		if err != nil {
+			t.Fatalf("工具 %s 的输出 schema 无效: %v", tool.Name, err)
+		}
+		schemas[tool.Name] = resolved
+	}
+
+	// 按顺序调用，后面的调用依赖前面创建的数据
+	calls := []struct {
+		tool      string
+		args      map[string]any
+		wantError string // 非空表示期望失败，错误文本应包含它
+	}{
+		{tool: "memory_create", args: map[string]any{"code": "schema-mem", "title": "结构化输出", "content": "工具同时返回文本和结构化内容", "tags": []string{"mcp"}}},
+		{tool: "memory_list", args: map[string]any{}},
+		{tool: "memory_get", args: map[string]any{"code": "schema-mem"}},
+		{tool: "memory_search", args: map[string]any{"keyword": "结构化"}},
+		{tool: "memory_semantic_search", args: map[string]any{"query": "结构化输出"}},
+		{tool: "memory_update", args: map[string]any{"code": "schema-mem", "priority": 3, "version": 1}},
+		{tool: "memory_update", args: map[string]any{"code": "schema-mem", "priority": 2, "version": 1}, wantError: "版本冲突"},
+		{tool: "memory_history", args: map[string]any{"code": "schema-mem"}},
+		{tool: "memory_history", args: map[string]any{"code": "schema-mem", "revision": 1}},
+		{tool: "memory_get", args: map[string]any{"code": "schema-missing"}, wantError: "not found"},
+		{tool: "plan_create", args: map[string]any{"code": "schema-plan", "title": "计划", "description": "描述", "content": "内容"}},
+		{tool: "plan_list", args: map[string]any{}},
+		{tool: "plan_get", args: map[string]any{"code": "schema-plan"}},
+		{tool: "plan_update", args: map[string]any{"code": "schema-plan", "progress": 10}},
+		{tool: "todo_batch_create", args: map[string]any{"items": []map[string]any{
+			{"code": "schema-todo-a", "plan_code": "schema-plan", "title": "待办 A"},
+			{"code": "schema-todo-b", "plan_code": "schema-plan", "title": "待办 B"},
+			{"code": "schema-todo-c", "plan_code": "schema-missing", "title": "计划不存在"},
+		}}},
+		{tool: "todo_list", args: map[string]any{}},
+		{tool: "todo_batch_start", args: map[string]any{"codes": []string{"schema-todo-a"}}},
+		{tool: "todo_batch_update", args: map[string]any{"items": []map[string]any{{"code": "schema-todo-a", "priority": 3}}}},
+		{tool: "todo_batch_complete", args: map[string]any{"codes": []string{"schema-todo-a", "schema-missing"}}},
+		{tool: "todo_batch_cancel", args: map[string]any{"codes": []string{"schema-todo-b"}}},
+		{tool: "todo_final", args: map[string]any{}},
+		{tool: "memory_delete", args: map[string]any{"code": "schema-mem"}},
+		{tool: "trash_list", args: map[string]any{}},
+		{tool: "trash_restore", args: map[string]any{"type": "memory", "code": "schema-mem"}},
+		{tool: "trash_purge", args: map[string]any{}},
+		{tool: "group_add_path", args: map[string]any{"group_name": "schema-team", "path": root}},
+	}
+	called := make(map[string]bool)
+	for i, call := range calls {
+		result, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: call.tool, Arguments: call.args})
+		if err != nil {
+			t.Fatalf("#%d %s 调用失败: %v", i, call.tool, err)
+		}
+		if len(result.Content) == 0 {
+			t.Fatalf("#%d %s 应返回文本内容", i, call.tool)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+
+		if call.wantError != "" {
+			if !result.IsError || !strings.Contains(text, call.wantError) {
+				t.Fatalf("#%d %s 应失败并提示 %q: %s", i, call.tool, call.wantError, text)
+			}
+			if result.StructuredContent != nil {
+				t.Fatalf("#%d %s 失败时不应返回结构化内容: %v", i, call.tool, result.StructuredContent)
+			}
+			continue
+		}
+		if result.IsError {
+			t.Fatalf("#%d %s 返回错误: %s", i, call.tool, text)
+		}
+		if result.StructuredContent == nil {
+			t.Fatalf("#%d %s 应返回结构化内容", i, call.tool)
+		}
+		if err := schemas[call.tool].Validate(result.StructuredContent); err != nil {
+			t.Fatalf("#%d %s 的结构化内容不符合输出 schema: %v\n%v", i, call.tool, err, result.StructuredContent)
+		}
+		called[call.tool] = true
+	}
+	for name := range schemas {
+		if !called[name] {
+			t.Errorf("工具 %s 没有被覆盖", name)
+		}
+	}
+}
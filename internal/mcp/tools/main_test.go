@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/XiaoLFeng/llm-memory/startup"
+)
+
+// testBS 测试共用的应用实例（数据库连接是进程内单例，整个测试包只能初始化一次）
+var testBS *startup.Bootstrap
+
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+// runTests 在临时目录中初始化应用后运行测试
+func runTests(m *testing.M) int {
+	dir, err := os.MkdirTemp("", "llm-memory-tools-")
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	testBS = startup.New(
+		startup.WithConfigPath(filepath.Join(dir, "config.json")),
+		startup.WithDBPath(filepath.Join(dir, "data.db")),
+		startup.WithSignalHandler(false),
+	)
+	if err := testBS.Initialize(context.Background()); err != nil {
+		fmt.Printf("初始化应用失败: %v\n", err)
+		return 1
+	}
+	defer testBS.Shutdown()
+	return m.Run()
+}
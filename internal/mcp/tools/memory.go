@@ -4,10 +4,14 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/XiaoLFeng/llm-memory/internal/service"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
 	"github.com/XiaoLFeng/llm-memory/startup"
 )
 
@@ -34,10 +38,17 @@ type MemoryDeleteInput struct {
 
 // MemorySearchInput memory_search 工具输入
 type MemorySearchInput struct {
-	Keyword string `json:"keyword" jsonschema:"搜索关键词，在标题和内容中模糊匹配"`
+	Keyword string `json:"keyword" jsonschema:"搜索关键词，多个词用空格分隔（需全部命中）"`
 	Scope   string `json:"scope,omitempty" jsonschema:"作用域过滤(personal/group/global/all)，默认all显示全部"`
 }
 
+// MemorySemanticSearchInput memory_semantic_search 工具输入
+type MemorySemanticSearchInput struct {
+	Query string `json:"query" jsonschema:"自然语言查询，按语义相似度匹配（不要求关键词完全一致）"`
+	Scope string `json:"scope,omitempty" jsonschema:"作用域过滤(personal/group/global/all)，默认all显示全部"`
+	TopK  int    `json:"top_k,omitempty" jsonschema:"返回的最大数量，默认5"`
+}
+
 // MemoryGetInput memory_get 工具输入
 type MemoryGetInput struct {
 	Code string `json:"code" jsonschema:"要获取的记忆code"`
@@ -51,42 +62,109 @@ type MemoryUpdateInput struct {
 	Category string   `json:"category,omitempty" jsonschema:"新分类（可选）"`
 	Tags     []string `json:"tags,omitempty" jsonschema:"新标签列表（可选）"`
 	Priority int      `json:"priority,omitempty" jsonschema:"新优先级 1-4（可选）"`
+	Version  int64    `json:"version,omitempty" jsonschema:"读取时的版本号（memory_get 返回，可选）；提供后若记忆已被他人修改则拒绝更新"`
+}
+
+// MemoryHistoryInput memory_history 工具输入
+type MemoryHistoryInput struct {
+	Code     string `json:"code" jsonschema:"记忆code"`
+	Revision int    `json:"revision,omitempty" jsonschema:"查看指定修订的完整内容（可选，省略则列出全部修订）"`
+}
+
+// MemoryListOutput memory_list 工具输出
+type MemoryListOutput struct {
+	Memories []dto.MemoryListDTO `json:"memories" jsonschema:"可见的记忆列表"`
+}
+
+// MemoryDeleteOutput memory_delete 工具输出
+type MemoryDeleteOutput struct {
+	Code string `json:"code" jsonschema:"已移入回收站的记忆code"`
+}
+
+// MemorySearchHit memory_search 命中结果
+type MemorySearchHit struct {
+	Memory  dto.MemoryListDTO `json:"memory"`
+	Snippet string            `json:"snippet,omitempty" jsonschema:"命中位置附近的摘要，命中词用【】标出"`
+}
+
+// MemorySearchOutput memory_search 工具输出
+type MemorySearchOutput struct {
+	Hits []MemorySearchHit `json:"hits" jsonschema:"按相关度排序的命中结果"`
+}
+
+// MemorySemanticHit memory_semantic_search 命中结果
+type MemorySemanticHit struct {
+	Memory     dto.MemoryListDTO `json:"memory"`
+	Similarity float64           `json:"similarity" jsonschema:"余弦相似度，越大越相关"`
+}
+
+// MemorySemanticSearchOutput memory_semantic_search 工具输出
+type MemorySemanticSearchOutput struct {
+	Hits []MemorySemanticHit `json:"hits" jsonschema:"按相似度排序的命中结果"`
+}
+
+// MemoryRevisionItem 修订历史条目
+type MemoryRevisionItem struct {
+	Revision  int       `json:"revision" jsonschema:"修订号，修订 N 为第 N 次修改之前的内容"`
+	Title     string    `json:"title" jsonschema:"修改前的标题"`
+	Action    string    `json:"action" jsonschema:"变更类型 update/revert"`
+	Source    string    `json:"source" jsonschema:"变更来源 cli/mcp/tui"`
+	CreatedAt time.Time `json:"created_at" jsonschema:"修改时间"`
+}
+
+// MemoryHistoryOutput memory_history 工具输出
+type MemoryHistoryOutput struct {
+	Code      string                 `json:"code"`
+	Title     string                 `json:"title,omitempty" jsonschema:"记忆当前标题（列出修订时返回）"`
+	Revisions []MemoryRevisionItem   `json:"revisions,omitempty" jsonschema:"修订列表（未指定 revision 时返回）"`
+	Snapshot  *dto.MemorySnapshotDTO `json:"snapshot,omitempty" jsonschema:"指定修订的完整内容（指定 revision 时返回）"`
+}
+
+// toMemoryListItem 转换为带作用域的记忆列表项
+func toMemoryListItem(m *entity.Memory, scopeCtx *types.ScopeContext) dto.MemoryListDTO {
+	item := service.ToMemoryListDTO(m)
+	item.Scope = string(types.GetScopeForDisplayWithGlobal(m.Global, m.PathID, scopeCtx))
+	return *item
 }
 
 // RegisterMemoryTools 注册记忆管理工具
 func RegisterMemoryTools(server *mcp.Server, bs *startup.Bootstrap) {
 	// memory_list - 列出所有记忆
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name: "memory_list",
 		Description: `列出可见记忆。scope参数说明（安全隔离）：
   - personal: 仅当前路径的项目数据
   - group: 仅当前小组的数据（需已加入小组）
   - global: 仅全局可见数据
   - all/省略: 全局 + 当前路径相关（默认，权限隔离）`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input MemoryListInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input MemoryListInput) (*mcp.CallToolResult, *MemoryListOutput, error) {
 		// 构建作用域上下文
-		scopeCtx := getScopeContext(bs)
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
 
 		memories, err := bs.MemoryService.ListMemoriesByScope(ctx, input.Scope, scopeCtx)
 		if err != nil {
 			return NewErrorResult(err.Error()), nil, nil
 		}
+		output := &MemoryListOutput{Memories: make([]dto.MemoryListDTO, 0, len(memories))}
+		for i := range memories {
+			output.Memories = append(output.Memories, toMemoryListItem(&memories[i], scopeCtx))
+		}
 		if len(memories) == 0 {
-			return NewTextResult("暂无记忆"), nil, nil
+			return NewTextResult("暂无记忆"), output, nil
 		}
 		result := "记忆列表:\n"
 		for _, m := range memories {
-			scopeTag := getScopeTagWithGlobal(m.Global, m.PathID, bs.CurrentScope)
+			scopeTag := getScopeTagWithGlobal(m.Global, m.PathID, scopeCtx)
 			result += fmt.Sprintf("- [%s] %s (分类: %s) %s\n", m.Code, m.Title, m.Category, scopeTag)
 		}
-		return NewTextResult(result), nil, nil
+		return NewTextResult(result), output, nil
 	})
 
 	// memory_create - 创建新记忆
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "memory_create",
 		Description: `创建记忆条目，适合长期事实、偏好、上下文片段。必填: title、content。可选: category、tags、global。global=true 存入全局；省略/false 存当前路径(项目，若在组内则组可见)。短任务请用 todo_create，需要进度跟踪的多步骤目标请用 plan_create。scope 参数仅用于列表筛选。`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input MemoryCreateInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input MemoryCreateInput) (*mcp.CallToolResult, *dto.MemoryResponseDTO, error) {
 		// 构建创建 DTO
 		createDTO := &dto.MemoryCreateDTO{
 			Code:     input.Code,
@@ -99,90 +177,131 @@ func RegisterMemoryTools(server *mcp.Server, bs *startup.Bootstrap) {
 		}
 
 		// 构建作用域上下文
-		scopeCtx := getScopeContext(bs)
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
 
 		memory, err := bs.MemoryService.CreateMemory(ctx, createDTO, scopeCtx)
 		if err != nil {
 			return NewErrorResult(err.Error()), nil, nil
 		}
-		scopeTag := getScopeTagWithGlobal(memory.Global, memory.PathID, bs.CurrentScope)
-		return NewTextResult(fmt.Sprintf("记忆创建成功! Code: %s, 标题: %s %s", memory.Code, memory.Title, scopeTag)), nil, nil
+		scopeTag := getScopeTagWithGlobal(memory.Global, memory.PathID, scopeCtx)
+		return NewTextResult(fmt.Sprintf("记忆创建成功! Code: %s, 标题: %s %s", memory.Code, memory.Title, scopeTag)),
+			service.ToMemoryResponseDTO(memory, scopeCtx), nil
 	})
 
 	// memory_delete - 删除记忆
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "memory_delete",
-		Description: `删除指定code的记忆，不可恢复。`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input MemoryDeleteInput) (*mcp.CallToolResult, any, error) {
+		Description: `删除指定code的记忆，移入回收站，可用 trash_restore 恢复。`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input MemoryDeleteInput) (*mcp.CallToolResult, *MemoryDeleteOutput, error) {
 		if err := bs.MemoryService.DeleteMemory(ctx, input.Code); err != nil {
 			return NewErrorResult(err.Error()), nil, nil
 		}
-		return NewTextResult(fmt.Sprintf("记忆 %s 已删除", input.Code)), nil, nil
+		return NewTextResult(fmt.Sprintf("记忆 %s 已删除", input.Code)), &MemoryDeleteOutput{Code: input.Code}, nil
 	})
 
 	// memory_search - 搜索记忆
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "memory_search",
-		Description: `搜索记忆（标题与内容模糊匹配 keyword）。scope: personal/group/global/all；默认不填=全部（全局+项目+小组）。`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input MemorySearchInput) (*mcp.CallToolResult, any, error) {
+		Description: `全文检索记忆（标题/内容/分类/标签），按相关度(bm25)排序并返回【高亮】摘要。keyword 可含多个词（空格分隔，需全部命中，支持前缀匹配）。scope: personal/group/global/all；默认不填=全部（全局+项目+小组）。`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input MemorySearchInput) (*mcp.CallToolResult, *MemorySearchOutput, error) {
 		// 构建作用域上下文
-		scopeCtx := getScopeContext(bs)
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
 
-		memories, err := bs.MemoryService.SearchMemoriesByScope(ctx, input.Keyword, input.Scope, scopeCtx)
+		hits, err := bs.MemoryService.SearchMemoriesWithSnippets(ctx, input.Keyword, input.Scope, scopeCtx)
 		if err != nil {
 			return NewErrorResult(err.Error()), nil, nil
 		}
-		if len(memories) == 0 {
-			return NewTextResult("未找到匹配的记忆"), nil, nil
+		output := &MemorySearchOutput{Hits: make([]MemorySearchHit, 0, len(hits))}
+		for i := range hits {
+			output.Hits = append(output.Hits, MemorySearchHit{
+				Memory:  toMemoryListItem(&hits[i].Memory, scopeCtx),
+				Snippet: hits[i].Snippet,
+			})
 		}
-		result := fmt.Sprintf("搜索结果 (%d 条):\n", len(memories))
-		for _, m := range memories {
-			scopeTag := getScopeTagWithGlobal(m.Global, m.PathID, bs.CurrentScope)
+		if len(hits) == 0 {
+			return NewTextResult("未找到匹配的记忆"), output, nil
+		}
+		result := fmt.Sprintf("搜索结果 (%d 条，按相关度排序):\n", len(hits))
+		for _, h := range hits {
+			m := h.Memory
+			scopeTag := getScopeTagWithGlobal(m.Global, m.PathID, scopeCtx)
 			result += fmt.Sprintf("- [%s] %s %s\n", m.Code, m.Title, scopeTag)
+			if h.Snippet != "" {
+				result += fmt.Sprintf("  %s\n", h.Snippet)
+			}
 		}
-		return NewTextResult(result), nil, nil
+		return NewTextResult(result), output, nil
+	})
+
+	// memory_semantic_search - 语义搜索记忆
+	addTool(server, &mcp.Tool{
+		Name:        "memory_semantic_search",
+		Description: `语义（向量）搜索记忆：按与 query 的余弦相似度返回最相关的 top_k 条，适合措辞与原文不同的查询。scope: personal/group/global/all；默认不填=全部（全局+项目+小组）。`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input MemorySemanticSearchInput) (*mcp.CallToolResult, *MemorySemanticSearchOutput, error) {
+		// 构建作用域上下文
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
+
+		hits, err := bs.MemoryService.SemanticSearchMemories(ctx, input.Query, input.Scope, scopeCtx, input.TopK)
+		if err != nil {
+			return NewErrorResult(err.Error()), nil, nil
+		}
+		output := &MemorySemanticSearchOutput{Hits: make([]MemorySemanticHit, 0, len(hits))}
+		for i := range hits {
+			output.Hits = append(output.Hits, MemorySemanticHit{
+				Memory:     toMemoryListItem(&hits[i].Memory, scopeCtx),
+				Similarity: hits[i].Similarity,
+			})
+		}
+		if len(hits) == 0 {
+			return NewTextResult("未找到匹配的记忆"), output, nil
+		}
+		result := fmt.Sprintf("语义搜索结果 (%d 条，按相似度排序):\n", len(hits))
+		for _, h := range hits {
+			m := h.Memory
+			scopeTag := getScopeTagWithGlobal(m.Global, m.PathID, scopeCtx)
+			result += fmt.Sprintf("- [%s] %s %s (相似度 %.3f)\n", m.Code, m.Title, scopeTag, h.Similarity)
+		}
+		return NewTextResult(result), output, nil
 	})
 
 	// memory_get - 获取记忆详情
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "memory_get",
 		Description: `获取指定code记忆的完整详情，包括内容、分类、标签等。`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input MemoryGetInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input MemoryGetInput) (*mcp.CallToolResult, *dto.MemoryResponseDTO, error) {
 		memory, err := bs.MemoryService.GetMemory(ctx, input.Code)
 		if err != nil {
 			return NewErrorResult(err.Error()), nil, nil
 		}
 
-		// 转换标签
-		tags := make([]string, 0, len(memory.Tags))
-		for _, t := range memory.Tags {
-			tags = append(tags, t.Tag)
-		}
-
-		scopeTag := getScopeTagWithGlobal(memory.Global, memory.PathID, bs.CurrentScope)
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
+		scopeTag := getScopeTagWithGlobal(memory.Global, memory.PathID, scopeCtx)
+		output := service.ToMemoryResponseDTO(memory, scopeCtx)
 		var sb strings.Builder
 		_, _ = fmt.Fprintf(&sb, "记忆详情:\n")
 		_, _ = fmt.Fprintf(&sb, "Code: %s\n", memory.Code)
 		_, _ = fmt.Fprintf(&sb, "标题: %s\n", memory.Title)
 		_, _ = fmt.Fprintf(&sb, "分类: %s\n", memory.Category)
 		_, _ = fmt.Fprintf(&sb, "优先级: %d\n", memory.Priority)
-		_, _ = fmt.Fprintf(&sb, "标签: %v\n", tags)
+		_, _ = fmt.Fprintf(&sb, "标签: %s\n", strings.Join(output.Tags, ", "))
 		_, _ = fmt.Fprintf(&sb, "作用域: %s\n", scopeTag)
+		_, _ = fmt.Fprintf(&sb, "版本: %d\n", memory.Version)
 		_, _ = fmt.Fprintf(&sb, "创建时间: %s\n", memory.CreatedAt.Format("2006-01-02 15:04:05"))
 		_, _ = fmt.Fprintf(&sb, "更新时间: %s\n", memory.UpdatedAt.Format("2006-01-02 15:04:05"))
 		_, _ = fmt.Fprintf(&sb, "\n内容:\n%s", memory.Content)
 		result := sb.String()
-		return NewTextResult(result), nil, nil
+		return NewTextResult(result), output, nil
 	})
 
 	// memory_update - 更新记忆
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "memory_update",
-		Description: `更新记忆，只更新提供的字段（title/content/category/tags/priority1-4）；至少提供一个字段，否则返回错误。`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input MemoryUpdateInput) (*mcp.CallToolResult, any, error) {
+		Description: `更新记忆，只更新提供的字段（title/content/category/tags/priority1-4）；至少提供一个字段，否则返回错误。建议传入 memory_get 返回的 version，记忆在读取后被他人修改时会返回版本冲突而不是覆盖。`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input MemoryUpdateInput) (*mcp.CallToolResult, *dto.MemoryResponseDTO, error) {
 		// 构建更新 DTO
 		updateDTO := &dto.MemoryUpdateDTO{
-			Code: input.Code,
+			Code:    input.Code,
+			Version: expectedVersion(input.Version),
 		}
 
 		// 只设置提供了的字段
@@ -209,10 +328,65 @@ func RegisterMemoryTools(server *mcp.Server, bs *startup.Bootstrap) {
 
 		// 执行更新
 		if err := bs.MemoryService.UpdateMemory(ctx, updateDTO); err != nil {
+			return NewUpdateErrorResult(err, "memory_get"), nil, nil
+		}
+
+		// 返回更新后的记忆（含新版本号）
+		memory, err := bs.MemoryService.GetMemory(ctx, input.Code)
+		if err != nil {
 			return NewErrorResult(err.Error()), nil, nil
 		}
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
+		return NewTextResult(fmt.Sprintf("记忆 %s 更新成功（版本 %d）", input.Code, memory.Version)),
+			service.ToMemoryResponseDTO(memory, scopeCtx), nil
+	})
 
-		return NewTextResult(fmt.Sprintf("记忆 %s 更新成功", input.Code)), nil, nil
+	// memory_history - 查看记忆修订历史
+	addTool(server, &mcp.Tool{
+		Name:        "memory_history",
+		Description: `查看记忆的修订历史。每次更新前的旧值都会保存为一条修订（修订 N = 第 N 次修改之前的内容），包含修改时间和来源(cli/mcp/tui)。传 revision 可查看该修订的完整内容，用于找回被覆盖的信息。`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input MemoryHistoryInput) (*mcp.CallToolResult, *MemoryHistoryOutput, error) {
+		if input.Revision > 0 {
+			snapshot, err := bs.MemoryService.GetMemorySnapshot(ctx, input.Code, input.Revision)
+			if err != nil {
+				return NewErrorResult(err.Error()), nil, nil
+			}
+			result := fmt.Sprintf("修订 %d（修改前的内容）:\n标题: %s\n分类: %s\n优先级: %d\n标签: %s\n内容:\n%s",
+				snapshot.Revision, snapshot.Title, snapshot.Category, snapshot.Priority,
+				strings.Join(snapshot.Tags, ", "), snapshot.Content)
+			if snapshot.Tags == nil {
+				snapshot.Tags = []string{}
+			}
+			return NewTextResult(result), &MemoryHistoryOutput{Code: input.Code, Snapshot: snapshot}, nil
+		}
+
+		memory, revisions, err := bs.MemoryService.ListMemoryRevisions(ctx, input.Code)
+		if err != nil {
+			return NewErrorResult(err.Error()), nil, nil
+		}
+		output := &MemoryHistoryOutput{
+			Code:      memory.Code,
+			Title:     memory.Title,
+			Revisions: make([]MemoryRevisionItem, 0, len(revisions)),
+		}
+		for _, rev := range revisions {
+			output.Revisions = append(output.Revisions, MemoryRevisionItem{
+				Revision:  rev.Revision,
+				Title:     rev.Title,
+				Action:    rev.Action,
+				Source:    rev.Source,
+				CreatedAt: rev.CreatedAt,
+			})
+		}
+		if len(revisions) == 0 {
+			return NewTextResult(fmt.Sprintf("记忆 [%s] 暂无修订历史", memory.Code)), output, nil
+		}
+		result := fmt.Sprintf("记忆 [%s] %s 的修订历史 (%d 条):\n", memory.Code, memory.Title, len(revisions))
+		for _, rev := range revisions {
+			result += fmt.Sprintf("- r%d %s [%s/%s] 修改前标题: %s\n",
+				rev.Revision, rev.CreatedAt.Format("2006-01-02 15:04:05"), rev.Action, rev.Source, rev.Title)
+		}
+		return NewTextResult(result), output, nil
 	})
 }
 
@@ -9,6 +9,8 @@ import (
 
 	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
 	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/XiaoLFeng/llm-memory/internal/service"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
 	"github.com/XiaoLFeng/llm-memory/startup"
 )
 
@@ -38,42 +40,54 @@ type PlanUpdateInput struct {
 	Description *string `json:"description,omitempty" jsonschema:"新描述（可选）"`
 	Content     *string `json:"content,omitempty" jsonschema:"新内容（可选），支持 Markdown 格式"`
 	Progress    *int    `json:"progress,omitempty" jsonschema:"完成进度 0-100（可选），系统自动调整状态：0=待开始，1-99=进行中，100=已完成"`
+	Version     int64   `json:"version,omitempty" jsonschema:"读取时的版本号（plan_get 返回，可选）；提供后若计划已被他人修改则拒绝更新"`
+}
+
+// PlanListOutput plan_list 工具输出
+type PlanListOutput struct {
+	Plans []dto.PlanListDTO `json:"plans" jsonschema:"可见的计划列表"`
 }
 
 // RegisterPlanTools 注册计划管理工具
 func RegisterPlanTools(server *mcp.Server, bs *startup.Bootstrap) {
 	// plan_list - 列出所有计划
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name: "plan_list",
 		Description: `列出所有计划及进度状态。scope参数说明（安全隔离）：
   - personal: 仅当前路径的项目数据
   - group: 仅当前小组的数据（需已加入小组）
   - all/省略: 当前路径 + 小组数据（默认，权限隔离）`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input PlanListInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input PlanListInput) (*mcp.CallToolResult, *PlanListOutput, error) {
 		// 构建作用域上下文
-		scopeCtx := getScopeContext(bs)
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
 
 		plans, err := bs.PlanService.ListPlansByScope(ctx, input.Scope, scopeCtx)
 		if err != nil {
 			return NewErrorResult(err.Error()), nil, nil
 		}
+		output := &PlanListOutput{Plans: make([]dto.PlanListDTO, 0, len(plans))}
+		for i := range plans {
+			item := service.ToPlanListDTO(&plans[i])
+			item.Scope = string(types.GetScopeForDisplayNoGlobal(plans[i].PathID, scopeCtx))
+			output.Plans = append(output.Plans, *item)
+		}
 		if len(plans) == 0 {
-			return NewTextResult("暂无计划"), nil, nil
+			return NewTextResult("暂无计划"), output, nil
 		}
 		result := "计划列表:\n"
 		for _, p := range plans {
 			status := getPlanStatusText(p.Status)
-			scopeTag := getScopeTagWithContext(p.PathID, bs.CurrentScope)
+			scopeTag := getScopeTagWithContext(p.PathID, scopeCtx)
 			result += fmt.Sprintf("- [%s] %s (%s, 进度: %d%%) %s\n", p.Code, p.Title, status, p.Progress, scopeTag)
 		}
-		return NewTextResult(result), nil, nil
+		return NewTextResult(result), output, nil
 	})
 
 	// plan_create - 创建新计划
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "plan_create",
 		Description: `创建计划，用于"需要跟踪进度的多步骤目标"。必填: title、description、content(Markdown)。短动作请用 todo_create；长期事实请用 memory_create。scope 参数仅用于列表筛选。`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input PlanCreateInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input PlanCreateInput) (*mcp.CallToolResult, *dto.PlanResponseDTO, error) {
 		// 构建创建 DTO
 		createDTO := &dto.PlanCreateDTO{
 			Code:        input.Code,
@@ -83,27 +97,29 @@ func RegisterPlanTools(server *mcp.Server, bs *startup.Bootstrap) {
 		}
 
 		// 构建作用域上下文
-		scopeCtx := getScopeContext(bs)
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
 
 		plan, err := bs.PlanService.CreatePlan(ctx, createDTO, scopeCtx)
 		if err != nil {
 			return NewErrorResult(err.Error()), nil, nil
 		}
-		scopeTag := getScopeTagWithContext(plan.PathID, bs.CurrentScope)
-		return NewTextResult(fmt.Sprintf("计划创建成功! Code: %s, 标题: %s %s", plan.Code, plan.Title, scopeTag)), nil, nil
+		scopeTag := getScopeTagWithContext(plan.PathID, scopeCtx)
+		return NewTextResult(fmt.Sprintf("计划创建成功! Code: %s, 标题: %s %s", plan.Code, plan.Title, scopeTag)),
+			service.ToPlanResponseDTO(plan, scopeCtx), nil
 	})
 
 	// plan_get - 获取计划详情
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "plan_get",
 		Description: `获取指定code计划的完整详情，包括标题、描述、内容、进度、子任务等。`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input PlanGetInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input PlanGetInput) (*mcp.CallToolResult, *dto.PlanResponseDTO, error) {
 		plan, err := bs.PlanService.GetPlan(ctx, input.Code)
 		if err != nil {
 			return NewErrorResult(err.Error()), nil, nil
 		}
 
-		scopeTag := getScopeTagWithContext(plan.PathID, bs.CurrentScope)
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
+		scopeTag := getScopeTagWithContext(plan.PathID, scopeCtx)
 
 		var sb strings.Builder
 		sb.WriteString("计划详情:\n")
@@ -112,6 +128,7 @@ func RegisterPlanTools(server *mcp.Server, bs *startup.Bootstrap) {
 		sb.WriteString(fmt.Sprintf("状态: %s\n", getPlanStatusText(plan.Status)))
 		sb.WriteString(fmt.Sprintf("进度: %d%%\n", plan.Progress))
 		sb.WriteString(fmt.Sprintf("作用域: %s\n", scopeTag))
+		sb.WriteString(fmt.Sprintf("版本: %d\n", plan.Version))
 		sb.WriteString(fmt.Sprintf("创建时间: %s\n", plan.CreatedAt.Format("2006-01-02 15:04:05")))
 		sb.WriteString(fmt.Sprintf("更新时间: %s\n", plan.UpdatedAt.Format("2006-01-02 15:04:05")))
 		sb.WriteString(fmt.Sprintf("\n描述:\n%s\n", plan.Description))
@@ -127,20 +144,26 @@ func RegisterPlanTools(server *mcp.Server, bs *startup.Bootstrap) {
 			}
 		}
 
-		return NewTextResult(sb.String()), nil, nil
+		return NewTextResult(sb.String()), service.ToPlanResponseDTO(plan, scopeCtx), nil
 	})
 
 	// plan_update - 更新计划
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "plan_update",
-		Description: `更新计划，只更新提供的字段（title/description/content/progress）；至少提供一个字段，否则返回错误。progress: 0=待开始，1-99=进行中，100=已完成（状态自动调整）。`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input PlanUpdateInput) (*mcp.CallToolResult, any, error) {
+		Description: `更新计划，只更新提供的字段（title/description/content/progress）；至少提供一个字段，否则返回错误。progress: 0=待开始，1-99=进行中，100=已完成（状态自动调整）。建议传入 plan_get 返回的 version，计划在读取后被他人修改时会返回版本冲突而不是覆盖。`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input PlanUpdateInput) (*mcp.CallToolResult, *dto.PlanResponseDTO, error) {
 		// 检查是否有更新（至少一个字段）
 		if input.Title == nil && input.Description == nil &&
 			input.Content == nil && input.Progress == nil {
 			return NewErrorResult("至少提供一个要更新的字段"), nil, nil
 		}
 
+		// 先记下计划 ID：进度更新到 100 后计划变为已完成，无法再按 code 查询
+		before, err := bs.PlanService.GetPlan(ctx, input.Code)
+		if err != nil {
+			return NewErrorResult(err.Error()), nil, nil
+		}
+
 		// 构建更新 DTO
 		updateDTO := &dto.PlanUpdateDTO{
 			Code:        input.Code,
@@ -148,11 +171,12 @@ func RegisterPlanTools(server *mcp.Server, bs *startup.Bootstrap) {
 			Description: input.Description,
 			Content:     input.Content,
 			Progress:    input.Progress,
+			Version:     expectedVersion(input.Version),
 		}
 
 		// 执行更新
 		if err := bs.PlanService.UpdatePlan(ctx, updateDTO); err != nil {
-			return NewErrorResult(err.Error()), nil, nil
+			return NewUpdateErrorResult(err, "plan_get"), nil, nil
 		}
 
 		// 构建响应消息
@@ -170,7 +194,14 @@ func RegisterPlanTools(server *mcp.Server, bs *startup.Bootstrap) {
 			parts = append(parts, fmt.Sprintf("进度(%d%%)", *input.Progress))
 		}
 
-		return NewTextResult(fmt.Sprintf("计划 %s 更新成功: %s", input.Code, strings.Join(parts, "、"))), nil, nil
+		// 返回更新后的计划（含新版本号）
+		plan, err := bs.PlanService.GetPlanByID(ctx, before.ID)
+		if err != nil {
+			return NewErrorResult(err.Error()), nil, nil
+		}
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
+		return NewTextResult(fmt.Sprintf("计划 %s 更新成功: %s（版本 %d）", input.Code, strings.Join(parts, "、"), plan.Version)),
+			service.ToPlanResponseDTO(plan, scopeCtx), nil
 	})
 }
 
@@ -0,0 +1,442 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/XiaoLFeng/llm-memory/internal/service"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
+	"github.com/XiaoLFeng/llm-memory/startup"
+)
+
+// 嘿嘿~ 这是 MCP 提示词！💬
+// 把每次会话都要重复粘贴的指令（加载项目记忆、查看进行中的计划、继续下一个待办……）
+// 做成服务端提示词，内容按会话作用域从实时数据生成：
+//   - resume_work      继续工作：进行中的计划、未完成的待办和高优先级记忆
+//   - plan_from_goal   把目标拆解为计划和待办
+//   - session_wrapup   会话收尾：记录新记忆、更新待办状态
+// 提示词参数（计划 code、记忆分类、优先级）支持补全~
+
+// 提示词名称
+const (
+	promptResumeWork    = "resume_work"
+	promptPlanFromGoal  = "plan_from_goal"
+	promptSessionWrapup = "session_wrapup"
+)
+
+// 提示词参数名称
+const (
+	promptArgPlan        = "plan"
+	promptArgMinPriority = "min_priority"
+	promptArgGoal        = "goal"
+	promptArgCategory    = "category"
+)
+
+// defaultPromptMinPriority resume_work 默认收录的记忆最低优先级（高）
+const defaultPromptMinPriority = entity.MemoryPriorityHigh
+
+// maxCompletionValues 补全结果的最大数量（MCP 规范上限）
+const maxCompletionValues = 100
+
+// RegisterPrompts 注册提示词
+func RegisterPrompts(server *mcp.Server, bs *startup.Bootstrap) {
+	server.AddPrompt(&mcp.Prompt{
+		Name:        promptResumeWork,
+		Title:       "继续工作",
+		Description: "汇总当前作用域进行中的计划、未完成的待办和高优先级记忆，并让助手继续下一个待办",
+		Arguments: []*mcp.PromptArgument{
+			{Name: promptArgPlan, Title: "计划", Description: "只继续指定 code 的计划（可选，省略则汇总所有进行中的计划）"},
+			{Name: promptArgMinPriority, Title: "记忆最低优先级", Description: "收录的记忆最低优先级 1-4（可选，默认3）"},
+		},
+	}, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return resumeWorkPrompt(ctx, bs, req)
+	})
+
+	server.AddPrompt(&mcp.Prompt{
+		Name:        promptPlanFromGoal,
+		Title:       "从目标创建计划",
+		Description: "给出把目标拆解为计划和待办的模板，并附上已有计划和相关记忆供参考",
+		Arguments: []*mcp.PromptArgument{
+			{Name: promptArgGoal, Title: "目标", Description: "要达成的目标", Required: true},
+			{Name: promptArgCategory, Title: "参考记忆分类", Description: "附上该分类下的记忆作为参考（可选）"},
+		},
+	}, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return planFromGoalPrompt(ctx, bs, req)
+	})
+
+	server.AddPrompt(&mcp.Prompt{
+		Name:        promptSessionWrapup,
+		Title:       "会话收尾",
+		Description: "列出未完成的待办，让助手记录本次会话的新记忆并更新待办状态",
+		Arguments: []*mcp.PromptArgument{
+			{Name: promptArgPlan, Title: "计划", Description: "只收尾指定 code 的计划（可选，省略则包含所有未完成的计划）"},
+			{Name: promptArgCategory, Title: "记忆分类", Description: "新记忆使用的分类（可选）"},
+		},
+	}, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return sessionWrapupPrompt(ctx, bs, req)
+	})
+}
+
+// resumeWorkPrompt 生成 resume_work 提示词
+func resumeWorkPrompt(ctx context.Context, bs *startup.Bootstrap, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := req.Params.Arguments
+	scopeCtx := getScopeContext(ctx, bs, req.Session)
+
+	minPriority := defaultPromptMinPriority
+	if v := strings.TrimSpace(args[promptArgMinPriority]); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p < entity.MemoryPriorityLow || p > entity.MemoryPriorityUrgent {
+			return nil, fmt.Errorf("min_priority 必须是 1-4 之间的整数: %s", v)
+		}
+		minPriority = p
+	}
+
+	plans, err := promptPlans(ctx, bs, scopeCtx, args[promptArgPlan], func(p *entity.Plan) bool {
+		return p.Status == entity.PlanStatusInProgress
+	})
+	if err != nil {
+		return nil, err
+	}
+	memories, err := bs.MemoryService.ListMemoriesByScope(ctx, "all", scopeCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("请继续之前的工作。以下是当前项目在 llm-memory 中的状态。\n\n")
+
+	_, _ = fmt.Fprintf(&sb, "## 进行中的计划（%d）\n\n", len(plans))
+	if len(plans) == 0 {
+		sb.WriteString("暂无进行中的计划\n\n")
+	}
+	for i := range plans {
+		writePromptPlan(&sb, &plans[i], scopeCtx)
+	}
+
+	// 优先级高的记忆排在前面
+	sort.SliceStable(memories, func(i, j int) bool {
+		return memories[i].Priority > memories[j].Priority
+	})
+
+	sb.WriteString("## 重要记忆\n\n")
+	count := 0
+	for _, m := range memories {
+		if m.IsArchived || m.Priority < minPriority {
+			continue
+		}
+		count++
+		scope := types.GetScopeForDisplayWithGlobal(m.Global, m.PathID, scopeCtx)
+		_, _ = fmt.Fprintf(&sb, "### %s `%s`（%s，优先级 %d，%s）\n\n%s\n\n", m.Title, m.Code, m.Category, m.Priority, scope, m.Content)
+	}
+	if count == 0 {
+		_, _ = fmt.Fprintf(&sb, "暂无优先级不低于 %d 的记忆\n\n", minPriority)
+	}
+
+	sb.WriteString(`## 接下来
+1. 先阅读上面的记忆，遵守其中的约定和偏好；需要更多背景时用 memory_search / memory_semantic_search 查找。
+2. 从进行中的计划里选择下一个待办（优先继续“进行中”的，其次按优先级选择“待处理”的），开始前用 todo_batch_start 标记为进行中。
+3. 完成后用 todo_batch_complete 标记完成；计划的所有待办完成后计划会自动完成。
+4. 如果没有进行中的计划，请先询问我接下来要做什么。
+`)
+
+	return &mcp.GetPromptResult{
+		Description: "继续工作",
+		Messages:    []*mcp.PromptMessage{userPromptMessage(sb.String())},
+	}, nil
+}
+
+// planFromGoalPrompt 生成 plan_from_goal 提示词
+func planFromGoalPrompt(ctx context.Context, bs *startup.Bootstrap, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := req.Params.Arguments
+	goal := strings.TrimSpace(args[promptArgGoal])
+	if goal == "" {
+		return nil, fmt.Errorf("缺少参数: %s", promptArgGoal)
+	}
+	scopeCtx := getScopeContext(ctx, bs, req.Session)
+
+	plans, err := bs.PlanService.ListPlansByScope(ctx, "all", scopeCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "请把下面的目标拆解为一个计划和若干待办，并保存到 llm-memory。\n\n## 目标\n\n%s\n\n", goal)
+
+	sb.WriteString(`## 步骤
+1. 先用 memory_search / memory_semantic_search 查找与目标相关的记忆（技术约定、历史决策、用户偏好），拆解时遵守它们。
+2. 调用 plan_create 创建计划：
+   - code: 简短的英文短横线标识，例如 ` + "`feat-login`" + `，不要与下方已有计划重复
+   - title: 一句话概括目标
+   - description: 目标、范围和完成标准
+   - content: Markdown 格式的方案，包括背景、拆解思路、风险和验证方式
+3. 调用 todo_batch_create 一次性创建待办，每个待办都填写 plan_code：
+   - 每个待办是一个可独立完成、可验证的步骤，按执行顺序排列
+   - priority: 1低 2中 3高 4紧急，阻塞后续步骤的待办用 3 或 4
+   - description 写清楚做什么以及怎样算完成
+4. 创建完成后，用列表向我汇报计划和待办，等我确认后再开始执行。
+
+`)
+
+	_, _ = fmt.Fprintf(&sb, "## 已有计划（%d）\n\n", len(plans))
+	if len(plans) == 0 {
+		sb.WriteString("暂无计划\n")
+	}
+	for _, p := range plans {
+		_, _ = fmt.Fprintf(&sb, "- `%s` %s（%s，进度 %d%%）\n", p.Code, p.Title, getPlanStatusText(p.Status), p.Progress)
+	}
+
+	if category := strings.TrimSpace(args[promptArgCategory]); category != "" {
+		memories, err := bs.MemoryService.ListMemoriesByScope(ctx, "all", scopeCtx)
+		if err != nil {
+			return nil, err
+		}
+		_, _ = fmt.Fprintf(&sb, "\n## 参考记忆（分类: %s）\n\n", category)
+		count := 0
+		for _, m := range memories {
+			if m.IsArchived || m.Category != category {
+				continue
+			}
+			count++
+			_, _ = fmt.Fprintf(&sb, "### %s `%s`\n\n%s\n\n", m.Title, m.Code, m.Content)
+		}
+		if count == 0 {
+			sb.WriteString("该分类下暂无记忆\n")
+		}
+	}
+
+	return &mcp.GetPromptResult{
+		Description: "从目标创建计划",
+		Messages:    []*mcp.PromptMessage{userPromptMessage(sb.String())},
+	}, nil
+}
+
+// sessionWrapupPrompt 生成 session_wrapup 提示词
+func sessionWrapupPrompt(ctx context.Context, bs *startup.Bootstrap, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := req.Params.Arguments
+	scopeCtx := getScopeContext(ctx, bs, req.Session)
+
+	plans, err := promptPlans(ctx, bs, scopeCtx, args[promptArgPlan], func(p *entity.Plan) bool {
+		return p.Status == entity.PlanStatusPending || p.Status == entity.PlanStatusInProgress
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	category := strings.TrimSpace(args[promptArgCategory])
+	categoryHint := "按内容选择合适的分类"
+	if category != "" {
+		categoryHint = fmt.Sprintf("category 使用“%s”", category)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("本次会话即将结束，请把成果同步到 llm-memory。\n\n")
+
+	_, _ = fmt.Fprintf(&sb, "## 未完成的计划（%d）\n\n", len(plans))
+	if len(plans) == 0 {
+		sb.WriteString("暂无未完成的计划\n\n")
+	}
+	for i := range plans {
+		writePromptPlan(&sb, &plans[i], scopeCtx)
+	}
+
+	_, _ = fmt.Fprintf(&sb, `## 请依次完成
+1. 更新待办状态：本次会话完成的用 todo_batch_complete，开始但未完成的用 todo_batch_start，不再需要的用 todo_batch_cancel；标题或描述需要调整的用 todo_batch_update。
+2. 发现了新的后续工作时，用 todo_batch_create 补充到对应计划；计划内容需要调整时用 plan_update。
+3. 记录新记忆：把本次会话中值得长期保留的信息（新的约定、踩过的坑、关键决策及原因、用户偏好）用 memory_create 保存，%s。
+   保存前先用 memory_search 检查是否已有相关记忆，已有的用 memory_update 更新而不是重复创建。
+4. 最后用简短的列表向我汇报更新了哪些待办、新增或更新了哪些记忆。
+`, categoryHint)
+
+	return &mcp.GetPromptResult{
+		Description: "会话收尾",
+		Messages:    []*mcp.PromptMessage{userPromptMessage(sb.String())},
+	}, nil
+}
+
+// promptPlans 获取提示词要包含的计划
+// 指定了 code 时只返回该计划（必须在作用域内），否则返回作用域内满足 match 的计划
+func promptPlans(ctx context.Context, bs *startup.Bootstrap, scopeCtx *types.ScopeContext, code string, match func(*entity.Plan) bool) ([]entity.Plan, error) {
+	if code = strings.TrimSpace(code); code != "" {
+		plan, err := bs.PlanService.GetPlan(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		if !service.IsPlanVisible(plan, scopeCtx) {
+			return nil, fmt.Errorf("计划不存在: %s", code)
+		}
+		return []entity.Plan{*plan}, nil
+	}
+
+	plans, err := bs.PlanService.ListPlansByScope(ctx, "all", scopeCtx)
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]entity.Plan, 0, len(plans))
+	for i := range plans {
+		if match(&plans[i]) {
+			matched = append(matched, plans[i])
+		}
+	}
+	return matched, nil
+}
+
+// writePromptPlan 写出计划概要和未完成的待办
+func writePromptPlan(sb *strings.Builder, p *entity.Plan, scopeCtx *types.ScopeContext) {
+	_, _ = fmt.Fprintf(sb, "### %s `%s`（%s，进度 %d%%，%s）\n\n", p.Title, p.Code, getPlanStatusText(p.Status), p.Progress, types.GetScopeForDisplayNoGlobal(p.PathID, scopeCtx))
+	if p.Description != "" {
+		_, _ = fmt.Fprintf(sb, "%s\n\n", p.Description)
+	}
+
+	pending := make([]entity.ToDo, 0, len(p.Todos))
+	for _, t := range p.Todos {
+		if t.Status == entity.ToDoStatusPending || t.Status == entity.ToDoStatusInProgress {
+			pending = append(pending, t)
+		}
+	}
+	if len(pending) == 0 {
+		sb.WriteString("没有未完成的待办\n\n")
+		return
+	}
+	sb.WriteString("未完成的待办：\n")
+	writeTodoChecklist(sb, pending)
+	sb.WriteString("\n")
+}
+
+// userPromptMessage 创建用户角色的提示词消息
+func userPromptMessage(text string) *mcp.PromptMessage {
+	return &mcp.PromptMessage{
+		Role:    "user",
+		Content: &mcp.TextContent{Text: text},
+	}
+}
+
+// Complete 补全提示词参数和资源模板变量
+// 嘿嘿~ 计划 code 和记忆分类来自会话作用域内的实时数据！✨
+func Complete(ctx context.Context, bs *startup.Bootstrap, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	params := req.Params
+	if params.Ref == nil {
+		return completionResult(nil, ""), nil
+	}
+
+	var candidates []string
+	var err error
+	switch params.Ref.Type {
+	case "ref/prompt":
+		candidates, err = promptArgumentCandidates(ctx, bs, req.Session, params.Ref.Name, params.Argument.Name)
+	case "ref/resource":
+		candidates, err = resourceArgumentCandidates(ctx, bs, req.Session, params.Ref.URI, params.Argument.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return completionResult(candidates, params.Argument.Value), nil
+}
+
+// promptArgumentCandidates 提示词参数的候选值
+func promptArgumentCandidates(ctx context.Context, bs *startup.Bootstrap, session *mcp.ServerSession, prompt, argument string) ([]string, error) {
+	switch argument {
+	case promptArgPlan:
+		if prompt == promptResumeWork {
+			return planCodeCandidates(ctx, bs, session, func(p *entity.Plan) bool {
+				return p.Status == entity.PlanStatusInProgress
+			})
+		}
+		return planCodeCandidates(ctx, bs, session, func(p *entity.Plan) bool {
+			return p.Status == entity.PlanStatusPending || p.Status == entity.PlanStatusInProgress
+		})
+	case promptArgCategory:
+		return memoryCategoryCandidates(ctx, bs, session)
+	case promptArgMinPriority:
+		return []string{"1", "2", "3", "4"}, nil
+	}
+	return nil, nil
+}
+
+// resourceArgumentCandidates 资源模板变量的候选值
+func resourceArgumentCandidates(ctx context.Context, bs *startup.Bootstrap, session *mcp.ServerSession, uri, argument string) ([]string, error) {
+	if argument != "code" {
+		return nil, nil
+	}
+	switch uri {
+	case memoryURITemplate:
+		scopeCtx := getScopeContext(ctx, bs, session)
+		memories, err := bs.MemoryService.ListMemoriesByScope(ctx, "all", scopeCtx)
+		if err != nil {
+			return nil, err
+		}
+		codes := make([]string, 0, len(memories))
+		for _, m := range memories {
+			codes = append(codes, m.Code)
+		}
+		return codes, nil
+	case planURITemplate, planTodosURITemplate:
+		return planCodeCandidates(ctx, bs, session, func(*entity.Plan) bool { return true })
+	}
+	return nil, nil
+}
+
+// planCodeCandidates 作用域内满足 match 的计划 code
+func planCodeCandidates(ctx context.Context, bs *startup.Bootstrap, session *mcp.ServerSession, match func(*entity.Plan) bool) ([]string, error) {
+	scopeCtx := getScopeContext(ctx, bs, session)
+	plans, err := bs.PlanService.ListPlansByScope(ctx, "all", scopeCtx)
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, len(plans))
+	for i := range plans {
+		if match(&plans[i]) {
+			codes = append(codes, plans[i].Code)
+		}
+	}
+	return codes, nil
+}
+
+// memoryCategoryCandidates 作用域内记忆使用过的分类
+func memoryCategoryCandidates(ctx context.Context, bs *startup.Bootstrap, session *mcp.ServerSession) ([]string, error) {
+	scopeCtx := getScopeContext(ctx, bs, session)
+	memories, err := bs.MemoryService.ListMemoriesByScope(ctx, "all", scopeCtx)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	categories := make([]string, 0)
+	for _, m := range memories {
+		if m.Category != "" && !seen[m.Category] {
+			seen[m.Category] = true
+			categories = append(categories, m.Category)
+		}
+	}
+	sort.Strings(categories)
+	return categories, nil
+}
+
+// completionResult 按已输入的前缀（不区分大小写）过滤候选值
+func completionResult(candidates []string, prefix string) *mcp.CompleteResult {
+	prefix = strings.ToLower(prefix)
+	values := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if strings.HasPrefix(strings.ToLower(c), prefix) {
+			values = append(values, c)
+		}
+	}
+
+	total := len(values)
+	hasMore := false
+	if total > maxCompletionValues {
+		values = values[:maxCompletionValues]
+		hasMore = true
+	}
+	return &mcp.CompleteResult{
+		Completion: mcp.CompletionResultDetails{
+			Values:  values,
+			Total:   total,
+			HasMore: hasMore,
+		},
+	}
+}
@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
+)
+
+// connectCompletionSession 建立支持参数补全的内存会话，客户端以 root 作为项目目录
+func connectCompletionSession(t *testing.T, root string) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0"}, &mcp.ServerOptions{
+		CompletionHandler: func(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+			return Complete(ctx, testBS, req)
+		},
+	})
+	RegisterPrompts(server, testBS)
+	RegisterResources(server, testBS)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0"}, nil)
+	client.AddRoots(fileRoot(root))
+	st, ct := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, st, nil); err != nil {
+		t.Fatalf("服务端连接失败: %v", err)
+	}
+	cs, err := client.Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatalf("客户端连接失败: %v", err)
+	}
+	t.Cleanup(func() { _ = cs.Close() })
+	return cs
+}
+
+// resolveTestScope 登记目录并解析它的作用域
+func resolveTestScope(t *testing.T, dir string) *types.ScopeContext {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := models.NewPersonalPathModel(testBS.DB()).EnsurePath(ctx, dir); err != nil {
+		t.Fatalf("登记路径失败: %v", err)
+	}
+	scopeCtx, err := testBS.GroupService.ResolveScope(ctx, dir)
+	if err != nil {
+		t.Fatalf("解析作用域失败: %v", err)
+	}
+	return scopeCtx
+}
+
+// TestComplete 提示词参数和资源模板变量的补全来自会话作用域内的数据，按前缀过滤
+func TestComplete(t *testing.T) {
+	ctx := context.Background()
+	root, other := t.TempDir(), t.TempDir()
+	scopeCtx, otherCtx := resolveTestScope(t, root), resolveTestScope(t, other)
+
+	createPlan := func(code string, scopeCtx *types.ScopeContext) {
+		t.Helper()
+		if _, err := testBS.PlanService.CreatePlan(ctx, &dto.PlanCreateDTO{Code: code, Title: code, Description: "描述", Content: "内容"}, scopeCtx); err != nil {
+			t.Fatalf("创建计划失败: %v", err)
+		}
+	}
+	createPlan("cmp-pending", scopeCtx)
+	createPlan("cmp-active", scopeCtx)
+	createPlan("cmp-done", scopeCtx)
+	createPlan("cmp-other", otherCtx)
+	if err := testBS.PlanService.StartPlan(ctx, "cmp-active"); err != nil {
+		t.Fatalf("开始计划失败: %v", err)
+	}
+	if err := testBS.PlanService.CompletePlan(ctx, "cmp-done"); err != nil {
+		t.Fatalf("完成计划失败: %v", err)
+	}
+	for code, category := range map[string]string{"cmp-mem-a": "cmp-架构", "cmp-mem-b": "cmp-部署", "cmp-mem-c": "cmp-架构"} {
+		if _, err := testBS.MemoryService.CreateMemory(ctx, &dto.MemoryCreateDTO{Code: code, Title: code, Content: "内容", Category: category}, scopeCtx); err != nil {
+			t.Fatalf("创建记忆失败: %v", err)
+		}
+	}
+	if _, err := testBS.MemoryService.CreateMemory(ctx, &dto.MemoryCreateDTO{Code: "cmp-mem-other", Title: "其他", Content: "内容", Category: "cmp-其他"}, otherCtx); err != nil {
+		t.Fatalf("创建记忆失败: %v", err)
+	}
+
+	cs := connectCompletionSession(t, root)
+	prompt := func(name string) *mcp.CompleteReference {
+		return &mcp.CompleteReference{Type: "ref/prompt", Name: name}
+	}
+	resource := func(uri string) *mcp.CompleteReference { return &mcp.CompleteReference{Type: "ref/resource", URI: uri} }
+	cases := []struct {
+		name     string
+		ref      *mcp.CompleteReference
+		argument string
+		value    string
+		want     []string
+	}{
+		{name: "继续工作只补全进行中的计划", ref: prompt(promptResumeWork), argument: promptArgPlan, value: "cmp-", want: []string{"cmp-active"}},
+		{name: "收尾补全未完成的计划", ref: prompt(promptSessionWrapup), argument: promptArgPlan, value: "cmp-", want: []string{"cmp-active", "cmp-pending"}},
+		{name: "前缀不区分大小写", ref: prompt(promptSessionWrapup), argument: promptArgPlan, value: "CMP-A", want: []string{"cmp-active"}},
+		{name: "记忆分类去重", ref: prompt(promptPlanFromGoal), argument: promptArgCategory, value: "cmp-", want: []string{"cmp-架构", "cmp-部署"}},
+		{name: "最低优先级", ref: prompt(promptResumeWork), argument: promptArgMinPriority, want: []string{"1", "2", "3", "4"}},
+		{name: "最低优先级按前缀过滤", ref: prompt(promptResumeWork), argument: promptArgMinPriority, value: "3", want: []string{"3"}},
+		{name: "自由文本参数没有候选", ref: prompt(promptPlanFromGoal), argument: promptArgGoal, want: []string{}},
+		{name: "记忆资源补全 code", ref: resource(memoryURITemplate), argument: "code", value: "cmp-mem", want: []string{"cmp-mem-a", "cmp-mem-b", "cmp-mem-c"}},
+		{name: "计划资源与资源列表一致，不含已完成的计划", ref: resource(planTodosURITemplate), argument: "code", value: "cmp-", want: []string{"cmp-active", "cmp-pending"}},
+		{name: "资源模板的其他变量没有候选", ref: resource(planURITemplate), argument: "other", want: []string{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := cs.Complete(ctx, &mcp.CompleteParams{
+				Ref:      tc.ref,
+				Argument: mcp.CompleteParamsArgument{Name: tc.argument, Value: tc.value},
+			})
+			if err != nil {
+				t.Fatalf("补全失败: %v", err)
+			}
+			got := result.Completion.Values
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("补全结果 = %q，期望 %q", got, tc.want)
+			}
+			if result.Completion.Total != len(tc.want) || result.Completion.HasMore {
+				t.Fatalf("total = %d hasMore = %v，期望 %d false", result.Completion.Total, result.Completion.HasMore, len(tc.want))
+			}
+		})
+	}
+}
+
+// TestCompletionResultLimit 超出上限时截断并标记还有更多
+func TestCompletionResultLimit(t *testing.T) {
+	candidates := make([]string, 0, maxCompletionValues+50)
+	for i := 0; i < maxCompletionValues+50; i++ {
+		candidates = append(candidates, fmt.Sprintf("plan-%03d", i))
+	}
+	result := completionResult(candidates, "PLAN-")
+	if len(result.Completion.Values) != maxCompletionValues || result.Completion.Total != maxCompletionValues+50 || !result.Completion.HasMore {
+		t.Fatalf("截断结果不符: %d 个，total %d，hasMore %v", len(result.Completion.Values), result.Completion.Total, result.Completion.HasMore)
+	}
+	result = completionResult(candidates, "plan-14")
+	if len(result.Completion.Values) != 10 || result.Completion.HasMore {
+		t.Fatalf("按前缀过滤后不应截断: %q", result.Completion.Values)
+	}
+}
@@ -0,0 +1,489 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/XiaoLFeng/llm-memory/internal/service"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
+	"github.com/XiaoLFeng/llm-memory/startup"
+)
+
+// 嘿嘿~ 这是 MCP 资源！📎
+// 支持资源的客户端可以把记忆、计划直接附加为上下文，而不必调用工具再解析文本：
+//   - llm-memory://memory/{code}       记忆
+//   - llm-memory://plan/{code}         计划（含待办概览）
+//   - llm-memory://plan/{code}/todos   计划下的待办
+// 每个资源同时返回 Markdown 和 JSON 两份内容，列表按会话作用域过滤（与工具的默认 all 范围一致）~
+
+// 资源 URI
+const (
+	resourceScheme       = "llm-memory://"
+	memoryURITemplate    = resourceScheme + "memory/{code}"
+	planURITemplate      = resourceScheme + "plan/{code}"
+	planTodosURITemplate = resourceScheme + "plan/{code}/todos"
+)
+
+// 资源内容的 MIME 类型
+const (
+	mimeMarkdown = "text/markdown"
+	mimeJSON     = "application/json"
+)
+
+// resourcePollInterval 检查已订阅资源是否变化的间隔
+const resourcePollInterval = 2 * time.Second
+
+// 资源类型
+const (
+	resourceKindMemory    = "memory"
+	resourceKindPlan      = "plan"
+	resourceKindPlanTodos = "todos"
+)
+
+// resourceRef 解析后的资源 URI
+type resourceRef struct {
+	kind string
+	code string
+}
+
+// memoryURI 记忆资源 URI
+func memoryURI(code string) string {
+	return resourceScheme + "memory/" + url.PathEscape(code)
+}
+
+// planURI 计划资源 URI
+func planURI(code string) string {
+	return resourceScheme + "plan/" + url.PathEscape(code)
+}
+
+// planTodosURI 计划待办资源 URI
+func planTodosURI(code string) string {
+	return planURI(code) + "/todos"
+}
+
+// parseResourceURI 解析资源 URI
+func parseResourceURI(uri string) (resourceRef, bool) {
+	rest, ok := strings.CutPrefix(uri, resourceScheme)
+	if !ok {
+		return resourceRef{}, false
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return resourceRef{}, false
+	}
+	code, err := url.PathUnescape(parts[1])
+	if err != nil || code == "" {
+		return resourceRef{}, false
+	}
+
+	switch {
+	case len(parts) == 2 && parts[0] == "memory":
+		return resourceRef{kind: resourceKindMemory, code: code}, true
+	case len(parts) == 2 && parts[0] == "plan":
+		return resourceRef{kind: resourceKindPlan, code: code}, true
+	case len(parts) == 3 && parts[0] == "plan" && parts[2] == "todos":
+		return resourceRef{kind: resourceKindPlanTodos, code: code}, true
+	}
+	return resourceRef{}, false
+}
+
+// resourceEntry 资源对应的数据（记忆或计划，计划已预加载待办）
+type resourceEntry struct {
+	ref    resourceRef
+	memory *entity.Memory
+	plan   *entity.Plan
+}
+
+// loadResource 读取资源对应的数据
+// 计划不论状态：已完成、已取消的计划不再出现在资源列表中，但订阅或引用它的客户端仍然可以读取
+func loadResource(ctx context.Context, bs *startup.Bootstrap, ref resourceRef) (*resourceEntry, error) {
+	entry := &resourceEntry{ref: ref}
+	var err error
+	if ref.kind == resourceKindMemory {
+		entry.memory, err = bs.MemoryService.GetMemory(ctx, ref.code)
+	} else {
+		entry.plan, err = bs.PlanService.GetLatestPlan(ctx, ref.code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// visible 资源是否在作用域内可见
+func (e *resourceEntry) visible(scopeCtx *types.ScopeContext) bool {
+	if e.memory != nil {
+		return service.IsMemoryVisible(e.memory, scopeCtx)
+	}
+	return service.IsPlanVisible(e.plan, scopeCtx)
+}
+
+// data 资源的结构化数据（JSON 内容）
+func (e *resourceEntry) data(scopeCtx *types.ScopeContext) any {
+	switch e.ref.kind {
+	case resourceKindMemory:
+		return service.ToMemoryResponseDTO(e.memory, scopeCtx)
+	case resourceKindPlan:
+		return service.ToPlanResponseDTO(e.plan, scopeCtx)
+	default:
+		todos := make([]*dto.ToDoResponseDTO, 0, len(e.plan.Todos))
+		for i := range e.plan.Todos {
+			todos = append(todos, service.ToToDoResponseDTOWithPlan(&e.plan.Todos[i], e.plan, scopeCtx))
+		}
+		return todos
+	}
+}
+
+// markdown 资源的 Markdown 内容
+func (e *resourceEntry) markdown(scopeCtx *types.ScopeContext) string {
+	switch e.ref.kind {
+	case resourceKindMemory:
+		return renderMemoryMarkdown(e.memory, scopeCtx)
+	case resourceKindPlan:
+		return renderPlanMarkdown(e.plan, scopeCtx)
+	default:
+		return renderPlanTodosMarkdown(e.plan)
+	}
+}
+
+// digest 资源内容摘要（与作用域无关），用于判断订阅的资源是否变化
+func (e *resourceEntry) digest() string {
+	data, _ := json.Marshal(e.data(nil))
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// RegisterResources 注册资源模板
+func RegisterResources(server *mcp.Server, bs *startup.Bootstrap) {
+	handler := func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return readResource(ctx, bs, req)
+	}
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "memory",
+		Title:       "记忆",
+		Description: "记忆的完整内容（Markdown，另附 JSON）",
+		URITemplate: memoryURITemplate,
+		MIMEType:    mimeMarkdown,
+	}, handler)
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "plan",
+		Title:       "计划",
+		Description: "计划的描述、内容、进度和待办概览（Markdown，另附 JSON）",
+		URITemplate: planURITemplate,
+		MIMEType:    mimeMarkdown,
+	}, handler)
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "plan_todos",
+		Title:       "计划待办",
+		Description: "计划下的所有待办（Markdown，另附 JSON）",
+		URITemplate: planTodosURITemplate,
+		MIMEType:    mimeMarkdown,
+	}, handler)
+}
+
+// readResource 读取资源，返回 Markdown 和 JSON 两份内容
+// 不在会话作用域内的资源与不存在的资源一样返回 not found
+func readResource(ctx context.Context, bs *startup.Bootstrap, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	ref, ok := parseResourceURI(uri)
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	entry, err := loadResource(ctx, bs, ref)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	scopeCtx := getScopeContext(ctx, bs, req.Session)
+	if !entry.visible(scopeCtx) {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	data, err := json.MarshalIndent(entry.data(scopeCtx), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{URI: uri, MIMEType: mimeMarkdown, Text: entry.markdown(scopeCtx)},
+			{URI: uri, MIMEType: mimeJSON, Text: string(data)},
+		},
+	}, nil
+}
+
+// ResourceListMiddleware 按会话作用域列出资源
+// SDK 只能列出静态注册的资源，这里拦截 resources/list，返回会话可见的记忆和计划
+func ResourceListMiddleware(bs *startup.Bootstrap) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			listReq, ok := req.(*mcp.ListResourcesRequest)
+			if method != "resources/list" || !ok {
+				return next(ctx, method, req)
+			}
+			resources, err := listResources(ctx, bs, listReq.Session)
+			if err != nil {
+				return nil, err
+			}
+			return &mcp.ListResourcesResult{Resources: resources}, nil
+		}
+	}
+}
+
+// listResources 列出会话作用域内的记忆、计划和计划待办
+func listResources(ctx context.Context, bs *startup.Bootstrap, session *mcp.ServerSession) ([]*mcp.Resource, error) {
+	scopeCtx := getScopeContext(ctx, bs, session)
+	memories, err := bs.MemoryService.ListMemoriesByScope(ctx, "all", scopeCtx)
+	if err != nil {
+		return nil, err
+	}
+	plans, err := bs.PlanService.ListPlansByScope(ctx, "all", scopeCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]*mcp.Resource, 0, len(memories)+len(plans)*2)
+	for _, m := range memories {
+		scope := types.GetScopeForDisplayWithGlobal(m.Global, m.PathID, scopeCtx)
+		resources = append(resources, &mcp.Resource{
+			URI:         memoryURI(m.Code),
+			Name:        m.Code,
+			Title:       m.Title,
+			Description: fmt.Sprintf("记忆 · %s · %s", m.Category, scope),
+			MIMEType:    mimeMarkdown,
+		})
+	}
+	for _, p := range plans {
+		scope := types.GetScopeForDisplayNoGlobal(p.PathID, scopeCtx)
+		resources = append(resources,
+			&mcp.Resource{
+				URI:         planURI(p.Code),
+				Name:        p.Code,
+				Title:       p.Title,
+				Description: fmt.Sprintf("计划 · %s · 进度 %d%% · %s", getPlanStatusText(p.Status), p.Progress, scope),
+				MIMEType:    mimeMarkdown,
+			},
+			&mcp.Resource{
+				URI:         planTodosURI(p.Code),
+				Name:        p.Code + "/todos",
+				Title:       p.Title + " 的待办",
+				Description: fmt.Sprintf("计划待办 · %d 项", len(p.Todos)),
+				MIMEType:    mimeMarkdown,
+			})
+	}
+	return resources, nil
+}
+
+// renderMemoryMarkdown 记忆的 Markdown 内容
+func renderMemoryMarkdown(m *entity.Memory, scopeCtx *types.ScopeContext) string {
+	tags := make([]string, 0, len(m.Tags))
+	for _, t := range m.Tags {
+		tags = append(tags, t.Tag)
+	}
+
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "# %s\n\n", m.Title)
+	_, _ = fmt.Fprintf(&sb, "- Code: `%s`\n", m.Code)
+	_, _ = fmt.Fprintf(&sb, "- 分类: %s\n", m.Category)
+	if len(tags) > 0 {
+		_, _ = fmt.Fprintf(&sb, "- 标签: %s\n", strings.Join(tags, ", "))
+	}
+	_, _ = fmt.Fprintf(&sb, "- 优先级: %d\n", m.Priority)
+	_, _ = fmt.Fprintf(&sb, "- 作用域: %s\n", types.GetScopeForDisplayWithGlobal(m.Global, m.PathID, scopeCtx))
+	if m.IsArchived {
+		sb.WriteString("- 已归档\n")
+	}
+	_, _ = fmt.Fprintf(&sb, "- 版本: %d\n", m.Version)
+	_, _ = fmt.Fprintf(&sb, "- 更新时间: %s\n\n", m.UpdatedAt.Format("2006-01-02 15:04:05"))
+	sb.WriteString(m.Content)
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// renderPlanMarkdown 计划的 Markdown 内容
+func renderPlanMarkdown(p *entity.Plan, scopeCtx *types.ScopeContext) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "# %s\n\n", p.Title)
+	_, _ = fmt.Fprintf(&sb, "- Code: `%s`\n", p.Code)
+	_, _ = fmt.Fprintf(&sb, "- 状态: %s\n", getPlanStatusText(p.Status))
+	_, _ = fmt.Fprintf(&sb, "- 进度: %d%%\n", p.Progress)
+	_, _ = fmt.Fprintf(&sb, "- 作用域: %s\n", types.GetScopeForDisplayNoGlobal(p.PathID, scopeCtx))
+	_, _ = fmt.Fprintf(&sb, "- 版本: %d\n", p.Version)
+	_, _ = fmt.Fprintf(&sb, "- 更新时间: %s\n\n", p.UpdatedAt.Format("2006-01-02 15:04:05"))
+	if p.Description != "" {
+		_, _ = fmt.Fprintf(&sb, "> %s\n\n", strings.ReplaceAll(p.Description, "\n", "\n> "))
+	}
+	if p.Content != "" {
+		sb.WriteString(p.Content)
+		sb.WriteString("\n\n")
+	}
+	_, _ = fmt.Fprintf(&sb, "## 待办（%d）\n\n", len(p.Todos))
+	writeTodoChecklist(&sb, p.Todos)
+	return sb.String()
+}
+
+// renderPlanTodosMarkdown 计划待办的 Markdown 内容
+func renderPlanTodosMarkdown(p *entity.Plan) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "# %s 的待办\n\n", p.Title)
+	writeTodoChecklist(&sb, p.Todos)
+	for _, t := range p.Todos {
+		if t.Description == "" {
+			continue
+		}
+		_, _ = fmt.Fprintf(&sb, "\n## %s `%s`\n\n%s\n", t.Title, t.Code, t.Description)
+	}
+	return sb.String()
+}
+
+// writeTodoChecklist 以任务列表形式写出待办
+func writeTodoChecklist(sb *strings.Builder, todos []entity.ToDo) {
+	if len(todos) == 0 {
+		sb.WriteString("暂无待办\n")
+		return
+	}
+	for _, t := range todos {
+		mark := " "
+		if t.Status == entity.ToDoStatusCompleted {
+			mark = "x"
+		}
+		_, _ = fmt.Fprintf(sb, "- [%s] `%s` %s（%s，优先级: %s", mark, t.Code, t.Title, getToDoStatusText(t.Status), getToDoPriorityText(t.Priority))
+		if t.DueDate != nil {
+			_, _ = fmt.Fprintf(sb, "，截止: %s", t.DueDate.Format("2006-01-02"))
+		}
+		sb.WriteString("）\n")
+	}
+}
+
+// ResourceWatcher 资源订阅
+// 嘿嘿~ 数据也可能被其他进程（CLI、TUI、另一个 MCP 服务）修改，所以定期检查已订阅的资源，
+// 内容变化（包括被删除）时向订阅的会话发送 resources/updated 通知！🔔
+type ResourceWatcher struct {
+	bs *startup.Bootstrap
+
+	mu            sync.Mutex
+	server        *mcp.Server
+	subscriptions map[string]*resourceSubscription // URI -> 订阅
+}
+
+// resourceSubscription 一个资源的订阅
+type resourceSubscription struct {
+	ref      resourceRef
+	sessions map[*mcp.ServerSession]bool
+	digest   string // 最近一次的内容摘要（资源不存在时为空）
+}
+
+// NewResourceWatcher 创建资源订阅
+// 需要在创建 MCP 服务器之前创建（Subscribe/Unsubscribe 作为服务器选项），之后调用 Start
+func NewResourceWatcher(bs *startup.Bootstrap) *ResourceWatcher {
+	return &ResourceWatcher{
+		bs:            bs,
+		subscriptions: make(map[string]*resourceSubscription),
+	}
+}
+
+// Subscribe 处理 resources/subscribe，资源不存在或不可见时拒绝订阅
+func (w *ResourceWatcher) Subscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	uri := req.Params.URI
+	ref, ok := parseResourceURI(uri)
+	if !ok {
+		return mcp.ResourceNotFoundError(uri)
+	}
+	entry, err := loadResource(ctx, w.bs, ref)
+	if err != nil || !entry.visible(getScopeContext(ctx, w.bs, req.Session)) {
+		return mcp.ResourceNotFoundError(uri)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	sub, ok := w.subscriptions[uri]
+	if !ok {
+		sub = &resourceSubscription{ref: ref, sessions: make(map[*mcp.ServerSession]bool), digest: entry.digest()}
+		w.subscriptions[uri] = sub
+	}
+	sub.sessions[req.Session] = true
+	return nil
+}
+
+// Unsubscribe 处理 resources/unsubscribe
+func (w *ResourceWatcher) Unsubscribe(_ context.Context, req *mcp.UnsubscribeRequest) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if sub, ok := w.subscriptions[req.Params.URI]; ok {
+		delete(sub.sessions, req.Session)
+		if len(sub.sessions) == 0 {
+			delete(w.subscriptions, req.Params.URI)
+		}
+	}
+	return nil
+}
+
+// Start 开始定期检查已订阅的资源，应用关闭时停止
+func (w *ResourceWatcher) Start(server *mcp.Server) {
+	w.mu.Lock()
+	w.server = server
+	w.mu.Unlock()
+
+	w.bs.AppContext().Go(func(ctx context.Context) {
+		ticker := time.NewTicker(resourcePollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.poll(ctx)
+			}
+		}
+	})
+}
+
+// poll 检查一次已订阅的资源，发送变化通知
+func (w *ResourceWatcher) poll(ctx context.Context) {
+	w.mu.Lock()
+	server := w.server
+	live := make(map[*mcp.ServerSession]bool)
+	for session := range server.Sessions() {
+		live[session] = true
+	}
+	// 清理已断开的会话
+	subs := make(map[string]*resourceSubscription, len(w.subscriptions))
+	for uri, sub := range w.subscriptions {
+		for session := range sub.sessions {
+			if !live[session] {
+				delete(sub.sessions, session)
+			}
+		}
+		if len(sub.sessions) == 0 {
+			delete(w.subscriptions, uri)
+			continue
+		}
+		subs[uri] = sub
+	}
+	w.mu.Unlock()
+
+	for uri, sub := range subs {
+		digest := ""
+		if entry, err := loadResource(ctx, w.bs, sub.ref); err == nil {
+			digest = entry.digest()
+		}
+
+		w.mu.Lock()
+		changed := digest != sub.digest
+		sub.digest = digest
+		w.mu.Unlock()
+
+		if changed {
+			_ = server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri})
+		}
+	}
+}
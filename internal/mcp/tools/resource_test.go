@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+)
+
+// connectResourceSession 建立注册了资源的内存会话（客户端不提供 roots，按进程工作目录解析作用域）
+func connectResourceSession(t *testing.T) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0"}, nil)
+	server.AddReceivingMiddleware(ResourceListMiddleware(testBS))
+	RegisterResources(server, testBS)
+
+	st, ct := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, st, nil); err != nil {
+		t.Fatalf("服务端连接失败: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0"}, nil).
+		Connect(ctx, &rootsProbe{Transport: ct, hideRoots: true}, nil)
+	if err != nil {
+		t.Fatalf("客户端连接失败: %v", err)
+	}
+	t.Cleanup(func() { _ = cs.Close() })
+	return cs
+}
+
+// TestPlanResourceAfterFinish 已完成、已取消的计划从资源列表中消失，但仍然可以读取
+func TestPlanResourceAfterFinish(t *testing.T) {
+	ctx := context.Background()
+	cs := connectResourceSession(t)
+
+	cases := []struct {
+		code   string
+		finish func(code string) error
+		status string
+	}{
+		{code: "res-plan-done", finish: func(code string) error { return testBS.PlanService.CompletePlan(ctx, code) }, status: "已完成"},
+		{code: "res-plan-cancel", finish: func(code string) error { return testBS.PlanService.CancelPlan(ctx, code) }, status: "已取消"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.code, func(t *testing.T) {
+			if _, err := testBS.PlanService.CreatePlan(ctx, &dto.PlanCreateDTO{
+				Code: tc.code, Title: "资源计划", Description: "描述", Content: "内容",
+			}, testBS.CurrentScope); err != nil {
+				t.Fatalf("创建计划失败: %v", err)
+			}
+			if !listsResource(t, cs, planURI(tc.code)) {
+				t.Fatalf("活跃计划应出现在资源列表中")
+			}
+
+			if err := tc.finish(tc.code); err != nil {
+				t.Fatalf("结束计划失败: %v", err)
+			}
+			if listsResource(t, cs, planURI(tc.code)) {
+				t.Fatalf("结束的计划不应出现在资源列表中")
+			}
+			for _, uri := range []string{planURI(tc.code), planTodosURI(tc.code)} {
+				result, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+				if err != nil {
+					t.Fatalf("读取 %s 失败: %v", uri, err)
+				}
+				if len(result.Contents) != 2 {
+					t.Fatalf("%s 应返回 Markdown 和 JSON 两份内容，实际 %d 份", uri, len(result.Contents))
+				}
+			}
+			result, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: planURI(tc.code)})
+			if err != nil {
+				t.Fatalf("读取计划失败: %v", err)
+			}
+			if text := result.Contents[0].Text; !strings.Contains(text, tc.status) {
+				t.Fatalf("计划内容应显示状态 %s:\n%s", tc.status, text)
+			}
+		})
+	}
+
+	if _, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: planURI("res-plan-missing")}); err == nil {
+		t.Fatalf("不存在的计划应返回 not found")
+	}
+}
+
+// listsResource 资源列表中是否包含 uri
+func listsResource(t *testing.T, cs *mcp.ClientSession, uri string) bool {
+	t.Helper()
+	result, err := cs.ListResources(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("列出资源失败: %v", err)
+	}
+	for _, r := range result.Resources {
+		if r.URI == uri {
+			return true
+		}
+	}
+	return false
+}
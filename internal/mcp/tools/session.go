@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/XiaoLFeng/llm-memory/startup"
+)
+
+// 嘿嘿~ 这是会话作用域！🧭
+// 服务进程的工作目录取决于客户端在哪里启动它（HTTP 模式下更是与客户端毫无关系），
+// 所以每个会话向客户端请求 roots，用第一个 file:// 根目录作为该会话的项目目录；
+// 结果按会话缓存，客户端发送 notifications/roots/list_changed 后重新请求。
+// 客户端没有声明 roots 能力或请求失败时退回服务进程的工作目录~
+
+// rootsTimeout 请求客户端 roots 的超时时间
+const rootsTimeout = 5 * time.Second
+
+// sessionRoot 会话的项目目录
+type sessionRoot struct {
+	mu     sync.Mutex
+	loaded bool   // 是否已向客户端请求过
+	path   string // 第一个文件根目录（为空时使用进程工作目录）
+}
+
+// sessionRoots 各会话的项目目录（*mcp.ServerSession -> *sessionRoot），会话结束时删除
+var sessionRoots sync.Map
+
+// InvalidateSessionRoots 客户端通知 roots 已变更，下次调用工具时重新请求
+func InvalidateSessionRoots(session *mcp.ServerSession) {
+	if v, ok := sessionRoots.Load(session); ok {
+		root := v.(*sessionRoot)
+		root.mu.Lock()
+		root.loaded = false
+		root.mu.Unlock()
+	}
+}
+
+// sessionRootOf 获取会话的项目目录记录（不存在时创建）
+func sessionRootOf(session *mcp.ServerSession) *sessionRoot {
+	if v, ok := sessionRoots.Load(session); ok {
+		return v.(*sessionRoot)
+	}
+	v, loaded := sessionRoots.LoadOrStore(session, &sessionRoot{})
+	if !loaded {
+		go func() {
+			_ = session.Wait()
+			sessionRoots.Delete(session)
+		}()
+	}
+	return v.(*sessionRoot)
+}
+
+// sessionRootPath 获取会话的项目目录
+// 首次调用（或 roots 变更后）向客户端请求 roots，并像启动时一样登记该目录（按解析模式映射）
+func sessionRootPath(ctx context.Context, bs *startup.Bootstrap, session *mcp.ServerSession) string {
+	if session == nil {
+		return ""
+	}
+	root := sessionRootOf(session)
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	if root.loaded {
+		return root.path
+	}
+
+	root.path = listRootPath(ctx, session)
+	root.loaded = true
+	if root.path != "" {
+		scopePath, _ := bs.GroupService.ResolveScopePath(ctx, root.path)
+		_, _ = bs.PathService.EnsurePath(ctx, scopePath)
+	}
+	return root.path
+}
+
+// listRootPath 请求客户端的 roots，返回第一个文件根目录
+// 客户端没有声明 roots 能力时不发请求，直接返回空字符串
+func listRootPath(ctx context.Context, session *mcp.ServerSession) string {
+	if !supportsRoots(session) {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(ctx, rootsTimeout)
+	defer cancel()
+	result, err := session.ListRoots(ctx, nil)
+	if err != nil {
+		return ""
+	}
+	for _, root := range result.Roots {
+		if path, ok := fileURIPath(root.URI); ok {
+			return path
+		}
+	}
+	return ""
+}
+
+// supportsRoots 客户端是否在初始化时声明了 roots 能力
+// SDK 的 ClientCapabilities.Roots 不是指针，无法区分 "roots": {} 和没有声明；
+// 声明 roots 的客户端（包括 SDK 自带的客户端）都会同时声明 listChanged，以它为准
+func supportsRoots(session *mcp.ServerSession) bool {
+	params := session.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Roots.ListChanged
+}
+
+// fileURIPath 把 file:// URI 转换为本地路径
+func fileURIPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" || u.Path == "" {
+		return "", false
+	}
+	path := u.Path
+	// Windows 下形如 file:///C:/code 的路径需要去掉开头的斜杠
+	if runtime.GOOS == "windows" {
+		path = strings.TrimPrefix(path, "/")
+	}
+	return filepath.Clean(filepath.FromSlash(path)), true
+}
@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+)
+
+// rootsProbe 包装客户端连接：统计服务端发来的 roots/list 请求，可选去掉初始化时声明的 roots 能力
+type rootsProbe struct {
+	mcp.Transport
+	hideRoots bool
+	requests  atomic.Int32
+}
+
+func (p *rootsProbe) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := p.Transport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &rootsProbeConn{Connection: conn, probe: p}, nil
+}
+
+type rootsProbeConn struct {
+	mcp.Connection
+	probe *rootsProbe
+}
+
+func (c *rootsProbeConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	msg, err := c.Connection.Read(ctx)
+	if req, ok := msg.(*jsonrpc.Request); ok && req.Method == "roots/list" {
+		c.probe.requests.Add(1)
+	}
+	return msg, err
+}
+
+func (c *rootsProbeConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	if req, ok := msg.(*jsonrpc.Request); ok && req.Method == "initialize" && c.probe.hideRoots {
+		var params map[string]any
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return err
+		}
+		if caps, ok := params["capabilities"].(map[string]any); ok {
+			delete(caps, "roots")
+		}
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = raw
+	}
+	return c.Connection.Write(ctx, msg)
+}
+
+// connectRootsSession 建立内存会话，客户端提供 roots，服务端在 roots 变更时失效缓存
+func connectRootsSession(t *testing.T, probe *rootsProbe, roots ...*mcp.Root) (*mcp.Client, *mcp.ServerSession) {
+	t.Helper()
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0"}, &mcp.ServerOptions{
+		RootsListChangedHandler: func(_ context.Context, req *mcp.RootsListChangedRequest) {
+			InvalidateSessionRoots(req.Session)
+		},
+	})
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0"}, nil)
+	client.AddRoots(roots...)
+
+	st, ct := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, st, nil)
+	if err != nil {
+		t.Fatalf("服务端连接失败: %v", err)
+	}
+	probe.Transport = ct
+	cs, err := client.Connect(ctx, probe, nil)
+	if err != nil {
+		t.Fatalf("客户端连接失败: %v", err)
+	}
+	t.Cleanup(func() { _ = cs.Close() })
+	return client, ss
+}
+
+// fileRoot 本地目录对应的 file:// 根目录
+func fileRoot(dir string) *mcp.Root {
+	return &mcp.Root{URI: (&url.URL{Scheme: "file", Path: dir}).String()}
+}
+
+func TestSessionRootPath(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name         string
+		hideRoots    bool
+		roots        []*mcp.Root
+		want         string
+		wantRequests int32
+	}{
+		{name: "使用第一个文件根目录", roots: []*mcp.Root{{URI: "https://example.com/repo"}, fileRoot(dir)}, want: dir, wantRequests: 1},
+		{name: "没有文件根目录", roots: []*mcp.Root{{URI: "https://example.com/repo"}}, want: "", wantRequests: 1},
+		{name: "没有声明 roots 能力时不请求", hideRoots: true, roots: []*mcp.Root{fileRoot(dir)}, want: "", wantRequests: 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			probe := &rootsProbe{hideRoots: tc.hideRoots}
+			_, session := connectRootsSession(t, probe, tc.roots...)
+			for i := 0; i < 2; i++ {
+				if got := sessionRootPath(context.Background(), testBS, session); got != tc.want {
+					t.Fatalf("第 %d 次: 项目目录 = %q，期望 %q", i+1, got, tc.want)
+				}
+			}
+			if got := probe.requests.Load(); got != tc.wantRequests {
+				t.Fatalf("roots/list 请求 %d 次，期望 %d 次（结果应按会话缓存）", got, tc.wantRequests)
+			}
+		})
+	}
+
+	// 项目目录像启动时的工作目录一样登记
+	exists, err := models.NewPersonalPathModel(testBS.DB()).Exists(context.Background(), dir)
+	if err != nil || !exists {
+		t.Fatalf("会话项目目录应已登记: exists=%v err=%v", exists, err)
+	}
+}
+
+// TestSessionRootPathRefresh 客户端通知 roots 变更后重新请求
+func TestSessionRootPathRefresh(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	probe := &rootsProbe{}
+	client, session := connectRootsSession(t, probe, fileRoot(first))
+	ctx := context.Background()
+
+	if got := sessionRootPath(ctx, testBS, session); got != first {
+		t.Fatalf("项目目录 = %q，期望 %q", got, first)
+	}
+
+	client.RemoveRoots(fileRoot(first).URI)
+	client.AddRoots(fileRoot(second))
+	deadline := time.Now().Add(5 * time.Second)
+	for sessionRootPath(ctx, testBS, session) != second {
+		if time.Now().After(deadline) {
+			t.Fatalf("roots 变更后项目目录仍为 %q，期望 %q", sessionRootPath(ctx, testBS, session), second)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := probe.requests.Load(); got < 2 {
+		t.Fatalf("roots 变更后应重新请求，实际请求 %d 次", got)
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
 	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/XiaoLFeng/llm-memory/internal/service"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
 	"github.com/XiaoLFeng/llm-memory/startup"
 )
 
@@ -36,11 +38,11 @@ type TodoBatchOperationInput struct {
 	Codes []string `json:"codes" jsonschema:"待办事项代码列表最多100个"`
 }
 
-// TodoBatchOperationResult 批量操作结果
+// TodoBatchOperationResult 批量操作结果（批量工具的结构化输出）
 type TodoBatchOperationResult struct {
-	SuccessCount int                `json:"success_count"`
-	FailCount    int                `json:"fail_count"`
-	Failures     []TodoBatchFailure `json:"failures,omitempty"`
+	SuccessCount int                `json:"success_count" jsonschema:"成功处理的待办数量"`
+	FailCount    int                `json:"fail_count" jsonschema:"处理失败的待办数量"`
+	Failures     []TodoBatchFailure `json:"failures,omitempty" jsonschema:"失败的待办及原因"`
 }
 
 type TodoBatchFailure struct {
@@ -59,6 +61,7 @@ type TodoUpdateItem struct {
 	Description string `json:"description,omitempty" jsonschema:"新的待办描述"`
 	Priority    int    `json:"priority,omitempty" jsonschema:"新的优先级 1低2中3高4紧急"`
 	Status      int    `json:"status,omitempty" jsonschema:"新的状态 0待处理1进行中2已完成3已取消"`
+	Version     int64  `json:"version,omitempty" jsonschema:"读取时的版本号（todo_list 返回，可选）；提供后若待办已被他人修改则拒绝更新"`
 }
 
 // TodoBatchStartInput todo_batch_start 工具输入
@@ -71,15 +74,24 @@ type TodoFinalInput struct {
 	Scope string `json:"scope,omitempty" jsonschema:"作用域过滤 personal group all 默认all显示全部"`
 }
 
+// TodoListOutput todo_list 工具输出
+type TodoListOutput struct {
+	Todos []dto.ToDoResponseDTO `json:"todos" jsonschema:"可见的待办列表"`
+}
+
+// TodoFinalOutput todo_final 工具输出
+type TodoFinalOutput struct {
+	DeletedCount int64 `json:"deleted_count" jsonschema:"移入回收站的待办数量"`
+}
+
 // validateTodoOwnership 验证待办所有权权限
-func validateTodoOwnership(ctx context.Context, bs *startup.Bootstrap, code string) (*entity.ToDo, error) {
+func validateTodoOwnership(ctx context.Context, bs *startup.Bootstrap, scope *types.ScopeContext, code string) (*entity.ToDo, error) {
 	todo, err := bs.ToDoService.GetToDo(ctx, code)
 	if err != nil {
 		return nil, fmt.Errorf("待办不存在: %s", code)
 	}
 
 	// 权限检查：只能操作自己作用域内的待办
-	scope := bs.CurrentScope
 	if scope == nil {
 		return nil, fmt.Errorf("当前作用域为空")
 	}
@@ -113,56 +125,38 @@ func validateBatchSize(count int) error {
 	return nil
 }
 
-// formatBatchResult 格式化批量操作结果为混合模式
-func formatBatchResult(result *TodoBatchOperationResult, operation string) string {
-	if result.FailCount == 0 {
-		return fmt.Sprintf("✅ %s成功! 共处理 %d 个待办事项", operation, result.SuccessCount)
-	}
-	if result.SuccessCount == 0 {
-		return fmt.Sprintf("❌ %s失败! 所有 %d 个待办事项都无法处理:\n%s",
-			operation, result.FailCount, formatFailures(result.Failures))
-	}
-	return fmt.Sprintf("⚠️ %s部分完成! 成功 %d 个，失败 %d 个:\n%s",
-		operation, result.SuccessCount, result.FailCount, formatFailures(result.Failures))
-}
-
-// formatFailures 格式化失败信息
-func formatFailures(failures []TodoBatchFailure) string {
-	var sb strings.Builder
-	for _, failure := range failures {
-		sb.WriteString(fmt.Sprintf("  • %s: %s\n", failure.Code, failure.Error))
-	}
-	return sb.String()
-}
-
 // RegisterTodoTools 注册 TODO 管理工具
 func RegisterTodoTools(server *mcp.Server, bs *startup.Bootstrap) {
 	// todo_list - 列出所有待办
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name: "todo_list",
 		Description: `列出所有待办及状态。每个 Todo 都归属于一个 Plan。
 scope参数说明（安全隔离）：
   - personal: 仅当前路径的项目数据
   - group: 仅当前小组的数据（需已加入小组）
   - all/省略: 当前路径 + 小组数据（默认，权限隔离）`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input TodoListInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input TodoListInput) (*mcp.CallToolResult, *TodoListOutput, error) {
 		// 构建作用域上下文
-		scopeCtx := getScopeContext(bs)
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
 
 		todos, err := bs.ToDoService.ListToDosByScope(ctx, input.Scope, scopeCtx)
 		if err != nil {
 			return NewErrorResult(err.Error()), nil, nil
 		}
 		if len(todos) == 0 {
-			return NewTextResult("暂无待办事项"), nil, nil
+			return NewTextResult("暂无待办事项"), &TodoListOutput{Todos: []dto.ToDoResponseDTO{}}, nil
 		}
+		output := &TodoListOutput{Todos: make([]dto.ToDoResponseDTO, 0, len(todos))}
 		result := "待办事项列表:\n"
-		for _, t := range todos {
+		for i, t := range todos {
 			status := getToDoStatusText(t.Status)
 			priority := getToDoPriorityText(t.Priority)
-			scopeTag := getScopeTagWithContext(t.PathID, bs.CurrentScope)
+			scopeTag := getScopeTagWithContext(t.PathID, scopeCtx)
 			// 获取 Plan Code
 			planCode, _ := bs.ToDoService.GetPlanCodeByTodoID(ctx, t.ID)
+			item := service.ToToDoResponseDTO(&todos[i], scopeCtx)
+			item.PlanCode = planCode
+			output.Todos = append(output.Todos, *item)
 			// 格式: title - description (如果有描述)
 			titlePart := t.Title
 			if t.Description != "" {
@@ -172,25 +166,25 @@ scope参数说明（安全隔离）：
 				}
 				titlePart = t.Title + " - " + desc
 			}
-			result += fmt.Sprintf("- [%s] %s (计划:%s, %s, %s) %s\n", t.Code, titlePart, planCode, status, priority, scopeTag)
+			result += fmt.Sprintf("- [%s] %s (计划:%s, %s, %s, 版本:%d) %s\n", t.Code, titlePart, planCode, status, priority, t.Version, scopeTag)
 		}
-		return NewTextResult(result), nil, nil
+		return NewTextResult(result), output, nil
 	})
 
 	// todo_batch_create - 批量创建待办
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name: "todo_batch_create",
 		Description: `批量创建待办事项，提高AI处理效率。支持最多100个待办项的批量创建。
 重要：每个待办必须指定 plan_code（所属计划的标识码），Todo 必须归属于一个 Plan。
-返回混合模式结果：成功显示统计，失败显示详情。`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input TodoBatchCreateInput) (*mcp.CallToolResult, any, error) {
+返回 JSON 结果：success_count、fail_count，以及失败项的 failures（code 与原因）。`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input TodoBatchCreateInput) (*mcp.CallToolResult, *TodoBatchOperationResult, error) {
 		// 验证批量大小
 		if err := validateBatchSize(len(input.Items)); err != nil {
 			return NewErrorResult(err.Error()), nil, nil
 		}
 
 		result := &TodoBatchOperationResult{}
-		scopeCtx := getScopeContext(bs)
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
 
 		// 批量创建
 		for _, item := range input.Items {
@@ -230,26 +224,26 @@ scope参数说明（安全隔离）：
 			}
 		}
 
-		response := formatBatchResult(result, "批量创建")
-		return NewTextResult(response), result, nil
+		return nil, result, nil
 	})
 
 	// todo_batch_complete - 批量完成待办
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "todo_batch_complete",
-		Description: `批量标记待办事项为已完成。支持最多100个待办的批量完成操作。返回混合模式结果。`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input TodoBatchOperationInput) (*mcp.CallToolResult, any, error) {
+		Description: `批量标记待办事项为已完成。支持最多100个待办的批量完成操作。返回 JSON 结果（success_count/fail_count/failures）。`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input TodoBatchOperationInput) (*mcp.CallToolResult, *TodoBatchOperationResult, error) {
 		// 验证批量大小
 		if err := validateBatchSize(len(input.Codes)); err != nil {
 			return NewErrorResult(err.Error()), nil, nil
 		}
 
 		result := &TodoBatchOperationResult{}
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
 
 		// 批量完成
 		for _, code := range input.Codes {
 			// 权限验证
-			_, err := validateTodoOwnership(ctx, bs, code)
+			_, err := validateTodoOwnership(ctx, bs, scopeCtx, code)
 			if err != nil {
 				result.FailCount++
 				result.Failures = append(result.Failures, TodoBatchFailure{
@@ -271,26 +265,26 @@ scope参数说明（安全隔离）：
 			}
 		}
 
-		response := formatBatchResult(result, "批量完成")
-		return NewTextResult(response), result, nil
+		return nil, result, nil
 	})
 
 	// todo_batch_cancel - 批量取消待办
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "todo_batch_cancel",
-		Description: `批量标记待办事项为已取消。支持最多100个待办的批量取消操作。返回混合模式结果。`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input TodoBatchOperationInput) (*mcp.CallToolResult, any, error) {
+		Description: `批量标记待办事项为已取消。支持最多100个待办的批量取消操作。返回 JSON 结果（success_count/fail_count/failures）。`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input TodoBatchOperationInput) (*mcp.CallToolResult, *TodoBatchOperationResult, error) {
 		// 验证批量大小
 		if err := validateBatchSize(len(input.Codes)); err != nil {
 			return NewErrorResult(err.Error()), nil, nil
 		}
 
 		result := &TodoBatchOperationResult{}
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
 
 		// 批量取消
 		for _, code := range input.Codes {
 			// 权限验证
-			_, err := validateTodoOwnership(ctx, bs, code)
+			_, err := validateTodoOwnership(ctx, bs, scopeCtx, code)
 			if err != nil {
 				result.FailCount++
 				result.Failures = append(result.Failures, TodoBatchFailure{
@@ -312,26 +306,26 @@ scope参数说明（安全隔离）：
 			}
 		}
 
-		response := formatBatchResult(result, "批量取消")
-		return NewTextResult(response), result, nil
+		return nil, result, nil
 	})
 
 	// todo_batch_start - 批量开始待办
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "todo_batch_start",
-		Description: `批量标记待办事项为进行中状态。支持最多100个待办的批量开始操作。返回混合模式结果。`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input TodoBatchStartInput) (*mcp.CallToolResult, any, error) {
+		Description: `批量标记待办事项为进行中状态。支持最多100个待办的批量开始操作。返回 JSON 结果（success_count/fail_count/failures）。`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input TodoBatchStartInput) (*mcp.CallToolResult, *TodoBatchOperationResult, error) {
 		// 验证批量大小
 		if err := validateBatchSize(len(input.Codes)); err != nil {
 			return NewErrorResult(err.Error()), nil, nil
 		}
 
 		result := &TodoBatchOperationResult{}
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
 
 		// 批量开始
 		for _, code := range input.Codes {
 			// 权限验证
-			_, err := validateTodoOwnership(ctx, bs, code)
+			_, err := validateTodoOwnership(ctx, bs, scopeCtx, code)
 			if err != nil {
 				result.FailCount++
 				result.Failures = append(result.Failures, TodoBatchFailure{
@@ -353,26 +347,26 @@ scope参数说明（安全隔离）：
 			}
 		}
 
-		response := formatBatchResult(result, "批量开始")
-		return NewTextResult(response), result, nil
+		return nil, result, nil
 	})
 
 	// todo_batch_update - 批量更新待办
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "todo_batch_update",
-		Description: `批量更新待办事项的标题、描述、优先级或状态。支持最多100个待办的批量更新。返回混合模式结果。`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input TodoBatchUpdateInput) (*mcp.CallToolResult, any, error) {
+		Description: `批量更新待办事项的标题、描述、优先级或状态。支持最多100个待办的批量更新。每项可传入 todo_list 返回的 version，待办在读取后被他人修改时该项返回版本冲突。返回 JSON 结果（success_count/fail_count/failures）。`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input TodoBatchUpdateInput) (*mcp.CallToolResult, *TodoBatchOperationResult, error) {
 		// 验证批量大小
 		if err := validateBatchSize(len(input.Items)); err != nil {
 			return NewErrorResult(err.Error()), nil, nil
 		}
 
 		result := &TodoBatchOperationResult{}
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
 
 		// 批量更新
 		for _, item := range input.Items {
 			// 权限验证
-			_, err := validateTodoOwnership(ctx, bs, item.Code)
+			_, err := validateTodoOwnership(ctx, bs, scopeCtx, item.Code)
 			if err != nil {
 				result.FailCount++
 				result.Failures = append(result.Failures, TodoBatchFailure{
@@ -385,7 +379,8 @@ scope参数说明（安全隔离）：
 			// 检查是否有更新内容
 			hasUpdates := false
 			updateDTO := &dto.ToDoUpdateDTO{
-				Code: item.Code,
+				Code:    item.Code,
+				Version: expectedVersion(item.Version),
 			}
 
 			if item.Title != "" {
@@ -419,27 +414,26 @@ scope参数说明（安全隔离）：
 				result.FailCount++
 				result.Failures = append(result.Failures, TodoBatchFailure{
 					Code:  item.Code,
-					Error: err.Error(),
+					Error: updateErrorMessage(err, "todo_list"),
 				})
 			} else {
 				result.SuccessCount++
 			}
 		}
 
-		response := formatBatchResult(result, "批量更新")
-		return NewTextResult(response), result, nil
+		return nil, result, nil
 	})
 
 	// todo_final - 删除所有待办
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name: "todo_final",
-		Description: `删除当前作用域内的所有待办事项。这是一个清理工具，会直接删除指定作用域内的所有待办（不可恢复）。
+		Description: `删除当前作用域内的所有待办事项。这是一个清理工具，会把指定作用域内的所有待办移入回收站（可用 trash_restore 逐个恢复）。
 删除逻辑：
   - 未加入小组：删除当前路径的项目待办
   - 已加入小组：删除小组内所有路径的待办`,
-	}, func(ctx context.Context, req *mcp.CallToolRequest, input TodoFinalInput) (*mcp.CallToolResult, any, error) {
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input TodoFinalInput) (*mcp.CallToolResult, *TodoFinalOutput, error) {
 		// 构建作用域上下文
-		scopeCtx := getScopeContext(bs)
+		scopeCtx := getScopeContext(ctx, bs, req.Session)
 
 		// 删除所有待办
 		deletedCount, err := bs.ToDoService.DeleteAllByScope(ctx, input.Scope, scopeCtx)
@@ -447,11 +441,12 @@ scope参数说明（安全隔离）：
 			return NewErrorResult(err.Error()), nil, nil
 		}
 
+		output := &TodoFinalOutput{DeletedCount: deletedCount}
 		if deletedCount == 0 {
-			return NewTextResult("当前作用域内没有待办事项需要删除"), nil, nil
+			return NewTextResult("当前作用域内没有待办事项需要删除"), output, nil
 		}
 
-		return NewTextResult(fmt.Sprintf("已删除 %d 个待办事项", deletedCount)), nil, nil
+		return NewTextResult(fmt.Sprintf("已删除 %d 个待办事项", deletedCount)), output, nil
 	})
 }
 
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/pkg/utils"
+	"github.com/XiaoLFeng/llm-memory/startup"
+)
+
+// TrashListInput trash_list 工具输入
+type TrashListInput struct{}
+
+// TrashRestoreInput trash_restore 工具输入
+type TrashRestoreInput struct {
+	Type string `json:"type" jsonschema:"条目类型(memory/plan/todo)"`
+	Code string `json:"code" jsonschema:"要恢复的条目code"`
+}
+
+// TrashPurgeInput trash_purge 工具输入
+type TrashPurgeInput struct {
+	OlderThan string `json:"older_than,omitempty" jsonschema:"只清理删除时间早于该时长的条目，支持 d/h/m 单位如 30d、12h，0 表示全部，默认30d"`
+}
+
+// TrashListOutput trash_list 工具输出
+type TrashListOutput struct {
+	Items []dto.TrashItemDTO `json:"items" jsonschema:"回收站条目，按删除时间倒序"`
+}
+
+// TrashRestoreOutput trash_restore 工具输出
+type TrashRestoreOutput struct {
+	Type string `json:"type" jsonschema:"条目类型 memory/plan/todo"`
+	Code string `json:"code" jsonschema:"已恢复的条目code"`
+}
+
+// RegisterTrashTools 注册回收站工具
+func RegisterTrashTools(server *mcp.Server, bs *startup.Bootstrap) {
+	// trash_list - 列出回收站
+	addTool(server, &mcp.Tool{
+		Name:        "trash_list",
+		Description: `列出当前路径（含小组）可见的回收站条目：已删除的记忆、计划和待办，按删除时间倒序。随计划一起删除的待办不单独列出，恢复计划时会一并恢复。`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input TrashListInput) (*mcp.CallToolResult, *TrashListOutput, error) {
+		items, err := bs.TrashService.ListTrash(ctx, getScopeContext(ctx, bs, req.Session))
+		if err != nil {
+			return NewErrorResult(err.Error()), nil, nil
+		}
+		output := &TrashListOutput{Items: items}
+		if output.Items == nil {
+			output.Items = []dto.TrashItemDTO{}
+		}
+		if len(items) == 0 {
+			return NewTextResult("回收站为空"), output, nil
+		}
+		result := fmt.Sprintf("回收站 (%d 条):\n", len(items))
+		for _, item := range items {
+			result += fmt.Sprintf("- [%s] %s %s (删除于 %s)\n",
+				item.Type, item.Code, item.Title, utils.FormatDateTime(item.DeletedAt))
+		}
+		return NewTextResult(result), output, nil
+	})
+
+	// trash_restore - 从回收站恢复
+	addTool(server, &mcp.Tool{
+		Name:        "trash_restore",
+		Description: `从回收站恢复已删除的记忆、计划或待办。必填: type(memory/plan/todo)、code。恢复计划时会一并恢复随计划删除的待办；所属计划仍在回收站的待办需先恢复计划。`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input TrashRestoreInput) (*mcp.CallToolResult, *TrashRestoreOutput, error) {
+		if err := bs.TrashService.RestoreItem(ctx, input.Type, input.Code, getScopeContext(ctx, bs, req.Session)); err != nil {
+			return NewErrorResult(err.Error()), nil, nil
+		}
+		return NewTextResult(fmt.Sprintf("%s %s 已从回收站恢复", input.Type, input.Code)),
+			&TrashRestoreOutput{Type: input.Type, Code: input.Code}, nil
+	})
+
+	// trash_purge - 彻底清理回收站
+	addTool(server, &mcp.Tool{
+		Name:        "trash_purge",
+		Description: `彻底删除回收站中删除时间早于 older_than 的条目（默认30d），删除后不可恢复。仅在用户明确要求清理回收站时使用。`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input TrashPurgeInput) (*mcp.CallToolResult, *dto.TrashPurgeResultDTO, error) {
+		olderThan := input.OlderThan
+		if olderThan == "" {
+			olderThan = "30d"
+		}
+		d, err := utils.ParseDurationWithDays(olderThan)
+		if err != nil {
+			return NewErrorResult(err.Error()), nil, nil
+		}
+		result, err := bs.TrashService.Purge(ctx, d)
+		if err != nil {
+			return NewErrorResult(err.Error()), nil, nil
+		}
+		return NewTextResult(fmt.Sprintf("已彻底删除 %d 条：记忆 %d，计划 %d，待办 %d",
+			result.Total(), result.Memories, result.Plans, result.Todos)), result, nil
+	})
+}
@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
+)
 
 // GroupCreateDTO 创建组请求
 // 嘿嘿~ 用于创建新组的数据传输对象！💖
@@ -49,8 +53,11 @@ type GroupListDTO struct {
 
 // ScopeInfoDTO 当前作用域信息
 type ScopeInfoDTO struct {
-	CurrentPath string `json:"current_path"`
-	GroupID     int64  `json:"group_id"`
-	GroupName   string `json:"group_name"`
-	IsInGroup   bool   `json:"is_in_group"`
+	CurrentPath string                `json:"current_path"`
+	ScopePath   string                `json:"scope_path"`
+	MatchedRule types.ScopeResolution `json:"matched_rule"`
+	ProjectID   string                `json:"project_id,omitempty"`
+	GroupID     int64                 `json:"group_id"`
+	GroupName   string                `json:"group_name"`
+	IsInGroup   bool                  `json:"is_in_group"`
 }
@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/pkg/utils"
+)
 
 // MemoryCreateDTO 创建记忆请求
 type MemoryCreateDTO struct {
@@ -21,6 +25,7 @@ type MemoryUpdateDTO struct {
 	Category *string   `json:"category,omitempty"`
 	Tags     *[]string `json:"tags,omitempty"`
 	Priority *int      `json:"priority,omitempty"`
+	Version  *int64    `json:"version,omitempty"` // 读取时的版本，与当前版本不一致时拒绝更新（留空不校验）
 }
 
 // MemoryResponseDTO 记忆响应
@@ -34,6 +39,7 @@ type MemoryResponseDTO struct {
 	Priority   int       `json:"priority"`
 	Scope      string    `json:"scope"` // Personal/Group/Global
 	IsArchived bool      `json:"is_archived"`
+	Version    int64     `json:"version"` // 乐观锁版本号，更新时回传
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
@@ -54,3 +60,28 @@ type MemorySearchDTO struct {
 	Keyword string `json:"keyword"`
 	Scope   string `json:"scope"` // personal/group/global/all
 }
+
+// MemorySnapshotDTO 记忆在某个修订点的内容快照
+// Revision 为 0 表示当前版本
+type MemorySnapshotDTO struct {
+	Revision int      `json:"revision"`
+	Title    string   `json:"title"`
+	Content  string   `json:"content"`
+	Category string   `json:"category"`
+	Priority int      `json:"priority"`
+	Tags     []string `json:"tags"`
+}
+
+// MemoryFieldDiffDTO 单个字段的差异
+type MemoryFieldDiffDTO struct {
+	Field string           `json:"field"` // 字段名称（标题/内容/分类/优先级/标签）
+	Lines []utils.DiffLine `json:"lines"`
+}
+
+// MemoryImportResultDTO Markdown 目录导入结果
+type MemoryImportResultDTO struct {
+	Created   []string `json:"created,omitempty"`  // 新建的记忆 code
+	Updated   []string `json:"updated,omitempty"`  // 内容变化而更新的记忆 code
+	Unchanged int      `json:"unchanged"`          // 内容未变化的文件数
+	Warnings  []string `json:"warnings,omitempty"` // 跳过的文件及原因
+}
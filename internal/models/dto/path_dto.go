@@ -0,0 +1,32 @@
+package dto
+
+// PathMoveDTO 一条路径的搬迁记录
+type PathMoveDTO struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// PathMoveResultDTO 路径搬迁结果
+type PathMoveResultDTO struct {
+	Moved    []PathMoveDTO `json:"moved"`    // 改写的路径（含子路径）
+	Replaced int           `json:"replaced"` // 删除的空目标路径记录数（启动时自动登记、没有数据的路径）
+}
+
+// CodeRenameDTO 合并时因冲突改名的条目
+type CodeRenameDTO struct {
+	Type string `json:"type"` // plan/todo
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// PathMergeResultDTO 路径合并结果
+type PathMergeResultDTO struct {
+	From        string          `json:"from"`
+	To          string          `json:"to"`
+	Memories    int64           `json:"memories"`
+	Plans       int64           `json:"plans"`
+	Todos       int64           `json:"todos"`
+	Renamed     []CodeRenameDTO `json:"renamed,omitempty"`
+	JoinedGroup bool            `json:"joined_group"` // 目标路径继承了源路径的组
+	LeftGroup   bool            `json:"left_group"`   // 源路径与目标路径属于不同的组，源路径的组成员关系被移除
+}
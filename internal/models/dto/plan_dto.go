@@ -17,6 +17,7 @@ type PlanUpdateDTO struct {
 	Description *string `json:"description,omitempty"`
 	Content     *string `json:"content,omitempty"`
 	Progress    *int    `json:"progress,omitempty"`
+	Version     *int64  `json:"version,omitempty"` // 读取时的版本，与当前版本不一致时拒绝更新（留空不校验）
 }
 
 // PlanProgressDTO 更新计划进度请求
@@ -37,6 +38,7 @@ type PlanResponseDTO struct {
 	Progress    int           `json:"progress"`
 	Todos       []ToDoListDTO `json:"todos"` // 关联的待办事项列表
 	Scope       string        `json:"scope"`
+	Version     int64         `json:"version"` // 乐观锁版本号，更新时回传
 	CreatedAt   time.Time     `json:"created_at"`
 	UpdatedAt   time.Time     `json:"updated_at"`
 }
@@ -22,6 +22,7 @@ type ToDoUpdateDTO struct {
 	Status      *int       `json:"status,omitempty"`
 	DueDate     *time.Time `json:"due_date,omitempty"`
 	Tags        *[]string  `json:"tags,omitempty"`
+	Version     *int64     `json:"version,omitempty"` // 读取时的版本，与当前版本不一致时拒绝更新（留空不校验）
 }
 
 // ToDoResponseDTO 待办响应
@@ -42,6 +43,7 @@ type ToDoResponseDTO struct {
 	Tags        []string   `json:"tags"`
 	Scope       string     `json:"scope"`
 	IsOverdue   bool       `json:"is_overdue"`
+	Version     int64      `json:"version"` // 乐观锁版本号，更新时回传
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 }
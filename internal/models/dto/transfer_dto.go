@@ -0,0 +1,147 @@
+package dto
+
+import "time"
+
+// 导出文件格式
+// 嘿嘿~ JSONL 格式：每行一条 {"type": "...", "data": {...}} 记录！✨
+// 第一行必须是 header，其余记录按 path -> group -> group_path -> memory -> plan -> todo 顺序写出
+// 记录中的 ID 仅在文件内部用于引用（如 PathID、PlanID），导入时会重新生成
+const (
+	ExportFormatName    = "llm-memory-export"
+	ExportFormatVersion = 1
+)
+
+// 导出记录类型
+const (
+	ExportRecordHeader    = "header"
+	ExportRecordPath      = "path"
+	ExportRecordGroup     = "group"
+	ExportRecordGroupPath = "group_path"
+	ExportRecordMemory    = "memory"
+	ExportRecordPlan      = "plan"
+	ExportRecordTodo      = "todo"
+)
+
+// 导入冲突策略
+const (
+	ImportStrategySkip      = "skip"      // 跳过冲突条目，保留本地数据
+	ImportStrategyOverwrite = "overwrite" // 用导入数据替换本地冲突条目
+	ImportStrategyRename    = "rename"    // 为导入条目生成新的 code
+)
+
+// ExportRecordDTO 导出文件中的一行记录
+type ExportRecordDTO struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// ExportHeaderDTO 导出文件头
+type ExportHeaderDTO struct {
+	Format        string    `json:"format"`         // 固定为 llm-memory-export
+	Version       int       `json:"version"`        // 导出格式版本
+	SchemaVersion int       `json:"schema_version"` // 导出时的数据库结构版本（仅供参考）
+	ExportedAt    time.Time `json:"exported_at"`
+}
+
+// ExportPathDTO 路径记录
+type ExportPathDTO struct {
+	ID        int64  `json:"id"`
+	Path      string `json:"path"`                 // 绝对路径
+	ProjectID string `json:"project_id,omitempty"` // 项目标识（由 git 远程地址生成），导入时用于匹配本机检出
+}
+
+// ExportGroupDTO 组记录
+type ExportGroupDTO struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ExportGroupPathDTO 组路径关联记录
+type ExportGroupPathDTO struct {
+	GroupID int64 `json:"group_id"` // 引用 group.id
+	PathID  int64 `json:"path_id"`  // 引用 path.id
+}
+
+// ExportMemoryDTO 记忆记录（含标签）
+type ExportMemoryDTO struct {
+	ID         int64     `json:"id"`
+	Code       string    `json:"code"`
+	Global     bool      `json:"global"`
+	PathID     int64     `json:"path_id"` // 引用 path.id，全局记忆为 0
+	Title      string    `json:"title"`
+	Content    string    `json:"content"`
+	Category   string    `json:"category"`
+	Priority   int       `json:"priority"`
+	IsArchived bool      `json:"is_archived"`
+	Tags       []string  `json:"tags,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ExportPlanDTO 计划记录
+type ExportPlanDTO struct {
+	ID          int64     `json:"id"`
+	Code        string    `json:"code"`
+	PathID      int64     `json:"path_id"` // 引用 path.id
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Content     string    `json:"content"`
+	Status      string    `json:"status"`
+	Progress    int       `json:"progress"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ExportTodoDTO 待办记录（含标签）
+type ExportTodoDTO struct {
+	ID          int64      `json:"id"`
+	Code        string     `json:"code"`
+	PlanID      int64      `json:"plan_id"` // 引用 plan.id
+	PathID      int64      `json:"path_id"` // 引用 path.id
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Priority    int        `json:"priority"`
+	Status      int        `json:"status"`
+	SortOrder   int        `json:"sort_order"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// ExportStatsDTO 导出/导入的条目统计
+type ExportStatsDTO struct {
+	Paths      int `json:"paths"`
+	Groups     int `json:"groups"`
+	GroupPaths int `json:"group_paths"`
+	Memories   int `json:"memories"`
+	Plans      int `json:"plans"`
+	Todos      int `json:"todos"`
+}
+
+// ImportOptionsDTO 导入选项
+type ImportOptionsDTO struct {
+	Strategy string            // skip/overwrite/rename，默认 skip
+	PathMap  map[string]string // 路径前缀映射：导出时的绝对路径 -> 本机绝对路径
+}
+
+// ImportResultDTO 导入结果
+type ImportResultDTO struct {
+	Imported    ExportStatsDTO `json:"imported"`
+	Skipped     int            `json:"skipped"`
+	Overwritten int            `json:"overwritten"`
+	Renamed     []string       `json:"renamed,omitempty"`  // "旧code -> 新code"
+	Matched     []string       `json:"matched,omitempty"`  // 按项目标识匹配到本机的路径 "导出方路径 -> 本机路径 (项目标识)"
+	Warnings    []string       `json:"warnings,omitempty"` // 无法导入的条目说明
+}
+
+// VaultExportResultDTO Markdown 仓库导出结果
+type VaultExportResultDTO struct {
+	Written   int `json:"written"`   // 新建或内容变化后重写的文件数
+	Unchanged int `json:"unchanged"` // 内容未变化而跳过的文件数
+	Removed   int `json:"removed"`   // 因记录删除而移除的文件数
+}
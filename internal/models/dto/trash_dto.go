@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+// 回收站条目类型
+const (
+	TrashTypeMemory = "memory"
+	TrashTypePlan   = "plan"
+	TrashTypeTodo   = "todo"
+)
+
+// TrashItemDTO 回收站条目
+type TrashItemDTO struct {
+	Type      string    `json:"type"` // memory/plan/todo
+	ID        int64     `json:"id"`
+	Code      string    `json:"code"`
+	Title     string    `json:"title"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// TrashPurgeResultDTO 彻底清理结果
+type TrashPurgeResultDTO struct {
+	Memories int64 `json:"memories"`
+	Plans    int64 `json:"plans"`
+	Todos    int64 `json:"todos"`
+}
+
+// Total 清理总数
+func (r *TrashPurgeResultDTO) Total() int64 {
+	return r.Memories + r.Plans + r.Todos
+}
@@ -1,24 +1,29 @@
 package entity
 
 import (
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Memory 记忆实体（数据表结构）
 // 记忆条目，用于持久化存储重要信息
 // 纯关联模式：PathID=0 表示 Global，PathID>0 关联 PersonalPath
 type Memory struct {
-	ID         int64     `gorm:"primaryKey"`                                       // 雪花算法生成
-	Code       string    `gorm:"uniqueIndex;size:100;not null;comment:人类可读的唯一标识码"` // 外部查询标识，全局唯一
-	Global     bool      `gorm:"index;default:false;comment:是否全局可见"`               // true=全局，false=项目/小组
-	PathID     int64     `gorm:"index;default:0;comment:关联路径ID(0=无绑定/全局)"`         // 关联 Path.ID，0 表示未绑定
-	Title      string    `gorm:"index;size:255;not null;comment:标题"`
-	Content    string    `gorm:"type:text;not null;comment:内容"`
-	Category   string    `gorm:"index;size:100;default:'默认';comment:分类"`
-	Priority   int       `gorm:"default:1;comment:优先级 1-4"`
-	IsArchived bool      `gorm:"index;default:false;comment:是否归档"`
-	CreatedAt  time.Time `gorm:"index;autoCreateTime"`
-	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+	ID         int64          `gorm:"primaryKey"`                                       // 雪花算法生成
+	Code       string         `gorm:"uniqueIndex;size:100;not null;comment:人类可读的唯一标识码"` // 外部查询标识，全局唯一
+	Global     bool           `gorm:"index;default:false;comment:是否全局可见"`               // true=全局，false=项目/小组
+	PathID     int64          `gorm:"index;default:0;comment:关联路径ID(0=无绑定/全局)"`         // 关联 Path.ID，0 表示未绑定
+	Title      string         `gorm:"index;size:255;not null;comment:标题"`
+	Content    string         `gorm:"type:text;not null;serializer:encrypted;comment:内容"`
+	Category   string         `gorm:"index;size:100;default:'默认';comment:分类"`
+	Priority   int            `gorm:"default:1;comment:优先级 1-4"`
+	IsArchived bool           `gorm:"index;default:false;comment:是否归档"`
+	Version    int64          `gorm:"not null;default:1;comment:乐观锁版本号"` // 每次更新 +1，更新时校验读取时的版本
+	CreatedAt  time.Time      `gorm:"index;autoCreateTime"`
+	UpdatedAt  time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt  gorm.DeletedAt `gorm:"index;comment:删除时间(回收站)"`
 
 	// 关联：标签
 	Tags []MemoryTag `gorm:"foreignKey:MemoryID;constraint:OnDelete:CASCADE"`
@@ -42,6 +47,57 @@ func (MemoryTag) TableName() string {
 	return "memory_tags"
 }
 
+// MemoryEmbedding 记忆向量表
+// 存储记忆的语义向量，与 Memory 一一对应
+type MemoryEmbedding struct {
+	MemoryID    int64     `gorm:"primaryKey;autoIncrement:false"`   // 关联记忆ID
+	Model       string    `gorm:"size:255;not null;comment:嵌入模型标识"` // 模型变化时需要重新嵌入
+	Dimensions  int       `gorm:"not null;comment:向量维度"`            // 向量维度
+	Vector      []byte    `gorm:"not null;comment:小端序float32向量"`    // 编码后的向量（SQLite 为 blob，PostgreSQL 为 bytea）
+	ContentHash string    `gorm:"size:64;not null;comment:嵌入文本哈希"`  // 内容变化时需要重新嵌入
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (MemoryEmbedding) TableName() string {
+	return "memory_embeddings"
+}
+
+// MemoryRevision 记忆修订历史表
+// 每次修改记忆前保存旧值，便于对比和回滚
+type MemoryRevision struct {
+	ID        int64     `gorm:"primaryKey"`                                             // 雪花算法生成
+	MemoryID  int64     `gorm:"uniqueIndex:idx_memory_revision;not null"`               // 关联记忆ID
+	Revision  int       `gorm:"uniqueIndex:idx_memory_revision;not null"`               // 修订号（每条记忆从 1 递增）
+	Title     string    `gorm:"size:255;not null;comment:修改前的标题"`                       // 修改前的标题
+	Content   string    `gorm:"type:text;not null;serializer:encrypted;comment:修改前的内容"` // 修改前的内容
+	Category  string    `gorm:"size:100;comment:修改前的分类"`                                // 修改前的分类
+	Priority  int       `gorm:"default:1;comment:修改前的优先级"`                              // 修改前的优先级
+	Tags      string    `gorm:"type:text;comment:修改前的标签(逗号分隔)"`                         // 修改前的标签
+	Action    string    `gorm:"size:20;not null;comment:变更类型(update/revert)"`           // 变更类型
+	Source    string    `gorm:"size:20;not null;comment:变更来源(cli/mcp/tui)"`             // 变更来源
+	CreatedAt time.Time `gorm:"index;autoCreateTime"`                                   // 变更时间
+}
+
+// TableName 指定表名
+func (MemoryRevision) TableName() string {
+	return "memory_revisions"
+}
+
+// 修订变更类型
+const (
+	MemoryRevisionActionUpdate = "update" // 普通更新
+	MemoryRevisionActionRevert = "revert" // 回滚到历史版本
+)
+
+// GetTagStrings 获取修订中的标签列表
+func (r *MemoryRevision) GetTagStrings() []string {
+	if r.Tags == "" {
+		return []string{}
+	}
+	return strings.Split(r.Tags, ",")
+}
+
 // MemoryPriority 记忆优先级常量
 // 统一的优先级定义
 const (
@@ -6,9 +6,12 @@ import (
 
 // PersonalPath 个人路径索引实体
 // 记录用户访问过的路径，用于自动创建 Personal 作用域索引
+// ProjectID 由 git 远程地址生成，同一仓库在不同机器、不同位置检出时相同，
+// 用于在绝对路径不同时匹配同一个项目（不在 git 仓库中时为空）
 type PersonalPath struct {
 	ID        int64     `gorm:"primaryKey"`                     // 雪花算法生成
 	Path      string    `gorm:"uniqueIndex;size:1024;not null"` // 路径（全局唯一）
+	ProjectID string    `gorm:"index;size:1024"`                // 项目标识（如 github.com/owner/repo）
 	LastVisit time.Time `gorm:"index"`                          // 最后访问时间
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime"`
@@ -2,6 +2,8 @@ package entity
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // PlanStatus 计划状态类型
@@ -21,16 +23,18 @@ const (
 // 用于跟踪长期目标和复杂任务的计划实体
 // PathID 关联 PersonalPath 或 Group 中的路径
 type Plan struct {
-	ID          int64      `gorm:"primaryKey"`                                 // 雪花算法生成
-	Code        string     `gorm:"index;size:100;not null;comment:人类可读的唯一标识码"` // 外部查询标识，活跃状态唯一
-	PathID      int64      `gorm:"index;not null;comment:路径ID（关联个人或小组路径）"`     // 关联 Path.ID
-	Title       string     `gorm:"index;size:255;not null;comment:标题"`
-	Description string     `gorm:"type:text;not null;comment:简要描述（摘要）"`
-	Content     string     `gorm:"type:text;not null;comment:详细内容"`
-	Status      PlanStatus `gorm:"index;size:20;default:'pending'"`
-	Progress    int        `gorm:"default:0;comment:进度 0-100"`
-	CreatedAt   time.Time  `gorm:"index;autoCreateTime"`
-	UpdatedAt   time.Time  `gorm:"autoUpdateTime"`
+	ID          int64          `gorm:"primaryKey"`                                 // 雪花算法生成
+	Code        string         `gorm:"index;size:100;not null;comment:人类可读的唯一标识码"` // 外部查询标识，活跃状态唯一
+	PathID      int64          `gorm:"index;not null;comment:路径ID（关联个人或小组路径）"`     // 关联 Path.ID
+	Title       string         `gorm:"index;size:255;not null;comment:标题"`
+	Description string         `gorm:"type:text;not null;comment:简要描述（摘要）"`
+	Content     string         `gorm:"type:text;not null;serializer:encrypted;comment:详细内容"`
+	Status      PlanStatus     `gorm:"index;size:20;default:'pending'"`
+	Progress    int            `gorm:"default:0;comment:进度 0-100"`
+	Version     int64          `gorm:"not null;default:1;comment:乐观锁版本号"` // 每次更新 +1，更新时校验读取时的版本
+	CreatedAt   time.Time      `gorm:"index;autoCreateTime"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt `gorm:"index;comment:删除时间(回收站)"`
 
 	// 关联：待办事项（替代原 SubTasks）
 	Todos []ToDo `gorm:"foreignKey:PlanID;constraint:OnDelete:CASCADE"`
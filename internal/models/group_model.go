@@ -3,7 +3,6 @@ package models
 import (
 	"context"
 	"path/filepath"
-	"time"
 
 	"github.com/XiaoLFeng/llm-memory/internal/database"
 	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
@@ -106,20 +105,9 @@ func (m *GroupModel) AddPath(ctx context.Context, groupID int64, path string) er
 	}
 
 	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 1. 确保 PersonalPath 存在
-		var personalPath entity.PersonalPath
-		err := tx.Where("path = ?", absPath).First(&personalPath).Error
-		if err == gorm.ErrRecordNotFound {
-			// 不存在，创建新的 PersonalPath
-			personalPath = entity.PersonalPath{
-				ID:        database.GenerateID(),
-				Path:      absPath,
-				LastVisit: time.Now(),
-			}
-			if err := tx.Create(&personalPath).Error; err != nil {
-				return err
-			}
-		} else if err != nil {
+		// 1. 确保 PersonalPath 存在（按项目标识匹配旧路径，不改写它）
+		personalPath, err := ensurePersonalPath(tx, absPath, false)
+		if err != nil {
 			return err
 		}
 
@@ -236,6 +224,16 @@ func (m *GroupModel) GetPathIDByPath(ctx context.Context, path string) (int64, e
 	return personalPath.ID, nil
 }
 
+// FindPathByProjectID 根据项目标识查找路径（同一项目有多个检出时取最近访问的）
+func (m *GroupModel) FindPathByProjectID(ctx context.Context, projectID string) (*entity.PersonalPath, error) {
+	var personalPath entity.PersonalPath
+	err := m.db.WithContext(ctx).Where("project_id = ?", projectID).Order("last_visit DESC").First(&personalPath).Error
+	if err != nil {
+		return nil, err
+	}
+	return &personalPath, nil
+}
+
 // GetPathIDsByGroupID 获取组下所有路径 ID
 // 嘿嘿~ 用于 Scope 查询时获取组内所有路径！💖
 func (m *GroupModel) GetPathIDsByGroupID(ctx context.Context, groupID int64) ([]int64, error) {
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MemoryEmbeddingModel 记忆向量数据访问层
+type MemoryEmbeddingModel struct {
+	db *gorm.DB
+}
+
+// NewMemoryEmbeddingModel 创建 MemoryEmbeddingModel 实例
+func NewMemoryEmbeddingModel(db *gorm.DB) *MemoryEmbeddingModel {
+	return &MemoryEmbeddingModel{db: db}
+}
+
+// Upsert 保存记忆向量（已存在则覆盖）
+func (m *MemoryEmbeddingModel) Upsert(ctx context.Context, embedding *entity.MemoryEmbedding) error {
+	return m.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "memory_id"}},
+		UpdateAll: true,
+	}).Create(embedding).Error
+}
+
+// DeleteByMemoryID 删除记忆向量
+func (m *MemoryEmbeddingModel) DeleteByMemoryID(ctx context.Context, memoryID int64) error {
+	return m.db.WithContext(ctx).Where("memory_id = ?", memoryID).Delete(&entity.MemoryEmbedding{}).Error
+}
+
+// FindByMemoryID 根据记忆 ID 查找向量
+func (m *MemoryEmbeddingModel) FindByMemoryID(ctx context.Context, memoryID int64) (*entity.MemoryEmbedding, error) {
+	var embedding entity.MemoryEmbedding
+	err := m.db.WithContext(ctx).Where("memory_id = ?", memoryID).First(&embedding).Error
+	if err != nil {
+		return nil, err
+	}
+	return &embedding, nil
+}
+
+// FindByMemoryIDs 批量查找向量，返回 memoryID -> 向量 的映射
+func (m *MemoryEmbeddingModel) FindByMemoryIDs(ctx context.Context, memoryIDs []int64) (map[int64]entity.MemoryEmbedding, error) {
+	result := make(map[int64]entity.MemoryEmbedding, len(memoryIDs))
+	if len(memoryIDs) == 0 {
+		return result, nil
+	}
+	var embeddings []entity.MemoryEmbedding
+	if err := m.db.WithContext(ctx).Where("memory_id IN ?", memoryIDs).Find(&embeddings).Error; err != nil {
+		return nil, err
+	}
+	for _, e := range embeddings {
+		result[e.MemoryID] = e
+	}
+	return result, nil
+}
@@ -2,6 +2,9 @@ package models
 
 import (
 	"context"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/XiaoLFeng/llm-memory/internal/database"
 	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
@@ -18,29 +21,75 @@ func NewMemoryModel(db *gorm.DB) *MemoryModel {
 	return &MemoryModel{db: db}
 }
 
-// Create 创建记忆
+// MemorySearchHit 全文检索命中结果
+type MemorySearchHit struct {
+	Memory  entity.Memory
+	Score   float64 // bm25 得分（越小越相关）
+	Snippet string  // 命中位置附近的高亮摘要
+}
+
+// MemorySemanticHit 语义检索命中结果
+type MemorySemanticHit struct {
+	Memory     entity.Memory
+	Similarity float64 // 余弦相似度（越大越相关）
+}
+
+// Create 创建记忆（同步全文索引）
 func (m *MemoryModel) Create(ctx context.Context, memory *entity.Memory) error {
 	memory.ID = database.GenerateID()
-	return m.db.WithContext(ctx).Create(memory).Error
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(memory).Error; err != nil {
+			return err
+		}
+		return database.SyncMemoryFTS(tx, memory.ID)
+	})
 }
 
-// Update 更新记忆
+// Update 更新记忆（同步全文索引）
+// 按读取时的版本条件更新，记录已被他人修改时返回 VersionConflictError
 func (m *MemoryModel) Update(ctx context.Context, memory *entity.Memory) error {
-	return m.db.WithContext(ctx).Save(memory).Error
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := updateVersioned(tx, memory, memory.ID, &memory.Version, "记忆", memory.Code); err != nil {
+			return err
+		}
+		return database.SyncMemoryFTS(tx, memory.ID)
+	})
 }
 
-// Delete 删除记忆（硬删除）
+// Delete 删除记忆（软删除，移入回收站）
+// 标签、全文索引、向量和修订历史都会保留，恢复后即可继续使用~
 func (m *MemoryModel) Delete(ctx context.Context, id int64) error {
+	return m.db.WithContext(ctx).Delete(&entity.Memory{}, id).Error
+}
+
+// Purge 彻底删除记忆（硬删除，包括回收站中的记忆）
+func (m *MemoryModel) Purge(ctx context.Context, id int64) error {
 	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 先删除关联的标签
-		if err := tx.Where("memory_id = ?", id).Unscoped().Delete(&entity.MemoryTag{}).Error; err != nil {
-			return err
-		}
-		// 硬删除记忆本身
-		return tx.Unscoped().Delete(&entity.Memory{}, id).Error
+		return purgeMemory(tx, id)
 	})
 }
 
+// purgeMemory 在事务中硬删除记忆及其关联数据
+func purgeMemory(tx *gorm.DB, id int64) error {
+	// 先删除关联的标签
+	if err := tx.Where("memory_id = ?", id).Unscoped().Delete(&entity.MemoryTag{}).Error; err != nil {
+		return err
+	}
+	// 删除全文索引和语义向量
+	if err := database.DeleteMemoryFTS(tx, id); err != nil {
+		return err
+	}
+	if err := tx.Where("memory_id = ?", id).Delete(&entity.MemoryEmbedding{}).Error; err != nil {
+		return err
+	}
+	// 删除修订历史
+	if err := tx.Where("memory_id = ?", id).Delete(&entity.MemoryRevision{}).Error; err != nil {
+		return err
+	}
+	// 硬删除记忆本身
+	return tx.Unscoped().Delete(&entity.Memory{}, id).Error
+}
+
 // FindByID 根据 ID 查找记忆
 func (m *MemoryModel) FindByID(ctx context.Context, id int64) (*entity.Memory, error) {
 	var memory entity.Memory
@@ -127,27 +176,130 @@ func (m *MemoryModel) SearchByScope(ctx context.Context, keyword string, pathID
 	return m.SearchByFilter(ctx, keyword, filter)
 }
 
-// SearchByFilter 统一过滤器搜索
+// SearchByFilter 统一过滤器搜索（按相关度排序）
 func (m *MemoryModel) SearchByFilter(ctx context.Context, keyword string, filter VisibilityFilter) ([]entity.Memory, error) {
+	hits, err := m.SearchRankedByFilter(ctx, keyword, filter, 0)
+	if err != nil {
+		return nil, err
+	}
+	memories := make([]entity.Memory, len(hits))
+	for i := range hits {
+		memories[i] = hits[i].Memory
+	}
+	return memories, nil
+}
+
+// searchSnippetWidth 搜索摘要的字符宽度
+const searchSnippetWidth = 48
+
+// SearchRankedByFilter 基于 FTS5 的全文检索
+// 中日韩文本按二元组分词，结果按 bm25 相关度排序并附带高亮摘要，limit <= 0 表示不限制数量
+func (m *MemoryModel) SearchRankedByFilter(ctx context.Context, keyword string, filter VisibilityFilter, limit int) ([]MemorySearchHit, error) {
+	// 内容加密后索引中没有内容列，PostgreSQL 没有 FTS5 索引，都改为在进程内检索
+	if database.ContentEncrypted() || !database.SupportsFTS(m.db) {
+		return m.searchInProcessByFilter(ctx, keyword, filter, limit)
+	}
+
+	match := database.BuildFTSMatchQuery(keyword)
+	if match == "" {
+		return []MemorySearchHit{}, nil
+	}
+
+	type ftsRow struct {
+		ID    int64
+		Score float64
+	}
+
+	// bm25 列权重：title > tags > category > content
+	fts := database.MemoryFTSTable
+	query := m.db.WithContext(ctx).Table(fts).
+		Select("memories.id AS id, bm25("+fts+", 10.0, 1.0, 2.0, 5.0) AS score").
+		Joins("JOIN memories ON memories.id = "+fts+".rowid").
+		Where(fts+" MATCH ?", match)
+	query = applyVisibilityFilter(query, filter).
+		Where("memories.is_archived = ? AND memories.deleted_at IS NULL", false).
+		Order("score ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var rows []ftsRow
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []MemorySearchHit{}, nil
+	}
+
+	// 批量加载记忆实体
+	ids := make([]int64, len(rows))
+	for i, r := range rows {
+		ids[i] = r.ID
+	}
 	var memories []entity.Memory
-	pattern := "%" + keyword + "%"
-	err := applyVisibilityFilter(
-		m.db.WithContext(ctx).Preload("Tags").
-			Where("title LIKE ? OR content LIKE ?", pattern, pattern),
-		filter,
-	).Where("is_archived = ?", false).
-		Order("created_at DESC").Find(&memories).Error
-	return memories, err
+	if err := m.db.WithContext(ctx).Preload("Tags").Where("id IN ?", ids).Find(&memories).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]entity.Memory, len(memories))
+	for _, mem := range memories {
+		byID[mem.ID] = mem
+	}
+
+	// 按相关度顺序组装结果
+	hits := make([]MemorySearchHit, 0, len(rows))
+	for _, r := range rows {
+		mem, ok := byID[r.ID]
+		if !ok {
+			continue
+		}
+		hits = append(hits, MemorySearchHit{
+			Memory: mem,
+			Score:  r.Score,
+			Snippet: database.BuildSearchSnippet(keyword, searchSnippetWidth,
+				mem.Content, mem.Title, mem.Category, strings.Join(mem.GetTagStrings(), " ")),
+		})
+	}
+	return hits, nil
+}
+
+// searchInProcessByFilter 在进程内检索（解密后的）记忆
+// 加载作用域内的全部记忆逐条匹配，字段权重与全文检索一致：title > tags > category > content
+func (m *MemoryModel) searchInProcessByFilter(ctx context.Context, keyword string, filter VisibilityFilter, limit int) ([]MemorySearchHit, error) {
+	memories, err := m.FindByFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := []float64{10.0, 5.0, 2.0, 1.0}
+	hits := make([]MemorySearchHit, 0)
+	for _, mem := range memories {
+		tags := strings.Join(mem.GetTagStrings(), " ")
+		score, ok := database.ScoreKeyword(keyword, weights, mem.Title, tags, mem.Category, mem.Content)
+		if !ok {
+			continue
+		}
+		hits = append(hits, MemorySearchHit{
+			Memory:  mem,
+			Score:   score,
+			Snippet: database.BuildSearchSnippet(keyword, searchSnippetWidth, mem.Content, mem.Title, mem.Category, tags),
+		})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score < hits[j].Score })
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
 }
 
 // Archive 归档记忆
 func (m *MemoryModel) Archive(ctx context.Context, id int64) error {
-	return m.db.WithContext(ctx).Model(&entity.Memory{}).Where("id = ?", id).Update("is_archived", true).Error
+	return m.db.WithContext(ctx).Model(&entity.Memory{}).Where("id = ?", id).Updates(bumpVersion(map[string]interface{}{"is_archived": true})).Error
 }
 
 // Unarchive 取消归档记忆
 func (m *MemoryModel) Unarchive(ctx context.Context, id int64) error {
-	return m.db.WithContext(ctx).Model(&entity.Memory{}).Where("id = ?", id).Update("is_archived", false).Error
+	return m.db.WithContext(ctx).Model(&entity.Memory{}).Where("id = ?", id).Updates(bumpVersion(map[string]interface{}{"is_archived": false})).Error
 }
 
 // UpdateTags 更新记忆标签
@@ -169,7 +321,8 @@ func (m *MemoryModel) UpdateTags(ctx context.Context, memoryID int64, tags []str
 				return err
 			}
 		}
-		return nil
+		// 标签变化后同步全文索引
+		return database.SyncMemoryFTS(tx, memoryID)
 	})
 }
 
@@ -179,3 +332,60 @@ func (m *MemoryModel) Count(ctx context.Context) (int64, error) {
 	err := m.db.WithContext(ctx).Model(&entity.Memory{}).Count(&count).Error
 	return count, err
 }
+
+// FindDeletedByFilter 查询回收站中的记忆（按删除时间倒序）
+func (m *MemoryModel) FindDeletedByFilter(ctx context.Context, filter VisibilityFilter) ([]entity.Memory, error) {
+	var memories []entity.Memory
+	err := applyVisibilityFilter(m.db.WithContext(ctx).Unscoped().Preload("Tags"), filter).
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&memories).Error
+	return memories, err
+}
+
+// FindDeletedByCode 根据 code 查找回收站中的记忆
+func (m *MemoryModel) FindDeletedByCode(ctx context.Context, code string) (*entity.Memory, error) {
+	var memory entity.Memory
+	err := m.db.WithContext(ctx).Unscoped().
+		Where("code = ? AND deleted_at IS NOT NULL", code).
+		First(&memory).Error
+	if err != nil {
+		return nil, err
+	}
+	return &memory, nil
+}
+
+// Restore 从回收站恢复记忆
+// 同时重新同步全文索引，防止在回收站期间索引行丢失后恢复的记忆搜不到
+func (m *MemoryModel) Restore(ctx context.Context, id int64) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&entity.Memory{}).
+			Where("id = ?", id).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return database.SyncMemoryFTS(tx, id)
+	})
+}
+
+// PurgeDeletedBefore 彻底删除在 before 之前移入回收站的记忆
+// 返回删除的数量
+func (m *MemoryModel) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		if err := tx.Unscoped().Model(&entity.Memory{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := purgeMemory(tx, id); err != nil {
+				return err
+			}
+		}
+		purged = int64(len(ids))
+		return nil
+	})
+	return purged, err
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/encryption"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+)
+
+// seedSearchMemories 创建搜索测试用的全局记忆
+func seedSearchMemories(t *testing.T, model *MemoryModel) {
+	t.Helper()
+	ctx := context.Background()
+	seeds := []struct {
+		code, title, content string
+		tags                 []string
+		archived, trashed    bool
+	}{
+		{code: "mem-title", title: "WAL 模式", content: "无关的内容"},
+		{code: "mem-content", title: "其他", content: "启用 wal 提升并发写入性能"},
+		{code: "mem-tag", title: "杂项", content: "随便记点什么", tags: []string{"wal"}},
+		{code: "mem-archived", title: "WAL 归档", content: "已归档", archived: true},
+		{code: "mem-trashed", title: "WAL 回收站", content: "已删除", trashed: true},
+		{code: "mem-db", title: "数据库备份", content: "定期执行数据库备份"},
+		{code: "mem-cjk", title: "笔记", content: "关于数据结构的整理"},
+	}
+	for _, s := range seeds {
+		memory := &entity.Memory{Code: s.code, Global: true, Title: s.title, Content: s.content, IsArchived: s.archived, Version: 1}
+		for _, tag := range s.tags {
+			memory.Tags = append(memory.Tags, entity.MemoryTag{ID: database.GenerateID(), Tag: tag})
+		}
+		if err := model.Create(ctx, memory); err != nil {
+			t.Fatalf("创建记忆 %s 失败: %v", s.code, err)
+		}
+		if s.trashed {
+			if err := model.Delete(ctx, memory.ID); err != nil {
+				t.Fatalf("删除记忆 %s 失败: %v", s.code, err)
+			}
+		}
+	}
+}
+
+// TestSearchRanked 全文检索按 title > tags > category > content 的权重排序，
+// 内容加密后改为进程内检索，结果和顺序保持一致
+func TestSearchRanked(t *testing.T) {
+	cases := []struct {
+		keyword     string
+		want        []string
+		wantSnippet string // 第一条结果的摘要应包含的片段
+	}{
+		{keyword: "wal", want: []string{"mem-title", "mem-tag", "mem-content"}, wantSnippet: "【WAL】"},
+		{keyword: "WA", want: []string{"mem-title", "mem-tag", "mem-content"}},
+		{keyword: "wal 并发", want: []string{"mem-content"}, wantSnippet: "【wal】 提升【并发】"},
+		{keyword: "数据库", want: []string{"mem-db"}, wantSnippet: "定期执行【数据库】备份"},
+		{keyword: "数据", want: []string{"mem-db", "mem-cjk"}},
+		{keyword: "库", want: []string{"mem-db"}},
+		{keyword: "不存在", want: []string{}},
+		{keyword: "!!!", want: []string{}},
+	}
+	modes := []struct {
+		name      string
+		encrypted bool
+	}{
+		{name: "fts"},
+		{name: "加密后进程内检索", encrypted: true},
+	}
+	for _, mode := range modes {
+		t.Run(mode.name, func(t *testing.T) {
+			db := openPathTestDB(t)
+			if mode.encrypted {
+				_, c, err := encryption.NewConfig(filepath.Join(t.TempDir(), "key"), "")
+				if err != nil {
+					t.Fatalf("创建加解密器失败: %v", err)
+				}
+				database.SetContentCipher(c)
+				t.Cleanup(func() { database.SetContentCipher(nil) })
+			}
+			model := NewMemoryModel(db)
+			seedSearchMemories(t, model)
+
+			for _, tc := range cases {
+				hits, err := model.SearchRankedByFilter(context.Background(), tc.keyword, DefaultVisibilityFilter(), 0)
+				if err != nil {
+					t.Fatalf("搜索 %q 失败: %v", tc.keyword, err)
+				}
+				got := make([]string, len(hits))
+				for i, hit := range hits {
+					got[i] = hit.Memory.Code
+				}
+				if !reflect.DeepEqual(got, tc.want) {
+					t.Fatalf("搜索 %q = %v，期望 %v", tc.keyword, got, tc.want)
+				}
+				if tc.wantSnippet != "" && !strings.Contains(hits[0].Snippet, tc.wantSnippet) {
+					t.Fatalf("搜索 %q 的摘要 = %q，期望包含 %q", tc.keyword, hits[0].Snippet, tc.wantSnippet)
+				}
+			}
+
+			limited, err := model.SearchRankedByFilter(context.Background(), "wal", DefaultVisibilityFilter(), 1)
+			if err != nil || len(limited) != 1 || limited[0].Memory.Code != "mem-title" {
+				t.Fatalf("limit=1 应只返回最相关的一条: %+v（%v）", limited, err)
+			}
+		})
+	}
+}
+
+// TestSearchIndexFollowsUpdates 更新、删除、恢复记忆后索引随之变化
+func TestSearchIndexFollowsUpdates(t *testing.T) {
+	ctx := context.Background()
+	model := NewMemoryModel(openPathTestDB(t))
+	search := func(keyword string) []string {
+		t.Helper()
+		memories, err := model.Search(ctx, keyword)
+		if err != nil {
+			t.Fatalf("搜索 %q 失败: %v", keyword, err)
+		}
+		codes := make([]string, len(memories))
+		for i, m := range memories {
+			codes[i] = m.Code
+		}
+		return codes
+	}
+
+	memory := &entity.Memory{Code: "mem", Global: true, Title: "旧标题", Content: "独角兽", Version: 1}
+	if err := model.Create(ctx, memory); err != nil {
+		t.Fatalf("创建记忆失败: %v", err)
+	}
+	memory.Content = "彩虹"
+	if err := model.Update(ctx, memory); err != nil {
+		t.Fatalf("更新记忆失败: %v", err)
+	}
+	if got := search("独角兽"); len(got) != 0 {
+		t.Fatalf("旧内容不应再被搜到: %v", got)
+	}
+	if got := search("彩虹"); !reflect.DeepEqual(got, []string{"mem"}) {
+		t.Fatalf("新内容应被搜到: %v", got)
+	}
+
+	if err := model.Delete(ctx, memory.ID); err != nil {
+		t.Fatalf("删除记忆失败: %v", err)
+	}
+	if got := search("彩虹"); len(got) != 0 {
+		t.Fatalf("回收站中的记忆不应被搜到: %v", got)
+	}
+	if err := model.Restore(ctx, memory.ID); err != nil {
+		t.Fatalf("恢复记忆失败: %v", err)
+	}
+	if got := search("彩虹"); !reflect.DeepEqual(got, []string{"mem"}) {
+		t.Fatalf("恢复后应被搜到: %v", got)
+	}
+}
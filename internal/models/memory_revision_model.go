@@ -0,0 +1,57 @@
+package models
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"gorm.io/gorm"
+)
+
+// MemoryRevisionModel 记忆修订历史数据访问层
+type MemoryRevisionModel struct {
+	db *gorm.DB
+}
+
+// NewMemoryRevisionModel 创建 MemoryRevisionModel 实例
+func NewMemoryRevisionModel(db *gorm.DB) *MemoryRevisionModel {
+	return &MemoryRevisionModel{db: db}
+}
+
+// Create 创建修订记录（自动分配递增的修订号）
+func (m *MemoryRevisionModel) Create(ctx context.Context, revision *entity.MemoryRevision) error {
+	revision.ID = database.GenerateID()
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := tx.Model(&entity.MemoryRevision{}).
+			Where("memory_id = ?", revision.MemoryID).
+			Select("COALESCE(MAX(revision), 0)").
+			Scan(&latest).Error; err != nil {
+			return err
+		}
+		revision.Revision = latest + 1
+		return tx.Create(revision).Error
+	})
+}
+
+// FindByMemoryID 查找记忆的所有修订（按修订号降序）
+func (m *MemoryRevisionModel) FindByMemoryID(ctx context.Context, memoryID int64) ([]entity.MemoryRevision, error) {
+	var revisions []entity.MemoryRevision
+	err := m.db.WithContext(ctx).
+		Where("memory_id = ?", memoryID).
+		Order("revision DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// FindByRevision 查找指定修订号的修订
+func (m *MemoryRevisionModel) FindByRevision(ctx context.Context, memoryID int64, revision int) (*entity.MemoryRevision, error) {
+	var rev entity.MemoryRevision
+	err := m.db.WithContext(ctx).
+		Where("memory_id = ? AND revision = ?", memoryID, revision).
+		First(&rev).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
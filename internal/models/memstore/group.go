@@ -0,0 +1,231 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"gorm.io/gorm"
+)
+
+// GroupRepository 组内存仓储
+type GroupRepository struct {
+	s *Store
+}
+
+// Create 创建组（组名唯一）
+func (r *GroupRepository) Create(ctx context.Context, group *entity.Group) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.findGroupByName(group.Name) != nil {
+		return gorm.ErrDuplicatedKey
+	}
+	group.ID = database.GenerateID()
+	stamp(&group.CreatedAt, &group.UpdatedAt)
+	copied := *group
+	copied.Paths = nil
+	r.s.groups[group.ID] = &copied
+	return nil
+}
+
+// Update 更新组
+func (r *GroupRepository) Update(ctx context.Context, group *entity.Group) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if g := r.s.findGroupByName(group.Name); g != nil && g.ID != group.ID {
+		return gorm.ErrDuplicatedKey
+	}
+	stamp(&group.CreatedAt, &group.UpdatedAt)
+	copied := *group
+	copied.Paths = nil
+	r.s.groups[group.ID] = &copied
+	return nil
+}
+
+// Delete 删除组（硬删除，同时删除路径映射）
+func (r *GroupRepository) Delete(ctx context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for gpID, gp := range r.s.groupPaths {
+		if gp.GroupID == id {
+			delete(r.s.groupPaths, gpID)
+		}
+	}
+	delete(r.s.groups, id)
+	return nil
+}
+
+// FindByID 根据 ID 查找组
+func (r *GroupRepository) FindByID(ctx context.Context, id int64) (*entity.Group, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if g, ok := r.s.groups[id]; ok {
+		return r.s.loadGroup(g), nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// FindByName 根据名称查找组
+func (r *GroupRepository) FindByName(ctx context.Context, name string) (*entity.Group, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if g := r.s.findGroupByName(name); g != nil {
+		return r.s.loadGroup(g), nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// FindByPath 根据路径查找所属组
+func (r *GroupRepository) FindByPath(ctx context.Context, path string) (*entity.Group, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	p := r.s.findPath(absPath(path))
+	if p == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	gp := r.s.findGroupPath(p.ID)
+	if gp == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	g, ok := r.s.groups[gp.GroupID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.s.loadGroup(g), nil
+}
+
+// FindAll 查找所有组（按创建时间倒序）
+func (r *GroupRepository) FindAll(ctx context.Context) ([]entity.Group, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	groups := make([]entity.Group, 0, len(r.s.groups))
+	for _, g := range r.s.groups {
+		groups = append(groups, *r.s.loadGroup(g))
+	}
+	sortByCreatedDesc(groups, func(g entity.Group) (time.Time, int64) { return g.CreatedAt, g.ID })
+	return groups, nil
+}
+
+// AddPath 添加路径到组
+// 路径不存在时自动创建；路径已属于其他组时返回 gorm.ErrDuplicatedKey
+func (r *GroupRepository) AddPath(ctx context.Context, groupID int64, path string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	p := r.s.ensurePath(absPath(path))
+	if gp := r.s.findGroupPath(p.ID); gp != nil {
+		if gp.GroupID == groupID {
+			return nil
+		}
+		return gorm.ErrDuplicatedKey
+	}
+	gp := &entity.GroupPath{
+		ID:             database.GenerateID(),
+		GroupID:        groupID,
+		PersonalPathID: p.ID,
+	}
+	r.s.groupPaths[gp.ID] = gp
+	return nil
+}
+
+// RemovePath 从组移除路径
+func (r *GroupRepository) RemovePath(ctx context.Context, groupID int64, path string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	p := r.s.findPath(absPath(path))
+	if p == nil {
+		return gorm.ErrRecordNotFound
+	}
+	for id, gp := range r.s.groupPaths {
+		if gp.GroupID == groupID && gp.PersonalPathID == p.ID {
+			delete(r.s.groupPaths, id)
+		}
+	}
+	return nil
+}
+
+// GetPathIDByPath 根据路径字符串获取 PersonalPath ID
+func (r *GroupRepository) GetPathIDByPath(ctx context.Context, path string) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if p := r.s.findPath(absPath(path)); p != nil {
+		return p.ID, nil
+	}
+	return 0, gorm.ErrRecordNotFound
+}
+
+// FindPathByProjectID 根据项目标识查找路径（同一项目有多个检出时取最近访问的）
+func (r *GroupRepository) FindPathByProjectID(ctx context.Context, projectID string) (*entity.PersonalPath, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	paths := r.s.findPathsByProjectID(projectID)
+	if len(paths) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *paths[0]
+	return &copied, nil
+}
+
+// GetPathIDsByGroupID 获取组下所有路径 ID
+func (r *GroupRepository) GetPathIDsByGroupID(ctx context.Context, groupID int64) ([]int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	pathIDs := make([]int64, 0)
+	for _, gp := range r.s.groupPaths {
+		if gp.GroupID == groupID {
+			pathIDs = append(pathIDs, gp.PersonalPathID)
+		}
+	}
+	return pathIDs, nil
+}
+
+// findGroupByName 按名称查找组（调用方需持有锁）
+func (s *Store) findGroupByName(name string) *entity.Group {
+	for _, g := range s.groups {
+		if g.Name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// findGroupPath 查找路径的组映射（调用方需持有锁）
+func (s *Store) findGroupPath(personalPathID int64) *entity.GroupPath {
+	for _, gp := range s.groupPaths {
+		if gp.PersonalPathID == personalPathID {
+			return gp
+		}
+	}
+	return nil
+}
+
+// loadGroup 复制组并预加载路径映射（调用方需持有锁）
+func (s *Store) loadGroup(g *entity.Group) *entity.Group {
+	copied := *g
+	copied.Paths = make([]entity.GroupPath, 0)
+	for _, gp := range s.groupPaths {
+		if gp.GroupID != g.ID {
+			continue
+		}
+		loaded := *gp
+		if p, ok := s.paths[gp.PersonalPathID]; ok {
+			loaded.PersonalPath = *p
+		}
+		copied.Paths = append(copied.Paths, loaded)
+	}
+	sort.Slice(copied.Paths, func(i, j int) bool { return copied.Paths[i].ID < copied.Paths[j].ID })
+	return &copied
+}
@@ -0,0 +1,342 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"gorm.io/gorm"
+)
+
+// searchSnippetWidth 搜索摘要的字符宽度（与 GORM 实现一致）
+const searchSnippetWidth = 48
+
+// MemoryRepository 记忆内存仓储
+type MemoryRepository struct {
+	s *Store
+}
+
+// Create 创建记忆（code 全局唯一，包括回收站中的记忆）
+func (r *MemoryRepository) Create(ctx context.Context, memory *entity.Memory) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, m := range r.s.memories {
+		if m.Code == memory.Code {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	memory.ID = database.GenerateID()
+	memory.Version = 1
+	stamp(&memory.CreatedAt, &memory.UpdatedAt)
+	for i := range memory.Tags {
+		memory.Tags[i].ID = database.GenerateID()
+		memory.Tags[i].MemoryID = memory.ID
+	}
+	r.s.memories[memory.ID] = cloneMemory(memory)
+	return nil
+}
+
+// Update 更新记忆（标签通过 UpdateTags 单独维护，按读取时的版本条件更新）
+func (r *MemoryRepository) Update(ctx context.Context, memory *entity.Memory) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	old, ok := r.s.memories[memory.ID]
+	if !ok || old.DeletedAt.Valid {
+		return gorm.ErrRecordNotFound
+	}
+	for _, m := range r.s.memories {
+		if m.Code == memory.Code && m.ID != memory.ID {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	version, err := nextVersion("记忆", memory.Code, old.Version, memory.Version)
+	if err != nil {
+		return err
+	}
+	memory.Version = version
+	stamp(&memory.CreatedAt, &memory.UpdatedAt)
+	stored := cloneMemory(memory)
+	stored.Tags = old.Tags
+	r.s.memories[memory.ID] = stored
+	return nil
+}
+
+// Delete 删除记忆（软删除，移入回收站）
+func (r *MemoryRepository) Delete(ctx context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if m, ok := r.s.memories[id]; ok && !m.DeletedAt.Valid {
+		m.DeletedAt = softDelete(time.Now())
+	}
+	return nil
+}
+
+// FindByID 根据 ID 查找记忆
+func (r *MemoryRepository) FindByID(ctx context.Context, id int64) (*entity.Memory, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if m, ok := r.s.memories[id]; ok && !m.DeletedAt.Valid {
+		return cloneMemory(m), nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// FindByCode 根据 code 查找记忆（排除已归档）
+func (r *MemoryRepository) FindByCode(ctx context.Context, code string) (*entity.Memory, error) {
+	return r.findOne(func(m *entity.Memory) bool {
+		return !m.DeletedAt.Valid && m.Code == code && !m.IsArchived
+	})
+}
+
+// FindDeletedByCode 根据 code 查找回收站中的记忆
+func (r *MemoryRepository) FindDeletedByCode(ctx context.Context, code string) (*entity.Memory, error) {
+	return r.findOne(func(m *entity.Memory) bool {
+		return m.DeletedAt.Valid && m.Code == code
+	})
+}
+
+// ExistsCode 检查 code 是否已存在（不含回收站）
+func (r *MemoryRepository) ExistsCode(ctx context.Context, code string, excludeID int64) (bool, error) {
+	m, _ := r.findOne(func(m *entity.Memory) bool {
+		return !m.DeletedAt.Valid && m.Code == code && (excludeID <= 0 || m.ID != excludeID)
+	})
+	return m != nil, nil
+}
+
+// FindByCategory 根据分类查找记忆
+func (r *MemoryRepository) FindByCategory(ctx context.Context, category string) ([]entity.Memory, error) {
+	return r.findAll(models.DefaultVisibilityFilter(), func(m *entity.Memory) bool {
+		return m.Category == category
+	}), nil
+}
+
+// FindByFilter 根据统一过滤器查询记忆
+func (r *MemoryRepository) FindByFilter(ctx context.Context, filter models.VisibilityFilter) ([]entity.Memory, error) {
+	return r.findAll(filter, nil), nil
+}
+
+// SearchByFilter 统一过滤器搜索（按相关度排序）
+func (r *MemoryRepository) SearchByFilter(ctx context.Context, keyword string, filter models.VisibilityFilter) ([]entity.Memory, error) {
+	hits, err := r.SearchRankedByFilter(ctx, keyword, filter, 0)
+	if err != nil {
+		return nil, err
+	}
+	memories := make([]entity.Memory, len(hits))
+	for i := range hits {
+		memories[i] = hits[i].Memory
+	}
+	return memories, nil
+}
+
+// SearchRankedByFilter 逐条匹配的检索
+// 分词与字段权重和全文检索一致：title > tags > category > content
+func (r *MemoryRepository) SearchRankedByFilter(ctx context.Context, keyword string, filter models.VisibilityFilter, limit int) ([]models.MemorySearchHit, error) {
+	weights := []float64{10.0, 5.0, 2.0, 1.0}
+	hits := make([]models.MemorySearchHit, 0)
+	for _, mem := range r.findAll(filter, nil) {
+		tags := strings.Join(mem.GetTagStrings(), " ")
+		score, ok := database.ScoreKeyword(keyword, weights, mem.Title, tags, mem.Category, mem.Content)
+		if !ok {
+			continue
+		}
+		hits = append(hits, models.MemorySearchHit{
+			Memory:  mem,
+			Score:   score,
+			Snippet: database.BuildSearchSnippet(keyword, searchSnippetWidth, mem.Content, mem.Title, mem.Category, tags),
+		})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score < hits[j].Score })
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// Archive 归档记忆
+func (r *MemoryRepository) Archive(ctx context.Context, id int64) error {
+	return r.setArchived(id, true)
+}
+
+// Unarchive 取消归档记忆
+func (r *MemoryRepository) Unarchive(ctx context.Context, id int64) error {
+	return r.setArchived(id, false)
+}
+
+// UpdateTags 更新记忆标签
+func (r *MemoryRepository) UpdateTags(ctx context.Context, memoryID int64, tags []string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	m, ok := r.s.memories[memoryID]
+	if !ok {
+		return nil
+	}
+	m.Tags = make([]entity.MemoryTag, len(tags))
+	for i, tag := range tags {
+		m.Tags[i] = entity.MemoryTag{ID: database.GenerateID(), MemoryID: memoryID, Tag: tag}
+	}
+	return nil
+}
+
+// setArchived 设置归档状态
+func (r *MemoryRepository) setArchived(id int64, archived bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if m, ok := r.s.memories[id]; ok && !m.DeletedAt.Valid {
+		m.IsArchived = archived
+		m.Version++
+		m.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// findOne 查找 ID 最小的匹配记忆（对应 GORM 的 First）
+func (r *MemoryRepository) findOne(match func(*entity.Memory) bool) (*entity.Memory, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var found *entity.Memory
+	for _, m := range r.s.memories {
+		if match(m) && (found == nil || m.ID < found.ID) {
+			found = m
+		}
+	}
+	if found == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return cloneMemory(found), nil
+}
+
+// findAll 查询作用域内未归档的记忆（按创建时间倒序）
+func (r *MemoryRepository) findAll(filter models.VisibilityFilter, match func(*entity.Memory) bool) []entity.Memory {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	memories := make([]entity.Memory, 0)
+	for _, m := range r.s.memories {
+		if m.DeletedAt.Valid || m.IsArchived || !filter.Matches(m.Global, m.PathID) {
+			continue
+		}
+		if match != nil && !match(m) {
+			continue
+		}
+		memories = append(memories, *cloneMemory(m))
+	}
+	sortByCreatedDesc(memories, func(m entity.Memory) (time.Time, int64) { return m.CreatedAt, m.ID })
+	return memories
+}
+
+// cloneMemory 复制记忆，避免调用方修改存储中的数据
+func cloneMemory(m *entity.Memory) *entity.Memory {
+	copied := *m
+	copied.Tags = append([]entity.MemoryTag(nil), m.Tags...)
+	return &copied
+}
+
+// MemoryRevisionRepository 记忆修订历史内存仓储
+type MemoryRevisionRepository struct {
+	s *Store
+}
+
+// Create 创建修订记录（自动分配递增的修订号）
+func (r *MemoryRevisionRepository) Create(ctx context.Context, revision *entity.MemoryRevision) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	latest := 0
+	for _, rev := range r.s.revisions {
+		if rev.MemoryID == revision.MemoryID && rev.Revision > latest {
+			latest = rev.Revision
+		}
+	}
+	revision.ID = database.GenerateID()
+	revision.Revision = latest + 1
+	if revision.CreatedAt.IsZero() {
+		revision.CreatedAt = time.Now()
+	}
+	copied := *revision
+	r.s.revisions[revision.ID] = &copied
+	return nil
+}
+
+// FindByMemoryID 查找记忆的所有修订（按修订号降序）
+func (r *MemoryRevisionRepository) FindByMemoryID(ctx context.Context, memoryID int64) ([]entity.MemoryRevision, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	revisions := make([]entity.MemoryRevision, 0)
+	for _, rev := range r.s.revisions {
+		if rev.MemoryID == memoryID {
+			revisions = append(revisions, *rev)
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision > revisions[j].Revision })
+	return revisions, nil
+}
+
+// FindByRevision 查找指定修订号的修订
+func (r *MemoryRevisionRepository) FindByRevision(ctx context.Context, memoryID int64, revision int) (*entity.MemoryRevision, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, rev := range r.s.revisions {
+		if rev.MemoryID == memoryID && rev.Revision == revision {
+			copied := *rev
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// MemoryEmbeddingRepository 记忆向量内存仓储
+type MemoryEmbeddingRepository struct {
+	s *Store
+}
+
+// Upsert 保存记忆向量（已存在则覆盖）
+func (r *MemoryEmbeddingRepository) Upsert(ctx context.Context, embedding *entity.MemoryEmbedding) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	embedding.UpdatedAt = time.Now()
+	copied := *embedding
+	copied.Vector = append([]byte(nil), embedding.Vector...)
+	r.s.embeddings[embedding.MemoryID] = &copied
+	return nil
+}
+
+// FindByMemoryID 根据记忆 ID 查找向量
+func (r *MemoryEmbeddingRepository) FindByMemoryID(ctx context.Context, memoryID int64) (*entity.MemoryEmbedding, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if e, ok := r.s.embeddings[memoryID]; ok {
+		copied := *e
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// FindByMemoryIDs 批量查找向量，返回 memoryID -> 向量 的映射
+func (r *MemoryEmbeddingRepository) FindByMemoryIDs(ctx context.Context, memoryIDs []int64) (map[int64]entity.MemoryEmbedding, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	result := make(map[int64]entity.MemoryEmbedding, len(memoryIDs))
+	for _, id := range memoryIDs {
+		if e, ok := r.s.embeddings[id]; ok {
+			result[id] = *e
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,214 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"gorm.io/gorm"
+)
+
+// PlanRepository 计划内存仓储
+type PlanRepository struct {
+	s *Store
+}
+
+// Create 创建计划
+func (r *PlanRepository) Create(ctx context.Context, plan *entity.Plan) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	plan.ID = database.GenerateID()
+	plan.Version = 1
+	if plan.Status == "" {
+		plan.Status = entity.PlanStatusPending
+	}
+	stamp(&plan.CreatedAt, &plan.UpdatedAt)
+	r.s.savePlan(plan)
+	return nil
+}
+
+// Update 更新计划（按读取时的版本条件更新）
+func (r *PlanRepository) Update(ctx context.Context, plan *entity.Plan) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	old, ok := r.s.plans[plan.ID]
+	if !ok || old.DeletedAt.Valid {
+		return gorm.ErrRecordNotFound
+	}
+	version, err := nextVersion("计划", plan.Code, old.Version, plan.Version)
+	if err != nil {
+		return err
+	}
+	plan.Version = version
+	stamp(&plan.CreatedAt, &plan.UpdatedAt)
+	r.s.savePlan(plan)
+	return nil
+}
+
+// Delete 删除计划（软删除，关联的 Todo 以相同的删除时间一并移入回收站）
+func (r *PlanRepository) Delete(ctx context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	for _, t := range r.s.todos {
+		if t.PlanID == id && !t.DeletedAt.Valid {
+			t.DeletedAt = softDelete(now)
+		}
+	}
+	if p, ok := r.s.plans[id]; ok && !p.DeletedAt.Valid {
+		p.DeletedAt = softDelete(now)
+	}
+	return nil
+}
+
+// FindByID 根据 ID 查找计划
+func (r *PlanRepository) FindByID(ctx context.Context, id int64) (*entity.Plan, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if p, ok := r.s.plans[id]; ok && !p.DeletedAt.Valid {
+		return r.s.loadPlan(p), nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// FindByCode 根据 code 查找活跃的计划（排除 completed 和 cancelled）
+func (r *PlanRepository) FindByCode(ctx context.Context, code string) (*entity.Plan, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var found *entity.Plan
+	for _, p := range r.s.plans {
+		if !p.DeletedAt.Valid && p.Code == code && isActivePlan(p) && (found == nil || p.ID < found.ID) {
+			found = p
+		}
+	}
+	if found == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.s.loadPlan(found), nil
+}
+
+// FindLatestByCode 根据 code 查找计划（包括已完成和已取消，活跃计划优先，其次最近更新的）
+func (r *PlanRepository) FindLatestByCode(ctx context.Context, code string) (*entity.Plan, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var found *entity.Plan
+	for _, p := range r.s.plans {
+		if p.DeletedAt.Valid || p.Code != code {
+			continue
+		}
+		if found == nil || laterPlan(p, found) {
+			found = p
+		}
+	}
+	if found == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.s.loadPlan(found), nil
+}
+
+// laterPlan 按 FindLatestByCode 的顺序比较：活跃优先，其次更新时间、ID 较大的
+func laterPlan(a, b *entity.Plan) bool {
+	if isActivePlan(a) != isActivePlan(b) {
+		return isActivePlan(a)
+	}
+	if !a.UpdatedAt.Equal(b.UpdatedAt) {
+		return a.UpdatedAt.After(b.UpdatedAt)
+	}
+	return a.ID > b.ID
+}
+
+// ExistsActiveCode 检查活跃记录中是否存在指定 code
+func (r *PlanRepository) ExistsActiveCode(ctx context.Context, code string, excludeID int64) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, p := range r.s.plans {
+		if !p.DeletedAt.Valid && p.Code == code && isActivePlan(p) && (excludeID <= 0 || p.ID != excludeID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// FindByStatus 根据状态查找计划
+func (r *PlanRepository) FindByStatus(ctx context.Context, status entity.PlanStatus, filter models.PathOnlyVisibilityFilter) ([]entity.Plan, error) {
+	return r.findAll(filter, func(p *entity.Plan) bool { return p.Status == status }), nil
+}
+
+// FindByPathOnlyFilter 根据路径过滤器查询计划（排除已完成和已取消的计划）
+func (r *PlanRepository) FindByPathOnlyFilter(ctx context.Context, filter models.PathOnlyVisibilityFilter) ([]entity.Plan, error) {
+	return r.findAll(filter, isActivePlan), nil
+}
+
+// UpdateProgress 更新计划进度
+func (r *PlanRepository) UpdateProgress(ctx context.Context, id int64, progress int) error {
+	plan, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	plan.UpdateProgress(progress)
+	return r.Update(ctx, plan)
+}
+
+// findAll 查询路径范围内的计划（按创建时间倒序）
+func (r *PlanRepository) findAll(filter models.PathOnlyVisibilityFilter, match func(*entity.Plan) bool) []entity.Plan {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	plans := make([]entity.Plan, 0)
+	for _, p := range r.s.plans {
+		if p.DeletedAt.Valid || !filter.Matches(p.PathID) || !match(p) {
+			continue
+		}
+		plans = append(plans, *r.s.loadPlan(p))
+	}
+	sortByCreatedDesc(plans, func(p entity.Plan) (time.Time, int64) { return p.CreatedAt, p.ID })
+	return plans
+}
+
+// isActivePlan 是否为活跃计划（pending / in_progress）
+func isActivePlan(p *entity.Plan) bool {
+	return p.Status != entity.PlanStatusCompleted && p.Status != entity.PlanStatusCancelled
+}
+
+// savePlan 保存计划行（Todo 单独存储，调用方需持有锁）
+func (s *Store) savePlan(plan *entity.Plan) {
+	copied := *plan
+	copied.Todos = nil
+	if old, ok := s.plans[plan.ID]; ok {
+		copied.DeletedAt = old.DeletedAt
+	}
+	s.plans[plan.ID] = &copied
+}
+
+// loadPlan 复制计划并预加载未删除的 Todo（调用方需持有锁）
+// Todo 排序与 GORM 实现一致：sort_order ASC, priority DESC
+func (s *Store) loadPlan(p *entity.Plan) *entity.Plan {
+	copied := *p
+	copied.Todos = make([]entity.ToDo, 0)
+	for _, t := range s.todos {
+		if t.PlanID == p.ID && !t.DeletedAt.Valid {
+			copied.Todos = append(copied.Todos, *cloneToDo(t))
+		}
+	}
+	sort.SliceStable(copied.Todos, func(i, j int) bool {
+		a, b := copied.Todos[i], copied.Todos[j]
+		if a.SortOrder != b.SortOrder {
+			return a.SortOrder < b.SortOrder
+		}
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return a.ID < b.ID
+	})
+	return &copied
+}
@@ -0,0 +1,200 @@
+package memstore
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/XiaoLFeng/llm-memory/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// 嘿嘿~ 这是纯内存的仓储实现！(´∀｀)💖
+// 行为与 GORM 实现保持一致（软删除、排序、活跃 code 规则、未找到返回 gorm.ErrRecordNotFound），
+// 不需要数据库文件，适合测试和临时会话~ 数据只存在于进程内，退出即丢失！
+
+// Store 内存数据存储
+// 所有仓储共享同一个 Store，相当于同一个数据库
+type Store struct {
+	mu         sync.Mutex
+	paths      map[int64]*entity.PersonalPath
+	groups     map[int64]*entity.Group
+	groupPaths map[int64]*entity.GroupPath
+	memories   map[int64]*entity.Memory
+	revisions  map[int64]*entity.MemoryRevision
+	embeddings map[int64]*entity.MemoryEmbedding
+	plans      map[int64]*entity.Plan
+	todos      map[int64]*entity.ToDo
+}
+
+// New 创建空的内存存储
+// ID 使用雪花算法生成，调用前需要先执行 database.InitSnowflake()
+func New() *Store {
+	return &Store{
+		paths:      make(map[int64]*entity.PersonalPath),
+		groups:     make(map[int64]*entity.Group),
+		groupPaths: make(map[int64]*entity.GroupPath),
+		memories:   make(map[int64]*entity.Memory),
+		revisions:  make(map[int64]*entity.MemoryRevision),
+		embeddings: make(map[int64]*entity.MemoryEmbedding),
+		plans:      make(map[int64]*entity.Plan),
+		todos:      make(map[int64]*entity.ToDo),
+	}
+}
+
+// Memories 记忆仓储
+func (s *Store) Memories() *MemoryRepository {
+	return &MemoryRepository{s: s}
+}
+
+// MemoryRevisions 记忆修订历史仓储
+func (s *Store) MemoryRevisions() *MemoryRevisionRepository {
+	return &MemoryRevisionRepository{s: s}
+}
+
+// MemoryEmbeddings 记忆向量仓储
+func (s *Store) MemoryEmbeddings() *MemoryEmbeddingRepository {
+	return &MemoryEmbeddingRepository{s: s}
+}
+
+// Plans 计划仓储
+func (s *Store) Plans() *PlanRepository {
+	return &PlanRepository{s: s}
+}
+
+// ToDos 待办仓储
+func (s *Store) ToDos() *ToDoRepository {
+	return &ToDoRepository{s: s}
+}
+
+// Groups 组仓储
+func (s *Store) Groups() *GroupRepository {
+	return &GroupRepository{s: s}
+}
+
+// 编译期检查：内存实现满足仓储接口
+var (
+	_ models.MemoryRepository          = (*MemoryRepository)(nil)
+	_ models.MemoryRevisionRepository  = (*MemoryRevisionRepository)(nil)
+	_ models.MemoryEmbeddingRepository = (*MemoryEmbeddingRepository)(nil)
+	_ models.PlanRepository            = (*PlanRepository)(nil)
+	_ models.ToDoRepository            = (*ToDoRepository)(nil)
+	_ models.GroupRepository           = (*GroupRepository)(nil)
+)
+
+// EnsurePath 确保路径存在，不存在则创建，存在则更新访问时间
+// 对应 PersonalPathModel.EnsurePath
+func (s *Store) EnsurePath(ctx context.Context, path string) (*entity.PersonalPath, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.ensurePath(absPath(path))
+	p.Touch()
+	copied := *p
+	return &copied, nil
+}
+
+// ensurePath 查找或创建路径记录（调用方需持有锁）
+// 与 GORM 实现的默认行为一致：维护项目标识，未登记时沿用同一项目在本机已不存在的旧路径记录（不改写）
+func (s *Store) ensurePath(path string) *entity.PersonalPath {
+	projectID := utils.ProjectIdentity(path)
+	if p := s.findPath(path); p != nil {
+		if projectID != "" {
+			p.ProjectID = projectID
+		}
+		return p
+	}
+	if projectID != "" {
+		candidates := s.findPathsByProjectID(projectID)
+		paths := make([]string, len(candidates))
+		for i, p := range candidates {
+			paths[i] = p.Path
+		}
+		if i := utils.FirstMissingPath(paths); i >= 0 {
+			return candidates[i]
+		}
+	}
+	now := time.Now()
+	p := &entity.PersonalPath{
+		ID:        database.GenerateID(),
+		Path:      path,
+		ProjectID: projectID,
+		LastVisit: now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.paths[p.ID] = p
+	return p
+}
+
+// findPathsByProjectID 按项目标识查找路径记录，最近访问的在前（调用方需持有锁）
+func (s *Store) findPathsByProjectID(projectID string) []*entity.PersonalPath {
+	paths := make([]*entity.PersonalPath, 0)
+	for _, p := range s.paths {
+		if p.ProjectID == projectID {
+			paths = append(paths, p)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return paths[i].LastVisit.After(paths[j].LastVisit)
+	})
+	return paths
+}
+
+// findPath 按路径字符串查找路径记录（调用方需持有锁）
+func (s *Store) findPath(path string) *entity.PersonalPath {
+	for _, p := range s.paths {
+		if p.Path == path {
+			return p
+		}
+	}
+	return nil
+}
+
+// absPath 规范化路径（与 GORM 实现一致）
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return abs
+}
+
+// stamp 设置创建/更新时间（对应 GORM 的 autoCreateTime / autoUpdateTime）
+func stamp(createdAt, updatedAt *time.Time) {
+	now := time.Now()
+	if createdAt != nil && createdAt.IsZero() {
+		*createdAt = now
+	}
+	*updatedAt = now
+}
+
+// nextVersion 校验读取时的版本并返回新版本（对应 GORM 实现的按版本条件更新）
+func nextVersion(entityName, code string, current, read int64) (int64, error) {
+	if current != read {
+		return 0, &models.VersionConflictError{Entity: entityName, Code: code, Expected: read, Actual: current}
+	}
+	return read + 1, nil
+}
+
+// softDelete 生成软删除时间
+func softDelete(at time.Time) gorm.DeletedAt {
+	return gorm.DeletedAt{Time: at, Valid: true}
+}
+
+// sortByCreatedDesc 按创建时间倒序排序（时间相同按 ID 倒序，保证结果稳定）
+func sortByCreatedDesc[T any](items []T, key func(T) (time.Time, int64)) {
+	sort.SliceStable(items, func(i, j int) bool {
+		ti, idi := key(items[i])
+		tj, idj := key(items[j])
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return idi > idj
+	})
+}
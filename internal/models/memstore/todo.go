@@ -0,0 +1,441 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"gorm.io/gorm"
+)
+
+// ToDoRepository 待办内存仓储
+type ToDoRepository struct {
+	s *Store
+}
+
+// Create 创建待办
+func (r *ToDoRepository) Create(ctx context.Context, todo *entity.ToDo) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.createToDo(todo)
+	return nil
+}
+
+// Update 更新待办（按读取时的版本条件更新）
+func (r *ToDoRepository) Update(ctx context.Context, todo *entity.ToDo) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.saveToDo(todo)
+}
+
+// Delete 删除待办（软删除，移入回收站）
+func (r *ToDoRepository) Delete(ctx context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.deleteToDo(id, time.Now())
+	return nil
+}
+
+// FindByID 根据 ID 查找待办
+func (r *ToDoRepository) FindByID(ctx context.Context, id int64) (*entity.ToDo, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if t := r.s.activeToDo(id); t != nil {
+		return cloneToDo(t), nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// FindByCode 根据 code 查找待办（所有状态）
+func (r *ToDoRepository) FindByCode(ctx context.Context, code string) (*entity.ToDo, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if t := r.s.findToDoByCode(code); t != nil {
+		return cloneToDo(t), nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ExistsActiveCode 检查活跃记录中是否存在指定 code（待处理和进行中）
+func (r *ToDoRepository) ExistsActiveCode(ctx context.Context, code string, excludeID int64) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, t := range r.s.todos {
+		if t.DeletedAt.Valid || t.Code != code || (excludeID > 0 && t.ID == excludeID) {
+			continue
+		}
+		if t.Status != entity.ToDoStatusCompleted && t.Status != entity.ToDoStatusCancelled {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// FindByStatus 根据状态查找待办
+func (r *ToDoRepository) FindByStatus(ctx context.Context, status entity.ToDoStatus, filter models.PathOnlyVisibilityFilter) ([]entity.ToDo, error) {
+	return r.findAll(filter, func(t *entity.ToDo) bool { return t.Status == status }), nil
+}
+
+// FindByPathOnlyFilter 根据路径过滤器查询待办（显示所有状态）
+func (r *ToDoRepository) FindByPathOnlyFilter(ctx context.Context, filter models.PathOnlyVisibilityFilter) ([]entity.ToDo, error) {
+	return r.findAll(filter, nil), nil
+}
+
+// FindByPlanID 根据 PlanID 查找待办（sort_order ASC, priority DESC, created_at DESC）
+func (r *ToDoRepository) FindByPlanID(ctx context.Context, planID int64) ([]entity.ToDo, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	todos := make([]entity.ToDo, 0)
+	for _, t := range r.s.todos {
+		if t.PlanID == planID && !t.DeletedAt.Valid {
+			todos = append(todos, *cloneToDo(t))
+		}
+	}
+	sort.SliceStable(todos, func(i, j int) bool {
+		a, b := todos[i], todos[j]
+		if a.SortOrder != b.SortOrder {
+			return a.SortOrder < b.SortOrder
+		}
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	})
+	return todos, nil
+}
+
+// CountByPlanID 统计计划下的待办数量
+// 返回：总数、已完成数
+func (r *ToDoRepository) CountByPlanID(ctx context.Context, planID int64) (total int64, completed int64, err error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, t := range r.s.todos {
+		if t.PlanID != planID || t.DeletedAt.Valid {
+			continue
+		}
+		total++
+		if t.Status == entity.ToDoStatusCompleted {
+			completed++
+		}
+	}
+	return total, completed, nil
+}
+
+// Complete 完成待办
+func (r *ToDoRepository) Complete(ctx context.Context, id int64) error {
+	return r.setStatus(id, entity.ToDoStatusCompleted)
+}
+
+// Start 开始待办
+func (r *ToDoRepository) Start(ctx context.Context, id int64) error {
+	return r.setStatus(id, entity.ToDoStatusInProgress)
+}
+
+// Cancel 取消待办
+func (r *ToDoRepository) Cancel(ctx context.Context, id int64) error {
+	return r.setStatus(id, entity.ToDoStatusCancelled)
+}
+
+// UpdateTags 更新待办标签
+func (r *ToDoRepository) UpdateTags(ctx context.Context, todoID int64, tags []string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.todos[todoID]
+	if !ok {
+		return nil
+	}
+	t.Tags = make([]entity.ToDoTag, len(tags))
+	for i, tag := range tags {
+		t.Tags[i] = entity.ToDoTag{ID: database.GenerateID(), ToDoID: todoID, Tag: tag}
+	}
+	return nil
+}
+
+// BatchCreate 批量创建待办
+func (r *ToDoRepository) BatchCreate(ctx context.Context, todos []entity.ToDo) (*dto.ToDoBatchResultDTO, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for i := range todos {
+		r.s.createToDo(&todos[i])
+	}
+	return &dto.ToDoBatchResultDTO{
+		Total:     len(todos),
+		Succeeded: len(todos),
+		Errors:    make([]string, 0),
+	}, nil
+}
+
+// BatchUpdate 批量更新待办
+func (r *ToDoRepository) BatchUpdate(ctx context.Context, updates []dto.ToDoUpdateDTO) (*dto.ToDoBatchResultDTO, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	result := &dto.ToDoBatchResultDTO{
+		Total:  len(updates),
+		Errors: make([]string, 0),
+	}
+	for i, update := range updates {
+		stored := r.s.findToDoByCode(update.Code)
+		if stored == nil {
+			result.Failed++
+			result.Errors = append(result.Errors,
+				fmt.Sprintf("第 %d 项（Code=%s）不存在", i+1, update.Code))
+			continue
+		}
+
+		if err := models.CheckVersion("待办", stored.Code, update.Version, stored.Version); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("第 %d 项: %s", i+1, err.Error()))
+			continue
+		}
+
+		todo := cloneToDo(stored)
+		if update.Title != nil {
+			todo.Title = *update.Title
+		}
+		if update.Description != nil {
+			todo.Description = *update.Description
+		}
+		if update.Priority != nil {
+			todo.Priority = entity.ToDoPriority(*update.Priority)
+		}
+		if update.Status != nil {
+			todo.Status = entity.ToDoStatus(*update.Status)
+			if todo.Status == entity.ToDoStatusCompleted {
+				now := time.Now()
+				todo.CompletedAt = &now
+			}
+		}
+		if update.DueDate != nil {
+			todo.DueDate = update.DueDate
+		}
+		if err := r.s.saveToDo(todo); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors,
+				fmt.Sprintf("第 %d 项（Code=%s）更新失败: %s", i+1, update.Code, err.Error()))
+			continue
+		}
+		result.Succeeded++
+	}
+	return result, nil
+}
+
+// BatchComplete 批量完成待办
+func (r *ToDoRepository) BatchComplete(ctx context.Context, ids []int64) (*dto.ToDoBatchResultDTO, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	result := &dto.ToDoBatchResultDTO{
+		Total:  len(ids),
+		Errors: make([]string, 0),
+	}
+	now := time.Now()
+	for _, id := range ids {
+		t := r.s.activeToDo(id)
+		if t == nil || t.Status == entity.ToDoStatusCompleted {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("ID=%d 不存在或已完成", id))
+			continue
+		}
+		t.Status = entity.ToDoStatusCompleted
+		t.CompletedAt = &now
+		t.Version++
+		t.UpdatedAt = now
+		result.Succeeded++
+	}
+	return result, nil
+}
+
+// BatchDelete 批量删除待办
+func (r *ToDoRepository) BatchDelete(ctx context.Context, ids []int64) (*dto.ToDoBatchResultDTO, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	result := &dto.ToDoBatchResultDTO{
+		Total:  len(ids),
+		Errors: make([]string, 0),
+	}
+	now := time.Now()
+	for _, id := range ids {
+		if !r.s.deleteToDo(id, now) {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("ID=%d 不存在", id))
+			continue
+		}
+		result.Succeeded++
+	}
+	return result, nil
+}
+
+// BatchUpdateStatus 批量更新状态
+func (r *ToDoRepository) BatchUpdateStatus(ctx context.Context, ids []int64, status entity.ToDoStatus) (*dto.ToDoBatchResultDTO, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	result := &dto.ToDoBatchResultDTO{
+		Total:  len(ids),
+		Errors: make([]string, 0),
+	}
+	now := time.Now()
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		t := r.s.activeToDo(id)
+		if t == nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		t.Status = status
+		if status == entity.ToDoStatusCompleted {
+			t.CompletedAt = &now
+		}
+		t.Version++
+		t.UpdatedAt = now
+		result.Succeeded++
+	}
+	result.Failed = len(ids) - result.Succeeded
+	return result, nil
+}
+
+// BatchDeleteByPathIDs 批量删除指定路径下的所有待办（软删除）
+// 返回删除的记录数量
+func (r *ToDoRepository) BatchDeleteByPathIDs(ctx context.Context, pathIDs []int64) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	filter := models.PathOnlyVisibilityFilter{PathIDs: pathIDs}
+	now := time.Now()
+	var deleted int64
+	for _, t := range r.s.todos {
+		if !t.DeletedAt.Valid && filter.Matches(t.PathID) {
+			t.DeletedAt = softDelete(now)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// setStatus 更新待办状态
+func (r *ToDoRepository) setStatus(id int64, status entity.ToDoStatus) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t := r.s.activeToDo(id)
+	if t == nil {
+		return nil
+	}
+	now := time.Now()
+	t.Status = status
+	if status == entity.ToDoStatusCompleted {
+		t.CompletedAt = &now
+	}
+	t.Version++
+	t.UpdatedAt = now
+	return nil
+}
+
+// findAll 查询路径范围内的待办（priority DESC, created_at DESC）
+func (r *ToDoRepository) findAll(filter models.PathOnlyVisibilityFilter, match func(*entity.ToDo) bool) []entity.ToDo {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	todos := make([]entity.ToDo, 0)
+	for _, t := range r.s.todos {
+		if t.DeletedAt.Valid || !filter.Matches(t.PathID) {
+			continue
+		}
+		if match != nil && !match(t) {
+			continue
+		}
+		todos = append(todos, *cloneToDo(t))
+	}
+	sortByCreatedDesc(todos, func(t entity.ToDo) (time.Time, int64) { return t.CreatedAt, t.ID })
+	sort.SliceStable(todos, func(i, j int) bool { return todos[i].Priority > todos[j].Priority })
+	return todos
+}
+
+// createToDo 创建待办（调用方需持有锁）
+func (s *Store) createToDo(todo *entity.ToDo) {
+	todo.ID = database.GenerateID()
+	todo.Version = 1
+	if todo.Priority == 0 {
+		todo.Priority = entity.ToDoPriorityMedium
+	}
+	stamp(&todo.CreatedAt, &todo.UpdatedAt)
+	for i := range todo.Tags {
+		todo.Tags[i].ID = database.GenerateID()
+		todo.Tags[i].ToDoID = todo.ID
+	}
+	s.todos[todo.ID] = cloneToDo(todo)
+}
+
+// saveToDo 按读取时的版本保存待办（标签通过 UpdateTags 单独维护，调用方需持有锁）
+func (s *Store) saveToDo(todo *entity.ToDo) error {
+	old := s.activeToDo(todo.ID)
+	if old == nil {
+		return gorm.ErrRecordNotFound
+	}
+	version, err := nextVersion("待办", todo.Code, old.Version, todo.Version)
+	if err != nil {
+		return err
+	}
+	todo.Version = version
+	stamp(&todo.CreatedAt, &todo.UpdatedAt)
+	stored := cloneToDo(todo)
+	stored.Tags = old.Tags
+	s.todos[todo.ID] = stored
+	return nil
+}
+
+// deleteToDo 软删除待办，返回是否有记录被删除（调用方需持有锁）
+func (s *Store) deleteToDo(id int64, at time.Time) bool {
+	t := s.activeToDo(id)
+	if t == nil {
+		return false
+	}
+	t.DeletedAt = softDelete(at)
+	return true
+}
+
+// activeToDo 获取未删除的待办（调用方需持有锁）
+func (s *Store) activeToDo(id int64) *entity.ToDo {
+	if t, ok := s.todos[id]; ok && !t.DeletedAt.Valid {
+		return t
+	}
+	return nil
+}
+
+// findToDoByCode 查找 ID 最小的未删除待办（调用方需持有锁）
+func (s *Store) findToDoByCode(code string) *entity.ToDo {
+	var found *entity.ToDo
+	for _, t := range s.todos {
+		if !t.DeletedAt.Valid && t.Code == code && (found == nil || t.ID < found.ID) {
+			found = t
+		}
+	}
+	return found
+}
+
+// cloneToDo 复制待办，避免调用方修改存储中的数据
+func cloneToDo(t *entity.ToDo) *entity.ToDo {
+	copied := *t
+	copied.Tags = append([]entity.ToDoTag(nil), t.Tags...)
+	return &copied
+}
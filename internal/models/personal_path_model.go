@@ -2,17 +2,21 @@ package models
 
 import (
 	"context"
+	"errors"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/XiaoLFeng/llm-memory/internal/database"
 	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/XiaoLFeng/llm-memory/pkg/utils"
 	"gorm.io/gorm"
 )
 
 // PersonalPathModel 个人路径数据访问层
 type PersonalPathModel struct {
-	db *gorm.DB
+	db         *gorm.DB
+	claimMoved bool // 登记新路径时是否认领同一项目在本机已不存在的旧路径
 }
 
 // NewPersonalPathModel 创建 PersonalPathModel 实例
@@ -20,7 +24,13 @@ func NewPersonalPathModel(db *gorm.DB) *PersonalPathModel {
 	return &PersonalPathModel{db: db}
 }
 
+// SetClaimMoved 设置登记新路径时是否自动认领旧路径（对应配置 scope.claim_moved，默认关闭）
+func (m *PersonalPathModel) SetClaimMoved(enabled bool) {
+	m.claimMoved = enabled
+}
+
 // EnsurePath 确保路径存在，不存在则创建，存在则更新访问时间
+// 路径未登记但同一项目有在本机已不存在的旧路径时，默认沿用旧路径记录而不改写它（见 ensurePersonalPath）
 func (m *PersonalPathModel) EnsurePath(ctx context.Context, path string) (*entity.PersonalPath, error) {
 	// 规范化路径
 	absPath, err := filepath.Abs(path)
@@ -28,29 +38,74 @@ func (m *PersonalPathModel) EnsurePath(ctx context.Context, path string) (*entit
 		absPath = path
 	}
 
-	var personalPath entity.PersonalPath
-	err = m.db.WithContext(ctx).Where("path = ?", absPath).First(&personalPath).Error
+	personalPath, err := ensurePersonalPath(m.db.WithContext(ctx), absPath, m.claimMoved)
+	if err != nil {
+		return nil, err
+	}
 
-	if err == gorm.ErrRecordNotFound {
-		// 不存在，创建新记录
-		personalPath = entity.PersonalPath{
-			ID:        database.GenerateID(),
-			Path:      absPath,
-			LastVisit: time.Now(),
-		}
-		if err := m.db.WithContext(ctx).Create(&personalPath).Error; err != nil {
-			return nil, err
+	// 更新访问时间
+	personalPath.Touch()
+	if err := m.db.WithContext(ctx).Save(personalPath).Error; err != nil {
+		return nil, err
+	}
+	return personalPath, nil
+}
+
+// ensurePersonalPath 查找或登记路径，同时维护项目标识
+// 路径未登记时按项目标识（git 远程地址）查找同一项目在本机已不存在的旧路径
+// （如从其他机器导入、仓库换了位置重新检出）：
+//   - claim 为 false（默认）时直接返回旧路径记录，不改写也不登记新路径，
+//     与作用域解析按项目标识只读匹配的结果一致；需要改写时使用 path move
+//   - claim 为 true 时把旧路径改写为当前路径，数据和组关系随之生效
+//
+// 找不到旧路径时创建新记录
+func ensurePersonalPath(db *gorm.DB, absPath string, claim bool) (*entity.PersonalPath, error) {
+	projectID := utils.ProjectIdentity(absPath)
+
+	var personalPath entity.PersonalPath
+	err := db.Where("path = ?", absPath).First(&personalPath).Error
+	if err == nil {
+		// 已登记：补齐或更新项目标识（远程地址可能变化）
+		if projectID != "" && personalPath.ProjectID != projectID {
+			personalPath.ProjectID = projectID
+			if err := db.Model(&personalPath).UpdateColumn("project_id", projectID).Error; err != nil {
+				return nil, err
+			}
 		}
 		return &personalPath, nil
 	}
-
-	if err != nil {
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	}
 
-	// 已存在，更新访问时间
-	personalPath.Touch()
-	if err := m.db.WithContext(ctx).Save(&personalPath).Error; err != nil {
+	if projectID != "" {
+		var candidates []entity.PersonalPath
+		if err := db.Where("project_id = ?", projectID).Order("last_visit DESC").Find(&candidates).Error; err != nil {
+			return nil, err
+		}
+		paths := make([]string, len(candidates))
+		for i := range candidates {
+			paths[i] = candidates[i].Path
+		}
+		if i := utils.FirstMissingPath(paths); i >= 0 {
+			if !claim {
+				return &candidates[i], nil
+			}
+			candidates[i].Path = absPath
+			if err := db.Model(&candidates[i]).UpdateColumn("path", absPath).Error; err != nil {
+				return nil, err
+			}
+			return &candidates[i], nil
+		}
+	}
+
+	personalPath = entity.PersonalPath{
+		ID:        database.GenerateID(),
+		Path:      absPath,
+		ProjectID: projectID,
+		LastVisit: time.Now(),
+	}
+	if err := db.Create(&personalPath).Error; err != nil {
 		return nil, err
 	}
 	return &personalPath, nil
@@ -118,3 +173,185 @@ func (m *PersonalPathModel) Count(ctx context.Context) (int64, error) {
 	err := m.db.WithContext(ctx).Model(&entity.PersonalPath{}).Count(&count).Error
 	return count, err
 }
+
+// PathUsage 路径下的数据量（包括回收站中的条目）
+type PathUsage struct {
+	Memories int64
+	Plans    int64
+	ToDos    int64
+}
+
+// Total 数据总数
+func (u PathUsage) Total() int64 {
+	return u.Memories + u.Plans + u.ToDos
+}
+
+// Transaction 在事务中执行路径维护操作
+// 嘿嘿~ 搬家和合并要么全部完成，要么什么都不改！✨
+func (m *PersonalPathModel) Transaction(ctx context.Context, fn func(pm *PersonalPathModel) error) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&PersonalPathModel{db: tx})
+	})
+}
+
+// FindDescendants 查找 path 下的所有子路径（不含 path 本身）
+func (m *PersonalPathModel) FindDescendants(ctx context.Context, path string) ([]entity.PersonalPath, error) {
+	prefix := strings.TrimSuffix(path, string(filepath.Separator)) + string(filepath.Separator)
+	escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+	var candidates []entity.PersonalPath
+	err := m.db.WithContext(ctx).
+		Where(`path LIKE ? ESCAPE '\'`, escaper.Replace(prefix)+"%").
+		Order("path ASC").
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite 的 LIKE 不区分大小写，再按前缀精确过滤一次
+	paths := make([]entity.PersonalPath, 0, len(candidates))
+	for _, p := range candidates {
+		if strings.HasPrefix(p.Path, prefix) {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// UpdatePath 修改路径（保留 ID，关联的数据和组成员关系不变）
+func (m *PersonalPathModel) UpdatePath(ctx context.Context, id int64, path string) error {
+	return m.db.WithContext(ctx).Model(&entity.PersonalPath{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"path": path, "updated_at": time.Now()}).Error
+}
+
+// Usage 统计路径下的记忆、计划、待办数量（包括回收站）
+func (m *PersonalPathModel) Usage(ctx context.Context, pathID int64) (PathUsage, error) {
+	var usage PathUsage
+	db := m.db.WithContext(ctx).Unscoped().Session(&gorm.Session{})
+	if err := db.Model(&entity.Memory{}).Where("path_id = ?", pathID).Count(&usage.Memories).Error; err != nil {
+		return usage, err
+	}
+	if err := db.Model(&entity.Plan{}).Where("path_id = ?", pathID).Count(&usage.Plans).Error; err != nil {
+		return usage, err
+	}
+	if err := db.Model(&entity.ToDo{}).Where("path_id = ?", pathID).Count(&usage.ToDos).Error; err != nil {
+		return usage, err
+	}
+	return usage, nil
+}
+
+// GroupIDOf 获取路径所属的组 ID（未加入组时为 0）
+func (m *PersonalPathModel) GroupIDOf(ctx context.Context, pathID int64) (int64, error) {
+	var groupPath entity.GroupPath
+	err := m.db.WithContext(ctx).Where("personal_path_id = ?", pathID).First(&groupPath).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return groupPath.GroupID, nil
+}
+
+// MoveGroupLink 把路径的组成员关系转给另一个路径
+func (m *PersonalPathModel) MoveGroupLink(ctx context.Context, fromID, toID int64) error {
+	return m.db.WithContext(ctx).Model(&entity.GroupPath{}).
+		Where("personal_path_id = ?", fromID).
+		Update("personal_path_id", toID).Error
+}
+
+// DeleteWithGroupLink 删除路径及其组成员关系（硬删除）
+func (m *PersonalPathModel) DeleteWithGroupLink(ctx context.Context, id int64) error {
+	if err := m.db.WithContext(ctx).Where("personal_path_id = ?", id).Delete(&entity.GroupPath{}).Error; err != nil {
+		return err
+	}
+	return m.Delete(ctx, id)
+}
+
+// ReassignData 把路径下的所有记忆、计划、待办（包括回收站）改为属于另一个路径
+// 只改 path_id，不更新 updated_at
+func (m *PersonalPathModel) ReassignData(ctx context.Context, fromID, toID int64) (PathUsage, error) {
+	var usage PathUsage
+	db := m.db.WithContext(ctx).Unscoped().Session(&gorm.Session{})
+
+	result := db.Model(&entity.Memory{}).Where("path_id = ?", fromID).UpdateColumn("path_id", toID)
+	if result.Error != nil {
+		return usage, result.Error
+	}
+	usage.Memories = result.RowsAffected
+
+	result = db.Model(&entity.Plan{}).Where("path_id = ?", fromID).UpdateColumn("path_id", toID)
+	if result.Error != nil {
+		return usage, result.Error
+	}
+	usage.Plans = result.RowsAffected
+
+	result = db.Model(&entity.ToDo{}).Where("path_id = ?", fromID).UpdateColumn("path_id", toID)
+	if result.Error != nil {
+		return usage, result.Error
+	}
+	usage.ToDos = result.RowsAffected
+	return usage, nil
+}
+
+// FindActivePlanCodeConflicts 查找 fromID 下与 toID 下活跃计划 code 相同的活跃计划
+func (m *PersonalPathModel) FindActivePlanCodeConflicts(ctx context.Context, fromID, toID int64) ([]entity.Plan, error) {
+	inactive := []entity.PlanStatus{entity.PlanStatusCompleted, entity.PlanStatusCancelled}
+	target := m.db.Model(&entity.Plan{}).Select("code").
+		Where("path_id = ? AND status NOT IN ?", toID, inactive)
+
+	var plans []entity.Plan
+	err := m.db.WithContext(ctx).
+		Where("path_id = ? AND status NOT IN ? AND code IN (?)", fromID, inactive, target).
+		Order("id ASC").
+		Find(&plans).Error
+	return plans, err
+}
+
+// FindActiveToDoCodeConflicts 查找 fromID 下与 toID 下活跃待办 code 相同的活跃待办
+func (m *PersonalPathModel) FindActiveToDoCodeConflicts(ctx context.Context, fromID, toID int64) ([]entity.ToDo, error) {
+	inactive := []entity.ToDoStatus{entity.ToDoStatusCompleted, entity.ToDoStatusCancelled}
+	target := m.db.Model(&entity.ToDo{}).Select("code").
+		Where("path_id = ? AND status NOT IN ?", toID, inactive)
+
+	var todos []entity.ToDo
+	err := m.db.WithContext(ctx).
+		Where("path_id = ? AND status NOT IN ? AND code IN (?)", fromID, inactive, target).
+		Order("id ASC").
+		Find(&todos).Error
+	return todos, err
+}
+
+// RenamePlanCode 修改计划 code（按读取时的版本条件更新，版本号 +1）
+// 计划在读取之后被其他进程修改时返回 VersionConflictError
+func (m *PersonalPathModel) RenamePlanCode(ctx context.Context, plan *entity.Plan, code string) error {
+	oldCode := plan.Code
+	plan.Code = code
+	if err := updateVersioned(m.db.WithContext(ctx), plan, plan.ID, &plan.Version, "计划", oldCode); err != nil {
+		plan.Code = oldCode
+		return err
+	}
+	return nil
+}
+
+// RenameToDoCode 修改待办 code（按读取时的版本条件更新，版本号 +1）
+// 待办在读取之后被其他进程修改时返回 VersionConflictError
+func (m *PersonalPathModel) RenameToDoCode(ctx context.Context, todo *entity.ToDo, code string) error {
+	oldCode := todo.Code
+	todo.Code = code
+	if err := updateVersioned(m.db.WithContext(ctx), todo, todo.ID, &todo.Version, "待办", oldCode); err != nil {
+		todo.Code = oldCode
+		return err
+	}
+	return nil
+}
+
+// PlanCodeInUse 检查活跃计划中是否已使用 code
+func (m *PersonalPathModel) PlanCodeInUse(ctx context.Context, code string) (bool, error) {
+	return NewPlanModel(m.db).ExistsActiveCode(ctx, code, 0)
+}
+
+// ToDoCodeInUse 检查活跃待办中是否已使用 code
+func (m *PersonalPathModel) ToDoCodeInUse(ctx context.Context, code string) (bool, error) {
+	return NewToDoModel(m.db).ExistsActiveCode(ctx, code, 0)
+}
@@ -0,0 +1,167 @@
+package models
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testProjectID = "github.com/xiaolfeng/llm-memory"
+
+// openPathTestDB 打开迁移好的临时数据库
+func openPathTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if _, err := database.NewMigrator(db).Migrate(context.Background()); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	return db
+}
+
+// newCheckout 创建远程地址为 testProjectID 的 git 检出目录
+func newCheckout(t *testing.T, dir string) string {
+	t.Helper()
+	config := "[remote \"origin\"]\n\turl = git@github.com:XiaoLFeng/llm-memory.git\n"
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatalf("创建检出目录失败: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".git", "config"), []byte(config), 0644); err != nil {
+		t.Fatalf("写入 git 配置失败: %v", err)
+	}
+	return dir
+}
+
+// TestEnsurePathClaim 登记同一项目的新检出：默认只沿用旧路径记录，开启 claimMoved 时才改写
+func TestEnsurePathClaim(t *testing.T) {
+	cases := []struct {
+		name       string
+		claim      bool
+		oldExists  bool // 旧路径在本机仍然存在（同一项目的另一个检出）
+		registered bool // 新路径本身已登记
+		wantOld    bool // 返回旧路径记录
+		wantPath   string
+		wantRows   int64
+	}{
+		{name: "默认沿用旧路径不改写", wantOld: true, wantPath: "old", wantRows: 1},
+		{name: "开启认领时改写旧路径", claim: true, wantOld: true, wantPath: "new", wantRows: 1},
+		{name: "旧路径仍存在时登记新路径", oldExists: true, wantPath: "old", wantRows: 2},
+		{name: "开启认领但旧路径仍存在", claim: true, oldExists: true, wantPath: "old", wantRows: 2},
+		{name: "新路径已登记时使用它", registered: true, wantPath: "old", wantRows: 2},
+		{name: "新路径已登记且开启认领", claim: true, registered: true, wantPath: "old", wantRows: 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := openPathTestDB(t)
+			root := t.TempDir()
+			newPath := newCheckout(t, filepath.Join(root, "new"))
+			oldPath := filepath.Join(root, "old")
+			if tc.oldExists {
+				newCheckout(t, oldPath)
+			}
+			paths := map[string]string{"old": oldPath, "new": newPath}
+
+			old := entity.PersonalPath{
+				ID: database.GenerateID(), Path: oldPath, ProjectID: testProjectID, LastVisit: time.Now(),
+			}
+			if err := db.Create(&old).Error; err != nil {
+				t.Fatalf("创建旧路径失败: %v", err)
+			}
+			var registeredID int64
+			if tc.registered {
+				registeredID = database.GenerateID()
+				if err := db.Create(&entity.PersonalPath{ID: registeredID, Path: newPath, LastVisit: time.Now()}).Error; err != nil {
+					t.Fatalf("登记新路径失败: %v", err)
+				}
+			}
+
+			model := NewPersonalPathModel(db)
+			model.SetClaimMoved(tc.claim)
+			got, err := model.EnsurePath(ctx, newPath)
+			if err != nil {
+				t.Fatalf("EnsurePath 失败: %v", err)
+			}
+
+			switch {
+			case tc.wantOld && got.ID != old.ID:
+				t.Fatalf("应返回旧路径记录 %d，实际 %d", old.ID, got.ID)
+			case !tc.wantOld && got.ID == old.ID:
+				t.Fatalf("不应返回旧路径记录")
+			case tc.registered && got.ID != registeredID:
+				t.Fatalf("应返回已登记的新路径记录 %d，实际 %d", registeredID, got.ID)
+			}
+			if got.ProjectID != testProjectID {
+				t.Fatalf("项目标识 = %q，期望 %q", got.ProjectID, testProjectID)
+			}
+
+			var stored entity.PersonalPath
+			if err := db.First(&stored, old.ID).Error; err != nil {
+				t.Fatalf("读取旧路径失败: %v", err)
+			}
+			if stored.Path != paths[tc.wantPath] {
+				t.Fatalf("旧路径记录的 path = %s，期望 %s", stored.Path, paths[tc.wantPath])
+			}
+			var count int64
+			db.Model(&entity.PersonalPath{}).Count(&count)
+			if count != tc.wantRows {
+				t.Fatalf("路径记录数 = %d，期望 %d", count, tc.wantRows)
+			}
+		})
+	}
+}
+
+// TestAddPathDoesNotClaim 把新检出加入组时关联旧路径记录，不改写它
+func TestAddPathDoesNotClaim(t *testing.T) {
+	ctx := context.Background()
+	db := openPathTestDB(t)
+	root := t.TempDir()
+	newPath := newCheckout(t, filepath.Join(root, "new"))
+	oldPath := filepath.Join(root, "old")
+
+	old := entity.PersonalPath{ID: database.GenerateID(), Path: oldPath, ProjectID: testProjectID, LastVisit: time.Now()}
+	if err := db.Create(&old).Error; err != nil {
+		t.Fatalf("创建旧路径失败: %v", err)
+	}
+	groupModel := NewGroupModel(db)
+	group := &entity.Group{ID: database.GenerateID(), Name: "team"}
+	if err := db.Create(group).Error; err != nil {
+		t.Fatalf("创建组失败: %v", err)
+	}
+	if err := groupModel.AddPath(ctx, group.ID, newPath); err != nil {
+		t.Fatalf("加入组失败: %v", err)
+	}
+
+	groupID, err := NewPersonalPathModel(db).GroupIDOf(ctx, old.ID)
+	if err != nil {
+		t.Fatalf("查询组失败: %v", err)
+	}
+	if groupID != group.ID {
+		t.Fatalf("旧路径记录应加入组 %d，实际 %d", group.ID, groupID)
+	}
+	var stored entity.PersonalPath
+	if err := db.First(&stored, old.ID).Error; err != nil {
+		t.Fatalf("读取旧路径失败: %v", err)
+	}
+	if stored.Path != oldPath {
+		t.Fatalf("旧路径不应被改写: %s", stored.Path)
+	}
+}
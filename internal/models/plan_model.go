@@ -2,10 +2,12 @@ package models
 
 import (
 	"context"
+	"time"
 
 	"github.com/XiaoLFeng/llm-memory/internal/database"
 	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // PlanModel 计划数据访问层
@@ -25,31 +27,48 @@ func (m *PlanModel) Create(ctx context.Context, plan *entity.Plan) error {
 }
 
 // Update 更新计划
+// 按读取时的版本条件更新，记录已被他人修改时返回 VersionConflictError
 func (m *PlanModel) Update(ctx context.Context, plan *entity.Plan) error {
-	return m.db.WithContext(ctx).Save(plan).Error
+	return updateVersioned(m.db.WithContext(ctx), plan, plan.ID, &plan.Version, "计划", plan.Code)
 }
 
-// Delete 删除计划（硬删除）
-// 注意：关联的 Todo 会通过 GORM 的 CASCADE 约束自动删除
+// Delete 删除计划（软删除，移入回收站）
+// 关联的 Todo 会以相同的删除时间一并移入回收站，恢复计划时一起恢复
 func (m *PlanModel) Delete(ctx context.Context, id int64) error {
+	now := time.Now()
 	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 先删除关联的 Todo 标签
-		var todoIDs []int64
-		if err := tx.Model(&entity.ToDo{}).Where("plan_id = ?", id).Pluck("id", &todoIDs).Error; err != nil {
+		if err := tx.Model(&entity.ToDo{}).Where("plan_id = ?", id).Update("deleted_at", now).Error; err != nil {
 			return err
 		}
-		if len(todoIDs) > 0 {
-			if err := tx.Where("to_do_id IN ?", todoIDs).Delete(&entity.ToDoTag{}).Error; err != nil {
-				return err
-			}
-		}
-		// 删除关联的 Todo
-		if err := tx.Where("plan_id = ?", id).Unscoped().Delete(&entity.ToDo{}).Error; err != nil {
+		return tx.Model(&entity.Plan{}).Where("id = ?", id).Update("deleted_at", now).Error
+	})
+}
+
+// Purge 彻底删除计划（硬删除，包括回收站中的计划）
+func (m *PlanModel) Purge(ctx context.Context, id int64) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return purgePlan(tx, id)
+	})
+}
+
+// purgePlan 在事务中硬删除计划及其所有 Todo
+func purgePlan(tx *gorm.DB, id int64) error {
+	// 先删除关联的 Todo 标签
+	var todoIDs []int64
+	if err := tx.Unscoped().Model(&entity.ToDo{}).Where("plan_id = ?", id).Pluck("id", &todoIDs).Error; err != nil {
+		return err
+	}
+	if len(todoIDs) > 0 {
+		if err := tx.Where("to_do_id IN ?", todoIDs).Delete(&entity.ToDoTag{}).Error; err != nil {
 			return err
 		}
-		// 硬删除计划本身
-		return tx.Unscoped().Delete(&entity.Plan{}, id).Error
-	})
+	}
+	// 删除关联的 Todo
+	if err := tx.Where("plan_id = ?", id).Unscoped().Delete(&entity.ToDo{}).Error; err != nil {
+		return err
+	}
+	// 硬删除计划本身
+	return tx.Unscoped().Delete(&entity.Plan{}, id).Error
 }
 
 // FindByID 根据 ID 查找计划
@@ -81,6 +100,26 @@ func (m *PlanModel) FindByCode(ctx context.Context, code string) (*entity.Plan,
 	return &plan, nil
 }
 
+// FindLatestByCode 根据 code 查找计划（包括已完成和已取消的计划）
+// 活跃计划优先；没有活跃计划时取最近更新的已完成/已取消计划
+func (m *PlanModel) FindLatestByCode(ctx context.Context, code string) (*entity.Plan, error) {
+	var plan entity.Plan
+	err := m.db.WithContext(ctx).
+		Preload("Todos", func(db *gorm.DB) *gorm.DB {
+			return db.Preload("Tags").Order("sort_order ASC, priority DESC")
+		}).
+		Where("code = ?", code).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "CASE WHEN status IN ? THEN 1 ELSE 0 END, updated_at DESC, id DESC",
+			Vars: []interface{}{[]entity.PlanStatus{entity.PlanStatusCompleted, entity.PlanStatusCancelled}},
+		}}).
+		Take(&plan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
 // ExistsActiveCode 检查活跃记录中是否存在指定 code
 // 只检查活跃状态（status NOT IN completed/cancelled）
 // excludeID: 如果 > 0，则排除该 ID（用于更新时检查）
@@ -198,3 +237,67 @@ func (m *PlanModel) Count(ctx context.Context) (int64, error) {
 	err := m.db.WithContext(ctx).Model(&entity.Plan{}).Count(&count).Error
 	return count, err
 }
+
+// FindDeletedByPathOnlyFilter 查询回收站中的计划（按删除时间倒序）
+func (m *PlanModel) FindDeletedByPathOnlyFilter(ctx context.Context, filter PathOnlyVisibilityFilter) ([]entity.Plan, error) {
+	var plans []entity.Plan
+	err := ApplyPathOnlyFilter(m.db.WithContext(ctx).Unscoped(), filter).
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&plans).Error
+	return plans, err
+}
+
+// FindDeletedByCode 根据 code 查找回收站中最近删除的计划
+func (m *PlanModel) FindDeletedByCode(ctx context.Context, code string) (*entity.Plan, error) {
+	var plan entity.Plan
+	err := m.db.WithContext(ctx).Unscoped().
+		Where("code = ? AND deleted_at IS NOT NULL", code).
+		Order("deleted_at DESC").
+		First(&plan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// Restore 从回收站恢复计划
+// 与计划一起删除的 Todo 会一并恢复，之前单独删除的 Todo 仍留在回收站
+func (m *PlanModel) Restore(ctx context.Context, id int64) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var plan entity.Plan
+		if err := tx.Unscoped().First(&plan, id).Error; err != nil {
+			return err
+		}
+		if plan.DeletedAt.Valid {
+			if err := tx.Unscoped().Model(&entity.ToDo{}).
+				Where("plan_id = ? AND deleted_at >= ?", id, plan.DeletedAt.Time).
+				Update("deleted_at", nil).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Model(&entity.Plan{}).Where("id = ?", id).Update("deleted_at", nil).Error
+	})
+}
+
+// PurgeDeletedBefore 彻底删除在 before 之前移入回收站的计划
+// 返回删除的数量
+func (m *PlanModel) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		if err := tx.Unscoped().Model(&entity.Plan{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := purgePlan(tx, id); err != nil {
+				return err
+			}
+		}
+		purged = int64(len(ids))
+		return nil
+	})
+	return purged, err
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"context"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+)
+
+// 嘿嘿~ 这里是服务层依赖的仓储接口！(´∀｀)💖
+// 服务只通过这些接口访问数据，默认实现是基于 GORM 的 *Model，
+// 测试时可以换成 memstore 包中的纯内存实现，不需要真实数据库~
+// 未找到记录时实现需返回 gorm.ErrRecordNotFound，与 GORM 行为保持一致
+
+// MemoryRepository 记忆仓储
+type MemoryRepository interface {
+	Create(ctx context.Context, memory *entity.Memory) error
+	Update(ctx context.Context, memory *entity.Memory) error
+	Delete(ctx context.Context, id int64) error
+	FindByID(ctx context.Context, id int64) (*entity.Memory, error)
+	FindByCode(ctx context.Context, code string) (*entity.Memory, error)
+	FindDeletedByCode(ctx context.Context, code string) (*entity.Memory, error)
+	ExistsCode(ctx context.Context, code string, excludeID int64) (bool, error)
+	FindByCategory(ctx context.Context, category string) ([]entity.Memory, error)
+	FindByFilter(ctx context.Context, filter VisibilityFilter) ([]entity.Memory, error)
+	SearchByFilter(ctx context.Context, keyword string, filter VisibilityFilter) ([]entity.Memory, error)
+	SearchRankedByFilter(ctx context.Context, keyword string, filter VisibilityFilter, limit int) ([]MemorySearchHit, error)
+	Archive(ctx context.Context, id int64) error
+	Unarchive(ctx context.Context, id int64) error
+	UpdateTags(ctx context.Context, memoryID int64, tags []string) error
+}
+
+// MemoryRevisionRepository 记忆修订历史仓储
+type MemoryRevisionRepository interface {
+	Create(ctx context.Context, revision *entity.MemoryRevision) error
+	FindByMemoryID(ctx context.Context, memoryID int64) ([]entity.MemoryRevision, error)
+	FindByRevision(ctx context.Context, memoryID int64, revision int) (*entity.MemoryRevision, error)
+}
+
+// MemoryEmbeddingRepository 记忆向量仓储
+type MemoryEmbeddingRepository interface {
+	Upsert(ctx context.Context, embedding *entity.MemoryEmbedding) error
+	FindByMemoryID(ctx context.Context, memoryID int64) (*entity.MemoryEmbedding, error)
+	FindByMemoryIDs(ctx context.Context, memoryIDs []int64) (map[int64]entity.MemoryEmbedding, error)
+}
+
+// PlanRepository 计划仓储
+type PlanRepository interface {
+	Create(ctx context.Context, plan *entity.Plan) error
+	Update(ctx context.Context, plan *entity.Plan) error
+	Delete(ctx context.Context, id int64) error
+	FindByID(ctx context.Context, id int64) (*entity.Plan, error)
+	FindByCode(ctx context.Context, code string) (*entity.Plan, error)
+	FindLatestByCode(ctx context.Context, code string) (*entity.Plan, error)
+	ExistsActiveCode(ctx context.Context, code string, excludeID int64) (bool, error)
+	FindByStatus(ctx context.Context, status entity.PlanStatus, filter PathOnlyVisibilityFilter) ([]entity.Plan, error)
+	FindByPathOnlyFilter(ctx context.Context, filter PathOnlyVisibilityFilter) ([]entity.Plan, error)
+	UpdateProgress(ctx context.Context, id int64, progress int) error
+}
+
+// ToDoRepository 待办仓储
+type ToDoRepository interface {
+	Create(ctx context.Context, todo *entity.ToDo) error
+	Update(ctx context.Context, todo *entity.ToDo) error
+	Delete(ctx context.Context, id int64) error
+	FindByID(ctx context.Context, id int64) (*entity.ToDo, error)
+	FindByCode(ctx context.Context, code string) (*entity.ToDo, error)
+	ExistsActiveCode(ctx context.Context, code string, excludeID int64) (bool, error)
+	FindByStatus(ctx context.Context, status entity.ToDoStatus, filter PathOnlyVisibilityFilter) ([]entity.ToDo, error)
+	FindByPathOnlyFilter(ctx context.Context, filter PathOnlyVisibilityFilter) ([]entity.ToDo, error)
+	FindByPlanID(ctx context.Context, planID int64) ([]entity.ToDo, error)
+	CountByPlanID(ctx context.Context, planID int64) (total int64, completed int64, err error)
+	Complete(ctx context.Context, id int64) error
+	Start(ctx context.Context, id int64) error
+	Cancel(ctx context.Context, id int64) error
+	UpdateTags(ctx context.Context, todoID int64, tags []string) error
+	BatchCreate(ctx context.Context, todos []entity.ToDo) (*dto.ToDoBatchResultDTO, error)
+	BatchUpdate(ctx context.Context, updates []dto.ToDoUpdateDTO) (*dto.ToDoBatchResultDTO, error)
+	BatchComplete(ctx context.Context, ids []int64) (*dto.ToDoBatchResultDTO, error)
+	BatchDelete(ctx context.Context, ids []int64) (*dto.ToDoBatchResultDTO, error)
+	BatchUpdateStatus(ctx context.Context, ids []int64, status entity.ToDoStatus) (*dto.ToDoBatchResultDTO, error)
+	BatchDeleteByPathIDs(ctx context.Context, pathIDs []int64) (int64, error)
+}
+
+// GroupRepository 组仓储
+type GroupRepository interface {
+	Create(ctx context.Context, group *entity.Group) error
+	Update(ctx context.Context, group *entity.Group) error
+	Delete(ctx context.Context, id int64) error
+	FindByID(ctx context.Context, id int64) (*entity.Group, error)
+	FindByName(ctx context.Context, name string) (*entity.Group, error)
+	FindByPath(ctx context.Context, path string) (*entity.Group, error)
+	FindAll(ctx context.Context) ([]entity.Group, error)
+	AddPath(ctx context.Context, groupID int64, path string) error
+	RemovePath(ctx context.Context, groupID int64, path string) error
+	GetPathIDByPath(ctx context.Context, path string) (int64, error)
+	FindPathByProjectID(ctx context.Context, projectID string) (*entity.PersonalPath, error)
+	GetPathIDsByGroupID(ctx context.Context, groupID int64) ([]int64, error)
+}
+
+// 编译期检查：GORM 实现满足仓储接口
+var (
+	_ MemoryRepository          = (*MemoryModel)(nil)
+	_ MemoryRevisionRepository  = (*MemoryRevisionModel)(nil)
+	_ MemoryEmbeddingRepository = (*MemoryEmbeddingModel)(nil)
+	_ PlanRepository            = (*PlanModel)(nil)
+	_ ToDoRepository            = (*ToDoModel)(nil)
+	_ GroupRepository           = (*GroupModel)(nil)
+)
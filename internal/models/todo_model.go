@@ -28,22 +28,33 @@ func (m *ToDoModel) Create(ctx context.Context, todo *entity.ToDo) error {
 }
 
 // Update 更新待办
+// 按读取时的版本条件更新，记录已被他人修改时返回 VersionConflictError
 func (m *ToDoModel) Update(ctx context.Context, todo *entity.ToDo) error {
-	return m.db.WithContext(ctx).Save(todo).Error
+	return updateVersioned(m.db.WithContext(ctx), todo, todo.ID, &todo.Version, "待办", todo.Code)
 }
 
-// Delete 删除待办（硬删除）
+// Delete 删除待办（软删除，移入回收站）
 func (m *ToDoModel) Delete(ctx context.Context, id int64) error {
+	return m.db.WithContext(ctx).Delete(&entity.ToDo{}, id).Error
+}
+
+// Purge 彻底删除待办（硬删除，包括回收站中的待办）
+func (m *ToDoModel) Purge(ctx context.Context, id int64) error {
 	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 先删除关联的标签
-		if err := tx.Where("to_do_id = ?", id).Unscoped().Delete(&entity.ToDoTag{}).Error; err != nil {
-			return err
-		}
-		// 硬删除待办本身
-		return tx.Unscoped().Delete(&entity.ToDo{}, id).Error
+		return purgeToDo(tx, id)
 	})
 }
 
+// purgeToDo 在事务中硬删除待办及其标签
+func purgeToDo(tx *gorm.DB, id int64) error {
+	// 先删除关联的标签
+	if err := tx.Where("to_do_id = ?", id).Unscoped().Delete(&entity.ToDoTag{}).Error; err != nil {
+		return err
+	}
+	// 硬删除待办本身
+	return tx.Unscoped().Delete(&entity.ToDo{}, id).Error
+}
+
 // FindByID 根据 ID 查找待办
 func (m *ToDoModel) FindByID(ctx context.Context, id int64) (*entity.ToDo, error) {
 	var todo entity.ToDo
@@ -138,20 +149,20 @@ func (m *ToDoModel) FindByPathOnlyFilter(ctx context.Context, filter PathOnlyVis
 // Complete 完成待办
 func (m *ToDoModel) Complete(ctx context.Context, id int64) error {
 	now := time.Now()
-	return m.db.WithContext(ctx).Model(&entity.ToDo{}).Where("id = ?", id).Updates(map[string]interface{}{
+	return m.db.WithContext(ctx).Model(&entity.ToDo{}).Where("id = ?", id).Updates(bumpVersion(map[string]interface{}{
 		"status":       entity.ToDoStatusCompleted,
 		"completed_at": now,
-	}).Error
+	})).Error
 }
 
 // Start 开始待办
 func (m *ToDoModel) Start(ctx context.Context, id int64) error {
-	return m.db.WithContext(ctx).Model(&entity.ToDo{}).Where("id = ?", id).Update("status", entity.ToDoStatusInProgress).Error
+	return m.db.WithContext(ctx).Model(&entity.ToDo{}).Where("id = ?", id).Updates(bumpVersion(map[string]interface{}{"status": entity.ToDoStatusInProgress})).Error
 }
 
 // Cancel 取消待办
 func (m *ToDoModel) Cancel(ctx context.Context, id int64) error {
-	return m.db.WithContext(ctx).Model(&entity.ToDo{}).Where("id = ?", id).Update("status", entity.ToDoStatusCancelled).Error
+	return m.db.WithContext(ctx).Model(&entity.ToDo{}).Where("id = ?", id).Updates(bumpVersion(map[string]interface{}{"status": entity.ToDoStatusCancelled})).Error
 }
 
 // UpdateTags 更新待办标签
@@ -218,6 +229,11 @@ func (m *ToDoModel) BatchUpdate(ctx context.Context, updates []dto.ToDoUpdateDTO
 					fmt.Sprintf("第 %d 项（Code=%s）不存在", i+1, update.Code))
 				continue
 			}
+			if err := CheckVersion("待办", todo.Code, update.Version, todo.Version); err != nil {
+				result.Failed++
+				result.Errors = append(result.Errors, fmt.Sprintf("第 %d 项: %s", i+1, err.Error()))
+				continue
+			}
 
 			// 应用更新
 			if update.Title != nil {
@@ -240,7 +256,7 @@ func (m *ToDoModel) BatchUpdate(ctx context.Context, updates []dto.ToDoUpdateDTO
 				todo.DueDate = update.DueDate
 			}
 
-			if err := tx.Save(todo).Error; err != nil {
+			if err := updateVersioned(tx, todo, todo.ID, &todo.Version, "待办", todo.Code); err != nil {
 				result.Failed++
 				result.Errors = append(result.Errors,
 					fmt.Sprintf("第 %d 项（Code=%s）更新失败: %s", i+1, update.Code, err.Error()))
@@ -267,10 +283,10 @@ func (m *ToDoModel) BatchComplete(ctx context.Context, ids []int64) (*dto.ToDoBa
 		for _, id := range ids {
 			updateResult := tx.Model(&entity.ToDo{}).
 				Where("id = ? AND status != ?", id, entity.ToDoStatusCompleted).
-				Updates(map[string]interface{}{
+				Updates(bumpVersion(map[string]interface{}{
 					"status":       entity.ToDoStatusCompleted,
 					"completed_at": now,
-				})
+				}))
 
 			if updateResult.Error != nil {
 				result.Failed++
@@ -325,9 +341,9 @@ func (m *ToDoModel) BatchUpdateStatus(ctx context.Context, ids []int64, status e
 		Errors: make([]string, 0),
 	}
 
-	updates := map[string]interface{}{
+	updates := bumpVersion(map[string]interface{}{
 		"status": status,
-	}
+	})
 	if status == entity.ToDoStatusCompleted {
 		updates["completed_at"] = time.Now()
 	}
@@ -386,7 +402,7 @@ func (m *ToDoModel) CountByPlanID(ctx context.Context, planID int64) (total int6
 }
 
 // BatchDeleteByPathIDs 批量删除指定路径下的所有待办（用于 todo_final）
-// 返回删除的记录数量
+// 软删除，标签保留以便从回收站恢复；返回删除的记录数量
 func (m *ToDoModel) BatchDeleteByPathIDs(ctx context.Context, pathIDs []int64) (int64, error) {
 	if len(pathIDs) == 0 {
 		return 0, nil
@@ -407,13 +423,7 @@ func (m *ToDoModel) BatchDeleteByPathIDs(ctx context.Context, pathIDs []int64) (
 			return nil
 		}
 
-		// 2. 删除关联标签
-		if err := tx.Where("to_do_id IN ?", todoIDs).
-			Delete(&entity.ToDoTag{}).Error; err != nil {
-			return err
-		}
-
-		// 3. 删除待办
+		// 2. 删除待办（移入回收站）
 		result := tx.Where("id IN ?", todoIDs).Delete(&entity.ToDo{})
 		if result.Error != nil {
 			return result.Error
@@ -425,3 +435,57 @@ func (m *ToDoModel) BatchDeleteByPathIDs(ctx context.Context, pathIDs []int64) (
 
 	return deletedCount, err
 }
+
+// FindDeletedByPathOnlyFilter 查询回收站中单独删除的待办（按删除时间倒序）
+// 随计划一起删除的待办不单独列出，恢复计划时会一并恢复
+func (m *ToDoModel) FindDeletedByPathOnlyFilter(ctx context.Context, filter PathOnlyVisibilityFilter) ([]entity.ToDo, error) {
+	var todos []entity.ToDo
+	err := ApplyPathOnlyFilter(m.db.WithContext(ctx).Unscoped().Preload("Tags"), filter).
+		Where("deleted_at IS NOT NULL").
+		Where("plan_id NOT IN (?)", m.db.Unscoped().Model(&entity.Plan{}).Select("id").Where("deleted_at IS NOT NULL")).
+		Order("deleted_at DESC").
+		Find(&todos).Error
+	return todos, err
+}
+
+// FindDeletedByCode 根据 code 查找回收站中最近删除的待办
+func (m *ToDoModel) FindDeletedByCode(ctx context.Context, code string) (*entity.ToDo, error) {
+	var todo entity.ToDo
+	err := m.db.WithContext(ctx).Unscoped().
+		Where("code = ? AND deleted_at IS NOT NULL", code).
+		Order("deleted_at DESC").
+		First(&todo).Error
+	if err != nil {
+		return nil, err
+	}
+	return &todo, nil
+}
+
+// Restore 从回收站恢复待办
+func (m *ToDoModel) Restore(ctx context.Context, id int64) error {
+	return m.db.WithContext(ctx).Unscoped().Model(&entity.ToDo{}).
+		Where("id = ?", id).
+		Update("deleted_at", nil).Error
+}
+
+// PurgeDeletedBefore 彻底删除在 before 之前移入回收站的待办
+// 返回删除的数量
+func (m *ToDoModel) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		if err := tx.Unscoped().Model(&entity.ToDo{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := purgeToDo(tx, id); err != nil {
+				return err
+			}
+		}
+		purged = int64(len(ids))
+		return nil
+	})
+	return purged, err
+}
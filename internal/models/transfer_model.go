@@ -0,0 +1,283 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TransferModel 导入导出数据访问层
+// 嘿嘿~ 负责整库读取和在单个事务中写入导入数据！💖
+type TransferModel struct {
+	db *gorm.DB
+}
+
+// NewTransferModel 创建 TransferModel 实例
+func NewTransferModel(db *gorm.DB) *TransferModel {
+	return &TransferModel{db: db}
+}
+
+// Transaction 在事务中执行导入，fn 中的 TransferModel 绑定到该事务
+func (m *TransferModel) Transaction(ctx context.Context, fn func(tm *TransferModel) error) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&TransferModel{db: tx})
+	})
+}
+
+// SchemaVersion 当前数据库结构版本
+func (m *TransferModel) SchemaVersion(ctx context.Context) (int, error) {
+	return database.NewMigrator(m.db).CurrentVersion(ctx)
+}
+
+// LoadPaths 读取所有路径
+func (m *TransferModel) LoadPaths(ctx context.Context) ([]entity.PersonalPath, error) {
+	var paths []entity.PersonalPath
+	err := m.db.WithContext(ctx).Order("created_at ASC").Find(&paths).Error
+	return paths, err
+}
+
+// LoadGroups 读取所有组（预加载路径关联）
+func (m *TransferModel) LoadGroups(ctx context.Context) ([]entity.Group, error) {
+	var groups []entity.Group
+	err := m.db.WithContext(ctx).Preload("Paths").Order("created_at ASC").Find(&groups).Error
+	return groups, err
+}
+
+// LoadMemories 读取所有记忆（不含回收站，预加载标签）
+func (m *TransferModel) LoadMemories(ctx context.Context) ([]entity.Memory, error) {
+	var memories []entity.Memory
+	err := m.db.WithContext(ctx).Preload("Tags").Order("created_at ASC").Find(&memories).Error
+	return memories, err
+}
+
+// LoadPlans 读取所有计划（不含回收站）
+func (m *TransferModel) LoadPlans(ctx context.Context) ([]entity.Plan, error) {
+	var plans []entity.Plan
+	err := m.db.WithContext(ctx).Order("created_at ASC").Find(&plans).Error
+	return plans, err
+}
+
+// LoadToDos 读取所有待办（不含回收站，预加载标签）
+func (m *TransferModel) LoadToDos(ctx context.Context) ([]entity.ToDo, error) {
+	var todos []entity.ToDo
+	err := m.db.WithContext(ctx).Preload("Tags").Order("plan_id ASC, sort_order ASC, created_at ASC").Find(&todos).Error
+	return todos, err
+}
+
+// EnsurePath 确保路径存在并返回其 ID（路径原样保存，不做相对路径解析）
+func (m *TransferModel) EnsurePath(ctx context.Context, path, projectID string) (int64, error) {
+	var personalPath entity.PersonalPath
+	err := m.db.WithContext(ctx).Where("path = ?", path).First(&personalPath).Error
+	if err == nil {
+		return personalPath.ID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	personalPath = entity.PersonalPath{
+		ID:        database.GenerateID(),
+		Path:      path,
+		ProjectID: projectID,
+		LastVisit: time.Now(),
+	}
+	if err := m.db.WithContext(ctx).Create(&personalPath).Error; err != nil {
+		return 0, err
+	}
+	return personalPath.ID, nil
+}
+
+// FindPathByProjectID 根据项目标识查找本机路径
+// 导出方路径本机也已登记时优先使用它，否则取同一项目最近访问的检出
+func (m *TransferModel) FindPathByProjectID(ctx context.Context, path, projectID string) (*entity.PersonalPath, error) {
+	var personalPath entity.PersonalPath
+	err := m.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "CASE WHEN path = ? THEN 0 ELSE 1 END, last_visit DESC",
+			Vars: []interface{}{path},
+		}}).
+		Take(&personalPath).Error
+	if err != nil {
+		return nil, err
+	}
+	return &personalPath, nil
+}
+
+// EnsureGroup 按名称确保组存在并返回其 ID，已存在时复用
+func (m *TransferModel) EnsureGroup(ctx context.Context, group *entity.Group) (int64, error) {
+	var existing entity.Group
+	err := m.db.WithContext(ctx).Where("name = ?", group.Name).First(&existing).Error
+	if err == nil {
+		return existing.ID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	group.ID = database.GenerateID()
+	group.Paths = nil
+	if err := m.db.WithContext(ctx).Create(group).Error; err != nil {
+		return 0, err
+	}
+	return group.ID, nil
+}
+
+// AddGroupPath 将路径加入组
+// 路径已属于其他组时返回 false（一个路径只能属于一个组）
+func (m *TransferModel) AddGroupPath(ctx context.Context, groupID, pathID int64) (bool, error) {
+	var existing entity.GroupPath
+	err := m.db.WithContext(ctx).Where("personal_path_id = ?", pathID).First(&existing).Error
+	if err == nil {
+		return existing.GroupID == groupID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	groupPath := entity.GroupPath{
+		ID:             database.GenerateID(),
+		GroupID:        groupID,
+		PersonalPathID: pathID,
+	}
+	return true, m.db.WithContext(ctx).Create(&groupPath).Error
+}
+
+// FindMemoryByCode 根据 code 查找记忆（包括回收站，code 受唯一索引约束，预加载标签）
+func (m *TransferModel) FindMemoryByCode(ctx context.Context, code string) (*entity.Memory, error) {
+	var memory entity.Memory
+	err := m.db.WithContext(ctx).Unscoped().Preload("Tags").Where("code = ?", code).First(&memory).Error
+	if err != nil {
+		return nil, err
+	}
+	return &memory, nil
+}
+
+// InsertMemory 写入记忆、标签并同步全文索引
+func (m *TransferModel) InsertMemory(ctx context.Context, memory *entity.Memory, tags []string) error {
+	db := m.db.WithContext(ctx)
+	memory.ID = database.GenerateID()
+	memory.Tags = nil
+	if err := db.Create(memory).Error; err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := db.Create(&entity.MemoryTag{ID: database.GenerateID(), MemoryID: memory.ID, Tag: tag}).Error; err != nil {
+			return err
+		}
+	}
+	return database.SyncMemoryFTS(db, memory.ID)
+}
+
+// OverwriteMemory 用导入的内容原地覆盖记忆（保留 ID 和修订历史，回收站中的记忆会一并恢复）
+// revision 不为 nil 时先保存覆盖前的内容作为一条修订
+func (m *TransferModel) OverwriteMemory(ctx context.Context, memory *entity.Memory, tags []string, revision *entity.MemoryRevision) error {
+	db := m.db.WithContext(ctx)
+	if revision != nil {
+		if err := NewMemoryRevisionModel(db).Create(ctx, revision); err != nil {
+			return err
+		}
+	}
+	memory.Tags = nil
+	memory.DeletedAt = gorm.DeletedAt{}
+	if err := updateVersioned(db.Unscoped(), memory, memory.ID, &memory.Version, "记忆", memory.Code); err != nil {
+		return err
+	}
+	if err := db.Where("memory_id = ?", memory.ID).Delete(&entity.MemoryTag{}).Error; err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := db.Create(&entity.MemoryTag{ID: database.GenerateID(), MemoryID: memory.ID, Tag: tag}).Error; err != nil {
+			return err
+		}
+	}
+	return database.SyncMemoryFTS(db, memory.ID)
+}
+
+// FindPlanForImport 按导出 ID 或 code 查找本地计划（不限状态，不含回收站）
+// ID 命中优先（同一数据库导出再导入），其次是活跃计划，最后是最近更新的计划
+func (m *TransferModel) FindPlanForImport(ctx context.Context, id int64, code string) (*entity.Plan, error) {
+	var plan entity.Plan
+	err := m.db.WithContext(ctx).
+		Where("id = ? OR code = ?", id, code).
+		Order(importMatchOrder(id, []entity.PlanStatus{entity.PlanStatusCompleted, entity.PlanStatusCancelled})).
+		Take(&plan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// InsertPlan 写入计划
+func (m *TransferModel) InsertPlan(ctx context.Context, plan *entity.Plan) error {
+	plan.ID = database.GenerateID()
+	plan.Todos = nil
+	return m.db.WithContext(ctx).Create(plan).Error
+}
+
+// UpdatePlan 用导入的内容原地覆盖计划（保留 ID，计划下已有的待办不受影响）
+func (m *TransferModel) UpdatePlan(ctx context.Context, plan *entity.Plan) error {
+	plan.Todos = nil
+	return updateVersioned(m.db.WithContext(ctx), plan, plan.ID, &plan.Version, "计划", plan.Code)
+}
+
+// FindToDoForImport 按导出 ID 或 code 查找本地待办（不限状态，不含回收站）
+// 匹配顺序同 FindPlanForImport
+func (m *TransferModel) FindToDoForImport(ctx context.Context, id int64, code string) (*entity.ToDo, error) {
+	var todo entity.ToDo
+	err := m.db.WithContext(ctx).
+		Where("id = ? OR code = ?", id, code).
+		Order(importMatchOrder(id, []entity.ToDoStatus{entity.ToDoStatusCompleted, entity.ToDoStatusCancelled})).
+		Take(&todo).Error
+	if err != nil {
+		return nil, err
+	}
+	return &todo, nil
+}
+
+// InsertToDo 写入待办及标签
+func (m *TransferModel) InsertToDo(ctx context.Context, todo *entity.ToDo, tags []string) error {
+	db := m.db.WithContext(ctx)
+	todo.ID = database.GenerateID()
+	todo.Tags = nil
+	if err := db.Create(todo).Error; err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := db.Create(&entity.ToDoTag{ID: database.GenerateID(), ToDoID: todo.ID, Tag: tag}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateToDo 用导入的内容原地覆盖待办并替换标签（保留 ID）
+func (m *TransferModel) UpdateToDo(ctx context.Context, todo *entity.ToDo, tags []string) error {
+	db := m.db.WithContext(ctx)
+	todo.Tags = nil
+	if err := updateVersioned(db, todo, todo.ID, &todo.Version, "待办", todo.Code); err != nil {
+		return err
+	}
+	if err := db.Where("to_do_id = ?", todo.ID).Delete(&entity.ToDoTag{}).Error; err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := db.Create(&entity.ToDoTag{ID: database.GenerateID(), ToDoID: todo.ID, Tag: tag}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importMatchOrder 导入匹配的排序：ID 命中 > 未结束（finished 为已结束的状态列表）> 最近更新
+func importMatchOrder(id int64, finished interface{}) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:  "CASE WHEN id = ? THEN 0 ELSE 1 END, CASE WHEN status IN ? THEN 1 ELSE 0 END, updated_at DESC",
+		Vars: []interface{}{id, finished},
+	}}
+}
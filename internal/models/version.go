@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 嘿嘿~ 这是乐观锁！🔒
+// 记忆、计划、待办都带有 version 列，每次更新 +1。更新时只有数据库中的版本与读取时一致才会写入，
+// 否则说明在读取之后有别人（另一个 TUI、MCP 客户端或 CLI）改过这条记录，返回版本冲突而不是悄悄覆盖~
+
+// ErrVersionConflict 版本冲突（记录在读取之后已被修改）
+// 使用 errors.Is(err, ErrVersionConflict) 判断，详细信息见 VersionConflictError
+var ErrVersionConflict = errors.New("记录已被修改")
+
+// VersionConflictError 版本冲突详情
+type VersionConflictError struct {
+	Entity   string // 记录类型（记忆/计划/待办）
+	Code     string // 记录标识码
+	Expected int64  // 调用方读取时的版本
+	Actual   int64  // 数据库中的当前版本
+}
+
+// Error 实现 error 接口
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s %s 在读取后已被修改（读取时版本 %d，当前版本 %d），请重新读取后再更新",
+		e.Entity, e.Code, e.Expected, e.Actual)
+}
+
+// Unwrap 支持 errors.Is(err, ErrVersionConflict)
+func (e *VersionConflictError) Unwrap() error {
+	return ErrVersionConflict
+}
+
+// CheckVersion 校验调用方期望的版本
+// expected 为 nil 时不校验（以本次读取到的版本为准）
+func CheckVersion(entityName, code string, expected *int64, actual int64) error {
+	if expected != nil && *expected != actual {
+		return &VersionConflictError{Entity: entityName, Code: code, Expected: *expected, Actual: actual}
+	}
+	return nil
+}
+
+// updateVersioned 按版本条件更新整条记录（不含关联），成功后 version 自动 +1
+// 数据库中的版本与 *version 不一致时返回 VersionConflictError，记录不存在时返回 gorm.ErrRecordNotFound
+// 注意：不能用 Save，Save 在没有更新到行时会退化为插入
+func updateVersioned(tx *gorm.DB, record interface{}, id int64, version *int64, entityName, code string) error {
+	expected := *version
+	*version = expected + 1
+	result := tx.Model(record).
+		Where("version = ?", expected).
+		Select("*").Omit(clause.Associations).
+		Updates(record)
+	if result.Error != nil {
+		*version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 1 {
+		return nil
+	}
+
+	*version = expected
+	var actual []int64
+	if err := tx.Session(&gorm.Session{NewDB: true}).Model(record).Where("id = ?", id).Pluck("version", &actual).Error; err != nil {
+		return err
+	}
+	if len(actual) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return &VersionConflictError{Entity: entityName, Code: code, Expected: expected, Actual: actual[0]}
+}
+
+// bumpVersion 字段级更新时同时递增版本号
+// 归档、完成、更新标签等操作不经过 updateVersioned，也需要让正在编辑的一方感知到变化
+func bumpVersion(updates map[string]interface{}) map[string]interface{} {
+	updates["version"] = gorm.Expr("version + 1")
+	return updates
+}
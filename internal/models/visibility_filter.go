@@ -40,17 +40,19 @@ func DefaultPathOnlyFilter() PathOnlyVisibilityFilter {
 // applyVisibilityFilter 将过滤条件应用到查询上
 // 统一的查询拼装逻辑，避免各模型重复造轮子~
 func applyVisibilityFilter(db *gorm.DB, filter VisibilityFilter) *gorm.DB {
+	// 布尔值使用参数绑定：SQLite 存为 0/1，PostgreSQL 为 boolean，不能写死 global = 1
 	conditions := make([]string, 0, 2)
-	args := make([]interface{}, 0, 1)
+	args := make([]interface{}, 0, 3)
 
 	if filter.IncludeGlobal {
-		conditions = append(conditions, "global = 1")
+		conditions = append(conditions, "global = ?")
+		args = append(args, true)
 	}
 
 	if filter.IncludeNonGlobal {
 		if len(filter.PathIDs) > 0 {
-			conditions = append(conditions, "(global = 0 AND path_id IN ?)")
-			args = append(args, filter.PathIDs)
+			conditions = append(conditions, "(global = ? AND path_id IN ?)")
+			args = append(args, false, filter.PathIDs)
 		}
 		// PathIDs 为空时，不添加非全局条件（只返回全局数据，安全优先）
 	}
@@ -93,3 +95,27 @@ func ApplyPathOnlyFilter(db *gorm.DB, filter PathOnlyVisibilityFilter) *gorm.DB
 	}
 	return db.Where("path_id IN ?", filter.PathIDs)
 }
+
+// Matches 判断一条记录是否在过滤范围内（与 applyVisibilityFilter 的 SQL 条件一致）
+// 供不经过 SQL 的仓储实现（如 memstore）使用
+func (f VisibilityFilter) Matches(global bool, pathID int64) bool {
+	if global {
+		return f.IncludeGlobal
+	}
+	return f.IncludeNonGlobal && containsPathID(f.PathIDs, pathID)
+}
+
+// Matches 判断一条记录是否在路径过滤范围内（与 ApplyPathOnlyFilter 的 SQL 条件一致）
+func (f PathOnlyVisibilityFilter) Matches(pathID int64) bool {
+	return containsPathID(f.PathIDs, pathID)
+}
+
+// containsPathID 判断路径列表是否包含指定路径
+func containsPathID(pathIDs []int64, pathID int64) bool {
+	for _, id := range pathIDs {
+		if id == pathID {
+			return true
+		}
+	}
+	return false
+}
@@ -11,21 +11,33 @@ import (
 	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
 	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
 	"github.com/XiaoLFeng/llm-memory/pkg/types"
+	"github.com/XiaoLFeng/llm-memory/pkg/utils"
 )
 
 // GroupService 组服务层
 // 用于管理 Group 的业务逻辑
 type GroupService struct {
-	model *models.GroupModel
+	model      models.GroupRepository
+	resolution types.ScopeResolution // 作用域路径解析模式
 }
 
 // NewGroupService 创建新的组服务实例
-func NewGroupService(model *models.GroupModel) *GroupService {
+// resolution 为空时使用精确匹配
+func NewGroupService(model models.GroupRepository, resolution types.ScopeResolution) *GroupService {
+	if resolution == "" {
+		resolution = types.ScopeResolutionExact
+	}
 	return &GroupService{
-		model: model,
+		model:      model,
+		resolution: resolution,
 	}
 }
 
+// Resolution 获取作用域路径解析模式
+func (s *GroupService) Resolution() types.ScopeResolution {
+	return s.resolution
+}
+
 // CreateGroup 创建新组
 func (s *GroupService) CreateGroup(ctx context.Context, name, description string) (*entity.Group, error) {
 	// 验证组名
@@ -116,12 +128,14 @@ func (s *GroupService) ListGroups(ctx context.Context) ([]entity.Group, error) {
 }
 
 // AddCurrentPath 将当前工作目录添加到组
+// 按解析模式映射（如 git-root 模式下添加仓库根目录）
 func (s *GroupService) AddCurrentPath(ctx context.Context, groupID int64) error {
 	pwd, err := os.Getwd()
 	if err != nil {
 		return errors.New("无法获取当前工作目录: " + err.Error())
 	}
-	return s.AddPath(ctx, groupID, pwd)
+	scopePath, _ := s.ResolveScopePath(ctx, pwd)
+	return s.AddPath(ctx, groupID, scopePath)
 }
 
 // AddPath 添加指定路径到组
@@ -157,13 +171,13 @@ func (s *GroupService) AddPathByName(ctx context.Context, groupName string, path
 		return errors.New("组不存在: " + groupName)
 	}
 
-	// 如果路径为空，使用当前目录
+	// 如果路径为空，使用当前目录（按解析模式映射）
 	if path == "" {
 		pwd, err := os.Getwd()
 		if err != nil {
 			return errors.New("无法获取当前工作目录: " + err.Error())
 		}
-		path = pwd
+		path, _ = s.ResolveScopePath(ctx, pwd)
 	}
 
 	return s.AddPath(ctx, group.ID, path)
@@ -215,8 +229,38 @@ func (s *GroupService) GetGroupByPath(ctx context.Context, path string) (*entity
 	return s.model.FindByPath(ctx, absPath)
 }
 
+// ResolveScopePath 按解析模式把工作目录映射为用于匹配的目录
+// 返回映射后的绝对路径和实际命中的规则；祖先目录或 git 根目录找不到时退回精确匹配
+func (s *GroupService) ResolveScopePath(ctx context.Context, pwd string) (string, types.ScopeResolution) {
+	absPath, err := filepath.Abs(pwd)
+	if err != nil {
+		absPath = pwd
+	}
+
+	switch s.resolution {
+	case types.ScopeResolutionAncestor:
+		// 从当前目录逐级向上，找到第一个已登记的路径
+		for dir := absPath; ; {
+			if _, err := s.model.GetPathIDByPath(ctx, dir); err == nil {
+				return dir, types.ScopeResolutionAncestor
+			}
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	case types.ScopeResolutionGitRoot:
+		if root, ok := utils.FindGitRoot(absPath); ok {
+			return root, types.ScopeResolutionGitRoot
+		}
+	}
+	return absPath, types.ScopeResolutionExact
+}
+
 // ResolveScope 解析当前作用域
 // 这是核心方法，根据 pwd 确定当前的 ScopeContext
+// 先按解析模式映射目录，再用映射后的目录查找 PathID 和组
 // 纯关联模式：会填充 PathID 和 GroupPathIDs
 func (s *GroupService) ResolveScope(ctx context.Context, pwd string) (*types.ScopeContext, error) {
 	// 规范化路径
@@ -227,15 +271,18 @@ func (s *GroupService) ResolveScope(ctx context.Context, pwd string) (*types.Sco
 
 	// 创建默认的作用域上下文
 	scope := types.NewScopeContext(absPath)
+	scope.ScopePath, scope.MatchedRule = s.ResolveScopePath(ctx, absPath)
+	scope.ProjectID = utils.ProjectIdentity(scope.ScopePath)
 
-	// 获取当前路径的 PathID
-	pathID, err := s.model.GetPathIDByPath(ctx, absPath)
+	// 获取映射后路径的 PathID（路径未登记时按项目标识匹配其他位置的检出）
+	matchPath := s.matchRegisteredPath(ctx, scope.ScopePath, scope.ProjectID)
+	pathID, err := s.model.GetPathIDByPath(ctx, matchPath)
 	if err == nil {
 		scope.PathID = pathID
 	}
 
 	// 查找路径所属的组
-	group, err := s.model.FindByPath(ctx, absPath)
+	group, err := s.model.FindByPath(ctx, matchPath)
 	if err != nil {
 		// 查找失败，使用默认作用域
 		return scope, nil
@@ -256,6 +303,18 @@ func (s *GroupService) ResolveScope(ctx context.Context, pwd string) (*types.Sco
 	return scope, nil
 }
 
+// matchRegisteredPath 获取用于查找 PathID 和组的已登记路径
+// 路径本身已登记时直接使用；否则按项目标识匹配同一项目的其他检出（如导入的其他机器上的路径）
+func (s *GroupService) matchRegisteredPath(ctx context.Context, path, projectID string) string {
+	if _, err := s.model.GetPathIDByPath(ctx, path); err == nil || projectID == "" {
+		return path
+	}
+	if p, err := s.model.FindPathByProjectID(ctx, projectID); err == nil {
+		return p.Path
+	}
+	return path
+}
+
 // GetCurrentScope 获取当前工作目录的作用域
 func (s *GroupService) GetCurrentScope(ctx context.Context) (*types.ScopeContext, error) {
 	pwd, err := os.Getwd()
@@ -286,9 +345,11 @@ func (s *GroupService) GetScopeInfo(ctx context.Context) (*dto.ScopeInfoDTO, err
 		CurrentPath: absPath,
 		IsInGroup:   false,
 	}
+	info.ScopePath, info.MatchedRule = s.ResolveScopePath(ctx, absPath)
+	info.ProjectID = utils.ProjectIdentity(info.ScopePath)
 
 	// 查找所属组
-	group, err := s.model.FindByPath(ctx, absPath)
+	group, err := s.model.FindByPath(ctx, s.matchRegisteredPath(ctx, info.ScopePath, info.ProjectID))
 	if err == nil && group != nil {
 		info.GroupID = group.ID
 		info.GroupName = group.Name
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
+)
+
+// TestResolveScopeModes exact / ancestor / git-root 三种解析模式下映射到的目录、PathID 和组
+//
+//	repo/            git 仓库根目录（已登记，属于组 team）
+//	repo/sub/        已登记
+//	repo/sub/deep/   未登记
+//	repo/other/      未登记
+//	plain/deep/      不在 git 仓库中，都未登记
+func TestResolveScopeModes(t *testing.T) {
+	for _, backend := range testBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc := backend.setup(t)
+			root := t.TempDir()
+			dir := func(rel string) string { return filepath.Join(root, filepath.FromSlash(rel)) }
+			for _, rel := range []string{"repo/.git", "repo/sub/deep", "repo/other", "plain/deep"} {
+				if err := os.MkdirAll(dir(rel), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			pathIDs := map[string]int64{}
+			for _, rel := range []string{"repo", "repo/sub"} {
+				path, err := svc.ensurePath(ctx, dir(rel))
+				if err != nil {
+					t.Fatalf("登记路径失败: %v", err)
+				}
+				pathIDs[rel] = path.ID
+			}
+			group, err := svc.group.CreateGroup(ctx, "team", "")
+			if err != nil {
+				t.Fatalf("创建组失败: %v", err)
+			}
+			if err := svc.group.AddPath(ctx, group.ID, dir("repo")); err != nil {
+				t.Fatalf("加入组失败: %v", err)
+			}
+
+			cases := []struct {
+				name       string
+				mode       types.ScopeResolution
+				pwd        string
+				wantPath   string // 映射后的目录
+				wantRule   types.ScopeResolution
+				wantPathID string // 期望命中的已登记路径，空表示未登记
+				wantGroup  string
+			}{
+				{name: "exact 命中已登记目录", mode: types.ScopeResolutionExact, pwd: "repo", wantPath: "repo", wantRule: types.ScopeResolutionExact, wantPathID: "repo", wantGroup: "team"},
+				{name: "exact 不向上查找", mode: types.ScopeResolutionExact, pwd: "repo/sub/deep", wantPath: "repo/sub/deep", wantRule: types.ScopeResolutionExact},
+				{name: "ancestor 当前目录已登记", mode: types.ScopeResolutionAncestor, pwd: "repo/sub", wantPath: "repo/sub", wantRule: types.ScopeResolutionAncestor, wantPathID: "repo/sub"},
+				{name: "ancestor 取最近的祖先", mode: types.ScopeResolutionAncestor, pwd: "repo/sub/deep", wantPath: "repo/sub", wantRule: types.ScopeResolutionAncestor, wantPathID: "repo/sub"},
+				{name: "ancestor 向上找到组成员", mode: types.ScopeResolutionAncestor, pwd: "repo/other", wantPath: "repo", wantRule: types.ScopeResolutionAncestor, wantPathID: "repo", wantGroup: "team"},
+				{name: "ancestor 没有已登记的祖先时退回 exact", mode: types.ScopeResolutionAncestor, pwd: "plain/deep", wantPath: "plain/deep", wantRule: types.ScopeResolutionExact},
+				{name: "git-root 忽略已登记的子目录", mode: types.ScopeResolutionGitRoot, pwd: "repo/sub/deep", wantPath: "repo", wantRule: types.ScopeResolutionGitRoot, wantPathID: "repo", wantGroup: "team"},
+				{name: "git-root 不在仓库中时退回 exact", mode: types.ScopeResolutionGitRoot, pwd: "plain/deep", wantPath: "plain/deep", wantRule: types.ScopeResolutionExact},
+			}
+			for _, tc := range cases {
+				t.Run(tc.name, func(t *testing.T) {
+					groupService := NewGroupService(svc.group.model, tc.mode)
+					scopeCtx, err := groupService.ResolveScope(ctx, dir(tc.pwd))
+					if err != nil {
+						t.Fatalf("解析作用域失败: %v", err)
+					}
+					if scopeCtx.CurrentPath != dir(tc.pwd) {
+						t.Fatalf("CurrentPath = %s，应保持为工作目录 %s", scopeCtx.CurrentPath, dir(tc.pwd))
+					}
+					if scopeCtx.ScopePath != dir(tc.wantPath) || scopeCtx.MatchedRule != tc.wantRule {
+						t.Fatalf("映射到 %s（%s），期望 %s（%s）", scopeCtx.ScopePath, scopeCtx.MatchedRule, dir(tc.wantPath), tc.wantRule)
+					}
+					if scopeCtx.PathID != pathIDs[tc.wantPathID] {
+						t.Fatalf("PathID = %d，期望 %d（%s）", scopeCtx.PathID, pathIDs[tc.wantPathID], tc.wantPathID)
+					}
+					if scopeCtx.GroupName != tc.wantGroup {
+						t.Fatalf("组 = %q，期望 %q", scopeCtx.GroupName, tc.wantGroup)
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
+	"github.com/XiaoLFeng/llm-memory/pkg/utils"
+)
+
+// markdownNote 从 Markdown 文件解析出的记忆字段
+type markdownNote struct {
+	code     string
+	title    string
+	content  string
+	category string   // 空表示 front matter 未指定
+	tags     []string // nil 表示 front matter 未指定
+	priority int      // 0 表示 front matter 未指定
+}
+
+// ImportMarkdownDir 将目录中的 Markdown 文件导入为当前作用域的记忆
+// 嘿嘿~ 团队仓库里的 ADR、笔记都能一键变成记忆！📝
+// 标题取 front matter 的 title 或第一个标题，code 取文件名的 slug（front matter 的 code 优先），
+// 标签和分类取自 front matter；code 已存在时更新该记忆而不是报错
+func (s *MemoryService) ImportMarkdownDir(ctx context.Context, dir string, scopeCtx *types.ScopeContext) (*dto.MemoryImportResultDTO, error) {
+	if resolveDefaultPathID(scopeCtx) == 0 {
+		return nil, errors.New("无法确定当前路径作用域，请在项目目录中执行")
+	}
+	stat, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !stat.IsDir() {
+		return nil, fmt.Errorf("%s 不是目录", dir)
+	}
+
+	result := &dto.MemoryImportResultDTO{}
+	seen := make(map[string]string) // code -> 首个使用该 code 的文件
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// 跳过 .git、.obsidian 等隐藏目录
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isMarkdownFile(d.Name()) {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+		note, err := parseMarkdownNote(path, rel)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", rel, err))
+			return nil
+		}
+		if first, ok := seen[note.code]; ok {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: 标识码 %s 与 %s 重复，已跳过", rel, note.code, first))
+			return nil
+		}
+		seen[note.code] = rel
+
+		if err := s.importMarkdownNote(ctx, note, scopeCtx, result); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", rel, err))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// importMarkdownNote 创建或更新单条记忆
+func (s *MemoryService) importMarkdownNote(ctx context.Context, note *markdownNote, scopeCtx *types.ScopeContext, result *dto.MemoryImportResultDTO) error {
+	exists, err := s.memoryModel.ExistsCode(ctx, note.code, 0)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if _, err := s.memoryModel.FindDeletedByCode(ctx, note.code); err == nil {
+			return fmt.Errorf("标识码 %s 已被回收站中的记忆占用", note.code)
+		}
+		// front matter 未指定优先级时为 0，由 CreateMemory 使用默认优先级
+		if _, err := s.CreateMemory(ctx, &dto.MemoryCreateDTO{
+			Code:     note.code,
+			Title:    note.title,
+			Content:  note.content,
+			Category: note.category,
+			Tags:     note.tags,
+			Priority: note.priority,
+		}, scopeCtx); err != nil {
+			return err
+		}
+		result.Created = append(result.Created, note.code)
+		return nil
+	}
+
+	memory, err := s.memoryModel.FindByCode(ctx, note.code)
+	if err != nil {
+		return fmt.Errorf("记忆 %s 已归档，无法更新", note.code)
+	}
+	if !memory.Global && !isPathVisible(memory.PathID, scopeCtx) {
+		return fmt.Errorf("标识码 %s 已被其他作用域的记忆占用", note.code)
+	}
+
+	// 只更新 front matter 中出现的字段
+	input := &dto.MemoryUpdateDTO{Code: note.code, Title: &note.title, Content: &note.content}
+	before := snapshotMemory(memory, 0)
+	after := before
+	after.Title, after.Content = note.title, note.content
+	if note.category != "" {
+		input.Category = &note.category
+		after.Category = note.category
+	}
+	if note.tags != nil {
+		input.Tags = &note.tags
+		after.Tags = note.tags
+	}
+	if note.priority != 0 {
+		input.Priority = &note.priority
+		after.Priority = note.priority
+	}
+	sort.Strings(before.Tags)
+	if snapshotEqual(before, after) {
+		result.Unchanged++
+		return nil
+	}
+
+	if err := s.UpdateMemory(ctx, input); err != nil {
+		return err
+	}
+	result.Updated = append(result.Updated, note.code)
+	return nil
+}
+
+// parseMarkdownNote 读取并解析 Markdown 文件
+func parseMarkdownNote(path, rel string) (*markdownNote, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fm, body := utils.ParseFrontMatter(string(data))
+
+	note := &markdownNote{
+		category: strings.TrimSpace(fm.String("category")),
+	}
+
+	// code：front matter 优先，否则使用文件名 slug
+	if code := strings.TrimSpace(fm.String("code")); code != "" {
+		if err := entity.ValidateCode(code); err != nil {
+			return nil, err
+		}
+		note.code = code
+	} else {
+		note.code = markdownCode(rel)
+	}
+
+	// 标题：front matter 优先，其次第一个标题，最后使用文件名
+	note.title = strings.TrimSpace(fm.String("title"))
+	if note.title == "" {
+		note.title = utils.FirstHeading(body)
+	}
+	if note.title == "" {
+		note.title = strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel))
+	}
+
+	note.content = stripTitleHeading(body, note.title)
+	if note.content == "" {
+		return nil, errors.New("正文为空，已跳过")
+	}
+
+	if _, ok := fm["tags"]; ok {
+		note.tags = normalizeImportTags(fm.List("tags"))
+	}
+	if p := fm.String("priority"); p != "" {
+		priority, err := strconv.Atoi(p)
+		if err != nil || priority < 1 || priority > 4 {
+			return nil, errors.New("priority 必须在 1-4 之间")
+		}
+		note.priority = priority
+	}
+	return note, nil
+}
+
+// markdownCode 根据文件名生成记忆 code
+// slug 为空（如纯中文文件名）时使用相对路径的哈希，保证重复导入得到相同的 code
+func markdownCode(rel string) string {
+	name := strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel))
+	slug := utils.Slugify(name)
+	if slug == "" {
+		sum := sha1.Sum([]byte(rel))
+		return "doc-" + hex.EncodeToString(sum[:])[:8]
+	}
+	if !entity.IsValidCode(slug) {
+		// 以数字开头（如 0001-use-go）或过短时加前缀
+		slug = "doc-" + slug
+	}
+	return slug
+}
+
+// stripTitleHeading 去掉正文开头与标题相同的标题行，避免内容里重复标题
+func stripTitleHeading(body, title string) string {
+	body = strings.TrimSpace(body)
+	first, rest, _ := strings.Cut(body, "\n")
+	if utils.FirstHeading(first) == title {
+		return strings.TrimSpace(rest)
+	}
+	return body
+}
+
+// normalizeImportTags 去掉 Obsidian 标签的 # 前缀，去重并排序
+func normalizeImportTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// isMarkdownFile 判断是否为 Markdown 文件
+func isMarkdownFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
+)
+
+// writeMarkdownFiles 在目录下写入 Markdown 文件，key 为相对路径
+func writeMarkdownFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestParseMarkdownNote(t *testing.T) {
+	hashCode := func(rel string) string {
+		sum := sha1.Sum([]byte(rel))
+		return "doc-" + hex.EncodeToString(sum[:])[:8]
+	}
+	cases := []struct {
+		name    string
+		rel     string
+		text    string
+		want    markdownNote
+		wantErr string
+	}{
+		{
+			name: "标题取第一个标题并从正文去掉",
+			rel:  "wal-mode.md",
+			text: "# 启用 WAL\n\n并发写入更快",
+			want: markdownNote{code: "wal-mode", title: "启用 WAL", content: "并发写入更快"},
+		},
+		{
+			name: "front matter 优先",
+			rel:  "notes/other-name.md",
+			text: "---\ncode: custom-code\ntitle: \"FM 标题\"\ncategory: 架构\ntags: [\"#go\", db, go]\npriority: 3\n---\n# 正文标题\n\n内容",
+			want: markdownNote{code: "custom-code", title: "FM 标题", content: "# 正文标题\n\n内容", category: "架构", tags: []string{"db", "go"}, priority: 3},
+		},
+		{
+			name: "块列表标签",
+			rel:  "tags.md",
+			text: "---\ntags:\n  - '#b'\n  - a\n---\n正文",
+			want: markdownNote{code: "tags", title: "tags", content: "正文", tags: []string{"a", "b"}},
+		},
+		{
+			name: "空标签列表表示清空",
+			rel:  "no-tags.md",
+			text: "---\ntags: []\n---\n正文",
+			want: markdownNote{code: "no-tags", title: "no-tags", content: "正文", tags: []string{}},
+		},
+		{
+			name: "数字开头的文件名加前缀",
+			rel:  "adr/0001-use-go.md",
+			text: "正文",
+			want: markdownNote{code: "doc-0001-use-go", title: "0001-use-go", content: "正文"},
+		},
+		{
+			name: "纯中文文件名使用路径哈希",
+			rel:  "设计/笔记.markdown",
+			text: "正文",
+			want: markdownNote{code: hashCode("设计/笔记.markdown"), title: "笔记", content: "正文"},
+		},
+		{name: "优先级下限", rel: "p1.md", text: "---\npriority: 1\n---\n正文", want: markdownNote{code: "doc-p1", title: "p1", content: "正文", priority: 1}},
+		{name: "优先级上限", rel: "p4.md", text: "---\npriority: 4\n---\n正文", want: markdownNote{code: "doc-p4", title: "p4", content: "正文", priority: 4}},
+		{name: "优先级为 0", rel: "p.md", text: "---\npriority: 0\n---\n正文", wantErr: "priority 必须在 1-4 之间"},
+		{name: "优先级超出范围", rel: "p.md", text: "---\npriority: 5\n---\n正文", wantErr: "priority 必须在 1-4 之间"},
+		{name: "优先级不是数字", rel: "p.md", text: "---\npriority: high\n---\n正文", wantErr: "priority 必须在 1-4 之间"},
+		{name: "front matter 的 code 无效", rel: "p.md", text: "---\ncode: Bad_Code\n---\n正文", wantErr: "code 格式错误"},
+		{name: "只有标题的正文为空", rel: "empty.md", text: "# 标题\n", wantErr: "正文为空"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "note.md")
+			if err := os.WriteFile(path, []byte(tc.text), 0644); err != nil {
+				t.Fatal(err)
+			}
+			note, err := parseMarkdownNote(path, tc.rel)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("错误 = %v，期望包含 %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if !reflect.DeepEqual(*note, tc.want) {
+				t.Fatalf("解析结果 = %+v，期望 %+v", *note, tc.want)
+			}
+		})
+	}
+}
+
+// TestImportMarkdownDir 新建、更新、未变化和各种跳过的情况
+func TestImportMarkdownDir(t *testing.T) {
+	for _, backend := range testBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc := backend.setup(t)
+			root := t.TempDir()
+			resolve := func(name string) *types.ScopeContext {
+				t.Helper()
+				dir := filepath.Join(root, name)
+				if err := os.Mkdir(dir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := svc.ensurePath(ctx, dir); err != nil {
+					t.Fatalf("注册路径失败: %v", err)
+				}
+				scopeCtx, err := svc.group.ResolveScope(ctx, dir)
+				if err != nil {
+					t.Fatalf("解析作用域失败: %v", err)
+				}
+				return scopeCtx
+			}
+			project, other := resolve("project"), resolve("other")
+
+			create := func(code string, global bool, scopeCtx *types.ScopeContext) int64 {
+				t.Helper()
+				memory, err := svc.memory.CreateMemory(ctx, &dto.MemoryCreateDTO{Code: code, Title: code, Content: "旧内容", Global: global}, scopeCtx)
+				if err != nil {
+					t.Fatalf("创建记忆 %s 失败: %v", code, err)
+				}
+				return memory.ID
+			}
+			create("global", true, project)
+			create("taken", false, other)
+			if err := svc.memory.ArchiveMemory(ctx, create("archived", false, project)); err != nil {
+				t.Fatalf("归档失败: %v", err)
+			}
+			create("trashed", false, project)
+			if err := svc.memory.DeleteMemory(ctx, "trashed"); err != nil {
+				t.Fatalf("删除失败: %v", err)
+			}
+
+			notes := filepath.Join(root, "notes")
+			writeMarkdownFiles(t, notes, map[string]string{
+				"a-note.md":           "# 笔记 A\n\n内容 A",
+				"archived.md":         "内容",
+				"dup.md":              "---\ncode: a-note\n---\n重复的 code",
+				"empty.md":            "# 空\n",
+				"global.md":           "---\ntags: [go]\n---\n新内容",
+				"taken.md":            "内容",
+				"trashed.md":          "内容",
+				"readme.txt":          "不是 Markdown",
+				".obsidian/hidden.md": "隐藏目录",
+			})
+
+			importDir := func() *dto.MemoryImportResultDTO {
+				t.Helper()
+				result, err := svc.memory.ImportMarkdownDir(ctx, notes, project)
+				if err != nil {
+					t.Fatalf("导入失败: %v", err)
+				}
+				return result
+			}
+			assertResult := func(step string, got *dto.MemoryImportResultDTO, created, updated []string, unchanged int) {
+				t.Helper()
+				assertCodes(t, step+"新建", got.Created, created)
+				assertCodes(t, step+"更新", got.Updated, updated)
+				if got.Unchanged != unchanged {
+					t.Fatalf("%s未变化 %d 个，期望 %d", step, got.Unchanged, unchanged)
+				}
+			}
+
+			first := importDir()
+			assertResult("首次导入", first, []string{"a-note"}, []string{"global"}, 0)
+			wantWarnings := []string{"archived.md: 记忆 archived 已归档", "dup.md: 标识码 a-note 与 a-note.md 重复", "empty.md: 正文为空", "taken.md: 标识码 taken 已被其他作用域", "trashed.md: 标识码 trashed 已被回收站"}
+			if len(first.Warnings) != len(wantWarnings) {
+				t.Fatalf("警告 = %q，期望 %d 条", first.Warnings, len(wantWarnings))
+			}
+			for i, want := range wantWarnings {
+				if !strings.HasPrefix(first.Warnings[i], want) {
+					t.Fatalf("第 %d 条警告 = %q，期望以 %q 开头", i, first.Warnings[i], want)
+				}
+			}
+
+			note, err := svc.memory.GetMemory(ctx, "a-note")
+			if err != nil {
+				t.Fatalf("获取导入的记忆失败: %v", err)
+			}
+			if note.Title != "笔记 A" || note.Content != "内容 A" || note.PathID != project.PathID || note.Priority != 1 {
+				t.Fatalf("导入的记忆不符: %+v", note)
+			}
+			global, err := svc.memory.GetMemory(ctx, "global")
+			if err != nil {
+				t.Fatalf("获取全局记忆失败: %v", err)
+			}
+			if !global.Global || global.Content != "新内容" || !reflect.DeepEqual(global.GetTagStrings(), []string{"go"}) {
+				t.Fatalf("全局记忆应被更新且保持全局: %+v", global)
+			}
+			if _, err := svc.memory.GetMemory(ctx, "hidden"); err == nil {
+				t.Fatal("隐藏目录中的文件不应导入")
+			}
+
+			assertResult("重复导入", importDir(), nil, nil, 2)
+
+			// front matter 指定优先级时更新，去掉后保留原优先级
+			writeMarkdownFiles(t, notes, map[string]string{"a-note.md": "---\npriority: 3\n---\n# 笔记 A\n\n内容 A"})
+			assertResult("指定优先级", importDir(), nil, []string{"a-note"}, 1)
+			writeMarkdownFiles(t, notes, map[string]string{"a-note.md": "# 笔记 A\n\n内容 A"})
+			assertResult("去掉优先级", importDir(), nil, nil, 2)
+			if note, err = svc.memory.GetMemory(ctx, "a-note"); err != nil || note.Priority != 3 {
+				t.Fatalf("优先级应保持为 3: %+v（%v）", note, err)
+			}
+
+			if _, err := svc.memory.ImportMarkdownDir(ctx, notes, &types.ScopeContext{}); err == nil {
+				t.Fatal("没有路径作用域时应报错")
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
+	"github.com/XiaoLFeng/llm-memory/pkg/utils"
+)
+
+// 嘿嘿~ 这是记忆的修订历史！(´∀｀)💖
+// 每次修改前都会把旧值存成一条修订，改坏了也能找回来~
+// 修订号 N 表示「第 N 次修改之前」的内容，0 表示当前版本
+
+// ListMemoryRevisions 获取记忆及其修订历史（按修订号降序）
+func (s *MemoryService) ListMemoryRevisions(ctx context.Context, code string) (*entity.Memory, []entity.MemoryRevision, error) {
+	memory, err := s.findMemoryForRevision(ctx, code)
+	if err != nil {
+		return nil, nil, err
+	}
+	revisions, err := s.revisionModel.FindByMemoryID(ctx, memory.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return memory, revisions, nil
+}
+
+// GetMemorySnapshot 获取记忆在指定修订点的快照（revision 为 0 表示当前版本）
+func (s *MemoryService) GetMemorySnapshot(ctx context.Context, code string, revision int) (*dto.MemorySnapshotDTO, error) {
+	memory, err := s.findMemoryForRevision(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolveSnapshot(ctx, memory, revision)
+}
+
+// DiffMemoryRevisions 对比记忆两个修订点的差异（revision 为 0 表示当前版本）
+// 只返回有变化的字段
+func (s *MemoryService) DiffMemoryRevisions(ctx context.Context, code string, fromRev, toRev int) ([]dto.MemoryFieldDiffDTO, error) {
+	memory, err := s.findMemoryForRevision(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	from, err := s.resolveSnapshot(ctx, memory, fromRev)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.resolveSnapshot(ctx, memory, toRev)
+	if err != nil {
+		return nil, err
+	}
+	return diffSnapshots(from, to), nil
+}
+
+// RevertMemory 将记忆回滚到指定修订的内容
+// 回滚本身也会记录一条修订，所以随时可以再滚回来~
+func (s *MemoryService) RevertMemory(ctx context.Context, code string, revision int) error {
+	if revision <= 0 {
+		return errors.New("修订号必须大于 0")
+	}
+	memory, err := s.findMemoryForRevision(ctx, code)
+	if err != nil {
+		return err
+	}
+	snapshot, err := s.resolveSnapshot(ctx, memory, revision)
+	if err != nil {
+		return err
+	}
+
+	tags := snapshot.Tags
+	return s.updateMemory(ctx, &dto.MemoryUpdateDTO{
+		Code:     memory.Code,
+		Title:    &snapshot.Title,
+		Content:  &snapshot.Content,
+		Category: &snapshot.Category,
+		Tags:     &tags,
+		Priority: &snapshot.Priority,
+	}, entity.MemoryRevisionActionRevert)
+}
+
+// ParseRevision 解析修订号参数（支持数字或 current）
+func ParseRevision(value string) (int, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	if value == "current" || value == "" {
+		return 0, nil
+	}
+	rev, err := strconv.Atoi(strings.TrimPrefix(value, "r"))
+	if err != nil || rev < 0 {
+		return 0, fmt.Errorf("无效的修订号: %s", value)
+	}
+	return rev, nil
+}
+
+// findMemoryForRevision 通过 Code 获取记忆
+func (s *MemoryService) findMemoryForRevision(ctx context.Context, code string) (*entity.Memory, error) {
+	if strings.TrimSpace(code) == "" {
+		return nil, errors.New("记忆标识码不能为空")
+	}
+	memory, err := s.memoryModel.FindByCode(ctx, code)
+	if err != nil {
+		return nil, errors.New("记忆不存在")
+	}
+	return memory, nil
+}
+
+// resolveSnapshot 获取指定修订点的快照
+func (s *MemoryService) resolveSnapshot(ctx context.Context, memory *entity.Memory, revision int) (*dto.MemorySnapshotDTO, error) {
+	if revision == 0 {
+		snapshot := snapshotMemory(memory, 0)
+		return &snapshot, nil
+	}
+	rev, err := s.revisionModel.FindByRevision(ctx, memory.ID, revision)
+	if err != nil {
+		return nil, fmt.Errorf("修订 %d 不存在", revision)
+	}
+	return &dto.MemorySnapshotDTO{
+		Revision: rev.Revision,
+		Title:    rev.Title,
+		Content:  rev.Content,
+		Category: rev.Category,
+		Priority: rev.Priority,
+		Tags:     rev.GetTagStrings(),
+	}, nil
+}
+
+// recordRevision 保存一条修订（记录变更来源）
+func (s *MemoryService) recordRevision(ctx context.Context, memoryID int64, before dto.MemorySnapshotDTO, action string) error {
+	if s.revisionModel == nil {
+		return nil
+	}
+	return s.revisionModel.Create(ctx, &entity.MemoryRevision{
+		MemoryID: memoryID,
+		Title:    before.Title,
+		Content:  before.Content,
+		Category: before.Category,
+		Priority: before.Priority,
+		Tags:     strings.Join(before.Tags, ","),
+		Action:   action,
+		Source:   types.ChangeSourceFromContext(ctx).String(),
+	})
+}
+
+// snapshotMemory 生成记忆当前内容的快照
+func snapshotMemory(memory *entity.Memory, revision int) dto.MemorySnapshotDTO {
+	return dto.MemorySnapshotDTO{
+		Revision: revision,
+		Title:    memory.Title,
+		Content:  memory.Content,
+		Category: memory.Category,
+		Priority: memory.Priority,
+		Tags:     memory.GetTagStrings(),
+	}
+}
+
+// snapshotEqual 判断两个快照内容是否一致
+func snapshotEqual(a, b dto.MemorySnapshotDTO) bool {
+	return a.Title == b.Title &&
+		a.Content == b.Content &&
+		a.Category == b.Category &&
+		a.Priority == b.Priority &&
+		strings.Join(a.Tags, ",") == strings.Join(b.Tags, ",")
+}
+
+// diffSnapshots 逐字段对比两个快照
+func diffSnapshots(from, to *dto.MemorySnapshotDTO) []dto.MemoryFieldDiffDTO {
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"标题", from.Title, to.Title},
+		{"分类", from.Category, to.Category},
+		{"优先级", strconv.Itoa(from.Priority), strconv.Itoa(to.Priority)},
+		{"标签", strings.Join(from.Tags, ", "), strings.Join(to.Tags, ", ")},
+		{"内容", from.Content, to.Content},
+	}
+
+	diffs := make([]dto.MemoryFieldDiffDTO, 0, len(fields))
+	for _, f := range fields {
+		if f.old == f.new {
+			continue
+		}
+		diffs = append(diffs, dto.MemoryFieldDiffDTO{
+			Field: f.name,
+			Lines: utils.DiffLines(f.old, f.new),
+		})
+	}
+	return diffs
+}
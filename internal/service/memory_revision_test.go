@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
+)
+
+// TestMemoryRevisions 更新记录修订，可以查看快照、对比差异并回滚，回滚本身也会记录修订
+func TestMemoryRevisions(t *testing.T) {
+	for _, backend := range testBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			svc := backend.setup(t)
+			ctx := context.Background()
+			cliCtx := types.WithChangeSource(ctx, types.ChangeSourceCLI)
+			mcpCtx := types.WithChangeSource(ctx, types.ChangeSourceMCP)
+			str := func(s string) *string { return &s }
+
+			if _, err := svc.memory.CreateMemory(ctx, &dto.MemoryCreateDTO{
+				Code: "mem-rev", Title: "v1", Content: "line a\nline b", Category: "笔记", Tags: []string{"x"}, Priority: 1, Global: true,
+			}, types.NewGlobalOnlyScope()); err != nil {
+				t.Fatalf("创建记忆失败: %v", err)
+			}
+			if err := svc.memory.UpdateMemory(cliCtx, &dto.MemoryUpdateDTO{Code: "mem-rev", Content: str("line a\nline c")}); err != nil {
+				t.Fatalf("更新内容失败: %v", err)
+			}
+			tags := []string{"x", "y"}
+			if err := svc.memory.UpdateMemory(mcpCtx, &dto.MemoryUpdateDTO{Code: "mem-rev", Title: str("v2"), Tags: &tags}); err != nil {
+				t.Fatalf("更新标题失败: %v", err)
+			}
+			// 没有实际变化的更新不记录修订
+			if err := svc.memory.UpdateMemory(cliCtx, &dto.MemoryUpdateDTO{Code: "mem-rev", Title: str("v2")}); err != nil {
+				t.Fatalf("空更新失败: %v", err)
+			}
+
+			_, revisions, err := svc.memory.ListMemoryRevisions(ctx, "mem-rev")
+			if err != nil {
+				t.Fatalf("获取修订历史失败: %v", err)
+			}
+			type revSummary struct {
+				Revision       int
+				Action, Source string
+			}
+			got := make([]revSummary, len(revisions))
+			for i, r := range revisions {
+				got[i] = revSummary{r.Revision, r.Action, r.Source}
+			}
+			want := []revSummary{
+				{2, entity.MemoryRevisionActionUpdate, "mcp"},
+				{1, entity.MemoryRevisionActionUpdate, "cli"},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("修订历史 = %+v，期望 %+v", got, want)
+			}
+
+			snapshots := []struct {
+				revision int
+				want     dto.MemorySnapshotDTO
+			}{
+				{1, dto.MemorySnapshotDTO{Revision: 1, Title: "v1", Content: "line a\nline b", Category: "笔记", Priority: 1, Tags: []string{"x"}}},
+				{2, dto.MemorySnapshotDTO{Revision: 2, Title: "v1", Content: "line a\nline c", Category: "笔记", Priority: 1, Tags: []string{"x"}}},
+				{0, dto.MemorySnapshotDTO{Revision: 0, Title: "v2", Content: "line a\nline c", Category: "笔记", Priority: 1, Tags: []string{"x", "y"}}},
+			}
+			for _, s := range snapshots {
+				snapshot, err := svc.memory.GetMemorySnapshot(ctx, "mem-rev", s.revision)
+				if err != nil {
+					t.Fatalf("获取修订 %d 失败: %v", s.revision, err)
+				}
+				if !reflect.DeepEqual(*snapshot, s.want) {
+					t.Fatalf("修订 %d = %+v，期望 %+v", s.revision, *snapshot, s.want)
+				}
+			}
+
+			diffs, err := svc.memory.DiffMemoryRevisions(ctx, "mem-rev", 1, 0)
+			if err != nil {
+				t.Fatalf("对比修订失败: %v", err)
+			}
+			fields := make(map[string][]string)
+			for _, d := range diffs {
+				for _, line := range d.Lines {
+					fields[d.Field] = append(fields[d.Field], line.String())
+				}
+			}
+			wantDiff := map[string][]string{
+				"标题": {"-v1", "+v2"},
+				"标签": {"-x", "+x, y"},
+				"内容": {" line a", "-line b", "+line c"},
+			}
+			if !reflect.DeepEqual(fields, wantDiff) {
+				t.Fatalf("差异 = %v，期望 %v", fields, wantDiff)
+			}
+
+			if err := svc.memory.RevertMemory(cliCtx, "mem-rev", 1); err != nil {
+				t.Fatalf("回滚失败: %v", err)
+			}
+			current, err := svc.memory.GetMemorySnapshot(ctx, "mem-rev", 0)
+			if err != nil {
+				t.Fatalf("获取当前版本失败: %v", err)
+			}
+			if reverted := snapshots[0].want; current.Title != reverted.Title || current.Content != reverted.Content ||
+				strings.Join(current.Tags, ",") != strings.Join(reverted.Tags, ",") {
+				t.Fatalf("回滚后 = %+v，期望与修订 1 一致", *current)
+			}
+			_, revisions, err = svc.memory.ListMemoryRevisions(ctx, "mem-rev")
+			if err != nil {
+				t.Fatalf("获取修订历史失败: %v", err)
+			}
+			if len(revisions) != 3 || revisions[0].Action != entity.MemoryRevisionActionRevert || revisions[0].Title != "v2" {
+				t.Fatalf("回滚应记录回滚前的内容: %+v", revisions[0])
+			}
+
+			errCases := []struct {
+				name     string
+				revision int
+				wantErr  string
+			}{
+				{name: "修订号为 0", revision: 0, wantErr: "修订号必须大于 0"},
+				{name: "修订不存在", revision: 99, wantErr: "修订 99 不存在"},
+			}
+			for _, tc := range errCases {
+				if err := svc.memory.RevertMemory(ctx, "mem-rev", tc.revision); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("%s: 错误 = %v，期望包含 %q", tc.name, err, tc.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRevision(t *testing.T) {
+	cases := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: "current", want: 0},
+		{value: " CURRENT ", want: 0},
+		{value: "3", want: 3},
+		{value: "r12", want: 12},
+		{value: "-1", wantErr: true},
+		{value: "abc", wantErr: true},
+	}
+	for _, tc := range cases {
+		got, err := ParseRevision(tc.value)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Fatalf("ParseRevision(%q) = %d, %v，期望 %d（错误 %v）", tc.value, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/XiaoLFeng/llm-memory/internal/embedding"
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
+)
+
+// 嘿嘿~ 这是记忆的语义搜索！(´∀｀)💖
+// 向量在写入/更新时计算，缺失或过期（内容、模型变化）的向量会在搜索时自动补齐~
+
+// DefaultSemanticTopK 语义搜索默认返回数量
+const DefaultSemanticTopK = 5
+
+// ErrEmbedderNotConfigured 未配置向量嵌入器
+var ErrEmbedderNotConfigured = errors.New("未配置向量嵌入器，无法进行语义搜索")
+
+// SemanticSearchMemories 根据作用域进行语义搜索
+// 返回与查询余弦相似度最高的 topK 条记忆（topK <= 0 时使用默认值）
+func (s *MemoryService) SemanticSearchMemories(ctx context.Context, query string, scope string, scopeCtx *types.ScopeContext, topK int) ([]models.MemorySemanticHit, error) {
+	if s.embedder == nil || s.embeddingModel == nil {
+		return nil, ErrEmbedderNotConfigured
+	}
+
+	// 验证查询不能为空
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("搜索内容不能为空")
+	}
+	if topK <= 0 {
+		topK = DefaultSemanticTopK
+	}
+
+	// 获取作用域内的候选记忆
+	filter := buildVisibilityFilter(scope, scopeCtx)
+	memories, err := s.memoryModel.FindByFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(memories) == 0 {
+		return []models.MemorySemanticHit{}, nil
+	}
+
+	vectors, err := s.ensureEmbeddings(ctx, memories)
+	if err != nil {
+		return nil, err
+	}
+
+	queryVectors, err := s.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	queryVector := queryVectors[0]
+
+	hits := make([]models.MemorySemanticHit, 0, len(memories))
+	for _, memory := range memories {
+		vec, ok := vectors[memory.ID]
+		if !ok {
+			continue
+		}
+		hits = append(hits, models.MemorySemanticHit{
+			Memory:     memory,
+			Similarity: embedding.CosineSimilarity(queryVector, vec),
+		})
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Similarity > hits[j].Similarity
+	})
+	if len(hits) > topK {
+		hits = hits[:topK]
+	}
+	return hits, nil
+}
+
+// ensureEmbeddings 获取记忆向量，缺失或过期的向量会批量重新计算并保存
+func (s *MemoryService) ensureEmbeddings(ctx context.Context, memories []entity.Memory) (map[int64][]float32, error) {
+	ids := make([]int64, len(memories))
+	for i := range memories {
+		ids[i] = memories[i].ID
+	}
+	stored, err := s.embeddingModel.FindByMemoryIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make(map[int64][]float32, len(memories))
+	var stale []*entity.Memory
+	var texts []string
+	for i := range memories {
+		memory := &memories[i]
+		text := memoryEmbeddingText(memory)
+		if e, ok := stored[memory.ID]; ok && e.Model == s.embedder.Name() && e.ContentHash == hashEmbeddingText(text) {
+			if vec, err := embedding.DecodeVector(e.Vector); err == nil {
+				vectors[memory.ID] = vec
+				continue
+			}
+		}
+		stale = append(stale, memory)
+		texts = append(texts, text)
+	}
+	if len(stale) == 0 {
+		return vectors, nil
+	}
+
+	embedded, err := s.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	for i, memory := range stale {
+		if err := s.saveEmbedding(ctx, memory.ID, texts[i], embedded[i]); err != nil {
+			return nil, err
+		}
+		vectors[memory.ID] = embedded[i]
+	}
+	return vectors, nil
+}
+
+// refreshEmbedding 在记忆内容变化时重新计算向量
+// 呀~ 嵌入失败不会影响记忆的写入，下次语义搜索时会自动补齐！✨
+func (s *MemoryService) refreshEmbedding(ctx context.Context, memory *entity.Memory) {
+	if s.embedder == nil || s.embeddingModel == nil || memory == nil {
+		return
+	}
+
+	text := memoryEmbeddingText(memory)
+	if e, err := s.embeddingModel.FindByMemoryID(ctx, memory.ID); err == nil &&
+		e.Model == s.embedder.Name() && e.ContentHash == hashEmbeddingText(text) {
+		return
+	}
+
+	vectors, err := s.embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return
+	}
+	_ = s.saveEmbedding(ctx, memory.ID, text, vectors[0])
+}
+
+// saveEmbedding 保存记忆向量
+func (s *MemoryService) saveEmbedding(ctx context.Context, memoryID int64, text string, vec []float32) error {
+	return s.embeddingModel.Upsert(ctx, &entity.MemoryEmbedding{
+		MemoryID:    memoryID,
+		Model:       s.embedder.Name(),
+		Dimensions:  len(vec),
+		Vector:      embedding.EncodeVector(vec),
+		ContentHash: hashEmbeddingText(text),
+	})
+}
+
+// memoryEmbeddingText 拼接用于嵌入的记忆文本（标题、分类、标签、内容）
+func memoryEmbeddingText(memory *entity.Memory) string {
+	parts := []string{memory.Title, memory.Category}
+	if tags := memory.GetTagStrings(); len(tags) > 0 {
+		parts = append(parts, strings.Join(tags, " "))
+	}
+	parts = append(parts, memory.Content)
+	return strings.Join(parts, "\n")
+}
+
+// hashEmbeddingText 计算嵌入文本的哈希
+func hashEmbeddingText(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
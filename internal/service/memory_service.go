@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strings"
 
+	"github.com/XiaoLFeng/llm-memory/internal/embedding"
 	"github.com/XiaoLFeng/llm-memory/internal/models"
 	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
 	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
@@ -14,13 +15,20 @@ import (
 // MemoryService 记忆服务结构体
 // 负责验证、处理和协调各种记忆操作
 type MemoryService struct {
-	memoryModel *models.MemoryModel
+	memoryModel    models.MemoryRepository
+	revisionModel  models.MemoryRevisionRepository
+	embeddingModel models.MemoryEmbeddingRepository
+	embedder       embedding.Embedder
 }
 
 // NewMemoryService 创建新的记忆服务实例
-func NewMemoryService(model *models.MemoryModel) *MemoryService {
+// embedder 为 nil 时不支持语义搜索
+func NewMemoryService(model models.MemoryRepository, revisionModel models.MemoryRevisionRepository, embeddingModel models.MemoryEmbeddingRepository, embedder embedding.Embedder) *MemoryService {
 	return &MemoryService{
-		memoryModel: model,
+		memoryModel:    model,
+		revisionModel:  revisionModel,
+		embeddingModel: embeddingModel,
+		embedder:       embedder,
 	}
 }
 
@@ -41,6 +49,10 @@ func (s *MemoryService) CreateMemory(ctx context.Context, input *dto.MemoryCreat
 	if exists {
 		return nil, errors.New("记忆标识码已存在，请使用其他唯一标识")
 	}
+	// 回收站中的记忆仍占用标识码（唯一索引）
+	if _, err := s.memoryModel.FindDeletedByCode(ctx, input.Code); err == nil {
+		return nil, errors.New("标识码已被回收站中的记忆占用，请先恢复或彻底删除")
+	}
 
 	// 验证标题不能为空
 	if strings.TrimSpace(input.Title) == "" {
@@ -98,11 +110,20 @@ func (s *MemoryService) CreateMemory(ctx context.Context, input *dto.MemoryCreat
 		memory, _ = s.memoryModel.FindByID(ctx, memory.ID)
 	}
 
+	// 计算语义向量
+	s.refreshEmbedding(ctx, memory)
+
 	return memory, nil
 }
 
 // UpdateMemory 更新记忆（通过 Code 定位）
+// 内容有变化时会先把旧值保存为一条修订
 func (s *MemoryService) UpdateMemory(ctx context.Context, input *dto.MemoryUpdateDTO) error {
+	return s.updateMemory(ctx, input, entity.MemoryRevisionActionUpdate)
+}
+
+// updateMemory 更新记忆并记录修订（action: update/revert）
+func (s *MemoryService) updateMemory(ctx context.Context, input *dto.MemoryUpdateDTO, action string) error {
 	// 验证 Code 不能为空
 	if strings.TrimSpace(input.Code) == "" {
 		return errors.New("记忆标识码不能为空")
@@ -114,6 +135,14 @@ func (s *MemoryService) UpdateMemory(ctx context.Context, input *dto.MemoryUpdat
 		return errors.New("记忆不存在，无法更新")
 	}
 
+	// 调用方读取后记录已被修改时拒绝覆盖
+	if err := models.CheckVersion("记忆", memory.Code, input.Version, memory.Version); err != nil {
+		return err
+	}
+
+	// 保存修改前的快照
+	before := snapshotMemory(memory, 0)
+
 	// 应用更新
 	if input.Title != nil {
 		title := strings.TrimSpace(*input.Title)
@@ -144,11 +173,23 @@ func (s *MemoryService) UpdateMemory(ctx context.Context, input *dto.MemoryUpdat
 		memory.Priority = priority
 	}
 
-	// 执行更新操作
+	// 执行更新操作（按读取时的版本条件更新）
 	if err := s.memoryModel.Update(ctx, memory); err != nil {
 		return err
 	}
 
+	// 记录修订（没有实际变化时不记录）
+	// 呀~ 放在更新成功之后，版本冲突时不会留下多余的修订！
+	after := snapshotMemory(memory, 0)
+	if input.Tags != nil {
+		after.Tags = *input.Tags
+	}
+	if !snapshotEqual(before, after) {
+		if err := s.recordRevision(ctx, memory.ID, before, action); err != nil {
+			return err
+		}
+	}
+
 	// 更新标签（如果提供）
 	if input.Tags != nil {
 		if err := s.memoryModel.UpdateTags(ctx, memory.ID, *input.Tags); err != nil {
@@ -156,10 +197,15 @@ func (s *MemoryService) UpdateMemory(ctx context.Context, input *dto.MemoryUpdat
 		}
 	}
 
+	// 内容变化时重新计算语义向量
+	if updated, err := s.memoryModel.FindByID(ctx, memory.ID); err == nil {
+		s.refreshEmbedding(ctx, updated)
+	}
+
 	return nil
 }
 
-// DeleteMemory 删除记忆（通过 Code 定位）
+// DeleteMemory 删除记忆（通过 Code 定位，移入回收站）
 func (s *MemoryService) DeleteMemory(ctx context.Context, code string) error {
 	// 验证 Code 不能为空
 	if strings.TrimSpace(code) == "" {
@@ -260,6 +306,18 @@ func (s *MemoryService) SearchMemoriesByScope(ctx context.Context, keyword strin
 	return s.memoryModel.SearchByFilter(ctx, keyword, filter)
 }
 
+// SearchMemoriesWithSnippets 根据作用域全文检索记忆
+// 结果按相关度排序，并附带命中位置的高亮摘要
+func (s *MemoryService) SearchMemoriesWithSnippets(ctx context.Context, keyword string, scope string, scopeCtx *types.ScopeContext) ([]models.MemorySearchHit, error) {
+	// 验证关键词不能为空
+	if strings.TrimSpace(keyword) == "" {
+		return nil, errors.New("搜索关键词不能为空")
+	}
+
+	filter := buildVisibilityFilter(scope, scopeCtx)
+	return s.memoryModel.SearchRankedByFilter(ctx, keyword, filter, 0)
+}
+
 // ArchiveMemory 归档记忆
 func (s *MemoryService) ArchiveMemory(ctx context.Context, id int64) error {
 	// 验证ID必须大于0
@@ -324,6 +382,7 @@ func ToMemoryResponseDTO(memory *entity.Memory, scopeCtx *types.ScopeContext) *d
 		Priority:   memory.Priority,
 		IsArchived: memory.IsArchived,
 		Scope:      string(scope),
+		Version:    memory.Version,
 		CreatedAt:  memory.CreatedAt,
 		UpdatedAt:  memory.UpdatedAt,
 	}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"gorm.io/gorm"
+)
+
+// PathService 路径维护服务
+// 嘿嘿~ 项目目录搬家或改名后，用它把数据带到新位置！📦
+type PathService struct {
+	model *models.PersonalPathModel
+}
+
+// NewPathService 创建路径维护服务实例
+func NewPathService(model *models.PersonalPathModel) *PathService {
+	return &PathService{model: model}
+}
+
+// EnsurePath 登记路径（已登记时更新访问时间）
+// MCP 会话从客户端 roots 得到项目目录后，像启动时登记工作目录一样登记它
+func (s *PathService) EnsurePath(ctx context.Context, path string) (*entity.PersonalPath, error) {
+	return s.model.EnsurePath(ctx, path)
+}
+
+// Move 把已登记的路径改为新位置
+// 保留路径 ID，因此记忆、计划、待办和组成员关系都不变；子路径一并改写
+// 新位置已登记但没有数据时（通常是在新目录运行命令时自动登记的）直接替换，有数据或已加入组时拒绝
+func (s *PathService) Move(ctx context.Context, oldPath, newPath string) (*dto.PathMoveResultDTO, error) {
+	oldAbs, newAbs, err := normalizePathPair(oldPath, newPath)
+	if err != nil {
+		return nil, err
+	}
+	if isSubPath(oldAbs, newAbs) || isSubPath(newAbs, oldAbs) {
+		return nil, errors.New("新旧路径不能互相包含")
+	}
+	if info, err := os.Stat(newAbs); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("新路径不存在或不是目录: %s", newAbs)
+	}
+
+	result := &dto.PathMoveResultDTO{Moved: make([]dto.PathMoveDTO, 0)}
+	err = s.model.Transaction(ctx, func(pm *models.PersonalPathModel) error {
+		old, err := pm.FindByPath(ctx, oldAbs)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("路径未登记: %s", oldAbs)
+		}
+		if err != nil {
+			return err
+		}
+		descendants, err := pm.FindDescendants(ctx, oldAbs)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range append([]entity.PersonalPath{*old}, descendants...) {
+			target := newAbs + strings.TrimPrefix(p.Path, oldAbs)
+			replaced, err := s.releaseTarget(ctx, pm, target)
+			if err != nil {
+				return err
+			}
+			if replaced {
+				result.Replaced++
+			}
+			if err := pm.UpdatePath(ctx, p.ID, target); err != nil {
+				return err
+			}
+			result.Moved = append(result.Moved, dto.PathMoveDTO{From: p.Path, To: target})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// releaseTarget 确保目标路径可以被占用：未登记时无需处理，空记录直接删除，有数据时返回错误
+func (s *PathService) releaseTarget(ctx context.Context, pm *models.PersonalPathModel, target string) (bool, error) {
+	existing, err := pm.FindByPath(ctx, target)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	usage, err := pm.Usage(ctx, existing.ID)
+	if err != nil {
+		return false, err
+	}
+	if usage.Total() > 0 {
+		return false, fmt.Errorf("目标路径 %s 已有 %d 条记忆、%d 个计划、%d 个待办，请使用 path merge 合并",
+			target, usage.Memories, usage.Plans, usage.ToDos)
+	}
+	groupID, err := pm.GroupIDOf(ctx, existing.ID)
+	if err != nil {
+		return false, err
+	}
+	if groupID != 0 {
+		return false, fmt.Errorf("目标路径 %s 已加入组，请先从组中移除或使用 path merge 合并", target)
+	}
+	return true, pm.DeleteWithGroupLink(ctx, existing.ID)
+}
+
+// Merge 把 from 路径下的所有记忆、计划、待办（包括回收站）合并到 to 路径，然后删除 from 路径
+// 两边有相同 code 的活跃计划或待办时，为 from 一侧的条目追加 -2、-3… 后缀
+// to 未加入组时继承 from 的组；两者属于不同的组时保留 to 的组
+func (s *PathService) Merge(ctx context.Context, fromPath, toPath string) (*dto.PathMergeResultDTO, error) {
+	fromAbs, toAbs, err := normalizePathPair(fromPath, toPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.PathMergeResultDTO{From: fromAbs, To: toAbs}
+	err = s.model.Transaction(ctx, func(pm *models.PersonalPathModel) error {
+		from, err := pm.FindByPath(ctx, fromAbs)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("路径未登记: %s", fromAbs)
+		}
+		if err != nil {
+			return err
+		}
+		to, err := pm.FindByPath(ctx, toAbs)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("目标路径未登记: %s（直接搬迁请使用 path move）", toAbs)
+		}
+		if err != nil {
+			return err
+		}
+
+		if result.Renamed, err = s.renameConflicts(ctx, pm, from.ID, to.ID); err != nil {
+			return err
+		}
+		usage, err := pm.ReassignData(ctx, from.ID, to.ID)
+		if err != nil {
+			return err
+		}
+		result.Memories, result.Plans, result.Todos = usage.Memories, usage.Plans, usage.ToDos
+
+		fromGroup, err := pm.GroupIDOf(ctx, from.ID)
+		if err != nil {
+			return err
+		}
+		toGroup, err := pm.GroupIDOf(ctx, to.ID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case fromGroup != 0 && toGroup == 0:
+			if err := pm.MoveGroupLink(ctx, from.ID, to.ID); err != nil {
+				return err
+			}
+			result.JoinedGroup = true
+		case fromGroup != 0 && fromGroup != toGroup:
+			result.LeftGroup = true
+		}
+		return pm.DeleteWithGroupLink(ctx, from.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// renameConflicts 为 from 一侧与 to 一侧 code 相同的活跃计划和待办改名
+func (s *PathService) renameConflicts(ctx context.Context, pm *models.PersonalPathModel, fromID, toID int64) ([]dto.CodeRenameDTO, error) {
+	renamed := make([]dto.CodeRenameDTO, 0)
+
+	plans, err := pm.FindActivePlanCodeConflicts(ctx, fromID, toID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range plans {
+		plan := &plans[i]
+		from := plan.Code
+		code, err := uniqueCode(from, func(c string) (bool, error) { return pm.PlanCodeInUse(ctx, c) })
+		if err != nil {
+			return nil, err
+		}
+		if err := pm.RenamePlanCode(ctx, plan, code); err != nil {
+			return nil, err
+		}
+		renamed = append(renamed, dto.CodeRenameDTO{Type: "plan", From: from, To: code})
+	}
+
+	todos, err := pm.FindActiveToDoCodeConflicts(ctx, fromID, toID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range todos {
+		todo := &todos[i]
+		from := todo.Code
+		code, err := uniqueCode(from, func(c string) (bool, error) { return pm.ToDoCodeInUse(ctx, c) })
+		if err != nil {
+			return nil, err
+		}
+		if err := pm.RenameToDoCode(ctx, todo, code); err != nil {
+			return nil, err
+		}
+		renamed = append(renamed, dto.CodeRenameDTO{Type: "todo", From: from, To: code})
+	}
+	return renamed, nil
+}
+
+// uniqueCode 用 renameCode 生成未被占用的 code（查询出错时返回错误）
+func uniqueCode(code string, inUse func(string) (bool, error)) (string, error) {
+	var queryErr error
+	newCode := renameCode(code, func(candidate string) bool {
+		exists, err := inUse(candidate)
+		if err != nil {
+			queryErr = err
+			return false
+		}
+		return exists
+	})
+	return newCode, queryErr
+}
+
+// normalizePathPair 把两个路径转换为绝对路径，并检查是否相同
+func normalizePathPair(a, b string) (string, string, error) {
+	absA, err := filepath.Abs(a)
+	if err != nil {
+		return "", "", err
+	}
+	absB, err := filepath.Abs(b)
+	if err != nil {
+		return "", "", err
+	}
+	if absA == absB {
+		return "", "", errors.New("两个路径相同")
+	}
+	return absA, absB, nil
+}
+
+// isSubPath 判断 path 是否位于 parent 之内
+func isSubPath(parent, path string) bool {
+	return strings.HasPrefix(path, strings.TrimSuffix(parent, string(filepath.Separator))+string(filepath.Separator))
+}
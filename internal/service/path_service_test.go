@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openServiceTestDB 打开迁移好的临时 SQLite 数据库
+func openServiceTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if _, err := database.NewMigrator(db).Migrate(context.Background()); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	return db
+}
+
+// pathFixture 路径测试数据的写入助手
+type pathFixture struct {
+	t  *testing.T
+	db *gorm.DB
+}
+
+// path 登记路径（目录不必存在）
+func (f pathFixture) path(path string) *entity.PersonalPath {
+	f.t.Helper()
+	p := &entity.PersonalPath{ID: database.GenerateID(), Path: path, LastVisit: time.Now()}
+	if err := f.db.Create(p).Error; err != nil {
+		f.t.Fatalf("登记路径失败: %v", err)
+	}
+	return p
+}
+
+// group 创建组并把路径加入组
+func (f pathFixture) group(name string, paths ...*entity.PersonalPath) int64 {
+	f.t.Helper()
+	group := &entity.Group{ID: database.GenerateID(), Name: name}
+	if err := f.db.Create(group).Error; err != nil {
+		f.t.Fatalf("创建组失败: %v", err)
+	}
+	for _, p := range paths {
+		link := &entity.GroupPath{ID: database.GenerateID(), GroupID: group.ID, PersonalPathID: p.ID}
+		if err := f.db.Create(link).Error; err != nil {
+			f.t.Fatalf("加入组失败: %v", err)
+		}
+	}
+	return group.ID
+}
+
+// memory 在路径下创建记忆，trashed 为 true 时放入回收站
+func (f pathFixture) memory(pathID int64, code string, trashed bool) *entity.Memory {
+	f.t.Helper()
+	m := &entity.Memory{ID: database.GenerateID(), Code: code, PathID: pathID, Title: code, Content: code, Version: 1}
+	if err := f.db.Create(m).Error; err != nil {
+		f.t.Fatalf("创建记忆失败: %v", err)
+	}
+	if trashed {
+		if err := f.db.Delete(m).Error; err != nil {
+			f.t.Fatalf("删除记忆失败: %v", err)
+		}
+	}
+	return m
+}
+
+// plan 在路径下创建计划
+func (f pathFixture) plan(pathID int64, code string, status entity.PlanStatus, trashed bool) *entity.Plan {
+	f.t.Helper()
+	p := &entity.Plan{ID: database.GenerateID(), Code: code, PathID: pathID, Title: code, Content: code, Status: status, Version: 1}
+	if err := f.db.Create(p).Error; err != nil {
+		f.t.Fatalf("创建计划失败: %v", err)
+	}
+	if trashed {
+		if err := f.db.Delete(p).Error; err != nil {
+			f.t.Fatalf("删除计划失败: %v", err)
+		}
+	}
+	return p
+}
+
+// todo 在计划下创建待办
+func (f pathFixture) todo(plan *entity.Plan, code string) *entity.ToDo {
+	f.t.Helper()
+	todo := &entity.ToDo{ID: database.GenerateID(), Code: code, PlanID: plan.ID, PathID: plan.PathID, Title: code, Version: 1}
+	if err := f.db.Create(todo).Error; err != nil {
+		f.t.Fatalf("创建待办失败: %v", err)
+	}
+	return todo
+}
+
+// TestPathMove 搬迁路径：空的目标记录被替换，有数据（含回收站）或已加入组的目标被拒绝
+func TestPathMove(t *testing.T) {
+	cases := []struct {
+		name         string
+		target       func(f pathFixture, target *entity.PersonalPath) // nil 表示目标路径未登记
+		wantErr      string
+		wantReplaced int
+	}{
+		{name: "目标未登记"},
+		{name: "目标已登记但为空", target: func(f pathFixture, target *entity.PersonalPath) {}, wantReplaced: 1},
+		{
+			name:    "目标有记忆",
+			target:  func(f pathFixture, target *entity.PersonalPath) { f.memory(target.ID, "mem-target", false) },
+			wantErr: "已有 1 条记忆",
+		},
+		{
+			name: "目标只有回收站中的计划",
+			target: func(f pathFixture, target *entity.PersonalPath) {
+				f.plan(target.ID, "plan-trashed", entity.PlanStatusPending, true)
+			},
+			wantErr: "1 个计划",
+		},
+		{
+			name:    "目标已加入组",
+			target:  func(f pathFixture, target *entity.PersonalPath) { f.group("team", target) },
+			wantErr: "已加入组",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := openServiceTestDB(t)
+			f := pathFixture{t: t, db: db}
+			root := t.TempDir()
+			oldPath := filepath.Join(root, "old")
+			newPath := filepath.Join(root, "new")
+			if err := os.MkdirAll(newPath, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			old := f.path(oldPath)
+			child := f.path(filepath.Join(oldPath, "sub"))
+			f.memory(old.ID, "mem-old", false)
+			if tc.target != nil {
+				tc.target(f, f.path(newPath))
+			}
+
+			result, err := NewPathService(models.NewPersonalPathModel(db)).Move(ctx, oldPath, newPath)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("错误 = %v，期望包含 %q", err, tc.wantErr)
+				}
+				// 事务回滚：旧路径保持原样
+				var stored entity.PersonalPath
+				if err := db.First(&stored, old.ID).Error; err != nil || stored.Path != oldPath {
+					t.Fatalf("旧路径不应被改写: %+v, %v", stored, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("搬迁失败: %v", err)
+			}
+			if result.Replaced != tc.wantReplaced {
+				t.Fatalf("替换数 = %d，期望 %d", result.Replaced, tc.wantReplaced)
+			}
+			if len(result.Moved) != 2 {
+				t.Fatalf("应改写路径及其子路径: %+v", result.Moved)
+			}
+			for id, want := range map[int64]string{old.ID: newPath, child.ID: filepath.Join(newPath, "sub")} {
+				var stored entity.PersonalPath
+				if err := db.First(&stored, id).Error; err != nil {
+					t.Fatalf("读取路径失败: %v", err)
+				}
+				if stored.Path != want {
+					t.Fatalf("路径 = %s，期望 %s", stored.Path, want)
+				}
+			}
+		})
+	}
+}
+
+// TestPathMerge 合并路径：冲突的活跃 code 按版本条件改名，回收站中的数据一并转移，组成员关系按规则继承
+func TestPathMerge(t *testing.T) {
+	ctx := context.Background()
+	db := openServiceTestDB(t)
+	f := pathFixture{t: t, db: db}
+	root := t.TempDir()
+	fromPath := filepath.Join(root, "from")
+	toPath := filepath.Join(root, "to")
+	from := f.path(fromPath)
+	to := f.path(toPath)
+
+	fromPlan := f.plan(from.ID, "plan-a", entity.PlanStatusInProgress, false)
+	fromTodo := f.todo(fromPlan, "todo-a")
+	toPlan := f.plan(to.ID, "plan-a", entity.PlanStatusPending, false)
+	f.todo(toPlan, "todo-a")
+	// 已完成的同名计划不算冲突
+	doneFrom := f.plan(from.ID, "plan-done", entity.PlanStatusCompleted, false)
+	f.plan(to.ID, "plan-done", entity.PlanStatusPending, false)
+	// plan-a-2 已被占用，改名跳到 -3
+	f.plan(to.ID, "plan-a-2", entity.PlanStatusPending, false)
+	trashedMemory := f.memory(from.ID, "mem-trashed", true)
+	trashedPlan := f.plan(from.ID, "plan-trashed", entity.PlanStatusPending, true)
+	fromGroup := f.group("team", from)
+
+	result, err := NewPathService(models.NewPersonalPathModel(db)).Merge(ctx, fromPath, toPath)
+	if err != nil {
+		t.Fatalf("合并失败: %v", err)
+	}
+
+	renamed := make(map[string]string)
+	for _, r := range result.Renamed {
+		renamed[r.Type+":"+r.From] = r.To
+	}
+	want := map[string]string{"plan:plan-a": "plan-a-3", "todo:todo-a": "todo-a-2"}
+	if len(renamed) != len(want) {
+		t.Fatalf("改名 = %+v，期望 %+v", result.Renamed, want)
+	}
+	for k, v := range want {
+		if renamed[k] != v {
+			t.Fatalf("改名 = %+v，期望 %+v", result.Renamed, want)
+		}
+	}
+	if result.Memories != 1 || result.Plans != 3 || result.Todos != 1 {
+		t.Fatalf("转移数量 = %d/%d/%d，期望 1/3/1", result.Memories, result.Plans, result.Todos)
+	}
+	if !result.JoinedGroup || result.LeftGroup {
+		t.Fatalf("目标路径应继承源路径的组: %+v", result)
+	}
+
+	// 改名走版本条件更新，版本号 +1
+	var plan entity.Plan
+	if err := db.First(&plan, fromPlan.ID).Error; err != nil {
+		t.Fatalf("读取计划失败: %v", err)
+	}
+	if plan.Code != "plan-a-3" || plan.Version != 2 || plan.PathID != to.ID {
+		t.Fatalf("计划 = code %s, version %d, path %d", plan.Code, plan.Version, plan.PathID)
+	}
+	var todo entity.ToDo
+	if err := db.First(&todo, fromTodo.ID).Error; err != nil {
+		t.Fatalf("读取待办失败: %v", err)
+	}
+	if todo.Code != "todo-a-2" || todo.Version != 2 || todo.PathID != to.ID {
+		t.Fatalf("待办 = code %s, version %d, path %d", todo.Code, todo.Version, todo.PathID)
+	}
+	var done entity.Plan
+	if err := db.First(&done, doneFrom.ID).Error; err != nil {
+		t.Fatalf("读取计划失败: %v", err)
+	}
+	if done.Code != "plan-done" || done.Version != 1 {
+		t.Fatalf("已完成的计划不应改名: code %s, version %d", done.Code, done.Version)
+	}
+
+	// 回收站中的数据也转移到目标路径
+	var memory entity.Memory
+	if err := db.Unscoped().First(&memory, trashedMemory.ID).Error; err != nil {
+		t.Fatalf("读取记忆失败: %v", err)
+	}
+	var trashed entity.Plan
+	if err := db.Unscoped().First(&trashed, trashedPlan.ID).Error; err != nil {
+		t.Fatalf("读取计划失败: %v", err)
+	}
+	if memory.PathID != to.ID || !memory.DeletedAt.Valid || trashed.PathID != to.ID || !trashed.DeletedAt.Valid {
+		t.Fatalf("回收站中的数据应转移并保留删除状态: memory %+v, plan %+v", memory, trashed)
+	}
+
+	pm := models.NewPersonalPathModel(db)
+	if groupID, err := pm.GroupIDOf(ctx, to.ID); err != nil || groupID != fromGroup {
+		t.Fatalf("目标路径的组 = %d（%v），期望 %d", groupID, err, fromGroup)
+	}
+	if _, err := pm.FindByPath(ctx, fromPath); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("源路径应被删除: %v", err)
+	}
+}
+
+// TestPathMergeGroups 两边组成员关系的处理
+func TestPathMergeGroups(t *testing.T) {
+	cases := []struct {
+		name       string
+		fromGroup  bool
+		toGroup    string // "" 未加入组，same 与源路径同组，other 其他组
+		wantJoined bool
+		wantLeft   bool
+		wantGroup  string // 合并后目标路径所属的组
+	}{
+		{name: "都未加入组"},
+		{name: "继承源路径的组", fromGroup: true, wantJoined: true, wantGroup: "from"},
+		{name: "保留目标路径的组", toGroup: "other", wantGroup: "other"},
+		{name: "同一个组", fromGroup: true, toGroup: "same", wantGroup: "from"},
+		{name: "不同的组", fromGroup: true, toGroup: "other", wantLeft: true, wantGroup: "other"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := openServiceTestDB(t)
+			f := pathFixture{t: t, db: db}
+			root := t.TempDir()
+			from := f.path(filepath.Join(root, "from"))
+			to := f.path(filepath.Join(root, "to"))
+
+			groups := map[string]int64{}
+			switch {
+			case tc.fromGroup && tc.toGroup == "same":
+				groups["from"] = f.group("from", from, to)
+			case tc.fromGroup:
+				groups["from"] = f.group("from", from)
+			}
+			if tc.toGroup == "other" {
+				groups["other"] = f.group("other", to)
+			}
+
+			result, err := NewPathService(models.NewPersonalPathModel(db)).Merge(ctx, from.Path, to.Path)
+			if err != nil {
+				t.Fatalf("合并失败: %v", err)
+			}
+			if result.JoinedGroup != tc.wantJoined || result.LeftGroup != tc.wantLeft {
+				t.Fatalf("joined/left = %v/%v，期望 %v/%v", result.JoinedGroup, result.LeftGroup, tc.wantJoined, tc.wantLeft)
+			}
+			groupID, err := models.NewPersonalPathModel(db).GroupIDOf(ctx, to.ID)
+			if err != nil {
+				t.Fatalf("查询组失败: %v", err)
+			}
+			if groupID != groups[tc.wantGroup] {
+				t.Fatalf("目标路径的组 = %d，期望 %d", groupID, groups[tc.wantGroup])
+			}
+			var links int64
+			db.Model(&entity.GroupPath{}).Where("personal_path_id = ?", from.ID).Count(&links)
+			if links != 0 {
+				t.Fatalf("源路径的组成员关系应被移除")
+			}
+		})
+	}
+}
+
+// TestRenameCodeVersionConflict 改名前记录已被其他进程修改时返回版本冲突，不覆盖对方的修改
+func TestRenameCodeVersionConflict(t *testing.T) {
+	ctx := context.Background()
+	db := openServiceTestDB(t)
+	f := pathFixture{t: t, db: db}
+	p := f.path("/tmp/conflict")
+	plan := f.plan(p.ID, "plan-a", entity.PlanStatusPending, false)
+	todo := f.todo(plan, "todo-a")
+
+	// 模拟读取之后另一个进程的更新
+	db.Model(&entity.Plan{}).Where("id = ?", plan.ID).Updates(map[string]interface{}{"title": "changed", "version": 2})
+	db.Model(&entity.ToDo{}).Where("id = ?", todo.ID).Updates(map[string]interface{}{"title": "changed", "version": 2})
+
+	pm := models.NewPersonalPathModel(db)
+	var conflict *models.VersionConflictError
+	if err := pm.RenamePlanCode(ctx, plan, "plan-a-2"); !errors.As(err, &conflict) || conflict.Actual != 2 {
+		t.Fatalf("计划改名应返回版本冲突: %v", err)
+	}
+	if plan.Code != "plan-a" || plan.Version != 1 {
+		t.Fatalf("冲突后不应修改调用方的记录: code %s, version %d", plan.Code, plan.Version)
+	}
+	if err := pm.RenameToDoCode(ctx, todo, "todo-a-2"); !errors.Is(err, models.ErrVersionConflict) {
+		t.Fatalf("待办改名应返回版本冲突: %v", err)
+	}
+
+	var stored entity.Plan
+	if err := db.First(&stored, plan.ID).Error; err != nil {
+		t.Fatalf("读取计划失败: %v", err)
+	}
+	if stored.Code != "plan-a" || stored.Title != "changed" {
+		t.Fatalf("不应覆盖其他进程的修改: %+v", stored)
+	}
+}
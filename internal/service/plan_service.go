@@ -13,11 +13,11 @@ import (
 
 // PlanService 计划服务层结构体
 type PlanService struct {
-	planModel *models.PlanModel
+	planModel models.PlanRepository
 }
 
 // NewPlanService 创建新的计划服务实例
-func NewPlanService(model *models.PlanModel) *PlanService {
+func NewPlanService(model models.PlanRepository) *PlanService {
 	return &PlanService{
 		planModel: model,
 	}
@@ -94,6 +94,11 @@ func (s *PlanService) UpdatePlan(ctx context.Context, input *dto.PlanUpdateDTO)
 		return errors.New("已取消的计划无法更新")
 	}
 
+	// 调用方读取后记录已被修改时拒绝覆盖
+	if err := models.CheckVersion("计划", plan.Code, input.Version, plan.Version); err != nil {
+		return err
+	}
+
 	// 应用更新
 	if input.Title != nil {
 		title := strings.TrimSpace(*input.Title)
@@ -181,6 +186,19 @@ func (s *PlanService) GetPlan(ctx context.Context, code string) (*entity.Plan, e
 	return plan, nil
 }
 
+// GetLatestPlan 获取单个计划（通过 code，包括已完成和已取消的计划）
+// 活跃计划优先，其次最近更新的；供计划资源使用，订阅的计划完成后仍然可以读取
+func (s *PlanService) GetLatestPlan(ctx context.Context, code string) (*entity.Plan, error) {
+	if strings.TrimSpace(code) == "" {
+		return nil, errors.New("无效的计划 code")
+	}
+	plan, err := s.planModel.FindLatestByCode(ctx, code)
+	if err != nil {
+		return nil, errors.New("计划不存在")
+	}
+	return plan, nil
+}
+
 // GetPlanByID 获取单个计划（通过 ID，TUI 内部使用）
 func (s *PlanService) GetPlanByID(ctx context.Context, id int64) (*entity.Plan, error) {
 	// 参数验证
@@ -530,6 +548,7 @@ func ToPlanResponseDTO(plan *entity.Plan, scopeCtx *types.ScopeContext) *dto.Pla
 		Progress:    plan.Progress,
 		Todos:       todos,
 		Scope:       string(scope),
+		Version:     plan.Version,
 		CreatedAt:   plan.CreatedAt,
 		UpdatedAt:   plan.UpdatedAt,
 	}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+)
+
+// TestGetLatestPlan 按 code 查找计划时包括已完成和已取消的计划：活跃计划优先，其次最近更新的
+func TestGetLatestPlan(t *testing.T) {
+	for _, backend := range testBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc := backend.setup(t)
+			dir := filepath.Join(t.TempDir(), "proj")
+			if err := os.Mkdir(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := svc.ensurePath(ctx, dir); err != nil {
+				t.Fatalf("登记路径失败: %v", err)
+			}
+			scopeCtx, err := svc.group.ResolveScope(ctx, dir)
+			if err != nil {
+				t.Fatalf("解析作用域失败: %v", err)
+			}
+
+			create := func() *entity.Plan {
+				t.Helper()
+				plan, err := svc.plan.CreatePlan(ctx, &dto.PlanCreateDTO{
+					Code: "plan-latest", Title: "计划", Description: "描述", Content: "内容",
+				}, scopeCtx)
+				if err != nil {
+					t.Fatalf("创建计划失败: %v", err)
+				}
+				// 保证更新时间有先后
+				time.Sleep(5 * time.Millisecond)
+				return plan
+			}
+
+			var first, second *entity.Plan
+			steps := []struct {
+				name   string
+				action func() error
+				want   func() *entity.Plan
+			}{
+				{
+					name:   "活跃计划",
+					action: func() error { first = create(); return nil },
+					want:   func() *entity.Plan { return first },
+				},
+				{
+					name:   "完成后仍能找到",
+					action: func() error { return svc.plan.CompletePlan(ctx, "plan-latest") },
+					want:   func() *entity.Plan { return first },
+				},
+				{
+					name:   "同 code 的新计划优先",
+					action: func() error { second = create(); return nil },
+					want:   func() *entity.Plan { return second },
+				},
+				{
+					name:   "都不活跃时取最近更新的",
+					action: func() error { return svc.plan.CancelPlan(ctx, "plan-latest") },
+					want:   func() *entity.Plan { return second },
+				},
+				{
+					name:   "回收站中的计划不算",
+					action: func() error { return svc.plan.DeletePlanByID(ctx, second.ID) },
+					want:   func() *entity.Plan { return first },
+				},
+			}
+			for _, step := range steps {
+				if err := step.action(); err != nil {
+					t.Fatalf("%s: 操作失败: %v", step.name, err)
+				}
+				got, err := svc.plan.GetLatestPlan(ctx, "plan-latest")
+				if err != nil {
+					t.Fatalf("%s: 查找计划失败: %v", step.name, err)
+				}
+				if want := step.want(); got.ID != want.ID {
+					t.Fatalf("%s: 找到计划 %d，期望 %d", step.name, got.ID, want.ID)
+				}
+			}
+
+			if _, err := svc.plan.GetLatestPlan(ctx, "plan-missing"); err == nil {
+				t.Fatalf("不存在的计划应返回错误")
+			}
+		})
+	}
+}
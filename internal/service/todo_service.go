@@ -15,12 +15,12 @@ import (
 // ToDoService 待办事项服务
 // 注意：类型名使用 ToDo，MCP 工具名保持 todo_*
 type ToDoService struct {
-	todoModel *models.ToDoModel
-	planModel *models.PlanModel
+	todoModel models.ToDoRepository
+	planModel models.PlanRepository
 }
 
 // NewToDoService 创建新的待办事项服务实例
-func NewToDoService(todoModel *models.ToDoModel, planModel *models.PlanModel) *ToDoService {
+func NewToDoService(todoModel models.ToDoRepository, planModel models.PlanRepository) *ToDoService {
 	return &ToDoService{
 		todoModel: todoModel,
 		planModel: planModel,
@@ -122,6 +122,11 @@ func (s *ToDoService) UpdateToDo(ctx context.Context, input *dto.ToDoUpdateDTO)
 		return errors.New("待办事项不存在")
 	}
 
+	// 调用方读取后记录已被修改时拒绝覆盖
+	if err := models.CheckVersion("待办", todo.Code, input.Version, todo.Version); err != nil {
+		return err
+	}
+
 	// 应用更新
 	if input.Title != nil {
 		title := strings.TrimSpace(*input.Title)
@@ -615,6 +620,7 @@ func ToToDoResponseDTO(todo *entity.ToDo, scopeCtx *types.ScopeContext) *dto.ToD
 		Tags:        tags,
 		Scope:       string(scope),
 		IsOverdue:   todo.IsOverdue(),
+		Version:     todo.Version,
 		CreatedAt:   todo.CreatedAt,
 		UpdatedAt:   todo.UpdatedAt,
 	}
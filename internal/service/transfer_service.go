@@ -0,0 +1,663 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
+)
+
+// maxImportLineSize 导入文件单行最大长度（记忆内容可能很长）
+const maxImportLineSize = 64 * 1024 * 1024
+
+// TransferService 导入导出服务
+// 嘿嘿~ 把整个数据库搬到另一台机器上就靠它啦！(´∀｀)💖
+type TransferService struct {
+	transferModel *models.TransferModel
+}
+
+// NewTransferService 创建导入导出服务实例
+func NewTransferService(model *models.TransferModel) *TransferService {
+	return &TransferService{transferModel: model}
+}
+
+// Export 将路径、组、记忆、计划、待办导出为 JSONL（不含回收站中的条目）
+func (s *TransferService) Export(ctx context.Context, w io.Writer) (*dto.ExportStatsDTO, error) {
+	schemaVersion, err := s.transferModel.SchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := s.transferModel.LoadPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := s.transferModel.LoadGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	memories, err := s.transferModel.LoadMemories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plans, err := s.transferModel.LoadPlans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	todos, err := s.transferModel.LoadToDos(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	write := func(recordType string, data interface{}) error {
+		return enc.Encode(dto.ExportRecordDTO{Type: recordType, Data: data})
+	}
+
+	stats := &dto.ExportStatsDTO{}
+	if err := write(dto.ExportRecordHeader, dto.ExportHeaderDTO{
+		Format:        dto.ExportFormatName,
+		Version:       dto.ExportFormatVersion,
+		SchemaVersion: schemaVersion,
+		ExportedAt:    time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, p := range paths {
+		if err := write(dto.ExportRecordPath, dto.ExportPathDTO{ID: p.ID, Path: p.Path, ProjectID: p.ProjectID}); err != nil {
+			return nil, err
+		}
+		stats.Paths++
+	}
+
+	for _, g := range groups {
+		if err := write(dto.ExportRecordGroup, dto.ExportGroupDTO{
+			ID:          g.ID,
+			Name:        g.Name,
+			Description: g.Description,
+			CreatedAt:   g.CreatedAt,
+			UpdatedAt:   g.UpdatedAt,
+		}); err != nil {
+			return nil, err
+		}
+		stats.Groups++
+	}
+	for _, g := range groups {
+		for _, gp := range g.Paths {
+			if err := write(dto.ExportRecordGroupPath, dto.ExportGroupPathDTO{GroupID: g.ID, PathID: gp.PersonalPathID}); err != nil {
+				return nil, err
+			}
+			stats.GroupPaths++
+		}
+	}
+
+	for _, m := range memories {
+		tags := make([]string, len(m.Tags))
+		for i, t := range m.Tags {
+			tags[i] = t.Tag
+		}
+		if err := write(dto.ExportRecordMemory, dto.ExportMemoryDTO{
+			ID:         m.ID,
+			Code:       m.Code,
+			Global:     m.Global,
+			PathID:     m.PathID,
+			Title:      m.Title,
+			Content:    m.Content,
+			Category:   m.Category,
+			Priority:   m.Priority,
+			IsArchived: m.IsArchived,
+			Tags:       tags,
+			CreatedAt:  m.CreatedAt,
+			UpdatedAt:  m.UpdatedAt,
+		}); err != nil {
+			return nil, err
+		}
+		stats.Memories++
+	}
+
+	for _, p := range plans {
+		if err := write(dto.ExportRecordPlan, dto.ExportPlanDTO{
+			ID:          p.ID,
+			Code:        p.Code,
+			PathID:      p.PathID,
+			Title:       p.Title,
+			Description: p.Description,
+			Content:     p.Content,
+			Status:      string(p.Status),
+			Progress:    p.Progress,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+		}); err != nil {
+			return nil, err
+		}
+		stats.Plans++
+	}
+
+	for _, t := range todos {
+		if err := write(dto.ExportRecordTodo, dto.ExportTodoDTO{
+			ID:          t.ID,
+			Code:        t.Code,
+			PlanID:      t.PlanID,
+			PathID:      t.PathID,
+			Title:       t.Title,
+			Description: t.Description,
+			Priority:    int(t.Priority),
+			Status:      int(t.Status),
+			SortOrder:   t.SortOrder,
+			DueDate:     t.DueDate,
+			CompletedAt: t.CompletedAt,
+			Tags:        t.GetTagStrings(),
+			CreatedAt:   t.CreatedAt,
+			UpdatedAt:   t.UpdatedAt,
+		}); err != nil {
+			return nil, err
+		}
+		stats.Todos++
+	}
+
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// importData 解析后的导入文件内容
+type importData struct {
+	header     dto.ExportHeaderDTO
+	paths      []dto.ExportPathDTO
+	groups     []dto.ExportGroupDTO
+	groupPaths []dto.ExportGroupPathDTO
+	memories   []dto.ExportMemoryDTO
+	plans      []dto.ExportPlanDTO
+	todos      []dto.ExportTodoDTO
+	unknown    map[string]int // 不认识的记录类型（来自更新的导出格式）
+}
+
+// Import 从 JSONL 导入数据，所有写入在同一个事务中完成
+// code 冲突按 opts.Strategy 处理；opts.PathMap 用于把导出方的路径映射到本机路径
+func (s *TransferService) Import(ctx context.Context, r io.Reader, opts *dto.ImportOptionsDTO) (*dto.ImportResultDTO, error) {
+	strategy := dto.ImportStrategySkip
+	pathMap := map[string]string{}
+	if opts != nil {
+		if opts.Strategy != "" {
+			strategy = strings.ToLower(opts.Strategy)
+		}
+		for from, to := range opts.PathMap {
+			pathMap[filepath.Clean(from)] = filepath.Clean(to)
+		}
+	}
+	switch strategy {
+	case dto.ImportStrategySkip, dto.ImportStrategyOverwrite, dto.ImportStrategyRename:
+	default:
+		return nil, errors.New("无效的冲突策略，可选值: skip/overwrite/rename")
+	}
+
+	data, err := parseImportData(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.ImportResultDTO{}
+	for recordType, count := range data.unknown {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("忽略 %d 条未知类型的记录: %s", count, recordType))
+	}
+
+	err = s.transferModel.Transaction(ctx, func(tm *models.TransferModel) error {
+		imp := &importer{
+			ctx:      ctx,
+			tm:       tm,
+			strategy: strategy,
+			pathMap:  pathMap,
+			result:   result,
+			pathIDs:  map[int64]int64{},
+			groupIDs: map[int64]int64{},
+			planIDs:  map[int64]*entity.Plan{},
+		}
+		return imp.run(data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseImportData 逐行解析 JSONL 导入文件
+func parseImportData(r io.Reader) (*importData, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1024*1024), maxImportLineSize)
+
+	data := &importData{unknown: map[string]int{}}
+	lineNo := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		lineNo++
+		if line == "" {
+			continue
+		}
+
+		var record struct {
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, fmt.Errorf("第 %d 行解析失败: %w", lineNo, err)
+		}
+
+		if data.header.Format == "" && record.Type != dto.ExportRecordHeader {
+			return nil, errors.New("导入文件缺少 header，可能不是 llm-memory 导出文件")
+		}
+
+		var err error
+		switch record.Type {
+		case dto.ExportRecordHeader:
+			if err = json.Unmarshal(record.Data, &data.header); err == nil {
+				err = validateExportHeader(&data.header)
+			}
+		case dto.ExportRecordPath:
+			var v dto.ExportPathDTO
+			if err = json.Unmarshal(record.Data, &v); err == nil {
+				data.paths = append(data.paths, v)
+			}
+		case dto.ExportRecordGroup:
+			var v dto.ExportGroupDTO
+			if err = json.Unmarshal(record.Data, &v); err == nil {
+				data.groups = append(data.groups, v)
+			}
+		case dto.ExportRecordGroupPath:
+			var v dto.ExportGroupPathDTO
+			if err = json.Unmarshal(record.Data, &v); err == nil {
+				data.groupPaths = append(data.groupPaths, v)
+			}
+		case dto.ExportRecordMemory:
+			var v dto.ExportMemoryDTO
+			if err = json.Unmarshal(record.Data, &v); err == nil {
+				data.memories = append(data.memories, v)
+			}
+		case dto.ExportRecordPlan:
+			var v dto.ExportPlanDTO
+			if err = json.Unmarshal(record.Data, &v); err == nil {
+				data.plans = append(data.plans, v)
+			}
+		case dto.ExportRecordTodo:
+			var v dto.ExportTodoDTO
+			if err = json.Unmarshal(record.Data, &v); err == nil {
+				data.todos = append(data.todos, v)
+			}
+		default:
+			data.unknown[record.Type]++
+		}
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行 (%s) 解析失败: %w", lineNo, record.Type, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if data.header.Format == "" {
+		return nil, errors.New("导入文件为空")
+	}
+	return data, nil
+}
+
+// validateExportHeader 校验导出文件头
+func validateExportHeader(header *dto.ExportHeaderDTO) error {
+	if header.Format != dto.ExportFormatName {
+		return fmt.Errorf("不支持的文件格式: %s", header.Format)
+	}
+	if header.Version < 1 || header.Version > dto.ExportFormatVersion {
+		return fmt.Errorf("不支持的导出格式版本 v%d（当前支持 v%d），请升级 llm-memory", header.Version, dto.ExportFormatVersion)
+	}
+	return nil
+}
+
+// importer 单次导入的状态
+// 维护导出文件中的 ID 到本地新 ID 的映射
+type importer struct {
+	ctx      context.Context
+	tm       *models.TransferModel
+	strategy string
+	pathMap  map[string]string
+	result   *dto.ImportResultDTO
+
+	pathIDs  map[int64]int64        // 导出 path.id -> 本地 path.id
+	groupIDs map[int64]int64        // 导出 group.id -> 本地 group.id
+	planIDs  map[int64]*entity.Plan // 导出 plan.id -> 本地计划
+}
+
+// run 按依赖顺序导入所有记录
+func (imp *importer) run(data *importData) error {
+	steps := []func(*importData) error{
+		imp.importPaths,
+		imp.importGroups,
+		imp.importMemories,
+		imp.importPlans,
+		imp.importToDos,
+	}
+	for _, step := range steps {
+		if err := step(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importPaths 导入路径（按映射规则或项目标识改写为本机路径）
+func (imp *importer) importPaths(data *importData) error {
+	for _, p := range data.paths {
+		target := remapPath(p.Path, imp.pathMap)
+		// 没有显式映射时按项目标识匹配本机同一仓库的检出（绝对路径可能不同）
+		if target == filepath.Clean(p.Path) && p.ProjectID != "" {
+			if local, err := imp.tm.FindPathByProjectID(imp.ctx, target, p.ProjectID); err == nil {
+				if local.Path != target {
+					imp.result.Matched = append(imp.result.Matched, fmt.Sprintf("%s -> %s (%s)", p.Path, local.Path, p.ProjectID))
+				}
+				imp.pathIDs[p.ID] = local.ID
+				imp.result.Imported.Paths++
+				continue
+			}
+		}
+		id, err := imp.tm.EnsurePath(imp.ctx, target, p.ProjectID)
+		if err != nil {
+			return err
+		}
+		imp.pathIDs[p.ID] = id
+		imp.result.Imported.Paths++
+	}
+	return nil
+}
+
+// importGroups 导入组及组路径（同名组会合并）
+func (imp *importer) importGroups(data *importData) error {
+	for _, g := range data.groups {
+		id, err := imp.tm.EnsureGroup(imp.ctx, &entity.Group{
+			Name:        g.Name,
+			Description: g.Description,
+			CreatedAt:   g.CreatedAt,
+			UpdatedAt:   g.UpdatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		imp.groupIDs[g.ID] = id
+		imp.result.Imported.Groups++
+	}
+
+	for _, gp := range data.groupPaths {
+		groupID, ok := imp.groupIDs[gp.GroupID]
+		pathID, ok2 := imp.pathIDs[gp.PathID]
+		if !ok || !ok2 {
+			imp.warn("组路径引用了不存在的组或路径 (group_id=%d, path_id=%d)", gp.GroupID, gp.PathID)
+			continue
+		}
+		added, err := imp.tm.AddGroupPath(imp.ctx, groupID, pathID)
+		if err != nil {
+			return err
+		}
+		if !added {
+			imp.warn("路径已属于本地其他组，未加入组 (path_id=%d)", gp.PathID)
+			continue
+		}
+		imp.result.Imported.GroupPaths++
+	}
+	return nil
+}
+
+// importMemories 导入记忆（code 全局唯一，包括回收站）
+func (imp *importer) importMemories(data *importData) error {
+	for _, m := range data.memories {
+		pathID := int64(0)
+		if !m.Global {
+			id, ok := imp.pathIDs[m.PathID]
+			if !ok {
+				imp.warn("记忆 %s 引用了不存在的路径，已跳过", m.Code)
+				continue
+			}
+			pathID = id
+		}
+
+		code := m.Code
+		existing, err := imp.tm.FindMemoryByCode(imp.ctx, code)
+		if err == nil {
+			switch imp.strategy {
+			case dto.ImportStrategySkip:
+				imp.result.Skipped++
+				continue
+			case dto.ImportStrategyOverwrite:
+				if err := imp.overwriteMemory(existing, m, pathID); err != nil {
+					return fmt.Errorf("导入记忆 %s 失败: %w", m.Code, err)
+				}
+				imp.result.Overwritten++
+				imp.result.Imported.Memories++
+				continue
+			case dto.ImportStrategyRename:
+				code = renameCode(code, func(c string) bool {
+					_, err := imp.tm.FindMemoryByCode(imp.ctx, c)
+					return err == nil
+				})
+				imp.result.Renamed = append(imp.result.Renamed, fmt.Sprintf("memory %s -> %s", m.Code, code))
+			}
+		}
+
+		memory := &entity.Memory{
+			Code:       code,
+			Global:     m.Global,
+			PathID:     pathID,
+			Title:      m.Title,
+			Content:    m.Content,
+			Category:   m.Category,
+			Priority:   m.Priority,
+			IsArchived: m.IsArchived,
+			CreatedAt:  m.CreatedAt,
+			UpdatedAt:  m.UpdatedAt,
+		}
+		if err := imp.tm.InsertMemory(imp.ctx, memory, m.Tags); err != nil {
+			return fmt.Errorf("导入记忆 %s 失败: %w", m.Code, err)
+		}
+		imp.result.Imported.Memories++
+	}
+	return nil
+}
+
+// overwriteMemory 原地覆盖已有记忆
+// 内容有变化时把旧值保存为一条来源为 import 的修订，覆盖后仍可在历史中找回
+func (imp *importer) overwriteMemory(existing *entity.Memory, m dto.ExportMemoryDTO, pathID int64) error {
+	before := snapshotMemory(existing, 0)
+	after := dto.MemorySnapshotDTO{
+		Title:    m.Title,
+		Content:  m.Content,
+		Category: m.Category,
+		Priority: m.Priority,
+		Tags:     m.Tags,
+	}
+	var revision *entity.MemoryRevision
+	if !snapshotEqual(before, after) {
+		revision = &entity.MemoryRevision{
+			MemoryID: existing.ID,
+			Title:    before.Title,
+			Content:  before.Content,
+			Category: before.Category,
+			Priority: before.Priority,
+			Tags:     strings.Join(before.Tags, ","),
+			Action:   entity.MemoryRevisionActionUpdate,
+			Source:   types.ChangeSourceImport.String(),
+		}
+	}
+
+	existing.Global = m.Global
+	existing.PathID = pathID
+	existing.Title = m.Title
+	existing.Content = m.Content
+	existing.Category = m.Category
+	existing.Priority = m.Priority
+	existing.IsArchived = m.IsArchived
+	return imp.tm.OverwriteMemory(imp.ctx, existing, m.Tags, revision)
+}
+
+// importPlans 导入计划（按导出 ID 或 code 匹配本地计划，不限状态，重复导入不会产生重复计划）
+func (imp *importer) importPlans(data *importData) error {
+	for _, p := range data.plans {
+		pathID, ok := imp.pathIDs[p.PathID]
+		if !ok {
+			imp.warn("计划 %s 引用了不存在的路径，已跳过", p.Code)
+			continue
+		}
+
+		plan := &entity.Plan{
+			Code:        p.Code,
+			PathID:      pathID,
+			Title:       p.Title,
+			Description: p.Description,
+			Content:     p.Content,
+			Status:      entity.PlanStatus(p.Status),
+			Progress:    p.Progress,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+		}
+
+		existing, err := imp.tm.FindPlanForImport(imp.ctx, p.ID, p.Code)
+		if err == nil {
+			switch imp.strategy {
+			case dto.ImportStrategySkip:
+				imp.result.Skipped++
+				continue
+			case dto.ImportStrategyOverwrite:
+				// 原地覆盖，保留本地 ID，计划下已有的待办继续挂在它下面
+				existing.PathID = plan.PathID
+				existing.Title = plan.Title
+				existing.Description = plan.Description
+				existing.Content = plan.Content
+				existing.Status = plan.Status
+				existing.Progress = plan.Progress
+				if err := imp.tm.UpdatePlan(imp.ctx, existing); err != nil {
+					return fmt.Errorf("导入计划 %s 失败: %w", p.Code, err)
+				}
+				imp.planIDs[p.ID] = existing
+				imp.result.Overwritten++
+				imp.result.Imported.Plans++
+				continue
+			case dto.ImportStrategyRename:
+				plan.Code = renameCode(p.Code, func(c string) bool {
+					_, err := imp.tm.FindPlanForImport(imp.ctx, 0, c)
+					return err == nil
+				})
+				imp.result.Renamed = append(imp.result.Renamed, fmt.Sprintf("plan %s -> %s", p.Code, plan.Code))
+			}
+		}
+
+		if err := imp.tm.InsertPlan(imp.ctx, plan); err != nil {
+			return fmt.Errorf("导入计划 %s 失败: %w", p.Code, err)
+		}
+		imp.planIDs[p.ID] = plan
+		imp.result.Imported.Plans++
+	}
+	return nil
+}
+
+// importToDos 导入待办（所属计划被跳过时待办也一并跳过；按导出 ID 或 code 匹配本地待办，不限状态）
+func (imp *importer) importToDos(data *importData) error {
+	for _, t := range data.todos {
+		plan, ok := imp.planIDs[t.PlanID]
+		if !ok {
+			imp.result.Skipped++
+			continue
+		}
+
+		todo := &entity.ToDo{
+			Code:        t.Code,
+			PlanID:      plan.ID,
+			PathID:      plan.PathID, // 待办继承计划的路径
+			Title:       t.Title,
+			Description: t.Description,
+			Priority:    entity.ToDoPriority(t.Priority),
+			Status:      entity.ToDoStatus(t.Status),
+			SortOrder:   t.SortOrder,
+			DueDate:     t.DueDate,
+			CompletedAt: t.CompletedAt,
+			CreatedAt:   t.CreatedAt,
+			UpdatedAt:   t.UpdatedAt,
+		}
+
+		existing, err := imp.tm.FindToDoForImport(imp.ctx, t.ID, t.Code)
+		if err == nil {
+			switch imp.strategy {
+			case dto.ImportStrategySkip:
+				imp.result.Skipped++
+				continue
+			case dto.ImportStrategyOverwrite:
+				// 原地覆盖，保留本地 ID（可能挂到导入的计划下）
+				existing.PlanID = todo.PlanID
+				existing.PathID = todo.PathID
+				existing.Title = todo.Title
+				existing.Description = todo.Description
+				existing.Priority = todo.Priority
+				existing.Status = todo.Status
+				existing.SortOrder = todo.SortOrder
+				existing.DueDate = todo.DueDate
+				existing.CompletedAt = todo.CompletedAt
+				if err := imp.tm.UpdateToDo(imp.ctx, existing, t.Tags); err != nil {
+					return fmt.Errorf("导入待办 %s 失败: %w", t.Code, err)
+				}
+				imp.result.Overwritten++
+				imp.result.Imported.Todos++
+				continue
+			case dto.ImportStrategyRename:
+				todo.Code = renameCode(t.Code, func(c string) bool {
+					_, err := imp.tm.FindToDoForImport(imp.ctx, 0, c)
+					return err == nil
+				})
+				imp.result.Renamed = append(imp.result.Renamed, fmt.Sprintf("todo %s -> %s", t.Code, todo.Code))
+			}
+		}
+
+		if err := imp.tm.InsertToDo(imp.ctx, todo, t.Tags); err != nil {
+			return fmt.Errorf("导入待办 %s 失败: %w", t.Code, err)
+		}
+		imp.result.Imported.Todos++
+	}
+	return nil
+}
+
+// warn 记录一条导入警告
+func (imp *importer) warn(format string, args ...interface{}) {
+	imp.result.Warnings = append(imp.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+// remapPath 按最长前缀匹配把导出方路径映射为本机路径
+func remapPath(path string, pathMap map[string]string) string {
+	path = filepath.Clean(path)
+	best := ""
+	for from := range pathMap {
+		if path != from && !strings.HasPrefix(path, strings.TrimSuffix(from, string(filepath.Separator))+string(filepath.Separator)) {
+			continue
+		}
+		if len(from) > len(best) {
+			best = from
+		}
+	}
+	if best == "" {
+		return path
+	}
+	return filepath.Join(pathMap[best], strings.TrimPrefix(path, best))
+}
+
+// renameCode 为冲突的 code 生成 code-2、code-3… 形式的新 code
+func renameCode(code string, exists func(string) bool) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", code, i)
+		if !exists(candidate) {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+)
+
+// exportTransferSource 在源数据库中准备数据并导出为 JSONL
+func exportTransferSource(t *testing.T) []byte {
+	t.Helper()
+	db := openServiceTestDB(t)
+	f := pathFixture{t: t, db: db}
+	p := f.path("/src/proj")
+
+	global := &entity.Memory{ID: database.GenerateID(), Code: "mem-g", Global: true, Title: "全局", Content: "导出内容", Priority: 1, Version: 1}
+	if err := db.Create(global).Error; err != nil {
+		t.Fatalf("创建记忆失败: %v", err)
+	}
+	f.memory(p.ID, "mem-p", false)
+	f.memory(p.ID, "mem-trashed", true)
+	plan := f.plan(p.ID, "plan-a", entity.PlanStatusInProgress, false)
+	f.todo(plan, "todo-a")
+
+	var buf bytes.Buffer
+	stats, err := NewTransferService(models.NewTransferModel(db)).Export(context.Background(), &buf)
+	if err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+	want := dto.ExportStatsDTO{Paths: 1, Memories: 2, Plans: 1, Todos: 1}
+	if *stats != want {
+		t.Fatalf("导出统计 = %+v，期望 %+v（回收站中的条目不导出）", *stats, want)
+	}
+	return buf.Bytes()
+}
+
+// TestTransferImportStrategies 导入到已有同名记忆、计划、待办的数据库，按冲突策略处理
+func TestTransferImportStrategies(t *testing.T) {
+	data := exportTransferSource(t)
+
+	cases := []struct {
+		strategy        string
+		wantImported    dto.ExportStatsDTO
+		wantSkipped     int
+		wantOverwritten int
+		wantRenamed     []string
+		wantContent     string // 导入后本地 mem-g 的内容
+	}{
+		{
+			strategy:     dto.ImportStrategySkip,
+			wantImported: dto.ExportStatsDTO{Paths: 1, Memories: 1},
+			wantSkipped:  3, // mem-g、plan-a 及其待办
+			wantContent:  "本地内容",
+		},
+		{
+			strategy:        dto.ImportStrategyOverwrite,
+			wantImported:    dto.ExportStatsDTO{Paths: 1, Memories: 2, Plans: 1, Todos: 1},
+			wantOverwritten: 3,
+			wantContent:     "导出内容",
+		},
+		{
+			strategy:     dto.ImportStrategyRename,
+			wantImported: dto.ExportStatsDTO{Paths: 1, Memories: 2, Plans: 1, Todos: 1},
+			wantRenamed:  []string{"memory mem-g -> mem-g-2", "plan plan-a -> plan-a-2", "todo todo-a -> todo-a-2"},
+			wantContent:  "本地内容",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.strategy, func(t *testing.T) {
+			ctx := context.Background()
+			db := openServiceTestDB(t)
+			f := pathFixture{t: t, db: db}
+			local := f.path("/local/other")
+			existing := &entity.Memory{ID: database.GenerateID(), Code: "mem-g", Global: true, Title: "全局", Content: "本地内容", Priority: 1, Version: 1}
+			if err := db.Create(existing).Error; err != nil {
+				t.Fatalf("创建记忆失败: %v", err)
+			}
+			localPlan := f.plan(local.ID, "plan-a", entity.PlanStatusPending, false)
+			f.todo(localPlan, "todo-a")
+
+			result, err := NewTransferService(models.NewTransferModel(db)).Import(ctx, bytes.NewReader(data), &dto.ImportOptionsDTO{
+				Strategy: tc.strategy,
+				PathMap:  map[string]string{"/src": "/dst"},
+			})
+			if err != nil {
+				t.Fatalf("导入失败: %v", err)
+			}
+			if result.Imported != tc.wantImported || result.Skipped != tc.wantSkipped || result.Overwritten != tc.wantOverwritten {
+				t.Fatalf("导入结果 = %+v", *result)
+			}
+			if !reflect.DeepEqual(result.Renamed, tc.wantRenamed) {
+				t.Fatalf("改名 = %v，期望 %v", result.Renamed, tc.wantRenamed)
+			}
+
+			memory, err := models.NewMemoryModel(db).FindByCode(ctx, "mem-g")
+			if err != nil {
+				t.Fatalf("读取记忆失败: %v", err)
+			}
+			if memory.Content != tc.wantContent {
+				t.Fatalf("mem-g 内容 = %q，期望 %q", memory.Content, tc.wantContent)
+			}
+			var revisions []entity.MemoryRevision
+			db.Where("memory_id = ?", existing.ID).Find(&revisions)
+			if tc.strategy == dto.ImportStrategyOverwrite {
+				if len(revisions) != 1 || revisions[0].Content != "本地内容" || revisions[0].Source != "import" {
+					t.Fatalf("覆盖前的内容应保存为来源 import 的修订: %+v", revisions)
+				}
+			} else if len(revisions) != 0 {
+				t.Fatalf("未覆盖时不应记录修订: %+v", revisions)
+			}
+
+			// 私有记忆按路径映射挂到本机路径下
+			private, err := models.NewMemoryModel(db).FindByCode(ctx, "mem-p")
+			if err != nil {
+				t.Fatalf("读取记忆失败: %v", err)
+			}
+			var p entity.PersonalPath
+			if err := db.First(&p, private.PathID).Error; err != nil || p.Path != filepath.Clean("/dst/proj") {
+				t.Fatalf("私有记忆的路径 = %+v（%v），期望 /dst/proj", p, err)
+			}
+		})
+	}
+}
+
+// TestTransferImportIdempotent 重复导入同一文件（skip）不产生重复数据
+func TestTransferImportIdempotent(t *testing.T) {
+	data := exportTransferSource(t)
+	db := openServiceTestDB(t)
+	svc := NewTransferService(models.NewTransferModel(db))
+	for i, wantSkipped := range []int{0, 4} {
+		result, err := svc.Import(context.Background(), bytes.NewReader(data), nil)
+		if err != nil {
+			t.Fatalf("第 %d 次导入失败: %v", i+1, err)
+		}
+		if result.Skipped != wantSkipped {
+			t.Fatalf("第 %d 次导入跳过 %d 条，期望 %d", i+1, result.Skipped, wantSkipped)
+		}
+	}
+	for table, want := range map[interface{}]int64{&entity.Memory{}: 2, &entity.Plan{}: 1, &entity.ToDo{}: 1, &entity.PersonalPath{}: 1} {
+		var count int64
+		db.Model(table).Count(&count)
+		if count != want {
+			t.Fatalf("%T 数量 = %d，期望 %d", table, count, want)
+		}
+	}
+}
+
+func TestTransferImportRejects(t *testing.T) {
+	header := `{"type":"header","data":{"format":"llm-memory-export","version":1}}`
+	cases := []struct {
+		name     string
+		input    string
+		strategy string
+		wantErr  string
+		wantWarn string
+	}{
+		{name: "空文件", input: "", wantErr: "导入文件为空"},
+		{name: "缺少 header", input: `{"type":"memory","data":{}}`, wantErr: "缺少 header"},
+		{name: "格式不对", input: `{"type":"header","data":{"format":"other","version":1}}`, wantErr: "不支持的文件格式"},
+		{name: "版本过新", input: `{"type":"header","data":{"format":"llm-memory-export","version":99}}`, wantErr: "请升级 llm-memory"},
+		{name: "JSON 损坏", input: header + "\n{not json", wantErr: "第 2 行解析失败"},
+		{name: "策略无效", input: header, strategy: "merge", wantErr: "无效的冲突策略"},
+		{name: "忽略未知类型", input: header + "\n\n" + `{"type":"attachment","data":{}}`, wantWarn: "忽略 1 条未知类型的记录: attachment"},
+		{name: "引用不存在的路径", input: header + "\n" + `{"type":"memory","data":{"code":"mem-x","path_id":42}}`, wantWarn: "记忆 mem-x 引用了不存在的路径"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewTransferService(models.NewTransferModel(openServiceTestDB(t)))
+			result, err := svc.Import(context.Background(), strings.NewReader(tc.input), &dto.ImportOptionsDTO{Strategy: tc.strategy})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("错误 = %v，期望包含 %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("导入失败: %v", err)
+			}
+			if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], tc.wantWarn) {
+				t.Fatalf("警告 = %v，期望包含 %q", result.Warnings, tc.wantWarn)
+			}
+		})
+	}
+}
+
+func TestRemapPath(t *testing.T) {
+	pathMap := map[string]string{
+		"/home/alice":          "/Users/bob",
+		"/home/alice/work":     "/srv/work",
+		"/home/alice/work/old": "/srv/new",
+	}
+	cases := []struct {
+		path string
+		want string
+	}{
+		{path: "/home/alice", want: "/Users/bob"},
+		{path: "/home/alice/notes/", want: "/Users/bob/notes"},
+		{path: "/home/alice/work/proj", want: "/srv/work/proj"},
+		{path: "/home/alice/work/old/x", want: "/srv/new/x"},
+		{path: "/home/alicexyz/proj", want: "/home/alicexyz/proj"},
+		{path: "/opt/other", want: "/opt/other"},
+	}
+	for _, tc := range cases {
+		if got := remapPath(tc.path, pathMap); got != filepath.FromSlash(tc.want) {
+			t.Fatalf("remapPath(%s) = %s，期望 %s", tc.path, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+)
+
+// vaultManifestName 仓库清单文件名
+// 记录本工具写出的每个文件及其内容哈希，用于增量更新和清理已删除的记录
+const vaultManifestName = ".llm-memory-vault.json"
+
+// vaultManifestVersion 清单格式版本
+const vaultManifestVersion = 1
+
+// vaultManifest 仓库清单
+type vaultManifest struct {
+	Version int                          `json:"version"`
+	Files   map[string]vaultManifestFile `json:"files"` // 相对路径（使用 /） -> 文件信息
+}
+
+// vaultManifestFile 清单中的单个文件
+type vaultManifestFile struct {
+	Type string `json:"type"` // memory/plan
+	ID   int64  `json:"id"`
+	Hash string `json:"hash"` // 内容 sha256
+}
+
+// vaultFile 待写出的文件
+type vaultFile struct {
+	rel     string
+	typ     string
+	id      int64
+	content string
+}
+
+// vaultScope 路径对应的仓库目录和作用域信息
+type vaultScope struct {
+	dir   string // 相对目录，如 groups/team、personal/proj-1a2b3c
+	scope string // global/group/personal
+	path  string
+	group string
+}
+
+// ExportVault 将记忆和计划导出为 Obsidian 兼容的 Markdown 仓库
+// 嘿嘿~ 按作用域和组分目录，重复执行时只重写变化的文件，并删除已删除记录对应的文件！📝
+// 目录结构：
+//
+//	global/memories/<code>.md
+//	groups/<组名>/memories|plans/<code>.md
+//	personal/<目录名-哈希>/memories|plans/<code>.md
+func (s *TransferService) ExportVault(ctx context.Context, dir string) (*dto.VaultExportResultDTO, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, errors.New("导出目录不能为空")
+	}
+
+	paths, err := s.transferModel.LoadPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := s.transferModel.LoadGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	memories, err := s.transferModel.LoadMemories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plans, err := s.transferModel.LoadPlans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	todos, err := s.transferModel.LoadToDos(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 路径 -> 目录映射
+	groupOfPath := make(map[int64]string)
+	for _, g := range groups {
+		for _, gp := range g.Paths {
+			groupOfPath[gp.PersonalPathID] = g.Name
+		}
+	}
+	scopes := make(map[int64]vaultScope, len(paths))
+	for _, p := range paths {
+		if name, ok := groupOfPath[p.ID]; ok {
+			scopes[p.ID] = vaultScope{dir: "groups/" + sanitizeFileName(name), scope: "group", path: p.Path, group: name}
+			continue
+		}
+		scopes[p.ID] = vaultScope{dir: "personal/" + pathFolderName(p.Path), scope: "personal", path: p.Path}
+	}
+	scopeOf := func(global bool, pathID int64) vaultScope {
+		if global {
+			return vaultScope{dir: "global", scope: "global"}
+		}
+		if sc, ok := scopes[pathID]; ok {
+			return sc
+		}
+		return vaultScope{dir: "personal/unknown", scope: "personal"}
+	}
+
+	todosByPlan := make(map[int64][]entity.ToDo)
+	for _, t := range todos {
+		todosByPlan[t.PlanID] = append(todosByPlan[t.PlanID], t)
+	}
+
+	// 生成所有文件内容（同目录下 code 重复时追加 ID）
+	var files []vaultFile
+	used := make(map[string]bool)
+	uniqueRel := func(dir, code string, id int64) string {
+		rel := dir + "/" + sanitizeFileName(code) + ".md"
+		if used[rel] {
+			rel = fmt.Sprintf("%s/%s-%d.md", dir, sanitizeFileName(code), id)
+		}
+		used[rel] = true
+		return rel
+	}
+	for i := range memories {
+		m := &memories[i]
+		sc := scopeOf(m.Global, m.PathID)
+		files = append(files, vaultFile{
+			rel:     uniqueRel(sc.dir+"/memories", m.Code, m.ID),
+			typ:     dto.ExportRecordMemory,
+			id:      m.ID,
+			content: renderMemoryMarkdown(m, sc),
+		})
+	}
+	for i := range plans {
+		p := &plans[i]
+		sc := scopeOf(false, p.PathID)
+		files = append(files, vaultFile{
+			rel:     uniqueRel(sc.dir+"/plans", p.Code, p.ID),
+			typ:     dto.ExportRecordPlan,
+			id:      p.ID,
+			content: renderPlanMarkdown(p, todosByPlan[p.ID], sc),
+		})
+	}
+
+	// 读取旧清单
+	manifestPath := filepath.Join(dir, vaultManifestName)
+	old := &vaultManifest{Files: map[string]vaultManifestFile{}}
+	if data, err := os.ReadFile(manifestPath); err == nil {
+		if err := json.Unmarshal(data, old); err != nil {
+			return nil, fmt.Errorf("解析仓库清单失败: %w", err)
+		}
+		if old.Files == nil {
+			old.Files = map[string]vaultManifestFile{}
+		}
+		// 清单中的路径会被用来删除文件，拒绝绝对路径和跳出仓库目录的路径
+		for rel := range old.Files {
+			if !isVaultRel(dir, rel) {
+				return nil, fmt.Errorf("仓库清单包含非法路径: %s", rel)
+			}
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	result := &dto.VaultExportResultDTO{}
+	manifest := &vaultManifest{Version: vaultManifestVersion, Files: make(map[string]vaultManifestFile, len(files))}
+	for _, f := range files {
+		sum := sha256.Sum256([]byte(f.content))
+		hash := hex.EncodeToString(sum[:])
+		manifest.Files[f.rel] = vaultManifestFile{Type: f.typ, ID: f.id, Hash: hash}
+
+		full := filepath.Join(dir, filepath.FromSlash(f.rel))
+		if prev, ok := old.Files[f.rel]; ok && prev.Hash == hash {
+			if _, err := os.Stat(full); err == nil {
+				result.Unchanged++
+				continue
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(full, []byte(f.content), 0644); err != nil {
+			return nil, err
+		}
+		result.Written++
+	}
+
+	// 删除清单中已不存在的记录对应的文件（只删除本工具写出的文件）
+	var stale []string
+	for rel := range old.Files {
+		if _, ok := manifest.Files[rel]; !ok {
+			stale = append(stale, rel)
+		}
+	}
+	sort.Strings(stale)
+	for _, rel := range stale {
+		full := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.Remove(full); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		removeEmptyDirs(filepath.Dir(full), dir)
+		result.Removed++
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(manifestPath, data, 0644); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// renderMemoryMarkdown 渲染记忆为带 YAML front matter 的 Markdown
+func renderMemoryMarkdown(m *entity.Memory, sc vaultScope) string {
+	tags := make([]string, len(m.Tags))
+	for i, t := range m.Tags {
+		tags[i] = t.Tag
+	}
+	sort.Strings(tags)
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	writeYAMLField(&b, "code", m.Code)
+	writeYAMLField(&b, "title", m.Title)
+	writeYAMLField(&b, "category", m.Category)
+	writeYAMLList(&b, "tags", tags)
+	fmt.Fprintf(&b, "priority: %d\n", m.Priority)
+	writeScopeFields(&b, sc)
+	if m.IsArchived {
+		b.WriteString("archived: true\n")
+	}
+	writeYAMLField(&b, "created", m.CreatedAt.Format(time.RFC3339))
+	writeYAMLField(&b, "updated", m.UpdatedAt.Format(time.RFC3339))
+	b.WriteString("---\n\n")
+	fmt.Fprintf(&b, "# %s\n\n", m.Title)
+	b.WriteString(strings.TrimSpace(m.Content))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// renderPlanMarkdown 渲染计划为 Markdown，待办渲染为任务清单
+func renderPlanMarkdown(p *entity.Plan, todos []entity.ToDo, sc vaultScope) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	writeYAMLField(&b, "code", p.Code)
+	writeYAMLField(&b, "title", p.Title)
+	writeYAMLField(&b, "status", string(p.Status))
+	fmt.Fprintf(&b, "progress: %d\n", p.Progress)
+	writeScopeFields(&b, sc)
+	writeYAMLField(&b, "created", p.CreatedAt.Format(time.RFC3339))
+	writeYAMLField(&b, "updated", p.UpdatedAt.Format(time.RFC3339))
+	b.WriteString("---\n\n")
+	fmt.Fprintf(&b, "# %s\n\n", p.Title)
+	if desc := strings.TrimSpace(p.Description); desc != "" {
+		for _, line := range strings.Split(desc, "\n") {
+			b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+		b.WriteString("\n")
+	}
+	if content := strings.TrimSpace(p.Content); content != "" {
+		b.WriteString(content)
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString("## 待办\n\n")
+	if len(todos) == 0 {
+		b.WriteString("暂无待办\n")
+		return b.String()
+	}
+	for _, t := range todos {
+		box := "[ ]"
+		title := t.Title
+		switch t.Status {
+		case entity.ToDoStatusCompleted:
+			box = "[x]"
+		case entity.ToDoStatusCancelled:
+			title = "~~" + title + "~~"
+		}
+		fmt.Fprintf(&b, "- %s %s `%s`\n", box, title, t.Code)
+	}
+	return b.String()
+}
+
+// writeScopeFields 写入作用域相关的 front matter 字段
+func writeScopeFields(b *strings.Builder, sc vaultScope) {
+	writeYAMLField(b, "scope", sc.scope)
+	if sc.group != "" {
+		writeYAMLField(b, "group", sc.group)
+	}
+	if sc.path != "" {
+		writeYAMLField(b, "path", sc.path)
+	}
+}
+
+// writeYAMLField 写入字符串字段（JSON 字符串同时也是合法的 YAML 双引号字符串）
+func writeYAMLField(b *strings.Builder, key, value string) {
+	quoted, _ := json.Marshal(value)
+	fmt.Fprintf(b, "%s: %s\n", key, quoted)
+}
+
+// writeYAMLList 写入字符串列表字段
+func writeYAMLList(b *strings.Builder, key string, values []string) {
+	if len(values) == 0 {
+		fmt.Fprintf(b, "%s: []\n", key)
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", key)
+	for _, v := range values {
+		quoted, _ := json.Marshal(v)
+		fmt.Fprintf(b, "  - %s\n", quoted)
+	}
+}
+
+// sanitizeFileName 去除文件名中不允许的字符
+func sanitizeFileName(name string) string {
+	replacer := strings.NewReplacer("/", "-", "\\", "-", ":", "-", "*", "-", "?", "-", "\"", "-", "<", "-", ">", "-", "|", "-")
+	name = strings.TrimSpace(replacer.Replace(name))
+	if name == "" || name == "." || name == ".." {
+		return "untitled"
+	}
+	return name
+}
+
+// pathFolderName 路径对应的目录名：最后一级目录名 + 路径哈希（避免同名目录冲突）
+func pathFolderName(path string) string {
+	sum := sha1.Sum([]byte(path))
+	return sanitizeFileName(filepath.Base(path)) + "-" + hex.EncodeToString(sum[:])[:6]
+}
+
+// isVaultRel 判断清单中的路径是否位于仓库目录内（不是绝对路径，清理后也不会跳出仓库目录）
+func isVaultRel(root, rel string) bool {
+	local := filepath.FromSlash(rel)
+	if rel == "" || strings.HasPrefix(rel, "/") || filepath.IsAbs(local) || filepath.VolumeName(local) != "" {
+		return false
+	}
+	r, err := filepath.Rel(root, filepath.Join(root, local))
+	if err != nil {
+		return false
+	}
+	return r != "." && r != ".." && !strings.HasPrefix(r, ".."+string(filepath.Separator))
+}
+
+// removeEmptyDirs 自下而上删除空目录，直到仓库根目录
+func removeEmptyDirs(dir, root string) {
+	root = filepath.Clean(root)
+	for dir = filepath.Clean(dir); dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		entries, err := os.ReadDir(dir)
+		if err != nil || len(entries) > 0 {
+			return
+		}
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+)
+
+// TestExportVault 增量导出：未变化的文件不重写，已删除记录的文件被移除，用户自己的文件不受影响
+func TestExportVault(t *testing.T) {
+	ctx := context.Background()
+	db := openServiceTestDB(t)
+	f := pathFixture{t: t, db: db}
+	solo := f.path("/work/solo")
+	team := f.path("/work/api")
+	f.group("team", team)
+
+	global := &entity.Memory{ID: database.GenerateID(), Code: "mem-global", Global: true, Title: "全局", Content: "全局内容", Version: 1}
+	if err := db.Create(global).Error; err != nil {
+		t.Fatalf("创建记忆失败: %v", err)
+	}
+	private := f.memory(solo.ID, "mem-solo", false)
+	plan := f.plan(team.ID, "plan-team", entity.PlanStatusInProgress, false)
+	f.todo(plan, "todo-team")
+
+	svc := NewTransferService(models.NewTransferModel(db))
+	dir := t.TempDir()
+	soloDir := "personal/" + pathFolderName(solo.Path)
+	wantFiles := []string{
+		"global/memories/mem-global.md",
+		soloDir + "/memories/mem-solo.md",
+		"groups/team/plans/plan-team.md",
+	}
+
+	steps := []struct {
+		name   string
+		before func()
+		want   dto.VaultExportResultDTO
+	}{
+		{name: "首次导出", want: dto.VaultExportResultDTO{Written: 3}},
+		{name: "重复导出", want: dto.VaultExportResultDTO{Unchanged: 3}},
+		{
+			name:   "文件被手动删除后重写",
+			before: func() { _ = os.Remove(filepath.Join(dir, filepath.FromSlash(wantFiles[0]))) },
+			want:   dto.VaultExportResultDTO{Written: 1, Unchanged: 2},
+		},
+		{
+			name: "记录删除后移除文件",
+			before: func() {
+				if err := db.Delete(private).Error; err != nil {
+					t.Fatalf("删除记忆失败: %v", err)
+				}
+			},
+			want: dto.VaultExportResultDTO{Unchanged: 2, Removed: 1},
+		},
+	}
+	userFile := filepath.Join(dir, "global", "memories", "my-note.md")
+	for _, step := range steps {
+		if step.before != nil {
+			step.before()
+		}
+		result, err := svc.ExportVault(ctx, dir)
+		if err != nil {
+			t.Fatalf("%s: 导出失败: %v", step.name, err)
+		}
+		if *result != step.want {
+			t.Fatalf("%s: 结果 = %+v，期望 %+v", step.name, *result, step.want)
+		}
+		if step.name == "首次导出" {
+			for _, rel := range wantFiles {
+				if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(rel))); err != nil {
+					t.Fatalf("缺少文件 %s: %v", rel, err)
+				}
+			}
+			content, _ := os.ReadFile(filepath.Join(dir, "groups", "team", "plans", "plan-team.md"))
+			if !strings.Contains(string(content), "todo-team") || !strings.Contains(string(content), `scope: "group"`) {
+				t.Fatalf("计划文件缺少待办或作用域:\n%s", content)
+			}
+			if err := os.WriteFile(userFile, []byte("mine"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, soloDir)); !os.IsNotExist(err) {
+		t.Fatalf("删除文件后应清理空目录: %v", err)
+	}
+	if _, err := os.Stat(userFile); err != nil {
+		t.Fatalf("不应删除清单以外的文件: %v", err)
+	}
+}
+
+// TestExportVaultRejectsManifest 清单中的路径会被用来删除文件，跳出仓库目录的路径必须拒绝
+func TestExportVaultRejectsManifest(t *testing.T) {
+	cases := []struct {
+		name string
+		rel  string
+	}{
+		{name: "上级目录", rel: "../outside.md"},
+		{name: "中途跳出", rel: "global/../../outside.md"},
+		{name: "绝对路径", rel: "/tmp/outside.md"},
+		{name: "仓库根目录", rel: "."},
+		{name: "空路径", rel: ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			dir := filepath.Join(root, "vault")
+			outside := filepath.Join(root, "outside.md")
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(outside, []byte("keep"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			manifest := `{"version": 1, "files": {"` + tc.rel + `": {"type": "memory", "id": 1, "hash": "x"}}}`
+			if err := os.WriteFile(filepath.Join(dir, vaultManifestName), []byte(manifest), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			svc := NewTransferService(models.NewTransferModel(openServiceTestDB(t)))
+			_, err := svc.ExportVault(context.Background(), dir)
+			if err == nil || !strings.Contains(err.Error(), "仓库清单包含非法路径") {
+				t.Fatalf("错误 = %v，期望拒绝清单", err)
+			}
+			if _, err := os.Stat(outside); err != nil {
+				t.Fatalf("仓库外的文件不应被删除: %v", err)
+			}
+		})
+	}
+}
+
+func TestIsVaultRel(t *testing.T) {
+	root := filepath.FromSlash("/vault")
+	cases := []struct {
+		rel  string
+		want bool
+	}{
+		{rel: "global/memories/a.md", want: true},
+		{rel: "groups/team/../team/plans/p.md", want: true},
+		{rel: "..md", want: true},
+		{rel: "", want: false},
+		{rel: ".", want: false},
+		{rel: "..", want: false},
+		{rel: "../a.md", want: false},
+		{rel: "a/../../b.md", want: false},
+		{rel: "/etc/passwd", want: false},
+	}
+	for _, tc := range cases {
+		if got := isVaultRel(root, tc.rel); got != tc.want {
+			t.Fatalf("isVaultRel(%q) = %v，期望 %v", tc.rel, got, tc.want)
+		}
+	}
+}
+
+func TestSanitizeFileName(t *testing.T) {
+	cases := []struct {
+		name string
+		want string
+	}{
+		{name: "mem-1", want: "mem-1"},
+		{name: "a/b\\c:d", want: "a-b-c-d"},
+		{name: `*?"<>|`, want: "------"},
+		{name: "  ", want: "untitled"},
+		{name: "..", want: "untitled"},
+	}
+	for _, tc := range cases {
+		if got := sanitizeFileName(tc.name); got != tc.want {
+			t.Fatalf("sanitizeFileName(%q) = %q，期望 %q", tc.name, got, tc.want)
+		}
+	}
+}
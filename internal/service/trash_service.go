@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
+)
+
+// TrashService 回收站服务
+// 嘿嘿~ 删除的记忆、计划、待办都会先进回收站，手滑也不怕啦！💖
+type TrashService struct {
+	memoryModel *models.MemoryModel
+	planModel   *models.PlanModel
+	todoModel   *models.ToDoModel
+}
+
+// NewTrashService 创建回收站服务实例
+func NewTrashService(memoryModel *models.MemoryModel, planModel *models.PlanModel, todoModel *models.ToDoModel) *TrashService {
+	return &TrashService{
+		memoryModel: memoryModel,
+		planModel:   planModel,
+		todoModel:   todoModel,
+	}
+}
+
+// ListTrash 列出当前作用域可见的回收站条目（按删除时间倒序）
+// 随计划一起删除的待办不单独列出，恢复计划时会一并恢复
+func (s *TrashService) ListTrash(ctx context.Context, scopeCtx *types.ScopeContext) ([]dto.TrashItemDTO, error) {
+	memories, err := s.memoryModel.FindDeletedByFilter(ctx, buildVisibilityFilter("all", scopeCtx))
+	if err != nil {
+		return nil, err
+	}
+	plans, err := s.planModel.FindDeletedByPathOnlyFilter(ctx, buildPathOnlyFilter("all", scopeCtx))
+	if err != nil {
+		return nil, err
+	}
+	todos, err := s.todoModel.FindDeletedByPathOnlyFilter(ctx, buildPathOnlyFilter("all", scopeCtx))
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.TrashItemDTO, 0, len(memories)+len(plans)+len(todos))
+	for _, m := range memories {
+		items = append(items, dto.TrashItemDTO{
+			Type:      dto.TrashTypeMemory,
+			ID:        m.ID,
+			Code:      m.Code,
+			Title:     m.Title,
+			DeletedAt: m.DeletedAt.Time,
+		})
+	}
+	for _, p := range plans {
+		items = append(items, dto.TrashItemDTO{
+			Type:      dto.TrashTypePlan,
+			ID:        p.ID,
+			Code:      p.Code,
+			Title:     p.Title,
+			DeletedAt: p.DeletedAt.Time,
+		})
+	}
+	for _, t := range todos {
+		items = append(items, dto.TrashItemDTO{
+			Type:      dto.TrashTypeTodo,
+			ID:        t.ID,
+			Code:      t.Code,
+			Title:     t.Title,
+			DeletedAt: t.DeletedAt.Time,
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// RestoreItem 从回收站恢复条目
+// itemType: memory/plan/todo；只能恢复当前作用域可见的条目
+func (s *TrashService) RestoreItem(ctx context.Context, itemType, code string, scopeCtx *types.ScopeContext) error {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return errors.New("标识码不能为空")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(itemType)) {
+	case dto.TrashTypeMemory:
+		return s.restoreMemory(ctx, code, scopeCtx)
+	case dto.TrashTypePlan:
+		return s.restorePlan(ctx, code, scopeCtx)
+	case dto.TrashTypeTodo:
+		return s.restoreToDo(ctx, code, scopeCtx)
+	default:
+		return errors.New("无效的类型，可选值: memory/plan/todo")
+	}
+}
+
+// restoreMemory 恢复记忆
+func (s *TrashService) restoreMemory(ctx context.Context, code string, scopeCtx *types.ScopeContext) error {
+	memory, err := s.memoryModel.FindDeletedByCode(ctx, code)
+	if err != nil {
+		return errors.New("回收站中不存在该记忆")
+	}
+	if !memory.Global && !isPathVisible(memory.PathID, scopeCtx) {
+		return errors.New("回收站中不存在该记忆")
+	}
+	return s.memoryModel.Restore(ctx, memory.ID)
+}
+
+// restorePlan 恢复计划（连同一起删除的待办）
+func (s *TrashService) restorePlan(ctx context.Context, code string, scopeCtx *types.ScopeContext) error {
+	plan, err := s.planModel.FindDeletedByCode(ctx, code)
+	if err != nil || !isPathVisible(plan.PathID, scopeCtx) {
+		return errors.New("回收站中不存在该计划")
+	}
+	if isActivePlanStatus(plan.Status) {
+		exists, err := s.planModel.ExistsActiveCode(ctx, plan.Code, plan.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return errors.New("活跃状态中已存在相同 code 的计划，无法恢复")
+		}
+	}
+	return s.planModel.Restore(ctx, plan.ID)
+}
+
+// restoreToDo 恢复单独删除的待办
+func (s *TrashService) restoreToDo(ctx context.Context, code string, scopeCtx *types.ScopeContext) error {
+	todo, err := s.todoModel.FindDeletedByCode(ctx, code)
+	if err != nil || !isPathVisible(todo.PathID, scopeCtx) {
+		return errors.New("回收站中不存在该待办")
+	}
+	if todo.PlanID > 0 {
+		if _, err := s.planModel.FindByID(ctx, todo.PlanID); err != nil {
+			return errors.New("所属计划在回收站中，请先恢复计划")
+		}
+	}
+	if todo.Status == entity.ToDoStatusPending || todo.Status == entity.ToDoStatusInProgress {
+		exists, err := s.todoModel.ExistsActiveCode(ctx, todo.Code, todo.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return errors.New("活跃状态中已存在相同 code 的待办，无法恢复")
+		}
+	}
+	if err := s.todoModel.Restore(ctx, todo.ID); err != nil {
+		return err
+	}
+
+	// 恢复后重新计算计划进度
+	if todo.PlanID > 0 {
+		if total, completed, err := s.todoModel.CountByPlanID(ctx, todo.PlanID); err == nil && total > 0 {
+			_ = s.planModel.UpdateProgress(ctx, todo.PlanID, int((completed*100)/total))
+		}
+	}
+	return nil
+}
+
+// Purge 彻底删除回收站中超过 olderThan 的条目
+// olderThan 为 0 时清空整个回收站；清理不区分作用域
+func (s *TrashService) Purge(ctx context.Context, olderThan time.Duration) (*dto.TrashPurgeResultDTO, error) {
+	if olderThan < 0 {
+		return nil, errors.New("时长不能为负数")
+	}
+	cutoff := time.Now().Add(-olderThan)
+
+	result := &dto.TrashPurgeResultDTO{}
+	var err error
+	// 先清理计划（会连带其下所有待办），再清理单独删除的待办
+	if result.Plans, err = s.planModel.PurgeDeletedBefore(ctx, cutoff); err != nil {
+		return nil, err
+	}
+	if result.Todos, err = s.todoModel.PurgeDeletedBefore(ctx, cutoff); err != nil {
+		return nil, err
+	}
+	if result.Memories, err = s.memoryModel.PurgeDeletedBefore(ctx, cutoff); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// isPathVisible 判断路径是否在当前作用域内（当前路径 + 组路径）
+func isPathVisible(pathID int64, scopeCtx *types.ScopeContext) bool {
+	if scopeCtx == nil {
+		return false
+	}
+	for _, id := range models.MergePathIDs(scopeCtx.PathID, scopeCtx.GroupPathIDs) {
+		if id == pathID {
+			return true
+		}
+	}
+	return false
+}
+
+// isActivePlanStatus 判断计划状态是否为活跃（未完成/未取消）
+func isActivePlanStatus(status entity.PlanStatus) bool {
+	return status != entity.PlanStatusCompleted && status != entity.PlanStatusCancelled
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestTrashRestoreKeepsSearchIndex 回收站中的记忆保留全文索引：
+// 一致性检查不把它当孤儿，--fix 后恢复的记忆仍然能搜到
+func TestTrashRestoreKeepsSearchIndex(t *testing.T) {
+	ctx := context.Background()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if _, err := database.NewMigrator(db).Migrate(ctx); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+
+	memoryModel := models.NewMemoryModel(db)
+	planModel := models.NewPlanModel(db)
+	memorySvc := NewMemoryService(memoryModel, models.NewMemoryRevisionModel(db), models.NewMemoryEmbeddingModel(db), nil)
+	trashSvc := NewTrashService(memoryModel, planModel, models.NewToDoModel(db))
+	scopeCtx := types.NewGlobalOnlyScope()
+
+	if _, err := memorySvc.CreateMemory(ctx, &dto.MemoryCreateDTO{
+		Code: "mem-trash", Title: "回收站测试", Content: "独角兽 rainbow", Global: true,
+	}, scopeCtx); err != nil {
+		t.Fatalf("创建记忆失败: %v", err)
+	}
+	if err := memorySvc.DeleteMemory(ctx, "mem-trash"); err != nil {
+		t.Fatalf("删除记忆失败: %v", err)
+	}
+
+	// 回收站中的记忆搜不到
+	found, err := memorySvc.SearchMemories(ctx, "rainbow")
+	if err != nil {
+		t.Fatalf("搜索失败: %v", err)
+	}
+	assertCodes(t, "回收站中的搜索结果", memoryCodes(found), nil)
+
+	// 索引行保留，检查不报问题，修复也不动它
+	report, err := database.CheckDatabase(ctx, db)
+	if err != nil {
+		t.Fatalf("检查失败: %v", err)
+	}
+	if report.ProblemCount() != 0 {
+		t.Fatalf("回收站中的记忆不应报告问题: %+v", report.Issues)
+	}
+	fixes, err := database.FixDatabase(ctx, db)
+	if err != nil {
+		t.Fatalf("修复失败: %v", err)
+	}
+	if len(fixes) != 0 {
+		t.Fatalf("不应有需要修复的项: %+v", fixes)
+	}
+
+	if err := trashSvc.RestoreItem(ctx, dto.TrashTypeMemory, "mem-trash", scopeCtx); err != nil {
+		t.Fatalf("恢复记忆失败: %v", err)
+	}
+	for _, keyword := range []string{"rainbow", "独角兽"} {
+		found, err := memorySvc.SearchMemories(ctx, keyword)
+		if err != nil {
+			t.Fatalf("搜索失败: %v", err)
+		}
+		assertCodes(t, "恢复后的搜索结果("+keyword+")", memoryCodes(found), []string{"mem-trash"})
+	}
+}
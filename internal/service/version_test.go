@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+)
+
+// versionedKind 一种带乐观锁的记录（记忆 / 计划 / 待办）的测试操作
+type versionedKind struct {
+	name       string
+	entityName string
+	code       string
+	// update 通过服务更新标题，version 为 nil 时不校验
+	update func(ctx context.Context, version *int64, title string) error
+	// get 读取当前标题和版本
+	get func(ctx context.Context) (string, int64, error)
+	// touch 不经过 Update DTO 的字段级操作（归档、开始等），也应递增版本
+	touch func(ctx context.Context) error
+	// staleWrite 两方读取同一版本后先后写入，返回后写入一方持有的版本和它的错误
+	staleWrite func(ctx context.Context) (int64, error)
+}
+
+// newVersionedKinds 在 svc 中创建记忆、计划和待办各一条并返回对应的操作
+func newVersionedKinds(t *testing.T, svc *testServices) []versionedKind {
+	t.Helper()
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "project")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ensurePath(ctx, dir); err != nil {
+		t.Fatalf("登记路径失败: %v", err)
+	}
+	scopeCtx, err := svc.group.ResolveScope(ctx, dir)
+	if err != nil {
+		t.Fatalf("解析作用域失败: %v", err)
+	}
+	if _, err := svc.memory.CreateMemory(ctx, &dto.MemoryCreateDTO{Code: "mem", Title: "记忆", Content: "内容"}, scopeCtx); err != nil {
+		t.Fatalf("创建记忆失败: %v", err)
+	}
+	if _, err := svc.plan.CreatePlan(ctx, &dto.PlanCreateDTO{Code: "plan", Title: "计划", Description: "描述", Content: "内容"}, scopeCtx); err != nil {
+		t.Fatalf("创建计划失败: %v", err)
+	}
+	if _, err := svc.todo.CreateToDo(ctx, &dto.ToDoCreateDTO{Code: "todo", PlanCode: "plan", Title: "待办"}, scopeCtx); err != nil {
+		t.Fatalf("创建待办失败: %v", err)
+	}
+
+	return []versionedKind{
+		{
+			name: "记忆", entityName: "记忆", code: "mem",
+			update: func(ctx context.Context, version *int64, title string) error {
+				return svc.memory.UpdateMemory(ctx, &dto.MemoryUpdateDTO{Code: "mem", Title: &title, Version: version})
+			},
+			get: func(ctx context.Context) (string, int64, error) {
+				m, err := svc.memory.GetMemory(ctx, "mem")
+				if err != nil {
+					return "", 0, err
+				}
+				return m.Title, m.Version, nil
+			},
+			touch: func(ctx context.Context) error {
+				m, err := svc.memory.GetMemory(ctx, "mem")
+				if err != nil {
+					return err
+				}
+				if err := svc.memory.ArchiveMemory(ctx, m.ID); err != nil {
+					return err
+				}
+				return svc.memory.UnarchiveMemory(ctx, m.ID)
+			},
+			staleWrite: func(ctx context.Context) (int64, error) {
+				repo := svc.memory.memoryModel
+				a, err := repo.FindByCode(ctx, "mem")
+				if err != nil {
+					return 0, err
+				}
+				b := *a
+				a.Title, b.Title = "A 的修改", "B 的修改"
+				if err := repo.Update(ctx, a); err != nil {
+					return 0, err
+				}
+				err = repo.Update(ctx, &b)
+				return b.Version, err
+			},
+		},
+		{
+			name: "计划", entityName: "计划", code: "plan",
+			update: func(ctx context.Context, version *int64, title string) error {
+				return svc.plan.UpdatePlan(ctx, &dto.PlanUpdateDTO{Code: "plan", Title: &title, Version: version})
+			},
+			get: func(ctx context.Context) (string, int64, error) {
+				p, err := svc.plan.GetPlan(ctx, "plan")
+				if err != nil {
+					return "", 0, err
+				}
+				return p.Title, p.Version, nil
+			},
+			touch: func(ctx context.Context) error {
+				return svc.plan.StartPlan(ctx, "plan")
+			},
+			staleWrite: func(ctx context.Context) (int64, error) {
+				repo := svc.plan.planModel
+				a, err := repo.FindByCode(ctx, "plan")
+				if err != nil {
+					return 0, err
+				}
+				b := *a
+				a.Title, b.Title = "A 的修改", "B 的修改"
+				if err := repo.Update(ctx, a); err != nil {
+					return 0, err
+				}
+				err = repo.Update(ctx, &b)
+				return b.Version, err
+			},
+		},
+		{
+			name: "待办", entityName: "待办", code: "todo",
+			update: func(ctx context.Context, version *int64, title string) error {
+				return svc.todo.UpdateToDo(ctx, &dto.ToDoUpdateDTO{Code: "todo", Title: &title, Version: version})
+			},
+			get: func(ctx context.Context) (string, int64, error) {
+				td, err := svc.todo.GetToDo(ctx, "todo")
+				if err != nil {
+					return "", 0, err
+				}
+				return td.Title, td.Version, nil
+			},
+			touch: func(ctx context.Context) error {
+				return svc.todo.StartToDo(ctx, "todo")
+			},
+			staleWrite: func(ctx context.Context) (int64, error) {
+				repo := svc.todo.todoModel
+				a, err := repo.FindByCode(ctx, "todo")
+				if err != nil {
+					return 0, err
+				}
+				b := *a
+				a.Title, b.Title = "A 的修改", "B 的修改"
+				if err := repo.Update(ctx, a); err != nil {
+					return 0, err
+				}
+				err = repo.Update(ctx, &b)
+				return b.Version, err
+			},
+		},
+	}
+}
+
+// TestVersionConflict 携带过期版本的更新被拒绝且不覆盖数据，字段级操作同样递增版本，
+// 读取之后被其他写入抢先时仓储层返回版本冲突
+func TestVersionConflict(t *testing.T) {
+	for _, backend := range testBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc := backend.setup(t)
+			for _, kind := range newVersionedKinds(t, svc) {
+				t.Run(kind.name, func(t *testing.T) {
+					current := func() (string, int64) {
+						t.Helper()
+						title, version, err := kind.get(ctx)
+						if err != nil {
+							t.Fatalf("读取失败: %v", err)
+						}
+						return title, version
+					}
+					assertConflict := func(step string, err error, expected, actual int64) {
+						t.Helper()
+						var conflict *models.VersionConflictError
+						if !errors.As(err, &conflict) || !errors.Is(err, models.ErrVersionConflict) {
+							t.Fatalf("%s应返回版本冲突: %v", step, err)
+						}
+						want := models.VersionConflictError{Entity: kind.entityName, Code: kind.code, Expected: expected, Actual: actual}
+						if *conflict != want {
+							t.Fatalf("%s冲突详情 = %+v，期望 %+v", step, *conflict, want)
+						}
+					}
+
+					_, v1 := current()
+					if err := kind.update(ctx, &v1, "第一次修改"); err != nil {
+						t.Fatalf("携带当前版本更新失败: %v", err)
+					}
+					title, v2 := current()
+					if title != "第一次修改" || v2 <= v1 {
+						t.Fatalf("更新后 = %q v%d，期望标题已修改且版本大于 %d", title, v2, v1)
+					}
+
+					assertConflict("携带过期版本更新", kind.update(ctx, &v1, "过期的修改"), v1, v2)
+					if title, v := current(); title != "第一次修改" || v != v2 {
+						t.Fatalf("冲突后不应写入: %q v%d", title, v)
+					}
+
+					if err := kind.touch(ctx); err != nil {
+						t.Fatalf("字段级操作失败: %v", err)
+					}
+					_, v3 := current()
+					if v3 <= v2 {
+						t.Fatalf("字段级操作后版本 = %d，应大于 %d", v3, v2)
+					}
+					assertConflict("字段级操作后携带旧版本更新", kind.update(ctx, &v2, "过期的修改"), v2, v3)
+
+					if err := kind.update(ctx, nil, "不校验版本"); err != nil {
+						t.Fatalf("不携带版本时应直接更新: %v", err)
+					}
+					_, v4 := current()
+
+					held, err := kind.staleWrite(ctx)
+					assertConflict("读取后被抢先写入", err, v4, v4+1)
+					if held != v4 {
+						t.Fatalf("冲突后调用方的版本 = %d，应保持读取时的 %d", held, v4)
+					}
+					if title, _ := current(); title != "A 的修改" {
+						t.Fatalf("不应覆盖先写入一方的修改: %q", title)
+					}
+				})
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
 	"github.com/XiaoLFeng/llm-memory/pkg/types"
 )
 
@@ -27,16 +28,14 @@ func buildVisibilityFilter(scope string, scopeCtx *types.ScopeContext) models.Vi
 		filter.IncludeGlobal = false
 		pathID := resolveDefaultPathID(scopeCtx)
 		if pathID > 0 {
+			filter.IncludeNonGlobal = true
 			filter.PathIDs = []int64{pathID}
-		} else {
-			filter.IncludeNonGlobal = false
 		}
 	case "group":
 		filter.IncludeGlobal = false
 		if scopeCtx != nil && len(scopeCtx.GroupPathIDs) > 0 {
+			filter.IncludeNonGlobal = true
 			filter.PathIDs = scopeCtx.GroupPathIDs
-		} else {
-			filter.IncludeNonGlobal = false
 		}
 	case "all", "":
 		// 默认：全局 + 当前路径相关（使用 scopeCtx，安全优先）
@@ -83,3 +82,13 @@ func buildPathOnlyFilter(scope string, scopeCtx *types.ScopeContext) models.Path
 
 	return filter
 }
+
+// IsMemoryVisible 判断记忆在作用域内是否可见（与默认 all 范围的列表查询一致）
+func IsMemoryVisible(memory *entity.Memory, scopeCtx *types.ScopeContext) bool {
+	return memory != nil && buildVisibilityFilter("all", scopeCtx).Matches(memory.Global, memory.PathID)
+}
+
+// IsPlanVisible 判断计划在作用域内是否可见（与默认 all 范围的列表查询一致）
+func IsPlanVisible(plan *entity.Plan, scopeCtx *types.ScopeContext) bool {
+	return plan != nil && buildPathOnlyFilter("all", scopeCtx).Matches(plan.PathID)
+}
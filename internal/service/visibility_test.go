@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
+	"github.com/XiaoLFeng/llm-memory/internal/models/memstore"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testServices 一组共享同一存储的服务
+type testServices struct {
+	memory     *MemoryService
+	plan       *PlanService
+	todo       *ToDoService
+	group      *GroupService
+	ensurePath func(ctx context.Context, path string) (*entity.PersonalPath, error)
+}
+
+// testPostgresDSNEnv 设置后额外在 PostgreSQL 上运行同一套用例（每个用例使用独立 schema）
+const testPostgresDSNEnv = "LLM_MEMORY_TEST_POSTGRES_DSN"
+
+// testBackends 仓储实现：GORM（临时 SQLite 文件 / PostgreSQL）和纯内存
+var testBackends = []struct {
+	name  string
+	setup func(t *testing.T) *testServices
+}{
+	{name: "gorm", setup: newGormServices},
+	{name: "postgres", setup: newPostgresServices},
+	{name: "memstore", setup: newMemstoreServices},
+}
+
+func newGormServices(t *testing.T) *testServices {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return newModelServices(t, db)
+}
+
+// newPostgresServices 在 PostgreSQL 的临时 schema 中运行，未设置 DSN 时跳过
+func newPostgresServices(t *testing.T) *testServices {
+	t.Helper()
+	dsn := os.Getenv(testPostgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("未设置 %s，跳过 PostgreSQL 用例", testPostgresDSNEnv)
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("连接 PostgreSQL 失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取连接失败: %v", err)
+	}
+	// 单连接保证 search_path 对后续所有语句生效
+	sqlDB.SetMaxOpenConns(1)
+
+	schema := fmt.Sprintf("llm_memory_test_%d", database.GenerateID())
+	if err := db.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		_ = sqlDB.Close()
+		t.Fatalf("创建 schema 失败: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Exec("DROP SCHEMA " + schema + " CASCADE").Error
+		_ = sqlDB.Close()
+	})
+	if err := db.Exec("SET search_path TO " + schema).Error; err != nil {
+		t.Fatalf("切换 schema 失败: %v", err)
+	}
+	return newModelServices(t, db)
+}
+
+// newModelServices 迁移数据库并组装基于 GORM 模型的服务
+func newModelServices(t *testing.T, db *gorm.DB) *testServices {
+	t.Helper()
+	if _, err := database.NewMigrator(db).Migrate(context.Background()); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+
+	planModel := models.NewPlanModel(db)
+	return &testServices{
+		memory:     NewMemoryService(models.NewMemoryModel(db), models.NewMemoryRevisionModel(db), models.NewMemoryEmbeddingModel(db), nil),
+		plan:       NewPlanService(planModel),
+		todo:       NewToDoService(models.NewToDoModel(db), planModel),
+		group:      NewGroupService(models.NewGroupModel(db), types.ScopeResolutionExact),
+		ensurePath: models.NewPersonalPathModel(db).EnsurePath,
+	}
+}
+
+func newMemstoreServices(t *testing.T) *testServices {
+	t.Helper()
+	store := memstore.New()
+	return &testServices{
+		memory:     NewMemoryService(store.Memories(), store.MemoryRevisions(), store.MemoryEmbeddings(), nil),
+		plan:       NewPlanService(store.Plans()),
+		todo:       NewToDoService(store.ToDos(), store.Plans()),
+		group:      NewGroupService(store.Groups(), types.ScopeResolutionExact),
+		ensurePath: store.EnsurePath,
+	}
+}
+
+// seedVisibilityData 准备测试数据，返回各工作目录的作用域上下文
+//
+//	solo      未加入组的项目
+//	team-api  组 team 的成员
+//	team-web  组 team 的成员
+//	other     未加入组的其他项目（任何作用域都不应看到它的私有数据）
+func seedVisibilityData(t *testing.T, svc *testServices) map[string]*types.ScopeContext {
+	t.Helper()
+	ctx := context.Background()
+	root := t.TempDir()
+	dir := func(name string) string { return filepath.Join(root, name) }
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("准备数据失败: %v", err)
+		}
+	}
+
+	for _, name := range []string{"solo", "team-api", "team-web", "other"} {
+		must(os.Mkdir(dir(name), 0755))
+		_, err := svc.ensurePath(ctx, dir(name))
+		must(err)
+	}
+	group, err := svc.group.CreateGroup(ctx, "team", "")
+	must(err)
+	must(svc.group.AddPath(ctx, group.ID, dir("team-api")))
+	must(svc.group.AddPath(ctx, group.ID, dir("team-web")))
+
+	resolve := func(path string) *types.ScopeContext {
+		t.Helper()
+		scopeCtx, err := svc.group.ResolveScope(ctx, path)
+		must(err)
+		return scopeCtx
+	}
+	solo, api, web, other := resolve(dir("solo")), resolve(dir("team-api")), resolve(dir("team-web")), resolve(dir("other"))
+	if api.GroupID != group.ID || len(api.GroupPathIDs) != 2 {
+		t.Fatalf("组作用域解析错误: %+v", api)
+	}
+
+	// 记忆
+	createMemory := func(code string, global bool, scopeCtx *types.ScopeContext) *entity.Memory {
+		t.Helper()
+		memory, err := svc.memory.CreateMemory(ctx, &dto.MemoryCreateDTO{
+			Code: code, Title: code, Content: "内容 " + code, Global: global,
+		}, scopeCtx)
+		must(err)
+		return memory
+	}
+	createMemory("mem-global", true, solo)
+	createMemory("mem-solo", false, solo)
+	createMemory("mem-api", false, api)
+	createMemory("mem-web", false, web)
+	createMemory("mem-other", false, other)
+	archived := createMemory("mem-solo-archived", false, solo)
+	must(svc.memory.ArchiveMemory(ctx, archived.ID))
+	createMemory("mem-solo-deleted", false, solo)
+	must(svc.memory.DeleteMemory(ctx, "mem-solo-deleted"))
+
+	// 计划和待办
+	createPlan := func(code string, scopeCtx *types.ScopeContext) {
+		t.Helper()
+		_, err := svc.plan.CreatePlan(ctx, &dto.PlanCreateDTO{
+			Code: code, Title: code, Description: "描述", Content: "内容",
+		}, scopeCtx)
+		must(err)
+	}
+	createToDo := func(code, planCode string, scopeCtx *types.ScopeContext) {
+		t.Helper()
+		_, err := svc.todo.CreateToDo(ctx, &dto.ToDoCreateDTO{Code: code, PlanCode: planCode, Title: code}, scopeCtx)
+		must(err)
+	}
+	createPlan("plan-solo", solo)
+	createPlan("plan-api", api)
+	createPlan("plan-web", web)
+	createPlan("plan-other", other)
+	createPlan("plan-solo-done", solo)
+	must(svc.plan.CompletePlan(ctx, "plan-solo-done"))
+
+	createToDo("todo-solo", "plan-solo", solo)
+	createToDo("todo-solo-done", "plan-solo", solo)
+	must(svc.todo.CompleteToDo(ctx, "todo-solo-done"))
+	createToDo("todo-api", "plan-api", api)
+	createToDo("todo-web", "plan-web", web)
+	createToDo("todo-other", "plan-other", other)
+	createToDo("todo-solo-deleted", "plan-solo", solo)
+	must(svc.todo.DeleteToDo(ctx, "todo-solo-deleted"))
+
+	return map[string]*types.ScopeContext{
+		"solo":        solo,
+		"team":        api,
+		"unbound":     resolve(dir("unknown")),
+		"global-only": types.NewGlobalOnlyScope(),
+		"nil":         nil,
+	}
+}
+
+// scopeCases 作用域规则用例
+// memories 对应 buildVisibilityFilter，plans/todos 对应 buildPathOnlyFilter
+var scopeCases = []struct {
+	scope    string
+	scopeCtx string
+	memories []string
+	plans    []string
+	todos    []string
+}{
+	// global：只有全局记忆，Plan/Todo 没有全局作用域
+	{scope: "global", scopeCtx: "solo", memories: []string{"mem-global"}},
+	{scope: "global", scopeCtx: "team", memories: []string{"mem-global"}},
+	{scope: "global", scopeCtx: "nil", memories: []string{"mem-global"}},
+
+	// personal：只有当前路径
+	{scope: "personal", scopeCtx: "solo", memories: []string{"mem-solo"}, plans: []string{"plan-solo"}, todos: []string{"todo-solo", "todo-solo-done"}},
+	{scope: "personal", scopeCtx: "team", memories: []string{"mem-api"}, plans: []string{"plan-api"}, todos: []string{"todo-api"}},
+	{scope: "personal", scopeCtx: "unbound"},
+	{scope: "personal", scopeCtx: "global-only"},
+	{scope: "personal", scopeCtx: "nil"},
+
+	// group：组内所有路径，不含全局
+	{scope: "group", scopeCtx: "solo"},
+	{scope: "group", scopeCtx: "team", memories: []string{"mem-api", "mem-web"}, plans: []string{"plan-api", "plan-web"}, todos: []string{"todo-api", "todo-web"}},
+	{scope: "group", scopeCtx: "nil"},
+
+	// all / 空字符串：全局 + 当前路径 + 组内路径
+	{scope: "all", scopeCtx: "solo", memories: []string{"mem-global", "mem-solo"}, plans: []string{"plan-solo"}, todos: []string{"todo-solo", "todo-solo-done"}},
+	{scope: "all", scopeCtx: "team", memories: []string{"mem-global", "mem-api", "mem-web"}, plans: []string{"plan-api", "plan-web"}, todos: []string{"todo-api", "todo-web"}},
+	{scope: "", scopeCtx: "team", memories: []string{"mem-global", "mem-api", "mem-web"}, plans: []string{"plan-api", "plan-web"}, todos: []string{"todo-api", "todo-web"}},
+	{scope: "ALL", scopeCtx: "solo", memories: []string{"mem-global", "mem-solo"}, plans: []string{"plan-solo"}, todos: []string{"todo-solo", "todo-solo-done"}},
+	{scope: "all", scopeCtx: "unbound", memories: []string{"mem-global"}},
+	{scope: "all", scopeCtx: "global-only", memories: []string{"mem-global"}},
+	{scope: "", scopeCtx: "nil", memories: []string{"mem-global"}},
+
+	// 未知 scope：只有全局记忆（安全优先）
+	{scope: "everything", scopeCtx: "team", memories: []string{"mem-global"}},
+}
+
+func TestScopeVisibility(t *testing.T) {
+	ctx := context.Background()
+	for _, backend := range testBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			svc := backend.setup(t)
+			scopes := seedVisibilityData(t, svc)
+
+			for _, tc := range scopeCases {
+				scopeCtx := scopes[tc.scopeCtx]
+				t.Run(tc.scope+"@"+tc.scopeCtx, func(t *testing.T) {
+					memories, err := svc.memory.ListMemoriesByScope(ctx, tc.scope, scopeCtx)
+					if err != nil {
+						t.Fatalf("列出记忆失败: %v", err)
+					}
+					assertCodes(t, "记忆", memoryCodes(memories), tc.memories)
+
+					plans, err := svc.plan.ListPlansByScope(ctx, tc.scope, scopeCtx)
+					if err != nil {
+						t.Fatalf("列出计划失败: %v", err)
+					}
+					assertCodes(t, "计划", planCodes(plans), tc.plans)
+
+					todos, err := svc.todo.ListToDosByScope(ctx, tc.scope, scopeCtx)
+					if err != nil {
+						t.Fatalf("列出待办失败: %v", err)
+					}
+					assertCodes(t, "待办", todoCodes(todos), tc.todos)
+				})
+			}
+		})
+	}
+}
+
+func TestVisibilityFilterMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter models.VisibilityFilter
+		global bool
+		pathID int64
+		want   bool
+	}{
+		{name: "全局数据", filter: models.DefaultVisibilityFilter(), global: true, want: true},
+		{name: "默认过滤器不含私有数据", filter: models.DefaultVisibilityFilter(), pathID: 1},
+		{name: "路径命中", filter: models.VisibilityFilter{IncludeNonGlobal: true, PathIDs: []int64{1, 2}}, pathID: 2, want: true},
+		{name: "路径未命中", filter: models.VisibilityFilter{IncludeNonGlobal: true, PathIDs: []int64{1}}, pathID: 3},
+		{name: "空路径列表不含私有数据", filter: models.VisibilityFilter{IncludeNonGlobal: true}, pathID: 1},
+		{name: "排除全局", filter: models.VisibilityFilter{IncludeNonGlobal: true, PathIDs: []int64{1}}, global: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(tt.global, tt.pathID); got != tt.want {
+				t.Errorf("Matches(%v, %d) = %v, want %v", tt.global, tt.pathID, got, tt.want)
+			}
+		})
+	}
+
+	if models.DefaultPathOnlyFilter().Matches(0) {
+		t.Error("空路径过滤器不应匹配任何数据")
+	}
+	if !(models.PathOnlyVisibilityFilter{PathIDs: []int64{5}}).Matches(5) {
+		t.Error("路径过滤器应匹配列表中的路径")
+	}
+}
+
+func assertCodes(t *testing.T, kind string, got, want []string) {
+	t.Helper()
+	sort.Strings(got)
+	want = append([]string(nil), want...)
+	sort.Strings(want)
+	if len(got) != len(want) {
+		t.Fatalf("%s不符: got %v, want %v", kind, got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("%s不符: got %v, want %v", kind, got, want)
+		}
+	}
+}
+
+func memoryCodes(memories []entity.Memory) []string {
+	codes := make([]string, len(memories))
+	for i, m := range memories {
+		codes[i] = m.Code
+	}
+	return codes
+}
+
+func planCodes(plans []entity.Plan) []string {
+	codes := make([]string, len(plans))
+	for i, p := range plans {
+		codes[i] = p.Code
+	}
+	return codes
+}
+
+func todoCodes(todos []entity.ToDo) []string {
+	codes := make([]string, len(todos))
+	for i, td := range todos {
+		codes[i] = td.Code
+	}
+	return codes
+}
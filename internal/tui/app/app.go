@@ -10,6 +10,7 @@ import (
 	"github.com/XiaoLFeng/llm-memory/internal/tui/pages/menu"
 	"github.com/XiaoLFeng/llm-memory/internal/tui/pages/plan"
 	"github.com/XiaoLFeng/llm-memory/internal/tui/pages/todo"
+	"github.com/XiaoLFeng/llm-memory/internal/tui/pages/trash"
 	"github.com/XiaoLFeng/llm-memory/startup"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -130,6 +131,8 @@ func (m *AppModel) makePageWithData(id core.PageID, data interface{}) core.Page
 		return group.NewCreatePage(m.bs, m.navigate)
 	case core.PageGroupEdit:
 		return group.NewEditPage(m.bs, m.navigate, data)
+	case core.PageTrash:
+		return trash.NewListPage(m.bs)
 	case core.PageHelp:
 		return help.NewPage(m.navigate)
 	default:
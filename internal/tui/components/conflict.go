@@ -0,0 +1,141 @@
+package components
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models"
+	"github.com/XiaoLFeng/llm-memory/internal/tui/theme"
+	"github.com/XiaoLFeng/llm-memory/internal/tui/utils"
+	pkgutils "github.com/XiaoLFeng/llm-memory/pkg/utils"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// 嘿嘿~ 这是编辑冲突提示！⚠️
+// 编辑页保存时发现记录在打开之后已被别人修改（版本冲突），弹出此提示让用户选择：
+// 重新加载最新内容、用自己的修改覆盖，或者先看看两边差在哪里~
+
+// ConflictAction 冲突提示中的选择
+type ConflictAction int
+
+const (
+	ConflictNone      ConflictAction = iota // 未做选择
+	ConflictReload                          // 重新加载最新内容（放弃自己的修改）
+	ConflictOverwrite                       // 以自己的修改覆盖最新内容
+	ConflictCancel                          // 关闭提示，返回继续编辑
+)
+
+// ConflictField 发生冲突的字段
+type ConflictField struct {
+	Label  string // 字段名
+	Theirs string // 数据库中的最新值
+	Mine   string // 表单中自己修改后的值
+}
+
+// ConflictPrompt 版本冲突提示
+type ConflictPrompt struct {
+	active   bool
+	message  string
+	fields   []ConflictField
+	showDiff bool
+}
+
+// NewConflictPrompt 创建版本冲突提示
+func NewConflictPrompt() *ConflictPrompt {
+	return &ConflictPrompt{}
+}
+
+// Open 打开提示
+// err 为保存时返回的版本冲突错误，fields 为最新值与表单值的对照
+func (c *ConflictPrompt) Open(err error, fields []ConflictField) {
+	message := err.Error()
+	var conflict *models.VersionConflictError
+	if errors.As(err, &conflict) {
+		message = fmt.Sprintf("这条%s在你打开之后已被修改（版本 %d → %d）。\n重新加载会丢弃你的修改；覆盖会用你的修改替换对方的修改。",
+			conflict.Entity, conflict.Expected, conflict.Actual)
+	}
+	c.active = true
+	c.message = message
+	c.fields = fields
+	c.showDiff = false
+}
+
+// Close 关闭提示
+func (c *ConflictPrompt) Close() {
+	c.active = false
+	c.fields = nil
+}
+
+// Active 是否正在显示
+func (c *ConflictPrompt) Active() bool {
+	return c.active
+}
+
+// HandleKey 处理按键，返回用户的选择（切换差异视图时返回 ConflictNone）
+func (c *ConflictPrompt) HandleKey(key string) ConflictAction {
+	switch key {
+	case "r", "R":
+		return ConflictReload
+	case "o", "O":
+		return ConflictOverwrite
+	case "d", "D":
+		c.showDiff = !c.showDiff
+	case "esc":
+		return ConflictCancel
+	}
+	return ConflictNone
+}
+
+// View 渲染提示
+func (c *ConflictPrompt) View(width int) string {
+	if width < 40 {
+		width = 40
+	}
+	inner := width - 6
+
+	parts := []string{
+		theme.ConfirmTitle.Render(theme.IconWarning + " 记录已被修改"),
+		theme.ConfirmMessage.Width(inner).Render(c.message),
+	}
+
+	if c.showDiff {
+		parts = append(parts, c.renderDiff(inner)...)
+	}
+
+	diffHint := "[D] 查看差异"
+	if c.showDiff {
+		diffHint = "[D] 收起差异"
+	}
+	parts = append(parts, theme.ConfirmHint.Render("[R] 重新加载  [O] 覆盖  "+diffHint+"  [Esc] 返回编辑"))
+
+	return theme.ConfirmBox.Width(width).Render(lipgloss.JoinVertical(lipgloss.Left, parts...))
+}
+
+// renderDiff 渲染最新版本与自己修改之间的差异（只显示有变化的字段）
+func (c *ConflictPrompt) renderDiff(width int) []string {
+	lines := []string{theme.TextDim.Render("- 最新版本  + 你的修改")}
+	changed := false
+	for _, f := range c.fields {
+		if f.Theirs == f.Mine {
+			continue
+		}
+		changed = true
+		lines = append(lines, "", theme.FormLabel.Bold(true).Render("["+f.Label+"]"))
+		for _, l := range pkgutils.DiffLines(f.Theirs, f.Mine) {
+			style := theme.TextDim
+			switch l.Op {
+			case pkgutils.DiffInsert:
+				style = lipgloss.NewStyle().Foreground(theme.Success)
+			case pkgutils.DiffDelete:
+				style = lipgloss.NewStyle().Foreground(theme.Error)
+			}
+			for _, wrapped := range utils.WrapText(l.String(), width) {
+				lines = append(lines, style.Render(wrapped))
+			}
+		}
+	}
+	if !changed {
+		lines = append(lines, "", theme.TextDim.Render("你的修改与最新版本内容一致"))
+	}
+	return append(lines, "")
+}
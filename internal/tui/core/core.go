@@ -13,6 +13,7 @@ const (
 	PageMemory PageID = "memory"
 	PagePlan   PageID = "plan"
 	PageGroup  PageID = "group"
+	PageTrash  PageID = "trash"
 
 	// CRUD 页面
 	PageMemoryCreate PageID = "memory_create"
@@ -1,10 +1,14 @@
 package memory
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/XiaoLFeng/llm-memory/internal/models"
 	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
 	"github.com/XiaoLFeng/llm-memory/internal/tui/components"
 	"github.com/XiaoLFeng/llm-memory/internal/tui/core"
 	"github.com/XiaoLFeng/llm-memory/internal/tui/layout"
@@ -22,10 +26,16 @@ type (
 		tags     []string
 		priority int
 		global   bool
+		version  int64
 		err      error
 	}
 	updateSuccessMsg struct{}
 	updateErrorMsg   struct{ err error }
+	// updateConflictMsg 保存时版本冲突，携带数据库中的最新内容
+	updateConflictMsg struct {
+		latest loadMemoryMsg
+		err    error
+	}
 )
 
 type EditPage struct {
@@ -40,6 +50,11 @@ type EditPage struct {
 	saving   bool
 	err      error
 
+	// 乐观锁：打开页面时读取的版本，冲突时保存最新内容供重新加载/覆盖
+	version  int64
+	latest   *loadMemoryMsg
+	conflict *components.ConflictPrompt
+
 	// 表单字段
 	inputTitle     *components.Input
 	textContent    *components.TextArea
@@ -58,6 +73,7 @@ func NewEditPage(bs *startup.Bootstrap, memoryID int64, pop func(core.PageID) te
 		pop:      pop,
 		memoryID: memoryID,
 		loading:  true,
+		conflict: components.NewConflictPrompt(),
 
 		inputTitle:    components.NewInput("标题", "请输入记忆标题", true),
 		textContent:   components.NewTextArea("内容", "请输入记忆内容", true),
@@ -91,6 +107,9 @@ func (p *EditPage) Update(msg tea.Msg) (core.Page, tea.Cmd) {
 		if p.loading || p.saving {
 			return p, nil
 		}
+		if p.conflict.Active() {
+			return p, p.handleConflict(v.String())
+		}
 
 		switch v.String() {
 		case "ctrl+s":
@@ -108,19 +127,7 @@ func (p *EditPage) Update(msg tea.Msg) (core.Page, tea.Cmd) {
 		if v.err != nil {
 			p.err = v.err
 		} else {
-			// 填充表单
-			p.inputTitle.SetValue(v.title)
-			p.textContent.SetValue(v.content)
-			p.inputCategory.SetValue(v.category)
-			if len(v.tags) > 0 {
-				p.inputTags.SetValue(strings.Join(v.tags, ", "))
-			}
-			p.selectPriority.SetSelectedIndex(v.priority - 1)
-			if v.global {
-				p.selectGlobal.SetSelectedIndex(1)
-			} else {
-				p.selectGlobal.SetSelectedIndex(0)
-			}
+			p.fill(v)
 			return p, p.inputTitle.Focus()
 		}
 
@@ -132,6 +139,12 @@ func (p *EditPage) Update(msg tea.Msg) (core.Page, tea.Cmd) {
 	case updateErrorMsg:
 		p.saving = false
 		p.err = v.err
+
+	case updateConflictMsg:
+		p.saving = false
+		p.latest = &v.latest
+		p.conflict.Open(v.err, p.conflictFields(v.latest))
+		return p, nil
 	}
 
 	// 更新当前聚焦的字段
@@ -155,6 +168,11 @@ func (p *EditPage) View() string {
 		return components.ErrorState(theme.IconMemory+" 编辑记忆", p.err.Error(), cardWidth)
 	}
 
+	// 版本冲突
+	if p.conflict.Active() {
+		return p.conflict.View(cardWidth)
+	}
+
 	// 设置所有组件宽度
 	formWidth := cardWidth - 8
 	p.inputTitle.SetWidth(formWidth)
@@ -272,15 +290,64 @@ func (p *EditPage) loadMemory() tea.Cmd {
 		if err != nil {
 			return loadMemoryMsg{err: err}
 		}
+		return newLoadMemoryMsg(memory)
+	}
+}
 
-		return loadMemoryMsg{
-			title:    memory.Title,
-			content:  memory.Content,
-			category: memory.Category,
-			tags:     memory.GetTagStrings(),
-			priority: memory.Priority,
-			global:   memory.Global,
-		}
+// newLoadMemoryMsg 将记忆转换为表单数据
+func newLoadMemoryMsg(memory *entity.Memory) loadMemoryMsg {
+	return loadMemoryMsg{
+		title:    memory.Title,
+		content:  memory.Content,
+		category: memory.Category,
+		tags:     memory.GetTagStrings(),
+		priority: memory.Priority,
+		global:   memory.Global,
+		version:  memory.Version,
+	}
+}
+
+// fill 用加载的数据填充表单，并记下读取时的版本
+func (p *EditPage) fill(v loadMemoryMsg) {
+	p.version = v.version
+	p.inputTitle.SetValue(v.title)
+	p.textContent.SetValue(v.content)
+	p.inputCategory.SetValue(v.category)
+	p.inputTags.SetValue(strings.Join(v.tags, ", "))
+	p.selectPriority.SetSelectedIndex(v.priority - 1)
+	if v.global {
+		p.selectGlobal.SetSelectedIndex(1)
+	} else {
+		p.selectGlobal.SetSelectedIndex(0)
+	}
+}
+
+// handleConflict 处理版本冲突提示的选择
+func (p *EditPage) handleConflict(key string) tea.Cmd {
+	switch p.conflict.HandleKey(key) {
+	case components.ConflictReload:
+		// 放弃自己的修改，载入最新内容
+		p.conflict.Close()
+		p.fill(*p.latest)
+	case components.ConflictOverwrite:
+		// 以最新版本为基准重新保存自己的修改
+		p.conflict.Close()
+		p.version = p.latest.version
+		return p.save()
+	case components.ConflictCancel:
+		p.conflict.Close()
+	}
+	return nil
+}
+
+// conflictFields 最新内容与表单内容的对照
+func (p *EditPage) conflictFields(latest loadMemoryMsg) []components.ConflictField {
+	return []components.ConflictField{
+		{Label: "标题", Theirs: latest.title, Mine: p.inputTitle.Value()},
+		{Label: "内容", Theirs: latest.content, Mine: p.textContent.Value()},
+		{Label: "分类", Theirs: latest.category, Mine: p.inputCategory.Value()},
+		{Label: "标签", Theirs: strings.Join(latest.tags, ", "), Mine: strings.Join(parseTags(p.inputTags.Value()), ", ")},
+		{Label: "优先级", Theirs: strconv.Itoa(latest.priority), Mine: strconv.Itoa(p.selectPriority.Value().(int))},
 	}
 }
 
@@ -316,6 +383,7 @@ func (p *EditPage) save() tea.Cmd {
 	// 准备更新数据
 	title := p.inputTitle.Value()
 	content := p.textContent.Value()
+	version := p.version
 
 	p.saving = true
 	return func() tea.Msg {
@@ -334,9 +402,16 @@ func (p *EditPage) save() tea.Cmd {
 			Category: &category,
 			Tags:     &tags,
 			Priority: &priority,
+			Version:  &version,
 		}
 
 		if err := p.bs.MemoryService.UpdateMemory(ctx, input); err != nil {
+			// 打开之后被别人改过：取回最新内容，交给用户决定
+			if errors.Is(err, models.ErrVersionConflict) {
+				if latest, findErr := p.bs.MemoryService.GetMemoryByID(ctx, p.memoryID); findErr == nil {
+					return updateConflictMsg{latest: newLoadMemoryMsg(latest), err: err}
+				}
+			}
 			return updateErrorMsg{err: err}
 		}
 
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
 	"github.com/XiaoLFeng/llm-memory/internal/tui/components"
 	"github.com/XiaoLFeng/llm-memory/internal/tui/core"
 	"github.com/XiaoLFeng/llm-memory/internal/tui/layout"
@@ -28,6 +29,7 @@ type (
 // typesMemory 只包含 TUI 展示需要的字段，避免直接耦合 entity
 type typesMemory struct {
 	ID        int64
+	Code      string
 	Title     string
 	Content   string // 记忆内容
 	Category  string
@@ -56,6 +58,14 @@ type ListPage struct {
 	deleteTarget     int64 // 要删除的 ID
 	deleteProcessing bool  // 是否正在处理删除
 	deleteYesActive  bool  // true=选中确认，false=选中取消
+
+	// 修订历史面板
+	showRevisions    bool
+	revisionsLoading bool
+	revisions        []typesRevision
+	revisionCursor   int
+	revisionDiffs    []dto.MemoryFieldDiffDTO
+	revisionErr      error
 }
 
 func NewListPage(bs *startup.Bootstrap, push func(core.PageID) tea.Cmd, pushWithData func(core.PageID, interface{}) tea.Cmd) *ListPage {
@@ -91,6 +101,7 @@ func (p *ListPage) load() tea.Cmd {
 		for _, m := range memories {
 			items = append(items, typesMemory{
 				ID:        m.ID,
+				Code:      m.Code,
 				Title:     m.Title,
 				Content:   m.Content,
 				Category:  m.Category,
@@ -141,12 +152,19 @@ func (p *ListPage) Update(msg tea.Msg) (core.Page, tea.Cmd) {
 			return p, nil
 		}
 
+		// 修订面板模式
+		if p.showing && p.showRevisions {
+			return p, p.updateRevisions(v.String())
+		}
+
 		// 详情页模式：处理滚动
 		if p.showing {
 			switch v.String() {
 			case "esc", "q":
 				p.showing = false
 				return p, nil
+			case "h":
+				return p, p.openRevisions()
 			case "up", "k":
 				p.detailViewport.LineUp(1)
 				return p, nil
@@ -193,6 +211,7 @@ func (p *ListPage) Update(msg tea.Msg) (core.Page, tea.Cmd) {
 				p.showing = !p.showing
 				// 进入详情页时重置滚动位置
 				if p.showing {
+					p.showRevisions = false
 					p.detailViewport.GotoTop()
 				}
 			}
@@ -226,6 +245,8 @@ func (p *ListPage) Update(msg tea.Msg) (core.Page, tea.Cmd) {
 				p.cursor = 0
 			}
 		}
+	case revisionsLoadMsg, revisionDiffMsg:
+		return p, p.handleRevisionMsg(v)
 	case deleteSuccessMsg:
 		p.deleteProcessing = false
 		p.deleteTarget = 0
@@ -260,7 +281,7 @@ func (p *ListPage) View() string {
 			itemName = p.items[p.cursor].Title
 		}
 		return components.ConfirmDialogWithButtons("确认删除",
-			fmt.Sprintf("确定要删除「%s」吗？\n此操作不可撤销。", itemName),
+			fmt.Sprintf("确定要删除「%s」吗？\n可在回收站中恢复。", itemName),
 			cardWidth, p.deleteYesActive)
 	}
 
@@ -296,7 +317,11 @@ func (p *ListPage) View() string {
 			scrollPercent := p.detailViewport.ScrollPercent() * 100
 			scrollInfo := fmt.Sprintf("%.0f%%", scrollPercent)
 			scrollHint := theme.TextDim.Render(fmt.Sprintf(
-				"滚动: %s | ↑/↓ j/k PgUp/PgDn Home/End | Esc 返回", scrollInfo))
+				"滚动: %s | ↑/↓ j/k PgUp/PgDn Home/End | h 修订历史 | Esc 返回", scrollInfo))
+			if p.showRevisions {
+				scrollHint = theme.TextDim.Render(fmt.Sprintf(
+					"滚动: %s | ↑/↓ j/k 选择修订 | PgUp/PgDn 滚动 | h/Esc 关闭修订历史", scrollInfo))
+			}
 
 			// 组合视图
 			title := theme.Title.Render(theme.IconMemory + " 记忆详情")
@@ -383,6 +408,13 @@ func (p *ListPage) renderDetail(width int) string {
 		Render(strings.Repeat("─", width))
 	lines = append(lines, separatorLine)
 
+	// === 区块 3：修订历史面板（替代内容区块） ===
+	if p.showRevisions {
+		lines = append(lines, "")
+		lines = append(lines, p.renderRevisions(width)...)
+		return strings.Join(lines, "\n")
+	}
+
 	// === 区块 3：内容 ===
 	if m.Content != "" {
 		lines = append(lines, "")
@@ -394,6 +426,19 @@ func (p *ListPage) renderDetail(width int) string {
 }
 
 func (p *ListPage) Meta() core.Meta {
+	// 修订面板模式
+	if p.showing && p.showRevisions {
+		return core.Meta{
+			Title:      "修订历史",
+			Breadcrumb: "记忆管理 > 详情 > 修订历史",
+			Keys: []components.KeyHint{
+				{Key: "↑/↓ j/k", Desc: "选择修订"},
+				{Key: "PgUp/PgDn", Desc: "滚动"},
+				{Key: "h/Esc", Desc: "关闭"},
+			},
+		}
+	}
+
 	// 详情页模式
 	if p.showing {
 		return core.Meta{
@@ -403,6 +448,7 @@ func (p *ListPage) Meta() core.Meta {
 				{Key: "↑/↓ j/k", Desc: "滚动"},
 				{Key: "PgUp/PgDn", Desc: "翻页"},
 				{Key: "Home/End", Desc: "首/尾"},
+				{Key: "h", Desc: "修订历史"},
 				{Key: "Esc", Desc: "返回列表"},
 			},
 		}
@@ -0,0 +1,179 @@
+package memory
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/tui/theme"
+	"github.com/XiaoLFeng/llm-memory/internal/tui/utils"
+	pkgutils "github.com/XiaoLFeng/llm-memory/pkg/utils"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// 嘿嘿~ 这是记忆详情里的修订历史面板！(´∀｀)💖
+// 详情页按 h 打开，↑/↓ 选择修订，下方实时显示该修订与当前版本的差异~
+
+type (
+	revisionsLoadMsg struct {
+		code  string
+		items []typesRevision
+		err   error
+	}
+	revisionDiffMsg struct {
+		code     string
+		revision int
+		diffs    []dto.MemoryFieldDiffDTO
+		err      error
+	}
+)
+
+// typesRevision 只包含 TUI 展示需要的修订字段
+type typesRevision struct {
+	Revision  int
+	Action    string
+	Source    string
+	Title     string
+	CreatedAt time.Time
+}
+
+// openRevisions 打开修订面板并加载修订列表
+func (p *ListPage) openRevisions() tea.Cmd {
+	if len(p.items) == 0 {
+		return nil
+	}
+	p.showRevisions = true
+	p.revisionsLoading = true
+	p.revisions = nil
+	p.revisionCursor = 0
+	p.revisionDiffs = nil
+	p.revisionErr = nil
+
+	code := p.items[p.cursor].Code
+	return func() tea.Msg {
+		_, revisions, err := p.bs.MemoryService.ListMemoryRevisions(p.bs.Context(), code)
+		if err != nil {
+			return revisionsLoadMsg{code: code, err: err}
+		}
+		items := make([]typesRevision, 0, len(revisions))
+		for _, r := range revisions {
+			items = append(items, typesRevision{
+				Revision:  r.Revision,
+				Action:    r.Action,
+				Source:    r.Source,
+				Title:     r.Title,
+				CreatedAt: r.CreatedAt,
+			})
+		}
+		return revisionsLoadMsg{code: code, items: items}
+	}
+}
+
+// loadRevisionDiff 加载选中修订与当前版本的差异
+func (p *ListPage) loadRevisionDiff() tea.Cmd {
+	if len(p.items) == 0 || p.revisionCursor >= len(p.revisions) {
+		return nil
+	}
+	code := p.items[p.cursor].Code
+	revision := p.revisions[p.revisionCursor].Revision
+	return func() tea.Msg {
+		diffs, err := p.bs.MemoryService.DiffMemoryRevisions(p.bs.Context(), code, revision, 0)
+		return revisionDiffMsg{code: code, revision: revision, diffs: diffs, err: err}
+	}
+}
+
+// updateRevisions 处理修订面板的按键
+func (p *ListPage) updateRevisions(key string) tea.Cmd {
+	switch key {
+	case "esc", "q", "h":
+		p.showRevisions = false
+		p.detailViewport.GotoTop()
+	case "up", "k":
+		if p.revisionCursor > 0 {
+			p.revisionCursor--
+			return p.loadRevisionDiff()
+		}
+	case "down", "j":
+		if p.revisionCursor < len(p.revisions)-1 {
+			p.revisionCursor++
+			return p.loadRevisionDiff()
+		}
+	case "pgup":
+		p.detailViewport.HalfViewUp()
+	case "pgdown":
+		p.detailViewport.HalfViewDown()
+	}
+	return nil
+}
+
+// handleRevisionMsg 处理修订相关的异步消息
+func (p *ListPage) handleRevisionMsg(msg tea.Msg) tea.Cmd {
+	switch v := msg.(type) {
+	case revisionsLoadMsg:
+		p.revisionsLoading = false
+		p.revisionErr = v.err
+		if v.err == nil {
+			p.revisions = v.items
+			return p.loadRevisionDiff()
+		}
+	case revisionDiffMsg:
+		if p.revisionCursor < len(p.revisions) && p.revisions[p.revisionCursor].Revision == v.revision {
+			p.revisionErr = v.err
+			p.revisionDiffs = v.diffs
+		}
+	}
+	return nil
+}
+
+// renderRevisions 渲染修订面板
+func (p *ListPage) renderRevisions(width int) []string {
+	lines := []string{theme.Subtitle.Render("🕘 修订历史"), ""}
+
+	switch {
+	case p.revisionsLoading:
+		return append(lines, theme.TextDim.Render("努力加载中..."))
+	case p.revisionErr != nil:
+		return append(lines, lipgloss.NewStyle().Foreground(theme.Error).Render(p.revisionErr.Error()))
+	case len(p.revisions) == 0:
+		return append(lines, theme.TextDim.Render("这条记忆还没有被修改过~"))
+	}
+
+	for i, r := range p.revisions {
+		line := fmt.Sprintf("r%d · %s · %s/%s · %s",
+			r.Revision, r.CreatedAt.Format("01-02 15:04"), r.Action, r.Source, r.Title)
+		if utils.LipWidth(line) > width-2 {
+			line = utils.Truncate(line, width-2)
+		}
+		if i == p.revisionCursor {
+			line = lipgloss.NewStyle().Foreground(theme.Info).Render("▶ " + line)
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+
+	// 选中修订与当前版本的差异
+	lines = append(lines, "", lipgloss.NewStyle().Foreground(theme.Border).Render(strings.Repeat("─", width)), "")
+	if len(p.revisionDiffs) == 0 {
+		return append(lines, theme.TextDim.Render("与当前版本内容一致"))
+	}
+	lines = append(lines, theme.TextDim.Render(fmt.Sprintf("r%d → 当前版本", p.revisions[p.revisionCursor].Revision)))
+	for _, d := range p.revisionDiffs {
+		lines = append(lines, "", theme.FormLabel.Bold(true).Render("["+d.Field+"]"))
+		for _, l := range d.Lines {
+			style := theme.TextDim
+			switch l.Op {
+			case pkgutils.DiffInsert:
+				style = lipgloss.NewStyle().Foreground(theme.Success)
+			case pkgutils.DiffDelete:
+				style = lipgloss.NewStyle().Foreground(theme.Error)
+			}
+			for _, wrapped := range utils.WrapText(l.String(), width) {
+				lines = append(lines, style.Render(wrapped))
+			}
+		}
+	}
+	return lines
+}
@@ -36,6 +36,7 @@ func NewPage(push func(core.PageID) tea.Cmd) *Page {
 			{title: "记忆管理", desc: "管理和搜索你的记忆", id: core.PageMemory, icon: theme.IconMemory},
 			{title: "计划管理", desc: "规划与跟踪计划及待办", id: core.PagePlan, icon: theme.IconPlan},
 			{title: "组管理", desc: "路径组与共享", id: core.PageGroup, icon: theme.IconGroup},
+			{title: "回收站", desc: "恢复或彻底删除已删除的内容", id: core.PageTrash, icon: theme.IconTrash},
 		},
 	}
 }
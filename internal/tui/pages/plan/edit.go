@@ -1,10 +1,13 @@
 package plan
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/XiaoLFeng/llm-memory/internal/models"
 	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
 	"github.com/XiaoLFeng/llm-memory/internal/tui/components"
 	"github.com/XiaoLFeng/llm-memory/internal/tui/core"
 	"github.com/XiaoLFeng/llm-memory/internal/tui/layout"
@@ -33,6 +36,11 @@ type EditPage struct {
 	loading    bool
 	submitting bool
 	err        error
+
+	// 乐观锁：打开页面时读取的版本，冲突时保存最新内容供重新加载/覆盖
+	version  int64
+	latest   *editLoadMsg
+	conflict *components.ConflictPrompt
 }
 
 type editLoadMsg struct {
@@ -40,6 +48,7 @@ type editLoadMsg struct {
 	description string
 	content     string
 	progress    int
+	version     int64
 	err         error
 }
 
@@ -48,6 +57,12 @@ type editResultMsg struct {
 	err     error
 }
 
+// editConflictMsg 保存时版本冲突，携带数据库中的最新内容
+type editConflictMsg struct {
+	latest editLoadMsg
+	err    error
+}
+
 // NewEditPage 创建计划编辑页面
 func NewEditPage(bs *startup.Bootstrap, planID int64, pop func(core.PageID) tea.Cmd) *EditPage {
 	// 初始化表单组件
@@ -72,6 +87,7 @@ func NewEditPage(bs *startup.Bootstrap, planID int64, pop func(core.PageID) tea.
 		contentArea:     contentArea,
 		progressInput:   progressInput,
 		loading:         true,
+		conflict:        components.NewConflictPrompt(),
 	}
 }
 
@@ -108,6 +124,9 @@ func (p *EditPage) Update(msg tea.Msg) (core.Page, tea.Cmd) {
 			}
 			return p, nil
 		}
+		if p.conflict.Active() {
+			return p, p.handleConflict(v.String())
+		}
 
 		switch v.String() {
 		case "esc":
@@ -127,16 +146,18 @@ func (p *EditPage) Update(msg tea.Msg) (core.Page, tea.Cmd) {
 		if v.err != nil {
 			p.err = v.err
 		} else {
-			// 填充表单
-			p.titleInput.SetValue(v.title)
-			p.descriptionArea.SetValue(v.description)
-			p.contentArea.SetValue(v.content)
-			p.progressInput.SetValue(fmt.Sprintf("%d", v.progress))
+			p.fill(v)
 			// 聚焦第一个字段
 			return p, p.titleInput.Focus()
 		}
 		return p, nil
 
+	case editConflictMsg:
+		p.submitting = false
+		p.latest = &v.latest
+		p.conflict.Open(v.err, p.conflictFields(v.latest))
+		return p, nil
+
 	case editResultMsg:
 		p.submitting = false
 		if v.success {
@@ -179,6 +200,11 @@ func (p *EditPage) View() string {
 		return components.ErrorState(theme.IconEdit+" 编辑计划", p.err.Error(), cardW)
 	}
 
+	// 版本冲突
+	if p.conflict.Active() {
+		return p.conflict.View(cardW)
+	}
+
 	var body strings.Builder
 
 	// 渲染表单
@@ -226,13 +252,55 @@ func (p *EditPage) loadPlan() tea.Cmd {
 		if err != nil {
 			return editLoadMsg{err: err}
 		}
+		return newEditLoadMsg(plan)
+	}
+}
 
-		return editLoadMsg{
-			title:       plan.Title,
-			description: plan.Description,
-			content:     plan.Content,
-			progress:    plan.Progress,
-		}
+// newEditLoadMsg 将计划转换为表单数据
+func newEditLoadMsg(plan *entity.Plan) editLoadMsg {
+	return editLoadMsg{
+		title:       plan.Title,
+		description: plan.Description,
+		content:     plan.Content,
+		progress:    plan.Progress,
+		version:     plan.Version,
+	}
+}
+
+// fill 用加载的数据填充表单，并记下读取时的版本
+func (p *EditPage) fill(v editLoadMsg) {
+	p.version = v.version
+	p.titleInput.SetValue(v.title)
+	p.descriptionArea.SetValue(v.description)
+	p.contentArea.SetValue(v.content)
+	p.progressInput.SetValue(fmt.Sprintf("%d", v.progress))
+}
+
+// handleConflict 处理版本冲突提示的选择
+func (p *EditPage) handleConflict(key string) tea.Cmd {
+	switch p.conflict.HandleKey(key) {
+	case components.ConflictReload:
+		// 放弃自己的修改，载入最新内容
+		p.conflict.Close()
+		p.fill(*p.latest)
+	case components.ConflictOverwrite:
+		// 以最新版本为基准重新保存自己的修改
+		p.conflict.Close()
+		p.version = p.latest.version
+		return p.submit()
+	case components.ConflictCancel:
+		p.conflict.Close()
+	}
+	return nil
+}
+
+// conflictFields 最新内容与表单内容的对照
+func (p *EditPage) conflictFields(latest editLoadMsg) []components.ConflictField {
+	return []components.ConflictField{
+		{Label: "标题", Theirs: latest.title, Mine: strings.TrimSpace(p.titleInput.Value())},
+		{Label: "描述", Theirs: latest.description, Mine: strings.TrimSpace(p.descriptionArea.Value())},
+		{Label: "详细内容", Theirs: latest.content, Mine: strings.TrimSpace(p.contentArea.Value())},
+		{Label: "进度", Theirs: fmt.Sprintf("%d", latest.progress), Mine: strings.TrimSpace(p.progressInput.Value())},
 	}
 }
 
@@ -297,6 +365,7 @@ func (p *EditPage) submit() tea.Cmd {
 
 	p.submitting = true
 	p.err = nil
+	version := p.version
 
 	return func() tea.Msg {
 		ctx := p.bs.Context()
@@ -315,7 +384,8 @@ func (p *EditPage) submit() tea.Cmd {
 
 		// 构建更新 DTO
 		input := &dto.PlanUpdateDTO{
-			Code: plan.Code,
+			Code:    plan.Code,
+			Version: &version,
 		}
 
 		// 只更新非空字段
@@ -339,6 +409,12 @@ func (p *EditPage) submit() tea.Cmd {
 
 		// 更新计划
 		if err := p.bs.PlanService.UpdatePlan(ctx, input); err != nil {
+			// 打开之后被别人改过：取回最新内容，交给用户决定
+			if errors.Is(err, models.ErrVersionConflict) {
+				if latest, findErr := p.bs.PlanService.GetPlanByID(ctx, p.planID); findErr == nil {
+					return editConflictMsg{latest: newEditLoadMsg(latest), err: err}
+				}
+			}
 			return editResultMsg{success: false, err: err}
 		}
 
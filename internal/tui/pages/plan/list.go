@@ -385,7 +385,7 @@ func (p *ListPage) View() string {
 			}
 		}
 		return components.ConfirmDialogWithButtons("确认删除",
-			fmt.Sprintf("确定要删除计划「%s」吗？\n此操作不可撤销。", itemName),
+			fmt.Sprintf("确定要删除计划「%s」吗？\n可在回收站中恢复。", itemName),
 			cardW, p.deleteYesActive)
 	}
 
@@ -401,7 +401,7 @@ func (p *ListPage) View() string {
 			}
 		}
 		return components.ConfirmDialogWithButtons("确认删除待办",
-			fmt.Sprintf("确定要删除待办「%s」吗？\n此操作不可撤销。", todoTitle),
+			fmt.Sprintf("确定要删除待办「%s」吗？\n可在回收站中恢复。", todoTitle),
 			cardW, p.todoYesActive)
 	}
 
@@ -1,10 +1,13 @@
 package todo
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/XiaoLFeng/llm-memory/internal/models"
 	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
 	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
 	"github.com/XiaoLFeng/llm-memory/internal/tui/components"
@@ -23,6 +26,12 @@ type editLoadMsg struct {
 
 type editSuccessMsg struct{}
 
+// editConflictMsg 保存时版本冲突，携带数据库中的最新内容
+type editConflictMsg struct {
+	latest *entity.ToDo
+	err    error
+}
+
 type EditPage struct {
 	bs     *startup.Bootstrap
 	frame  *layout.Frame
@@ -49,6 +58,10 @@ type EditPage struct {
 
 	submitting bool
 	err        error
+
+	// 乐观锁：p.todo.Version 为打开页面时读取的版本，冲突时保存最新内容供重新加载/覆盖
+	latest   *entity.ToDo
+	conflict *components.ConflictPrompt
 }
 
 func NewEditPage(bs *startup.Bootstrap, todoID int64, pop func(core.PageID) tea.Cmd) *EditPage {
@@ -87,6 +100,7 @@ func NewEditPage(bs *startup.Bootstrap, todoID int64, pop func(core.PageID) tea.
 		dueDateInput:     dueDateInput,
 		tagsInput:        tagsInput,
 		maxFocus:         5, // 6个字段，索引0-5
+		conflict:         components.NewConflictPrompt(),
 	}
 }
 
@@ -138,6 +152,9 @@ func (p *EditPage) Update(msg tea.Msg) (core.Page, tea.Cmd) {
 		if p.loading || p.submitting {
 			return p, nil
 		}
+		if p.conflict.Active() {
+			return p, p.handleConflict(v.String())
+		}
 
 		switch v.String() {
 		case "ctrl+s":
@@ -154,6 +171,12 @@ func (p *EditPage) Update(msg tea.Msg) (core.Page, tea.Cmd) {
 			return p, p.updateFocused(msg)
 		}
 
+	case editConflictMsg:
+		p.submitting = false
+		p.latest = v.latest
+		p.conflict.Open(v.err, p.conflictFields(v.latest))
+		return p, nil
+
 	case editSuccessMsg:
 		// 更新成功，返回计划列表（Todo 现在是 Plan 的子项）
 		if p.pop != nil {
@@ -180,6 +203,11 @@ func (p *EditPage) View() string {
 		return components.LoadingState(theme.IconTodo+" 编辑待办", "正在保存...", cardW)
 	}
 
+	// 版本冲突
+	if p.conflict.Active() {
+		return p.conflict.View(cardW)
+	}
+
 	if p.err != nil {
 		errCard := lipgloss.JoinVertical(lipgloss.Left,
 			theme.FormError.Render("错误: "+p.err.Error()),
@@ -238,20 +266,60 @@ func (p *EditPage) populateForm() {
 	p.statusSelect.SetSelectedIndex(int(p.todo.Status))
 
 	// 填充截止日期
-	if p.todo.DueDate != nil {
-		p.dueDateInput.SetValue(p.todo.DueDate.Format("2006-01-02"))
-	}
+	p.dueDateInput.SetValue(formatDueDate(p.todo.DueDate))
 
 	// 填充标签
-	if len(p.todo.Tags) > 0 {
-		tags := make([]string, len(p.todo.Tags))
-		for i, tag := range p.todo.Tags {
-			tags[i] = tag.Tag
-		}
-		p.tagsInput.SetValue(strings.Join(tags, ", "))
+	p.tagsInput.SetValue(joinToDoTags(p.todo))
+}
+
+// handleConflict 处理版本冲突提示的选择
+func (p *EditPage) handleConflict(key string) tea.Cmd {
+	switch p.conflict.HandleKey(key) {
+	case components.ConflictReload:
+		// 放弃自己的修改，载入最新内容
+		p.conflict.Close()
+		p.todo = p.latest
+		p.populateForm()
+	case components.ConflictOverwrite:
+		// 以最新版本为基准重新保存自己的修改
+		p.conflict.Close()
+		p.todo.Version = p.latest.Version
+		return p.submit()
+	case components.ConflictCancel:
+		p.conflict.Close()
+	}
+	return nil
+}
+
+// conflictFields 最新内容与表单内容的对照
+func (p *EditPage) conflictFields(latest *entity.ToDo) []components.ConflictField {
+	return []components.ConflictField{
+		{Label: "标题", Theirs: latest.Title, Mine: p.titleInput.Value()},
+		{Label: "描述", Theirs: latest.Description, Mine: p.descriptionInput.Value()},
+		{Label: "优先级", Theirs: strconv.Itoa(int(latest.Priority)), Mine: strconv.Itoa(p.prioritySelect.Value().(int))},
+		{Label: "状态", Theirs: strconv.Itoa(int(latest.Status)), Mine: strconv.Itoa(p.statusSelect.Value().(int))},
+		{Label: "截止日期", Theirs: formatDueDate(latest.DueDate), Mine: strings.TrimSpace(p.dueDateInput.Value())},
+		{Label: "标签", Theirs: joinToDoTags(latest), Mine: strings.TrimSpace(p.tagsInput.Value())},
 	}
 }
 
+// formatDueDate 格式化截止日期（未设置时为空）
+func formatDueDate(dueDate *time.Time) string {
+	if dueDate == nil {
+		return ""
+	}
+	return dueDate.Format("2006-01-02")
+}
+
+// joinToDoTags 标签以逗号分隔
+func joinToDoTags(todo *entity.ToDo) string {
+	tags := make([]string, len(todo.Tags))
+	for i, tag := range todo.Tags {
+		tags[i] = tag.Tag
+	}
+	return strings.Join(tags, ", ")
+}
+
 // nextField 聚焦下一个字段
 func (p *EditPage) nextField() {
 	p.blurCurrent()
@@ -328,6 +396,10 @@ func (p *EditPage) updateFocused(msg tea.Msg) tea.Cmd {
 
 // submit 提交表单
 func (p *EditPage) submit() tea.Cmd {
+	if p.todo == nil {
+		return nil
+	}
+	version := p.todo.Version
 	return func() tea.Msg {
 		p.submitting = true
 		p.err = nil
@@ -386,11 +458,18 @@ func (p *EditPage) submit() tea.Cmd {
 			Status:      &status,
 			DueDate:     dueDate,
 			Tags:        &tags,
+			Version:     &version,
 		}
 
 		// 调用服务更新待办
 		err = p.bs.ToDoService.UpdateToDo(ctx, updateDTO)
 		if err != nil {
+			// 打开之后被别人改过：取回最新内容，交给用户决定
+			if errors.Is(err, models.ErrVersionConflict) {
+				if latest, findErr := p.bs.ToDoService.GetToDoByID(ctx, p.todoID); findErr == nil {
+					return editConflictMsg{latest: latest, err: err}
+				}
+			}
 			p.err = err
 			p.submitting = false
 			return nil
@@ -0,0 +1,243 @@
+package trash
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/models/dto"
+	"github.com/XiaoLFeng/llm-memory/internal/tui/components"
+	"github.com/XiaoLFeng/llm-memory/internal/tui/core"
+	"github.com/XiaoLFeng/llm-memory/internal/tui/layout"
+	"github.com/XiaoLFeng/llm-memory/internal/tui/theme"
+	"github.com/XiaoLFeng/llm-memory/internal/tui/utils"
+	"github.com/XiaoLFeng/llm-memory/startup"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// purgeOlderThan TUI 中清理回收站的默认时长
+const purgeOlderThan = 30 * 24 * time.Hour
+
+type loadMsg struct {
+	items []dto.TrashItemDTO
+	err   error
+}
+
+// restoreResult 恢复结果消息
+type restoreResult struct {
+	item dto.TrashItemDTO
+	err  error
+}
+
+// purgeResult 清理结果消息
+type purgeResult struct {
+	result *dto.TrashPurgeResultDTO
+	err    error
+}
+
+// ListPage 回收站列表页
+// 嘿嘿~ 选中条目按 u 就能恢复啦！💖
+type ListPage struct {
+	bs           *startup.Bootstrap
+	frame        *layout.Frame
+	loading      bool
+	err          error
+	notice       string
+	items        []dto.TrashItemDTO
+	cursor       int
+	confirmPurge bool
+}
+
+func NewListPage(bs *startup.Bootstrap) *ListPage {
+	return &ListPage{
+		bs:      bs,
+		frame:   layout.NewFrame(80, 24),
+		loading: true,
+	}
+}
+
+func (p *ListPage) Init() tea.Cmd { return p.load() }
+
+func (p *ListPage) load() tea.Cmd {
+	return func() tea.Msg {
+		items, err := p.bs.TrashService.ListTrash(p.bs.Context(), p.bs.CurrentScope)
+		return loadMsg{items: items, err: err}
+	}
+}
+
+func (p *ListPage) Resize(w, h int) { p.frame.Resize(w, h) }
+
+func (p *ListPage) Update(msg tea.Msg) (core.Page, tea.Cmd) {
+	switch v := msg.(type) {
+	case tea.KeyMsg:
+		// 清理确认对话框激活状态
+		if p.confirmPurge {
+			switch v.String() {
+			case "y", "Y":
+				p.confirmPurge = false
+				return p, p.doPurge()
+			case "n", "N", "esc":
+				p.confirmPurge = false
+			}
+			return p, nil
+		}
+
+		switch v.String() {
+		case "r":
+			p.loading = true
+			p.err = nil
+			p.notice = ""
+			return p, p.load()
+		case "up", "k":
+			if p.cursor > 0 {
+				p.cursor--
+			}
+		case "down", "j":
+			if p.cursor < len(p.items)-1 {
+				p.cursor++
+			}
+		case "u", "enter":
+			if len(p.items) > 0 {
+				return p, p.doRestore()
+			}
+		case "p":
+			p.confirmPurge = true
+		}
+	case loadMsg:
+		p.loading = false
+		p.err = v.err
+		if v.err == nil {
+			p.items = v.items
+			if p.cursor >= len(p.items) {
+				p.cursor = len(p.items) - 1
+			}
+			if p.cursor < 0 {
+				p.cursor = 0
+			}
+		}
+	case restoreResult:
+		if v.err != nil {
+			p.notice = theme.IconError + " " + v.err.Error()
+			return p, nil
+		}
+		p.notice = fmt.Sprintf("%s 已恢复 %s %s", theme.IconSuccess, v.item.Type, v.item.Code)
+		p.loading = true
+		return p, p.load()
+	case purgeResult:
+		if v.err != nil {
+			p.notice = theme.IconError + " " + v.err.Error()
+			return p, nil
+		}
+		p.notice = fmt.Sprintf("%s 已彻底删除 %d 条", theme.IconSuccess, v.result.Total())
+		p.loading = true
+		return p, p.load()
+	}
+	return p, nil
+}
+
+func (p *ListPage) View() string {
+	cw, _ := p.frame.ContentSize()
+	cardW := layout.FitCardWidth(cw)
+
+	// 清理确认对话框覆盖层
+	if p.confirmPurge {
+		return components.ConfirmDialog(
+			"确认清理",
+			"确定要彻底删除 30 天前移入回收站的条目吗？\n此操作不可撤销。",
+			"[Y] 确认清理  [N/Esc] 取消",
+			cardW,
+		)
+	}
+
+	title := theme.IconTrash + " 回收站"
+	switch {
+	case p.loading:
+		return components.LoadingState(title, "加载回收站中...", cardW)
+	case p.err != nil:
+		return components.ErrorState(title, p.err.Error(), cardW)
+	case len(p.items) == 0:
+		body := "回收站是空的~"
+		if p.notice != "" {
+			body = p.notice + "\n\n" + body
+		}
+		return components.Card(title, body, cardW)
+	default:
+		body := p.renderList(cardW - 6)
+		if p.notice != "" {
+			body = p.notice + "\n\n" + body
+		}
+		return components.Card(title, body, cardW)
+	}
+}
+
+func (p *ListPage) renderList(width int) string {
+	var b strings.Builder
+	max := len(p.items)
+	if max > 20 {
+		max = 20
+	}
+	for i := 0; i < max; i++ {
+		item := p.items[i]
+		line := fmt.Sprintf("[%s] %s · %s · %s", typeLabel(item.Type), item.Code, item.Title, item.DeletedAt.Format("2006-01-02 15:04"))
+		if utils.LipWidth(line) > width {
+			line = utils.Truncate(line, width)
+		}
+		if i == p.cursor {
+			line = lipgloss.NewStyle().Foreground(theme.Info).Render("▶ " + line)
+		} else {
+			line = "  " + line
+		}
+		b.WriteString(line)
+		if i != max-1 {
+			b.WriteRune('\n')
+		}
+	}
+	return b.String()
+}
+
+func (p *ListPage) Meta() core.Meta {
+	return core.Meta{
+		Title:      "回收站",
+		Breadcrumb: "回收站 > 列表",
+		Extra:      fmt.Sprintf("%d 条", len(p.items)),
+		Keys: []components.KeyHint{
+			{Key: "u/Enter", Desc: "恢复"},
+			{Key: "p", Desc: "清理30天前"},
+			{Key: "r", Desc: "刷新"},
+			{Key: "Esc", Desc: "返回"},
+			{Key: "↑/↓", Desc: "移动"},
+		},
+	}
+}
+
+// doRestore 恢复选中条目
+func (p *ListPage) doRestore() tea.Cmd {
+	item := p.items[p.cursor]
+	return func() tea.Msg {
+		err := p.bs.TrashService.RestoreItem(p.bs.Context(), item.Type, item.Code, p.bs.CurrentScope)
+		return restoreResult{item: item, err: err}
+	}
+}
+
+// doPurge 彻底删除 30 天前移入回收站的条目
+func (p *ListPage) doPurge() tea.Cmd {
+	return func() tea.Msg {
+		result, err := p.bs.TrashService.Purge(p.bs.Context(), purgeOlderThan)
+		return purgeResult{result: result, err: err}
+	}
+}
+
+// typeLabel 条目类型的中文标签
+func typeLabel(itemType string) string {
+	switch itemType {
+	case dto.TrashTypeMemory:
+		return "记忆"
+	case dto.TrashTypePlan:
+		return "计划"
+	case dto.TrashTypeTodo:
+		return "待办"
+	default:
+		return itemType
+	}
+}
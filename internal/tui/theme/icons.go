@@ -6,6 +6,7 @@ const (
 	IconPlan   = "🗂"
 	IconTodo   = "✅"
 	IconGroup  = "👥"
+	IconTrash  = "🗑"
 	IconBack   = "↩"
 
 	// 操作图标
@@ -4,10 +4,16 @@ import (
 	"github.com/XiaoLFeng/llm-memory/cmd"
 
 	// 导入子命令包，触发 init() 注册命令
+	_ "github.com/XiaoLFeng/llm-memory/cmd/backup"
+	_ "github.com/XiaoLFeng/llm-memory/cmd/db"
+	_ "github.com/XiaoLFeng/llm-memory/cmd/encrypt"
 	_ "github.com/XiaoLFeng/llm-memory/cmd/group"
 	_ "github.com/XiaoLFeng/llm-memory/cmd/memory"
+	_ "github.com/XiaoLFeng/llm-memory/cmd/path"
 	_ "github.com/XiaoLFeng/llm-memory/cmd/plan"
 	_ "github.com/XiaoLFeng/llm-memory/cmd/todo"
+	_ "github.com/XiaoLFeng/llm-memory/cmd/transfer"
+	_ "github.com/XiaoLFeng/llm-memory/cmd/trash"
 )
 
 // main 是程序的入口点
@@ -26,6 +26,32 @@ func (s Scope) IsValid() bool {
 	}
 }
 
+// ScopeResolution 作用域路径解析模式
+// 决定当前工作目录映射到哪个已登记的路径，再据此查找 PathID 和组
+type ScopeResolution string
+
+// 作用域路径解析模式常量
+const (
+	ScopeResolutionExact    ScopeResolution = "exact"    // 精确匹配当前目录（默认）
+	ScopeResolutionAncestor ScopeResolution = "ancestor" // 当前目录或最近的已登记祖先目录
+	ScopeResolutionGitRoot  ScopeResolution = "git-root" // 所在 git 工作区的根目录
+)
+
+// String 将 ScopeResolution 转换为字符串
+func (r ScopeResolution) String() string {
+	return string(r)
+}
+
+// IsValid 检查解析模式是否有效
+func (r ScopeResolution) IsValid() bool {
+	switch r {
+	case ScopeResolutionExact, ScopeResolutionAncestor, ScopeResolutionGitRoot:
+		return true
+	default:
+		return false
+	}
+}
+
 // GlobalGroupID 全局作用域的特殊 GroupID
 // 值为 0，表示不属于任何特定组，全局可见
 const GlobalGroupID = 0
@@ -34,14 +60,17 @@ const GlobalGroupID = 0
 // 用于在请求链路中传递当前作用域信息
 // 纯关联模式：使用 PathID 代替 Path 字符串进行查询
 type ScopeContext struct {
-	CurrentPath     string  // 当前工作目录
-	PathID          int64   // 当前路径的 PersonalPath ID（0 表示无路径记录）
-	GroupID         int64   // 所属组 ID（0 表示无组）
-	GroupName       string  // 组名称（方便显示）
-	GroupPathIDs    []int64 // 组内所有路径 ID 列表（用于组作用域查询）
-	IncludePersonal bool    // 查询时是否包含 Personal 数据
-	IncludeGroup    bool    // 查询时是否包含 Group 数据
-	IncludeGlobal   bool    // 查询时是否包含 Global 数据
+	CurrentPath     string          // 当前工作目录
+	ScopePath       string          // 按解析模式映射后用于匹配路径和组的目录（精确匹配时等于 CurrentPath）
+	MatchedRule     ScopeResolution // 实际命中的解析规则（祖先目录 / git 根目录未找到时退回 exact）
+	ProjectID       string          // 作用域路径的项目标识（由 git 远程地址生成，不在仓库中时为空）
+	PathID          int64           // 当前路径的 PersonalPath ID（0 表示无路径记录）
+	GroupID         int64           // 所属组 ID（0 表示无组）
+	GroupName       string          // 组名称（方便显示）
+	GroupPathIDs    []int64         // 组内所有路径 ID 列表（用于组作用域查询）
+	IncludePersonal bool            // 查询时是否包含 Personal 数据
+	IncludeGroup    bool            // 查询时是否包含 Group 数据
+	IncludeGlobal   bool            // 查询时是否包含 Global 数据
 }
 
 // NewScopeContext 创建默认的作用域上下文
@@ -49,6 +78,8 @@ type ScopeContext struct {
 func NewScopeContext(currentPath string) *ScopeContext {
 	return &ScopeContext{
 		CurrentPath:     currentPath,
+		ScopePath:       currentPath,
+		MatchedRule:     ScopeResolutionExact,
 		GroupID:         GlobalGroupID,
 		GroupName:       "",
 		IncludePersonal: true,
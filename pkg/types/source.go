@@ -0,0 +1,37 @@
+package types
+
+import "context"
+
+// ChangeSource 数据变更来源
+// 嘿嘿~ 记录是哪个入口改动了数据，方便追溯！🔍
+type ChangeSource string
+
+// 变更来源常量定义
+const (
+	ChangeSourceCLI     ChangeSource = "cli"     // 命令行
+	ChangeSourceMCP     ChangeSource = "mcp"     // MCP 服务
+	ChangeSourceTUI     ChangeSource = "tui"     // 终端界面
+	ChangeSourceImport  ChangeSource = "import"  // 导入（覆盖已有记忆）
+	ChangeSourceUnknown ChangeSource = "unknown" // 未知来源
+)
+
+// String 将 ChangeSource 转换为字符串
+func (s ChangeSource) String() string {
+	return string(s)
+}
+
+// changeSourceKey context 中存放变更来源的键
+type changeSourceKey struct{}
+
+// WithChangeSource 在 context 中标记变更来源
+func WithChangeSource(ctx context.Context, source ChangeSource) context.Context {
+	return context.WithValue(ctx, changeSourceKey{}, source)
+}
+
+// ChangeSourceFromContext 从 context 中读取变更来源（未标记时返回 unknown）
+func ChangeSourceFromContext(ctx context.Context) ChangeSource {
+	if source, ok := ctx.Value(changeSourceKey{}).(ChangeSource); ok && source != "" {
+		return source
+	}
+	return ChangeSourceUnknown
+}
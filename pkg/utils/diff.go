@@ -0,0 +1,80 @@
+package utils
+
+import "strings"
+
+// DiffOp 差异行类型
+type DiffOp byte
+
+// 差异行类型常量
+const (
+	DiffEqual  DiffOp = ' ' // 未变化
+	DiffInsert DiffOp = '+' // 新增
+	DiffDelete DiffOp = '-' // 删除
+)
+
+// DiffLine 差异中的一行
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// String 以 unified diff 风格输出（如 "+新增的行"）
+func (l DiffLine) String() string {
+	return string(l.Op) + l.Text
+}
+
+// DiffLines 逐行比较两段文本，返回差异序列
+// 基于最长公共子序列(LCS)，适合记忆这类中短文本
+func DiffLines(oldText, newText string) []DiffLine {
+	a := splitLines(oldText)
+	b := splitLines(newText)
+
+	// lcs[i][j] 表示 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]DiffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, DiffLine{Op: DiffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, DiffLine{Op: DiffDelete, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, DiffLine{Op: DiffInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, DiffLine{Op: DiffDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, DiffLine{Op: DiffInsert, Text: b[j]})
+	}
+	return lines
+}
+
+// splitLines 按行拆分文本（空文本返回空切片）
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
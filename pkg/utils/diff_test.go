@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	cases := []struct {
+		name     string
+		old, new string
+		want     []string
+	}{
+		{name: "都为空", want: []string{}},
+		{name: "相同", old: "a\nb", new: "a\nb", want: []string{" a", " b"}},
+		{name: "新增", old: "", new: "a\nb", want: []string{"+a", "+b"}},
+		{name: "删除", old: "a\nb", new: "", want: []string{"-a", "-b"}},
+		{name: "修改中间一行", old: "a\nb\nc", new: "a\nx\nc", want: []string{" a", "-b", "+x", " c"}},
+		{name: "CRLF 与 LF 等价", old: "a\r\nb", new: "a\nb", want: []string{" a", " b"}},
+		{name: "插入和删除交错", old: "a\nb\nc\nd", new: "b\nc\ne", want: []string{"-a", " b", " c", "-d", "+e"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lines := DiffLines(tc.old, tc.new)
+			got := make([]string, len(lines))
+			for i, l := range lines {
+				got[i] = l.String()
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("DiffLines = %q，期望 %q", got, tc.want)
+			}
+		})
+	}
+}
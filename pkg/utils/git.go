@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FindGitRoot 从 dir 向上查找 git 工作区根目录
+// .git 可以是目录（普通仓库）或文件（worktree / submodule），找不到时返回 false
+func FindGitRoot(dir string) (string, bool) {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// ProjectIdentity 根据 git 远程地址生成目录的项目标识
+// 同一个仓库在不同机器、不同位置检出时标识相同，如 github.com/xiaolfeng/llm-memory；
+// 仓库子目录在后面加上 #相对路径。不在 git 仓库中或没有远程地址时返回空字符串
+func ProjectIdentity(dir string) string {
+	root, ok := FindGitRoot(dir)
+	if !ok {
+		return ""
+	}
+	identity := NormalizeGitRemote(gitRemoteURL(root))
+	if identity == "" {
+		return ""
+	}
+	if rel, err := filepath.Rel(root, dir); err == nil && rel != "." {
+		identity += "#" + filepath.ToSlash(rel)
+	}
+	return identity
+}
+
+// FirstMissingPath 返回 paths 中第一个在本机已不存在的路径的下标，都存在时返回 -1
+// 用于在同一项目的多个已登记检出中找出已搬走或来自其他机器的旧路径；
+// 无法确定是否存在（如没有权限）时视为存在，避免误认领
+func FirstMissingPath(paths []string) int {
+	for i, path := range paths {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return i
+		}
+	}
+	return -1
+}
+
+// NormalizeGitRemote 把 git 远程地址规范化为 host/owner/repo
+// 同一仓库的 https、ssh 和 scp 风格地址得到相同结果；本地路径等无法识别的地址返回空字符串
+func NormalizeGitRemote(remote string) string {
+	remote = strings.TrimSpace(remote)
+	if remote == "" {
+		return ""
+	}
+
+	var host, path string
+	if strings.Contains(remote, "://") {
+		// https://host/owner/repo.git、ssh://git@host:22/owner/repo.git
+		u, err := url.Parse(remote)
+		if err != nil {
+			return ""
+		}
+		host, path = u.Hostname(), u.Path
+	} else {
+		// scp 风格：git@host:owner/repo.git
+		colon := strings.Index(remote, ":")
+		if colon <= 0 || strings.Contains(remote[:colon], "/") {
+			return ""
+		}
+		host, path = remote[:colon], remote[colon+1:]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+	}
+
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".git")
+	path = strings.Trim(path, "/")
+	if host == "" || path == "" {
+		return ""
+	}
+	return strings.ToLower(host + "/" + path)
+}
+
+// gitRemoteURL 从仓库配置中读取远程地址（优先 origin，否则取第一个远程）
+func gitRemoteURL(root string) string {
+	file, err := os.Open(gitConfigPath(root))
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = file.Close() }()
+
+	var first, origin, remote string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			// [remote "origin"]
+			remote = ""
+			section := strings.Trim(line, "[]")
+			if name, ok := strings.CutPrefix(section, "remote "); ok {
+				remote = strings.Trim(strings.TrimSpace(name), `"`)
+			}
+			continue
+		}
+		if remote == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(key), "url") {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		if first == "" {
+			first = value
+		}
+		if remote == "origin" && origin == "" {
+			origin = value
+		}
+	}
+	if origin != "" {
+		return origin
+	}
+	return first
+}
+
+// gitConfigPath 获取仓库配置文件路径
+// worktree / submodule 的 .git 是指向真实 git 目录的文件，worktree 的配置在公共目录中
+func gitConfigPath(root string) string {
+	gitDir := filepath.Join(root, ".git")
+	info, err := os.Stat(gitDir)
+	if err != nil {
+		return ""
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(gitDir)
+		if err != nil {
+			return ""
+		}
+		target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+		if !ok {
+			return ""
+		}
+		gitDir = resolveGitPath(root, strings.TrimSpace(target))
+		if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+			gitDir = resolveGitPath(gitDir, strings.TrimSpace(string(common)))
+		}
+	}
+	return filepath.Join(gitDir, "config")
+}
+
+// resolveGitPath 把 git 文件中的相对路径解析为绝对路径
+func resolveGitPath(base, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
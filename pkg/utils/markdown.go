@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FrontMatter Markdown 文件开头 --- 包裹的 YAML 元数据
+// 只支持笔记中常见的子集：标量、引号字符串、行内列表 [a, b] 和块列表（- item）
+type FrontMatter map[string]interface{}
+
+// String 获取字符串字段，不存在或为列表时返回空字符串
+func (fm FrontMatter) String(key string) string {
+	if v, ok := fm[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// List 获取列表字段；字符串字段按逗号拆分（兼容 tags: a, b 写法）
+func (fm FrontMatter) List(key string) []string {
+	switch v := fm[key].(type) {
+	case []string:
+		return v
+	case string:
+		var items []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+	return nil
+}
+
+// frontMatterKeyRegex 匹配 key: value 行
+var frontMatterKeyRegex = regexp.MustCompile(`^([A-Za-z_][\w-]*)\s*:\s*(.*)$`)
+
+// headingRegex 匹配 ATX 标题行（# 标题）
+var headingRegex = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*\s*$`)
+
+// ParseFrontMatter 拆分 Markdown 文本的 front matter 和正文
+// 没有 front matter 时返回空的 FrontMatter 和原文
+func ParseFrontMatter(text string) (FrontMatter, string) {
+	fm := FrontMatter{}
+	text = strings.TrimPrefix(text, "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	lines := strings.Split(text, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return fm, text
+	}
+
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if t := strings.TrimSpace(lines[i]); t == "---" || t == "..." {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return fm, text
+	}
+
+	listKey := ""
+	for _, line := range lines[1:end] {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		// 块列表项
+		if listKey != "" && strings.HasPrefix(trimmed, "- ") {
+			fm[listKey] = append(fm[listKey].([]string), parseYAMLScalar(strings.TrimSpace(trimmed[2:])))
+			continue
+		}
+		listKey = ""
+
+		match := frontMatterKeyRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		key, value := strings.ToLower(match[1]), strings.TrimSpace(match[2])
+		switch {
+		case value == "":
+			// 后续可能是块列表
+			listKey = key
+			fm[key] = []string{}
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			items := []string{}
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = parseYAMLScalar(strings.TrimSpace(item)); item != "" {
+					items = append(items, item)
+				}
+			}
+			fm[key] = items
+		default:
+			fm[key] = parseYAMLScalar(value)
+		}
+	}
+
+	return fm, strings.Join(lines[end+1:], "\n")
+}
+
+// parseYAMLScalar 解析标量：去掉引号和行尾注释
+func parseYAMLScalar(value string) string {
+	if len(value) >= 2 {
+		switch {
+		case value[0] == '"' && value[len(value)-1] == '"':
+			if s, err := strconv.Unquote(value); err == nil {
+				return s
+			}
+			return value[1 : len(value)-1]
+		case value[0] == '\'' && value[len(value)-1] == '\'':
+			return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		}
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
+
+// FirstHeading 返回正文中第一个标题的文本，没有时返回空字符串
+// 代码块中的 # 行不计入
+func FirstHeading(body string) string {
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if match := headingRegex.FindStringSubmatch(trimmed); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// Slugify 将文本转换为小写字母、数字和连字符组成的 slug
+// 非 ASCII 字符会被丢弃，调用方需要处理返回空字符串的情况
+func Slugify(text string) string {
+	var b strings.Builder
+	lastDash := true
+	for _, r := range strings.ToLower(text) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			lastDash = false
+			continue
+		}
+		if !lastDash {
+			b.WriteByte('-')
+			lastDash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
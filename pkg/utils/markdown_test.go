@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	cases := []struct {
+		name     string
+		text     string
+		wantFM   FrontMatter
+		wantBody string
+	}{
+		{name: "没有 front matter", text: "# 标题\n正文", wantFM: FrontMatter{}, wantBody: "# 标题\n正文"},
+		{name: "未闭合时视为正文", text: "---\ntitle: x\n正文", wantFM: FrontMatter{}, wantBody: "---\ntitle: x\n正文"},
+		{
+			name:     "标量与引号",
+			text:     "---\ntitle: \"a: b\"\nauthor: 'it''s'\ncategory: 架构 # 注释\n# 整行注释\n---\n正文",
+			wantFM:   FrontMatter{"title": "a: b", "author": "it's", "category": "架构"},
+			wantBody: "正文",
+		},
+		{
+			name:     "行内列表和块列表",
+			text:     "---\ntags: [a, \"b\", ]\nAliases:\n  - x\n  - 'y'\n---\n正文",
+			wantFM:   FrontMatter{"tags": []string{"a", "b"}, "aliases": []string{"x", "y"}},
+			wantBody: "正文",
+		},
+		{name: "BOM 和 CRLF", text: "\ufeff---\r\ntitle: x\r\n...\r\n正文", wantFM: FrontMatter{"title": "x"}, wantBody: "正文"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fm, body := ParseFrontMatter(tc.text)
+			if !reflect.DeepEqual(fm, tc.wantFM) || body != tc.wantBody {
+				t.Fatalf("ParseFrontMatter = %v, %q，期望 %v, %q", fm, body, tc.wantFM, tc.wantBody)
+			}
+		})
+	}
+}
+
+func TestFrontMatterList(t *testing.T) {
+	fm := FrontMatter{"list": []string{"a"}, "csv": "a, b,,c", "empty": ""}
+	cases := map[string][]string{"list": {"a"}, "csv": {"a", "b", "c"}, "empty": nil, "missing": nil}
+	for key, want := range cases {
+		if got := fm.List(key); !reflect.DeepEqual(got, want) {
+			t.Fatalf("List(%q) = %q，期望 %q", key, got, want)
+		}
+	}
+}
+
+func TestFirstHeading(t *testing.T) {
+	cases := []struct {
+		name, body, want string
+	}{
+		{name: "没有标题", body: "正文", want: ""},
+		{name: "去掉结尾的 #", body: "正文\n## 二级标题 ##\n# 一级", want: "二级标题"},
+		{name: "跳过代码块", body: "```sh\n# 注释\n```\n# 真正的标题", want: "真正的标题"},
+		{name: "# 后需要空格", body: "#tag\n# 标题", want: "标题"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := FirstHeading(tc.body); got != tc.want {
+				t.Fatalf("FirstHeading = %q，期望 %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"0001 Use Go!": "0001-use-go",
+		"--a__b--":     "a-b",
+		"设计笔记":         "",
+		"WAL 模式 v2":    "wal-v2",
+	}
+	for text, want := range cases {
+		if got := Slugify(text); got != want {
+			t.Fatalf("Slugify(%q) = %q，期望 %q", text, got, want)
+		}
+	}
+}
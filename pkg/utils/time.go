@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -131,3 +134,33 @@ func DaysBetween(start, end time.Time) int {
 	duration := end.Sub(start)
 	return int(duration.Hours() / 24)
 }
+
+// ParseDurationWithDays 解析时长字符串，在 time.ParseDuration 基础上支持天（d）单位
+// 参数: s - 时长字符串，如 "30d"、"12h"、"1d12h"、"0"
+// 返回: 解析后的时长
+func ParseDurationWithDays(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("时长不能为空")
+	}
+	orig := s
+
+	var days time.Duration
+	if idx := strings.Index(s, "d"); idx >= 0 {
+		n, err := strconv.Atoi(s[:idx])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("无效的时长: %s", orig)
+		}
+		days = time.Duration(n) * 24 * time.Hour
+		s = s[idx+1:]
+		if s == "" {
+			return days, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("无效的时长: %s", orig)
+	}
+	return days + d, nil
+}
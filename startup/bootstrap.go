@@ -5,11 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/XiaoLFeng/llm-memory/internal/app"
 	"github.com/XiaoLFeng/llm-memory/internal/database"
+	"github.com/XiaoLFeng/llm-memory/internal/embedding"
 	"github.com/XiaoLFeng/llm-memory/internal/models"
-	"github.com/XiaoLFeng/llm-memory/internal/models/entity"
 	"github.com/XiaoLFeng/llm-memory/internal/service"
 	"github.com/XiaoLFeng/llm-memory/pkg/types"
 	"gorm.io/gorm"
@@ -33,13 +36,18 @@ type Bootstrap struct {
 	options *Options
 
 	// 数据库
-	db *gorm.DB
+	db        *gorm.DB
+	nodeLease *database.NodeLease // 雪花节点租约
+	dbClosed  bool                // 数据库连接已提前关闭（恢复备份）
 
 	// Service 层（公开，供外部使用）
-	MemoryService *service.MemoryService
-	PlanService   *service.PlanService
-	ToDoService   *service.ToDoService  // 注意：类型名使用 ToDo
-	GroupService  *service.GroupService // 组服务
+	MemoryService   *service.MemoryService
+	PlanService     *service.PlanService
+	ToDoService     *service.ToDoService     // 注意：类型名使用 ToDo
+	GroupService    *service.GroupService    // 组服务
+	TrashService    *service.TrashService    // 回收站服务
+	TransferService *service.TransferService // 导入导出服务
+	PathService     *service.PathService     // 路径维护服务
 
 	// 当前作用域上下文
 	// 嘿嘿~ 启动时自动解析当前目录的作用域！✨
@@ -50,12 +58,16 @@ type Bootstrap struct {
 
 	// 状态
 	initialized bool
+	shutdownMu  sync.Mutex // 信号处理与调用方可能同时关闭，串行执行
 }
 
 // New 创建新的 Bootstrap 实例
 // 呀~ 只是创建实例，还没有初始化哦！✨
 func New(opts ...Option) *Bootstrap {
 	options := DefaultOptions()
+	for _, opt := range globalOptions {
+		opt(options)
+	}
 	for _, opt := range opts {
 		opt(options)
 	}
@@ -67,20 +79,20 @@ func New(opts ...Option) *Bootstrap {
 
 // Initialize 初始化应用
 // 嘿嘿~ 按照正确的顺序初始化所有组件！💫
-// 顺序：Snowflake -> Context -> Config -> Database -> Model -> Service
+// 顺序：Snowflake -> Context -> Config -> Encryption -> Database -> Migration -> Backup -> Model -> Service
 func (b *Bootstrap) Initialize(ctx context.Context) error {
 	if b.initialized {
 		return ErrAlreadyInitialized
 	}
 
 	// 0. 初始化雪花算法
-	// 嘿嘿~ 节点 ID 基于机器 MAC 地址或 hostname 自动生成！✨
+	// 嘿嘿~ 节点 ID 先基于机器 MAC 地址或 hostname 生成，连接数据库后再租用独立节点！✨
 	if err := database.InitSnowflake(); err != nil {
 		return fmt.Errorf("初始化雪花算法失败: %w", err)
 	}
 
-	// 1. 创建应用级 Context
-	b.appCtx = NewAppContext(ctx)
+	// 1. 创建应用级 Context（携带变更来源）
+	b.appCtx = NewAppContext(types.WithChangeSource(ctx, b.options.ChangeSource))
 
 	// 2. 加载配置
 	config, err := b.loadConfig()
@@ -89,60 +101,87 @@ func (b *Bootstrap) Initialize(ctx context.Context) error {
 	}
 	b.config = config
 
+	// 加载静态加密密钥（未启用时为 nil，按明文读写）
+	// 呀~ 必须在迁移之前设置，迁移中重建索引时也需要解密内容！🔐
+	contentCipher, err := config.Encryption.LoadCipher()
+	if err != nil {
+		return fmt.Errorf("加载加密密钥失败: %w", err)
+	}
+	database.SetContentCipher(contentCipher)
+
 	// 3. 初始化 GORM 数据库
-	// 嘿嘿~ 使用 SQLite + WAL 模式支持并发读写！💖
-	gormDB, err := database.OpenSQLite(&database.SQLiteConfig{
-		DBPath: config.DBPath,
-		Debug:  config.Debug,
-	})
+	// 嘿嘿~ 默认使用 SQLite + WAL 模式，配置 driver=postgres 时连接共享的 PostgreSQL！💖
+	gormDB, err := b.openDatabase(config)
 	if err != nil {
 		return fmt.Errorf("初始化数据库失败: %w", err)
 	}
 	b.db = gormDB
 
-	// 4. 自动迁移表结构
-	// 呀~ 确保数据库表结构是最新的！✨
-	if err := database.AutoMigrateSQLite(gormDB,
-		&entity.Memory{},
-		&entity.MemoryTag{},
-		&entity.Plan{},
-		&entity.ToDo{},
-		&entity.ToDoTag{},
-		&entity.Group{},
-		&entity.GroupPath{},
-		&entity.PersonalPath{},
-	); err != nil {
-		return fmt.Errorf("迁移数据库表结构失败: %w", err)
+	// 4. 执行版本化迁移
+	// 呀~ 数据库版本比程序新时拒绝启动，避免破坏数据！✨
+	if b.options.AutoMigrate {
+		migrator := database.NewMigrator(gormDB)
+		if err := migrator.CheckCompatibility(b.appCtx.Context()); err != nil {
+			return err
+		}
+		if _, err := migrator.Migrate(b.appCtx.Context()); err != nil {
+			return fmt.Errorf("迁移数据库表结构失败: %w", err)
+		}
+	}
+
+	// 租用独立的雪花节点
+	// 呀~ MCP 服务、TUI 和 CLI 各自使用不同的节点，并发写入也不会生成重复 ID！🪪
+	// 不自动迁移时（db 维护命令）租约表可能还不存在，继续使用默认节点
+	if gormDB.Migrator().HasTable(&database.SnowflakeNodeLease{}) {
+		lease, err := database.UseNodeLease(b.appCtx.Context(), gormDB)
+		if err != nil {
+			return fmt.Errorf("租用雪花节点失败: %w", err)
+		}
+		b.nodeLease = lease
+	}
+
+	// 5. 每日自动备份（可选，仅 SQLite）
+	// 嘿嘿~ 每天第一次启动时生成一份快照，失败不影响启动！💾
+	// 不自动迁移时（db 维护命令）数据库可能还未迁移或迁移到一半，不做备份
+	if b.options.AutoMigrate && config.Backup.AutoDaily && !database.IsPostgres(gormDB) {
+		b.autoBackup()
 	}
 
 	// 6. 创建 Model 实例
 	memoryModel := models.NewMemoryModel(gormDB)
+	memoryRevisionModel := models.NewMemoryRevisionModel(gormDB)
+	memoryEmbeddingModel := models.NewMemoryEmbeddingModel(gormDB)
 	planModel := models.NewPlanModel(gormDB)
 	todoModel := models.NewToDoModel(gormDB)
 	groupModel := models.NewGroupModel(gormDB)
 	personalPathModel := models.NewPersonalPathModel(gormDB)
+	personalPathModel.SetClaimMoved(config.Scope.ClaimMoved)
 
-	// 7. 初始化当前路径到 personal_paths
-	// 嘿嘿~ 启动时自动注册当前工作目录！💖
-	pwd, err := os.Getwd()
-	if err == nil && pwd != "" {
-		_, _ = personalPathModel.EnsurePath(b.appCtx.Context(), pwd)
+	// 7. 创建 Service 实例
+	// 呀~ 语义搜索的向量嵌入器由配置决定，默认离线哈希嵌入！✨
+	embedder, err := embedding.New(config.Embedding)
+	if err != nil {
+		return fmt.Errorf("初始化向量嵌入器失败: %w", err)
 	}
-
-	// 8. 创建 Service 实例
-	b.MemoryService = service.NewMemoryService(memoryModel)
+	resolution, err := config.ScopeResolution()
+	if err != nil {
+		return err
+	}
+	b.MemoryService = service.NewMemoryService(memoryModel, memoryRevisionModel, memoryEmbeddingModel, embedder)
 	b.PlanService = service.NewPlanService(planModel)
 	b.ToDoService = service.NewToDoService(todoModel, planModel)
-	b.GroupService = service.NewGroupService(groupModel)
+	b.GroupService = service.NewGroupService(groupModel, resolution)
+	b.TrashService = service.NewTrashService(memoryModel, planModel, todoModel)
+	b.TransferService = service.NewTransferService(models.NewTransferModel(gormDB))
+	b.PathService = service.NewPathService(personalPathModel)
 
+	// 8. 初始化当前路径到 personal_paths
 	// 9. 解析当前作用域
-	// 嘿嘿~ 启动时自动获取当前目录的作用域上下文！💖
-	scope, err := b.GroupService.GetCurrentScope(b.appCtx.Context())
-	if err != nil {
-		// 如果解析失败，使用仅包含 Global 的作用域
-		scope = types.NewGlobalOnlyScope()
+	// 呀~ db 维护命令（不自动迁移）不能写入正在检查的数据库，表结构也可能不完整，只使用全局作用域！
+	b.CurrentScope = types.NewGlobalOnlyScope()
+	if b.options.AutoMigrate {
+		b.CurrentScope = b.resolveCurrentScope(personalPathModel)
 	}
-	b.CurrentScope = scope
 
 	// 10. 启动信号处理
 	if b.options.EnableSignalHandler {
@@ -157,10 +196,70 @@ func (b *Bootstrap) Initialize(ctx context.Context) error {
 	return nil
 }
 
+// resolveCurrentScope 注册当前工作目录并解析当前作用域
+// 嘿嘿~ 启动时自动注册当前工作目录（按解析模式映射，如 git-root 模式下注册仓库根目录）！💖
+// 解析失败时使用仅包含 Global 的作用域
+func (b *Bootstrap) resolveCurrentScope(personalPathModel *models.PersonalPathModel) *types.ScopeContext {
+	ctx := b.appCtx.Context()
+	pwd, err := os.Getwd()
+	if err == nil && pwd != "" {
+		scopePath, _ := b.GroupService.ResolveScopePath(ctx, pwd)
+		_, _ = personalPathModel.EnsurePath(ctx, scopePath)
+	}
+
+	scope, err := b.GroupService.GetCurrentScope(ctx)
+	if err != nil {
+		return types.NewGlobalOnlyScope()
+	}
+	return scope
+}
+
+// openDatabase 按配置的驱动打开数据库连接
+func (b *Bootstrap) openDatabase(config *app.Config) (*gorm.DB, error) {
+	driver, err := config.DatabaseDriver()
+	if err != nil {
+		return nil, err
+	}
+	if driver == database.DriverPostgres {
+		return database.OpenPostgres(&database.PostgresConfig{
+			DSN:   config.PostgresDSN(),
+			Debug: config.Debug,
+		})
+	}
+	return database.OpenSQLite(&database.SQLiteConfig{
+		DBPath: config.DBPath,
+		Debug:  config.Debug,
+	})
+}
+
+// autoBackup 当天还没有备份时生成一份并轮转
+// 注意：MCP 通过标准输出通信，提示信息只能写到标准错误
+func (b *Bootstrap) autoBackup() {
+	dir := b.config.BackupDir()
+	now := time.Now()
+	exists, err := database.HasBackupOnDay(dir, now)
+	if err != nil || exists {
+		return
+	}
+
+	dest := filepath.Join(dir, database.BackupFileName(now))
+	if err := database.BackupSQLite(b.appCtx.Context(), b.db, dest); err != nil {
+		fmt.Fprintf(os.Stderr, "自动备份失败: %v\n", err)
+		return
+	}
+	if _, err := database.RotateBackups(dir, b.config.Backup.Keep); err != nil {
+		fmt.Fprintf(os.Stderr, "轮转备份失败: %v\n", err)
+	}
+}
+
 // loadConfig 加载配置
+// 呀~ 按选项选择配置文件、profile 和数据库路径！✨
 func (b *Bootstrap) loadConfig() (*app.Config, error) {
-	// TODO: 支持从指定路径加载配置
-	return app.LoadConfig()
+	return app.LoadConfigWith(app.LoadOptions{
+		ConfigPath: b.options.ConfigPath,
+		Profile:    b.options.Profile,
+		DBPath:     b.options.DBPath,
+	})
 }
 
 // Context 获取应用级 Context
@@ -191,6 +290,8 @@ func (b *Bootstrap) DB() *gorm.DB {
 // Shutdown 优雅关闭
 // 嘿嘿~ 按照逆序关闭所有组件！✨
 func (b *Bootstrap) Shutdown() error {
+	b.shutdownMu.Lock()
+	defer b.shutdownMu.Unlock()
 	if !b.initialized {
 		return ErrNotInitialized
 	}
@@ -207,15 +308,53 @@ func (b *Bootstrap) Shutdown() error {
 		}
 	}
 
-	// 关闭数据库连接
-	if err := database.CloseSQLite(); err != nil {
-		fmt.Printf("关闭数据库连接失败: %v\n", err)
+	if err := b.closeDatabase(); err != nil {
+		fmt.Println(err)
 	}
 
 	b.initialized = false
 	return nil
 }
 
+// CloseDatabase 提前释放雪花节点租约并关闭数据库连接
+// 呀~ 恢复备份要替换数据库文件，必须先放开连接！之后 Shutdown 不会重复关闭
+func (b *Bootstrap) CloseDatabase() error {
+	b.shutdownMu.Lock()
+	defer b.shutdownMu.Unlock()
+	return b.closeDatabase()
+}
+
+// closeDatabase 释放雪花节点租约（需要在关闭数据库之前）并关闭数据库连接（调用方需持有 shutdownMu）
+func (b *Bootstrap) closeDatabase() error {
+	if b.dbClosed {
+		return nil
+	}
+	var errs []error
+	if b.nodeLease != nil {
+		if err := b.nodeLease.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("释放雪花节点失败: %w", err))
+		}
+		b.nodeLease = nil
+	}
+	if err := database.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("关闭数据库连接失败: %w", err))
+	}
+	b.dbClosed = true
+	return errors.Join(errs...)
+}
+
+// NodeLease 获取当前进程持有的雪花节点租约（数据库还没有租约表时为 nil）
+func (b *Bootstrap) NodeLease() *database.NodeLease {
+	return b.nodeLease
+}
+
+// Exit 关闭应用后以 code 退出进程
+// 呀~ os.Exit 不会执行 defer，命令出错直接退出会留下雪花节点租约，要用它代替！
+func (b *Bootstrap) Exit(code int) {
+	_ = b.Shutdown()
+	os.Exit(code)
+}
+
 // MustInitialize 初始化应用（失败时退出）
 // 嘿嘿~ 简化启动代码，失败直接退出！💫
 func (b *Bootstrap) MustInitialize(ctx context.Context) *Bootstrap {
@@ -1,6 +1,12 @@
 package startup
 
-import "time"
+import (
+	"os"
+	"time"
+
+	"github.com/XiaoLFeng/llm-memory/internal/app"
+	"github.com/XiaoLFeng/llm-memory/pkg/types"
+)
 
 // Options 启动选项
 // 呀~ 配置启动行为的各种选项！✨
@@ -8,6 +14,12 @@ type Options struct {
 	// ConfigPath 配置文件路径（可选，为空时使用默认路径）
 	ConfigPath string
 
+	// Profile 使用的配置档名称（可选，为空时使用顶层配置）
+	Profile string
+
+	// DBPath 覆盖数据库文件路径（可选）
+	DBPath string
+
 	// ShutdownTimeout 优雅关闭超时时间
 	ShutdownTimeout time.Duration
 
@@ -16,16 +28,36 @@ type Options struct {
 
 	// Debug 调试模式
 	Debug bool
+
+	// AutoMigrate 启动时是否自动执行数据库迁移
+	AutoMigrate bool
+
+	// ChangeSource 当前入口（写入应用 Context，用于记录数据变更来源）
+	ChangeSource types.ChangeSource
+}
+
+// globalOptions 全局选项（根命令的 --config / --db / --profile）
+// 每次 New 时在默认选项之后、调用方选项之前应用
+var globalOptions []Option
+
+// SetGlobalOptions 设置所有 Bootstrap 共用的全局选项
+func SetGlobalOptions(opts ...Option) {
+	globalOptions = opts
 }
 
 // DefaultOptions 返回默认选项
 // 嘿嘿~ 合理的默认值让使用更简单！(´∀｀)
+// 配置文件、数据库和 profile 默认取自环境变量 LLM_MEMORY_CONFIG / LLM_MEMORY_DB / LLM_MEMORY_PROFILE
 func DefaultOptions() *Options {
 	return &Options{
-		ConfigPath:          "",
+		ConfigPath:          os.Getenv(app.ConfigPathEnv),
+		Profile:             os.Getenv(app.ProfileEnv),
+		DBPath:              os.Getenv(app.DBPathEnv),
 		ShutdownTimeout:     30 * time.Second,
 		EnableSignalHandler: true,
 		Debug:               false,
+		AutoMigrate:         true,
+		ChangeSource:        types.ChangeSourceCLI,
 	}
 }
 
@@ -39,6 +71,20 @@ func WithConfigPath(path string) Option {
 	}
 }
 
+// WithProfile 设置使用的配置档
+func WithProfile(profile string) Option {
+	return func(o *Options) {
+		o.Profile = profile
+	}
+}
+
+// WithDBPath 设置数据库文件路径（覆盖配置文件）
+func WithDBPath(path string) Option {
+	return func(o *Options) {
+		o.DBPath = path
+	}
+}
+
 // WithShutdownTimeout 设置关闭超时时间
 func WithShutdownTimeout(timeout time.Duration) Option {
 	return func(o *Options) {
@@ -59,3 +105,20 @@ func WithDebug(debug bool) Option {
 		o.Debug = debug
 	}
 }
+
+// WithAutoMigrate 启用/禁用启动时自动迁移
+// 呀~ db 子命令需要自己控制迁移，所以要关掉它！
+// 关闭后启动时也不会自动备份、注册当前路径，当前作用域固定为全局
+func WithAutoMigrate(enabled bool) Option {
+	return func(o *Options) {
+		o.AutoMigrate = enabled
+	}
+}
+
+// WithChangeSource 设置当前入口（cli/mcp/tui）
+// 嘿嘿~ 修订历史会记录是谁改动了记忆！✨
+func WithChangeSource(source types.ChangeSource) Option {
+	return func(o *Options) {
+		o.ChangeSource = source
+	}
+}